@@ -6,18 +6,27 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/asr"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/bootstrap"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/handlers"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/middleware"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/router"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/ws"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/kws"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/opus"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/tts"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/vad"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
 	_ "github.com/zhangjun/AeroSpeech-ONNX/docs/swagger" // swagger docs
 )
 
@@ -32,6 +41,7 @@ import (
 // 流式处理接口：
 // - STT流式识别: ws://localhost:8080/ws/stt
 // - TTS流式合成: ws://localhost:8080/ws/tts
+// - 语音对话: ws://localhost:8080/ws/voice
 // 详细文档请参考: docs/03-websocket接口设计.md
 //
 // @termsOfService  http://swagger.io/terms/
@@ -100,6 +110,7 @@ func main() {
 	} else {
 		r = router.NewRouter()
 	}
+	r.SetAuthenticator(deps.Authenticator)
 	r.SetupMiddleware()
 	r.SetupStaticFiles("web/static", "web/templates")
 
@@ -118,26 +129,74 @@ func main() {
 		// 创建STT配置（用于处理器）
 		sttCfg := &config.STTConfig{
 			Server:    cfg.Server,
-			ASR:       *cfg.STT,
+			ASR:       *asrManager.GetModelConfig(),
 			Audio:     cfg.Audio,
 			WebSocket: cfg.WebSocket,
 			Session:   cfg.Session,
+			Batch:     cfg.Batch,
 			Logging:   cfg.Logging,
 		}
 		sttHandler = handlers.NewSTTHandler(asrManager, sttCfg)
+		sttHandler.SetModelLookup(func(name string) (handlers.STTManager, error) {
+			return lookupASRModel(deps, name)
+		})
+		sttHandler.SetLanguageRouter(func(ctx context.Context, language string, audio []byte) (handlers.STTRoute, error) {
+			route, err := deps.ASRRouter.Route(ctx, language, audio)
+			return handlers.STTRoute{Model: route.Model, Language: route.Language}, err
+		})
+		if deps.Hotwords != nil {
+			sttHandler.SetHotwordResolver(deps.Hotwords.Resolve)
+		}
+		if deps.Diarizer != nil {
+			sttHandler.SetDiarizer(func(ctx context.Context, audio []byte, opts handlers.DiarizationOptions) ([]handlers.SpeakerTurn, error) {
+				segments, err := deps.Diarizer.Diarize(ctx, audio, opts.SampleRate, opts.NumSpeakers, opts.Threshold)
+				if err != nil {
+					return nil, err
+				}
+				turns := make([]handlers.SpeakerTurn, len(segments))
+				for i, s := range segments {
+					turns[i] = handlers.SpeakerTurn{Speaker: s.Speaker, Start: s.Start, End: s.End}
+				}
+				return turns, nil
+			})
+		}
+		if deps.Enhancer != nil {
+			sttHandler.SetEnhancer(deps.Enhancer.Enhance, cfg.Enhancement.ApplyByDefault)
+		}
+		if deps.ASRRouter != nil && deps.ASRRouter.CanDetect() {
+			sttHandler.SetLanguageDetector(deps.ASRRouter.Detect)
+		}
 	}
 
 	if ttsManager != nil {
 		// 创建TTS配置（用于处理器）
 		ttsCfg := &config.TTSConfig{
 			Server:    cfg.Server,
-			TTS:       *cfg.TTS,
+			TTS:       *ttsManager.GetModelConfig(),
 			Audio:     cfg.Audio,
 			WebSocket: cfg.WebSocket,
 			Session:   cfg.Session,
 			Logging:   cfg.Logging,
 		}
 		ttsHandler = handlers.NewTTSHandler(ttsManager, ttsCfg)
+		ttsHandler.SetModelLookup(func(name string) (handlers.TTSManager, error) {
+			return lookupTTSModel(deps, name)
+		})
+	}
+
+	var jobsHandler *handlers.JobsHandler
+	if deps.JobManager != nil {
+		jobsHandler = handlers.NewJobsHandler(deps.JobManager, &cfg.Jobs)
+		if deps.ASRModels != nil {
+			jobsHandler.SetSTTModelLookup(func(name string) (handlers.STTManager, error) {
+				return lookupASRModel(deps, name)
+			})
+		}
+		if deps.TTSModels != nil {
+			jobsHandler.SetTTSModelLookup(func(name string) (handlers.TTSManager, error) {
+				return lookupTTSModel(deps, name)
+			})
+		}
 	}
 
 	// 创建WebSocket升级器
@@ -149,6 +208,16 @@ func main() {
 		int64(cfg.WebSocket.MaxMessageSize),
 		cfg.WebSocket.EnableCompression,
 	)
+	if deps.HotReloadMgr != nil {
+		deps.HotReloadMgr.RegisterCallback([]string{"websocket.max_message_size"}, func(newCfg *config.UnifiedConfig) error {
+			if newCfg.WebSocket.MaxMessageSize <= 0 {
+				return fmt.Errorf("websocket.max_message_size must be positive")
+			}
+			upgrader.SetMaxMessageSize(int64(newCfg.WebSocket.MaxMessageSize))
+			logger.Infof("WebSocket max message size updated: %d", newCfg.WebSocket.MaxMessageSize)
+			return nil
+		})
+	}
 
 	// 创建WebSocket处理器
 	var sttWSHandler *ws.STTHandler
@@ -157,25 +226,85 @@ func main() {
 	if asrManager != nil {
 		sttCfg := &config.STTConfig{
 			Server:    cfg.Server,
-			ASR:       *cfg.STT,
+			ASR:       *asrManager.GetModelConfig(),
 			Audio:     cfg.Audio,
 			WebSocket: cfg.WebSocket,
 			Session:   cfg.Session,
 			Logging:   cfg.Logging,
 		}
 		sttWSHandler = ws.NewSTTHandler(sessionManager, asrManager, sttCfg)
+		sttWSHandler.SetModelLookup(func(name string) (ws.ASRManager, error) {
+			return lookupASRModel(deps, name)
+		})
+		sttWSHandler.SetLanguageRouter(func(ctx context.Context, language string, audio []byte) (ws.ASRRoute, error) {
+			route, err := deps.ASRRouter.Route(ctx, language, audio)
+			return ws.ASRRoute{Model: route.Model, Language: route.Language}, err
+		})
+		if deps.Hotwords != nil {
+			sttWSHandler.SetHotwordResolver(deps.Hotwords.Resolve)
+		}
+		if deps.Speakers != nil {
+			threshold := cfg.Speaker.Verification.Threshold
+			sttWSHandler.SetSpeakerIdentifier(func(ctx context.Context, audio []byte) (*ws.SpeakerMatch, error) {
+				embedding, err := deps.SpeakerEmbedder.Embed(ctx, audio)
+				if err != nil {
+					return nil, err
+				}
+				match := deps.Speakers.Identify(embedding)
+				if match == nil || match.Score < threshold {
+					return nil, nil
+				}
+				return &ws.SpeakerMatch{ID: match.Profile.ID, Name: match.Profile.Name, Score: match.Score}, nil
+			})
+		}
+		if deps.Enhancer != nil {
+			sttWSHandler.SetAudioEnhancer(deps.Enhancer.Enhance, cfg.Enhancement.ApplyByDefault)
+		}
+		if deps.VoiceDetector != nil {
+			sttWSHandler.SetVoiceActivityDetector(vadDetector{deps.VoiceDetector})
+		}
+		sttWSHandler.SetOpusDecoder(opusDecoder)
 	}
 
 	if ttsManager != nil {
 		ttsCfg := &config.TTSConfig{
 			Server:    cfg.Server,
-			TTS:       *cfg.TTS,
+			TTS:       *ttsManager.GetModelConfig(),
 			Audio:     cfg.Audio,
 			WebSocket: cfg.WebSocket,
 			Session:   cfg.Session,
 			Logging:   cfg.Logging,
 		}
 		ttsWSHandler = ws.NewTTSHandler(sessionManager, ttsManager, ttsCfg)
+		ttsWSHandler.SetModelLookup(func(name string) (ws.TTSManager, error) {
+			return lookupTTSModel(deps, name)
+		})
+		ttsWSHandler.SetOpusEncoder(opusEncoder)
+	}
+
+	var kwsWSHandler *ws.KWSHandler
+	if deps.KeywordSpotter != nil {
+		kwsCfg := &config.STTConfig{
+			Server:    cfg.Server,
+			Audio:     cfg.Audio,
+			WebSocket: cfg.WebSocket,
+			Session:   cfg.Session,
+			Logging:   cfg.Logging,
+		}
+		kwsWSHandler = ws.NewKWSHandler(sessionManager, kwsSpotter{deps.KeywordSpotter}, kwsCfg)
+	}
+
+	var voiceWSHandler *ws.VoiceHandler
+	if deps.VoiceDetector != nil && asrManager != nil && ttsManager != nil {
+		voiceCfg := &config.STTConfig{
+			Server:    cfg.Server,
+			Audio:     cfg.Audio,
+			WebSocket: cfg.WebSocket,
+			Session:   cfg.Session,
+			Logging:   cfg.Logging,
+		}
+		dialog := ws.NewWebhookDialog(cfg.Voice.DialogURL, time.Duration(cfg.Voice.DialogTimeout)*time.Second)
+		voiceWSHandler = ws.NewVoiceHandler(sessionManager, vadDetector{deps.VoiceDetector}, asrManager, ttsManager, dialog, voiceCfg)
 	}
 
 	// 设置路由
@@ -186,35 +315,89 @@ func main() {
 			// 健康检查
 			providerInfo := &handlers.ProviderInfo{}
 			if asrManager != nil {
-				providerInfo.ASR = cfg.STT.Provider.Provider
-				providerInfo.GPUAvailable = cfg.STT.Provider.Provider == "cuda"
-				providerInfo.GPUDeviceID = cfg.STT.Provider.DeviceID
+				sttProvider := asrManager.GetModelConfig().Provider
+				providerInfo.ASR = sttProvider.Provider
+				providerInfo.GPUAvailable = sttProvider.Provider == "cuda"
+				providerInfo.GPUDeviceID = sttProvider.DeviceID
 			}
 			if ttsManager != nil {
-				providerInfo.TTS = cfg.TTS.Provider.Provider
+				ttsProvider := ttsManager.GetModelConfig().Provider
+				providerInfo.TTS = ttsProvider.Provider
 				if !providerInfo.GPUAvailable {
-					providerInfo.GPUAvailable = cfg.TTS.Provider.Provider == "cuda"
+					providerInfo.GPUAvailable = ttsProvider.Provider == "cuda"
 				}
 				if providerInfo.GPUDeviceID == 0 {
-					providerInfo.GPUDeviceID = cfg.TTS.Provider.DeviceID
+					providerInfo.GPUDeviceID = ttsProvider.DeviceID
 				}
 			}
 			api.GET("/health", handlers.HealthHandler(nil, providerInfo))
 
 			// STT API
 			if sttHandler != nil {
-				stt := api.Group("/stt")
+				stt := api.Group("/stt", r.RequireScope(middleware.ScopeSTT))
 				{
 					stt.POST("/recognize", sttHandler.Recognize)
 					stt.POST("/batch", sttHandler.BatchRecognize)
 					stt.GET("/config", sttHandler.GetConfig)
 					stt.GET("/stats", sttHandler.GetStats)
+					if deps.ASRRouter != nil && deps.ASRRouter.CanDetect() {
+						stt.POST("/language", sttHandler.DetectLanguage)
+					}
+				}
+			}
+
+			// 命名热词集API
+			if deps.Hotwords != nil {
+				hotwordsHandler := handlers.NewHotwordsHandler(deps.Hotwords)
+				hotwordsAPI := api.Group("/hotwords")
+				{
+					hotwordsAPI.GET("", r.RequireScope(middleware.ScopeSTT), hotwordsHandler.List)
+					hotwordsAPI.GET("/:name", r.RequireScope(middleware.ScopeSTT), hotwordsHandler.Get)
+					hotwordsAPI.PUT("/:name", r.RequireScope(middleware.ScopeAdmin), hotwordsHandler.Put)
+					hotwordsAPI.DELETE("/:name", r.RequireScope(middleware.ScopeAdmin), hotwordsHandler.Delete)
+				}
+			}
+
+			// 说话人注册、验证和辨认API
+			if deps.Speakers != nil {
+				speakersHandler := handlers.NewSpeakersHandler(deps.Speakers, deps.SpeakerEmbedder.Embed, cfg.Speaker.Verification.Threshold)
+				speakersAPI := api.Group("/speakers")
+				{
+					speakersAPI.GET("", r.RequireScope(middleware.ScopeSTT), speakersHandler.List)
+					speakersAPI.POST("/identify", r.RequireScope(middleware.ScopeSTT), speakersHandler.Identify)
+					speakersAPI.GET("/:id", r.RequireScope(middleware.ScopeSTT), speakersHandler.Get)
+					speakersAPI.POST("/:id/verify", r.RequireScope(middleware.ScopeSTT), speakersHandler.Verify)
+					speakersAPI.POST("/:id/enroll", r.RequireScope(middleware.ScopeAdmin), speakersHandler.Enroll)
+					speakersAPI.PATCH("/:id", r.RequireScope(middleware.ScopeAdmin), speakersHandler.Update)
+					speakersAPI.DELETE("/:id", r.RequireScope(middleware.ScopeAdmin), speakersHandler.Delete)
+				}
+			}
+
+			// 音频分析与处理API
+			if deps.AudioTagger != nil || deps.Enhancer != nil {
+				var tagger handlers.AudioTagger
+				var enhancer handlers.AudioEnhancer
+				if deps.AudioTagger != nil {
+					tagger = deps.AudioTagger.Tag
+				}
+				if deps.Enhancer != nil {
+					enhancer = deps.Enhancer.Enhance
+				}
+				audioHandler := handlers.NewAudioHandler(tagger, enhancer)
+				audioAPI := api.Group("/audio", r.RequireScope(middleware.ScopeSTT))
+				{
+					if tagger != nil {
+						audioAPI.POST("/tag", audioHandler.Tag)
+					}
+					if enhancer != nil {
+						audioAPI.POST("/enhance", audioHandler.Enhance)
+					}
 				}
 			}
 
 			// TTS API
 			if ttsHandler != nil {
-				ttsAPI := api.Group("/tts")
+				ttsAPI := api.Group("/tts", r.RequireScope(middleware.ScopeTTS))
 				{
 					ttsAPI.POST("/synthesize", ttsHandler.Synthesize)
 					ttsAPI.POST("/batch", ttsHandler.BatchSynthesize)
@@ -224,13 +407,53 @@ func main() {
 				}
 			}
 
+			// 异步任务API
+			if jobsHandler != nil {
+				jobsAPI := api.Group("/jobs")
+				{
+					if sttHandler != nil {
+						jobsAPI.POST("/stt", r.RequireScope(middleware.ScopeSTT), jobsHandler.SubmitSTT)
+					}
+					if ttsHandler != nil {
+						jobsAPI.POST("/tts", r.RequireScope(middleware.ScopeTTS), jobsHandler.SubmitTTS)
+					}
+					jobsAPI.GET("/stats", r.RequireScope(middleware.ScopeAdmin), jobsHandler.GetStats)
+					jobsAPI.GET("/:id", jobsHandler.GetJob)
+					jobsAPI.GET("/:id/audio", jobsHandler.GetJobAudio)
+					jobsAPI.POST("/:id/cancel", jobsHandler.CancelJob)
+				}
+			}
+
 			// 统计信息（包含限流器统计）
-			api.GET("/stats", handlers.StatsHandler(nil))
-			api.GET("/monitor", handlers.MonitorHandler(nil))
+			requireAdmin := r.RequireScope(middleware.ScopeAdmin)
+			api.GET("/stats", requireAdmin, handlers.StatsHandler(nil))
+			api.GET("/monitor", requireAdmin, handlers.MonitorHandler(nil))
+
+			// 配置热加载
+			if deps.HotReloadMgr != nil {
+				api.GET("/config/reload", requireAdmin, handlers.ReloadStatusHandler(deps.HotReloadMgr))
+				api.POST("/config/reload", requireAdmin, handlers.ReloadHandler(deps.HotReloadMgr))
+			}
+
+			// 模型列表与热切换
+			var sttCatalog, ttsCatalog handlers.ModelCatalog
+			if deps.ASRModels != nil {
+				sttCatalog = func() interface{} { return deps.ASRModels.List() }
+				api.POST("/models/stt/reload", requireAdmin, handlers.STTModelReloadHandler(func(name string) (handlers.STTModelSwapper, error) {
+					return lookupASRModel(deps, name)
+				}))
+			}
+			if deps.TTSModels != nil {
+				ttsCatalog = func() interface{} { return deps.TTSModels.List() }
+				api.POST("/models/tts/reload", requireAdmin, handlers.TTSModelReloadHandler(func(name string) (handlers.TTSModelSwapper, error) {
+					return lookupTTSModel(deps, name)
+				}))
+			}
+			api.GET("/models", r.RequireAnyScope(middleware.ScopeSTT, middleware.ScopeTTS), handlers.ModelsHandler(sttCatalog, ttsCatalog))
 
 			// 限流器统计
 			if deps.RateLimiter != nil {
-				api.GET("/rate-limit/stats", func(c *gin.Context) {
+				api.GET("/rate-limit/stats", requireAdmin, func(c *gin.Context) {
 					stats := deps.RateLimiter.GetStats()
 					c.JSON(http.StatusOK, gin.H{
 						"code":    200,
@@ -243,24 +466,76 @@ func main() {
 
 		// WebSocket路由
 		if sttWSHandler != nil {
-			ginEngine.GET("/ws/stt", func(c *gin.Context) {
-				conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+			ginEngine.GET("/ws/stt", r.RequireScope(middleware.ScopeSTT), func(c *gin.Context) {
+				resume, ok := wsResumeRequest(c)
+				if !ok {
+					return
+				}
+				opts, ok := sttSessionOptions(c)
+				if !ok {
+					return
+				}
+				conn, err := upgrader.Upgrade(c.Writer, c.Request, ws.ProtocolHeader(c.Request))
 				if err != nil {
 					logger.Errorf("WebSocket upgrade failed: %v", err)
 					return
 				}
-				sttWSHandler.HandleConnection(conn)
+				if resume != nil {
+					sttWSHandler.ResumeConnection(conn, *resume)
+					return
+				}
+				sttWSHandler.HandleConnectionWithOptions(conn, opts)
 			})
 		}
 
 		if ttsWSHandler != nil {
-			ginEngine.GET("/ws/tts", func(c *gin.Context) {
+			ginEngine.GET("/ws/tts", r.RequireScope(middleware.ScopeTTS), func(c *gin.Context) {
+				resume, ok := wsResumeRequest(c)
+				if !ok {
+					return
+				}
+				conn, err := upgrader.Upgrade(c.Writer, c.Request, ws.ProtocolHeader(c.Request))
+				if err != nil {
+					logger.Errorf("WebSocket upgrade failed: %v", err)
+					return
+				}
+				if resume != nil {
+					ttsWSHandler.ResumeConnection(conn, *resume)
+					return
+				}
+				ttsWSHandler.HandleConnectionWithModel(conn, c.Query("model"))
+			})
+		}
+
+		if kwsWSHandler != nil {
+			ginEngine.GET("/ws/kws", r.RequireScope(middleware.ScopeSTT), func(c *gin.Context) {
+				var keywords []string
+				for _, k := range strings.Split(c.Query("keywords"), ",") {
+					if k = strings.TrimSpace(k); k != "" {
+						keywords = append(keywords, k)
+					}
+				}
+				conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+				if err != nil {
+					logger.Errorf("WebSocket upgrade failed: %v", err)
+					return
+				}
+				kwsWSHandler.HandleConnection(conn, keywords)
+			})
+		}
+
+		if voiceWSHandler != nil {
+			ginEngine.GET("/ws/voice", r.RequireScope(middleware.ScopeSTT), r.RequireScope(middleware.ScopeTTS), func(c *gin.Context) {
+				opts, ok := voiceSessionOptions(c, &cfg.Voice)
+				if !ok {
+					return
+				}
 				conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 				if err != nil {
 					logger.Errorf("WebSocket upgrade failed: %v", err)
 					return
 				}
-				ttsWSHandler.HandleConnection(conn)
+				voiceWSHandler.HandleConnection(conn, opts)
 			})
 		}
 
@@ -268,23 +543,45 @@ func main() {
 		if cfg.Mode == "unified" {
 			if sttWSHandler != nil {
 				ginEngine.GET("/ws", func(c *gin.Context) {
+					resume, ok := wsResumeRequest(c)
+					if !ok {
+						return
+					}
 					// 根据查询参数或路径判断是STT还是TTS
 					serviceType := c.Query("type")
 					if serviceType == "tts" && ttsWSHandler != nil {
-						conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+						if !r.Authorize(c, middleware.ScopeTTS) {
+							return
+						}
+						conn, err := upgrader.Upgrade(c.Writer, c.Request, ws.ProtocolHeader(c.Request))
 						if err != nil {
 							logger.Errorf("WebSocket upgrade failed: %v", err)
 							return
 						}
-						ttsWSHandler.HandleConnection(conn)
+						if resume != nil {
+							ttsWSHandler.ResumeConnection(conn, *resume)
+							return
+						}
+						ttsWSHandler.HandleConnectionWithModel(conn, c.Query("model"))
 					} else if sttWSHandler != nil {
 						// 默认是STT
-						conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+						if !r.Authorize(c, middleware.ScopeSTT) {
+							return
+						}
+						opts, ok := sttSessionOptions(c)
+						if !ok {
+							return
+						}
+						conn, err := upgrader.Upgrade(c.Writer, c.Request, ws.ProtocolHeader(c.Request))
 						if err != nil {
 							logger.Errorf("WebSocket upgrade failed: %v", err)
 							return
 						}
-						sttWSHandler.HandleConnection(conn)
+						if resume != nil {
+							sttWSHandler.ResumeConnection(conn, *resume)
+							return
+						}
+						sttWSHandler.HandleConnectionWithOptions(conn, opts)
 					}
 				})
 			}
@@ -367,3 +664,170 @@ func main() {
 	logger.Info("Server stopped")
 }
 
+// sttSessionOptions 从查询参数读取识别会话参数，参数无效时返回400
+func sttSessionOptions(c *gin.Context) (ws.STTSessionOptions, bool) {
+	options, err := config.ParseASROptions(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "invalid request",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInvalidParams),
+				"details": err.Error(),
+			},
+		})
+		return ws.STTSessionOptions{}, false
+	}
+	opts := ws.STTSessionOptions{Model: c.Query("model"), ASROptions: options}
+	for _, name := range []string{"identify_speaker", "enhance"} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": "invalid request",
+				"error": gin.H{
+					"type":    string(utils.ErrCodeInvalidParams),
+					"details": fmt.Sprintf("invalid %s: %s", name, v),
+				},
+			})
+			return ws.STTSessionOptions{}, false
+		}
+		if name == "enhance" {
+			opts.Enhance = &b
+		} else {
+			opts.IdentifySpeaker = b
+		}
+	}
+	return opts, true
+}
+
+// wsResumeRequest 读取WebSocket重连的恢复参数（resume_token和last_seq），参数无效时返回400
+func wsResumeRequest(c *gin.Context) (*ws.ResumeRequest, bool) {
+	resume, err := ws.ParseResumeRequest(c.Request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "invalid request",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInvalidParams),
+				"details": err.Error(),
+			},
+		})
+		return nil, false
+	}
+	return resume, true
+}
+
+// voiceSessionOptions 从查询参数读取语音对话会话参数，未指定时使用配置的默认值，参数无效时返回400
+func voiceSessionOptions(c *gin.Context, voice *config.VoiceConfig) (ws.VoiceSessionOptions, bool) {
+	opts := ws.VoiceSessionOptions{Language: c.Query("language"), SpeakerID: voice.SpeakerID, Speed: voice.Speed}
+	var err error
+	if v := c.Query("speaker_id"); v != "" {
+		if opts.SpeakerID, err = strconv.Atoi(v); err != nil || opts.SpeakerID < 0 {
+			err = fmt.Errorf("invalid speaker_id: %s", v)
+		}
+	}
+	if v := c.Query("speed"); v != "" && err == nil {
+		speed, perr := strconv.ParseFloat(v, 32)
+		if perr != nil || speed < 0.5 || speed > 2.0 {
+			err = fmt.Errorf("invalid speed: %s, must be between 0.5 and 2.0", v)
+		}
+		opts.Speed = float32(speed)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "invalid request",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInvalidParams),
+				"details": err.Error(),
+			},
+		})
+		return ws.VoiceSessionOptions{}, false
+	}
+	return opts, true
+}
+
+// lookupASRModel 按名称查找识别模型，名称为空时返回默认模型
+func lookupASRModel(deps *bootstrap.AppDependencies, name string) (*asr.Manager, error) {
+	return deps.ASRModels.Get(name)
+}
+
+// lookupTTSModel 按名称查找合成模型，名称为空时返回默认模型
+func lookupTTSModel(deps *bootstrap.AppDependencies, name string) (*tts.Manager, error) {
+	return deps.TTSModels.Get(name)
+}
+
+// kwsSpotter 将kws.Spotter适配为ws.KeywordSpotter
+type kwsSpotter struct {
+	*kws.Spotter
+}
+
+// NewStream 创建关键词检测流
+func (s kwsSpotter) NewStream(keywords []string) (ws.KeywordStream, error) {
+	stream, err := s.Spotter.NewStream(keywords)
+	if err != nil {
+		return nil, err
+	}
+	return kwsStream{stream}, nil
+}
+
+// kwsStream 将kws.Stream适配为ws.KeywordStream
+type kwsStream struct {
+	*kws.Stream
+}
+
+// Accept 输入音频并转换检测结果
+func (s kwsStream) Accept(audio []byte) []ws.KeywordEvent {
+	detections := s.Stream.Accept(audio)
+	events := make([]ws.KeywordEvent, len(detections))
+	for i, d := range detections {
+		events[i] = ws.KeywordEvent{Keyword: d.Keyword, Offset: d.Offset, Boost: d.Boost, Threshold: d.Threshold}
+	}
+	return events
+}
+
+// vadDetector 将vad.Detector适配为ws.VoiceActivityDetector
+type vadDetector struct {
+	*vad.Detector
+}
+
+// NewStream 创建语音活动检测流，opts为nil时使用VAD配置
+func (d vadDetector) NewStream(opts *ws.VADOptions) (ws.VoiceStream, error) {
+	var streamOpts *vad.StreamOptions
+	if opts != nil {
+		streamOpts = &vad.StreamOptions{
+			Threshold:          opts.Threshold,
+			MinSilenceDuration: opts.MinSilenceDuration,
+			MinSpeechDuration:  opts.MinSpeechDuration,
+			MaxSpeechDuration:  opts.MaxSpeechDuration,
+		}
+	}
+	stream, err := d.Detector.NewStream(streamOpts)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// opusDecoder 创建WebSocket STT的Opus解码器
+func opusDecoder(sampleRate, channels int) (ws.OpusDecoder, error) {
+	decoder, err := opus.NewDecoder(sampleRate, channels)
+	if err != nil {
+		return nil, err
+	}
+	return decoder, nil
+}
+
+// opusEncoder 创建WebSocket TTS的Opus编码器
+func opusEncoder(sampleRate, channels, bitrate int) (ws.OpusEncoder, error) {
+	encoder, err := opus.NewEncoder(sampleRate, channels, bitrate)
+	if err != nil {
+		return nil, err
+	}
+	return encoder, nil
+}
@@ -13,10 +13,13 @@ import (
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/handlers"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/middleware"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/router"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/ws"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/opus"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/tts"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
 )
 
 func main() {
@@ -59,10 +62,19 @@ func main() {
 	defer ttsManager.Close()
 
 	// 创建会话管理器
-	sessionManager := session.NewManager(1000, 30*time.Minute)
+	sessionManager := session.NewManager(cfg.Session.MaxSessions, time.Duration(cfg.Session.Timeout)*time.Second)
+
+	// 创建认证器
+	authenticator, err := middleware.NewAuthenticator(&cfg.Auth)
+	if err != nil {
+		logger.Errorf("Failed to create authenticator: %v", err)
+		os.Exit(1)
+	}
+	defer authenticator.Close()
 
 	// 创建路由
 	r := router.NewRouter()
+	r.SetAuthenticator(authenticator)
 	r.SetupMiddleware()
 	r.SetupStaticFiles("web/static", "web/templates")
 
@@ -81,6 +93,13 @@ func main() {
 
 	// 创建TTS WebSocket处理器
 	ttsWSHandler := ws.NewTTSHandler(sessionManager, ttsManager, cfg)
+	ttsWSHandler.SetOpusEncoder(func(sampleRate, channels, bitrate int) (ws.OpusEncoder, error) {
+		encoder, err := opus.NewEncoder(sampleRate, channels, bitrate)
+		if err != nil {
+			return nil, err
+		}
+		return encoder, nil
+	})
 
 	// 设置路由
 	r.SetupRoutes(func(ginEngine *gin.Engine) {
@@ -95,7 +114,7 @@ func main() {
 			}))
 
 			// TTS API
-			ttsAPI := api.Group("/tts")
+			ttsAPI := api.Group("/tts", r.RequireScope(middleware.ScopeTTS))
 			{
 				ttsAPI.POST("/synthesize", ttsHandler.Synthesize)
 				ttsAPI.POST("/batch", ttsHandler.BatchSynthesize)
@@ -105,17 +124,35 @@ func main() {
 			}
 
 			// 统计信息
-			api.GET("/stats", handlers.StatsHandler(nil))
-			api.GET("/monitor", handlers.MonitorHandler(nil))
+			requireAdmin := r.RequireScope(middleware.ScopeAdmin)
+			api.GET("/stats", requireAdmin, handlers.StatsHandler(nil))
+			api.GET("/monitor", requireAdmin, handlers.MonitorHandler(nil))
 		}
 
 		// WebSocket路由
-		ginEngine.GET("/ws", func(c *gin.Context) {
-			conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		ginEngine.GET("/ws", r.RequireScope(middleware.ScopeTTS), func(c *gin.Context) {
+			// 带resume_token时恢复断开的会话
+			resume, err := ws.ParseResumeRequest(c.Request)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"code":    400,
+					"message": "invalid request",
+					"error": gin.H{
+						"type":    string(utils.ErrCodeInvalidParams),
+						"details": err.Error(),
+					},
+				})
+				return
+			}
+			conn, err := upgrader.Upgrade(c.Writer, c.Request, ws.ProtocolHeader(c.Request))
 			if err != nil {
 				logger.Errorf("WebSocket upgrade failed: %v", err)
 				return
 			}
+			if resume != nil {
+				ttsWSHandler.ResumeConnection(conn, *resume)
+				return
+			}
 			ttsWSHandler.HandleConnection(conn)
 		})
 
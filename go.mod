@@ -14,6 +14,7 @@ require (
 	github.com/swaggo/swag v1.16.6
 	golang.org/x/time v0.12.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	layeh.com/gopus v0.0.0-20210501142526-1ee02d434e32
 )
 
 require (
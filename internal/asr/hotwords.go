@@ -0,0 +1,291 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// 请求热词错误
+var (
+	ErrHotwordsNotPrepared = fmt.Errorf("%w: hotwords must match a prepared hotword set, use hotword_sets", ErrUnsupportedOption)
+	ErrHotwordsBusy        = errors.New("too many hotword models are being prepared")
+	ErrHotwordsDisabled    = fmt.Errorf("%w: request hotwords are disabled (max_hotword_models is 0)", ErrUnsupportedOption)
+)
+
+// hotwordKey 请求热词识别器的缓存键：请求热词（去重排序，每行一个）和热词加分
+type hotwordKey struct {
+	hotwords string
+	score    float32
+}
+
+// hotwordModel 按请求热词构建的识别器，由资源池中的所有Provider共享
+type hotwordModel struct {
+	key        hotwordKey
+	recognizer *sherpa.OfflineRecognizer
+	refs       int  // 正在使用的请求数
+	evicted    bool // 已移出缓存，最后一个请求结束后释放
+}
+
+// hotwordBuild 进行中的识别器构建
+type hotwordBuild struct {
+	done chan struct{}
+	err  error
+}
+
+// hotwordModels 请求热词识别器缓存
+// sherpa-onnx-go 不支持为单个识别流指定热词，请求热词需要单独加载一份模型。
+// 为避免在请求路径上加载模型，识别器在后台逐个构建（不占用资源池），缓存数受max_hotword_models限制；
+// 只有热词集展开的热词会触发构建，直接指定的热词只能使用已缓存的识别器
+type hotwordModels struct {
+	limit   int
+	build   func(key hotwordKey) (*sherpa.OfflineRecognizer, error)
+	release func(recognizer *sherpa.OfflineRecognizer)
+	decode  func(recognizer *sherpa.OfflineRecognizer, audio []byte) (*Recognition, error)
+
+	buildMu  sync.Mutex // 串行构建，同一时间最多加载一份模型
+	mu       sync.Mutex
+	entries  []*hotwordModel // 按最近使用排序
+	building map[hotwordKey]*hotwordBuild
+	closed   bool
+}
+
+// newHotwordModels 创建模型cfg的请求热词识别器缓存
+func newHotwordModels(cfg *config.ASRConfig) *hotwordModels {
+	return &hotwordModels{
+		limit: cfg.MaxHotwordModels,
+		build: func(key hotwordKey) (*sherpa.OfflineRecognizer, error) {
+			return newHotwordRecognizer(cfg, key)
+		},
+		release: sherpa.DeleteOfflineRecognizer,
+		decode: func(recognizer *sherpa.OfflineRecognizer, audio []byte) (*Recognition, error) {
+			return decodeAudio(recognizer, modelSampleRate, audio)
+		},
+		building: make(map[hotwordKey]*hotwordBuild),
+	}
+}
+
+// cached 检查识别器是否已缓存
+func (h *hotwordModels) cached(key hotwordKey) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, entry := range h.entries {
+		if entry.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire 获取key对应的识别器，使用结束后调用done。
+// 未缓存时：allowBuild为true则在后台构建并等待（受ctx控制），否则返回ErrHotwordsNotPrepared
+func (h *hotwordModels) acquire(ctx context.Context, key hotwordKey, allowBuild bool) (*hotwordModel, error) {
+	if h.limit <= 0 {
+		return nil, ErrHotwordsDisabled
+	}
+
+	for {
+		h.mu.Lock()
+		if h.closed {
+			h.mu.Unlock()
+			return nil, fmt.Errorf("pool is closed")
+		}
+		for i, entry := range h.entries {
+			if entry.key == key {
+				// 移到末尾，保持按最近使用排序
+				copy(h.entries[i:], h.entries[i+1:])
+				h.entries[len(h.entries)-1] = entry
+				entry.refs++
+				h.mu.Unlock()
+				return entry, nil
+			}
+		}
+		if !allowBuild {
+			h.mu.Unlock()
+			return nil, ErrHotwordsNotPrepared
+		}
+		build, ok := h.building[key]
+		if !ok {
+			if len(h.building) >= h.limit {
+				h.mu.Unlock()
+				return nil, ErrHotwordsBusy
+			}
+			build = &hotwordBuild{done: make(chan struct{})}
+			h.building[key] = build
+			go h.run(key, build)
+		}
+		h.mu.Unlock()
+
+		select {
+		case <-build.done:
+			if build.err != nil {
+				return nil, build.err
+			}
+			// 构建完成后重新查找（期间可能已被淘汰）
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// prepare 在后台构建key对应的识别器（已缓存或正在构建时忽略），不等待构建完成
+func (h *hotwordModels) prepare(key hotwordKey) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.limit <= 0 || h.closed || len(h.building) >= h.limit {
+		return
+	}
+	if _, ok := h.building[key]; ok {
+		return
+	}
+	for _, entry := range h.entries {
+		if entry.key == key {
+			return
+		}
+	}
+	build := &hotwordBuild{done: make(chan struct{})}
+	h.building[key] = build
+	go h.run(key, build)
+}
+
+// run 构建识别器并加入缓存，超出上限时淘汰最久未使用的识别器
+func (h *hotwordModels) run(key hotwordKey, build *hotwordBuild) {
+	h.buildMu.Lock()
+	recognizer, err := h.build(key)
+	h.buildMu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.building, key)
+	build.err = err
+	defer close(build.done)
+	if err != nil {
+		logger.Errorf("Failed to build hotword recognizer: %v", err)
+		return
+	}
+	if h.closed {
+		h.release(recognizer)
+		return
+	}
+
+	for len(h.entries) >= h.limit {
+		h.evict(h.entries[0])
+		h.entries = h.entries[1:]
+	}
+	h.entries = append(h.entries, &hotwordModel{key: key, recognizer: recognizer})
+}
+
+// done 结束使用识别器
+func (h *hotwordModels) done(entry *hotwordModel) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry.refs--
+	if entry.evicted && entry.refs == 0 {
+		h.release(entry.recognizer)
+	}
+}
+
+// evict 将识别器移出缓存，无请求使用时立即释放（调用方需持有锁）
+func (h *hotwordModels) evict(entry *hotwordModel) {
+	entry.evicted = true
+	if entry.refs == 0 {
+		h.release(entry.recognizer)
+	}
+}
+
+// close 释放所有识别器，正在使用的识别器在请求结束后释放
+func (h *hotwordModels) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, entry := range h.entries {
+		h.evict(entry)
+	}
+	h.entries = nil
+}
+
+// newHotwordRecognizer 构建使用请求热词的识别器。
+// 热词文件包含模型配置的热词文件、全局热词和请求热词，识别器创建时读取，之后即可删除
+func newHotwordRecognizer(cfg *config.ASRConfig, key hotwordKey) (*sherpa.OfflineRecognizer, error) {
+	recognizerConfig, err := newRecognizerConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := resolveSettings(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	settings.DecodingMethod = config.DecodingModifiedBeamSearch
+	recognizerConfig = applySettings(recognizerConfig, settings)
+
+	content, err := hotwordsContent(cfg)
+	if err != nil {
+		return nil, err
+	}
+	path, err := writeHotwordsFile(content + key.hotwords + "\n")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	recognizerConfig.HotwordsFile = path
+	recognizerConfig.HotwordsScore = key.score
+	recognizer := sherpa.NewOfflineRecognizer(&recognizerConfig)
+	if recognizer == nil {
+		return nil, fmt.Errorf("failed to create hotword recognizer")
+	}
+	return recognizer, nil
+}
+
+// hotwordsContent 模型配置的热词文件内容和全局热词（每行一个，非空时以换行结尾）
+func hotwordsContent(cfg *config.ASRConfig) (string, error) {
+	var lines []string
+	if cfg.HotwordsFile != "" {
+		data, err := os.ReadFile(cfg.HotwordsFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read hotwords file: %w", err)
+		}
+		if content := strings.TrimSpace(string(data)); content != "" {
+			lines = append(lines, content)
+		}
+	}
+	for _, word := range cfg.Hotwords {
+		if word = strings.TrimSpace(word); word != "" {
+			lines = append(lines, word)
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// writeHotwordsFile 将热词写入临时文件，返回文件路径
+func writeHotwordsFile(content string) (string, error) {
+	file, err := os.CreateTemp("", "hotwords-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create hotwords file: %w", err)
+	}
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write hotwords file: %w", err)
+	}
+	return file.Name(), nil
+}
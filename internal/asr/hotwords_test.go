@@ -0,0 +1,122 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// fakeHotwordModels 使用假识别器的热词识别器缓存，记录构建和释放
+type fakeHotwordModels struct {
+	mu       sync.Mutex
+	built    []hotwordKey
+	released map[*sherpa.OfflineRecognizer]bool
+}
+
+func newFakeHotwordModels(limit int) (*hotwordModels, *fakeHotwordModels) {
+	fake := &fakeHotwordModels{released: make(map[*sherpa.OfflineRecognizer]bool)}
+	return &hotwordModels{
+		limit: limit,
+		build: func(key hotwordKey) (*sherpa.OfflineRecognizer, error) {
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			fake.built = append(fake.built, key)
+			return &sherpa.OfflineRecognizer{}, nil
+		},
+		release: func(recognizer *sherpa.OfflineRecognizer) {
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			fake.released[recognizer] = true
+		},
+		building: make(map[hotwordKey]*hotwordBuild),
+	}, fake
+}
+
+func (f *fakeHotwordModels) isReleased(recognizer *sherpa.OfflineRecognizer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.released[recognizer]
+}
+
+func TestHotwordModels_Acquire(t *testing.T) {
+	models, fake := newFakeHotwordModels(1)
+	ctx := context.Background()
+	alpha := hotwordKey{hotwords: "alpha", score: 1.5}
+	beta := hotwordKey{hotwords: "beta", score: 1.5}
+
+	// 直接指定的热词不会触发构建
+	if _, err := models.acquire(ctx, alpha, false); !errors.Is(err, ErrHotwordsNotPrepared) {
+		t.Fatalf("Expected ErrHotwordsNotPrepared, got %v", err)
+	}
+
+	// 热词集的识别器在后台构建
+	first, err := models.acquire(ctx, alpha, true)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if !models.cached(alpha) {
+		t.Error("Expected alpha to be cached")
+	}
+	again, err := models.acquire(ctx, alpha, false)
+	if err != nil || again != first {
+		t.Fatalf("Expected cached recognizer, got %v, %v", again, err)
+	}
+	models.done(again)
+
+	// 超出上限时淘汰最久未使用的识别器，仍在使用时延迟释放
+	second, err := models.acquire(ctx, beta, true)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	if models.cached(alpha) {
+		t.Error("Expected alpha to be evicted")
+	}
+	if fake.isReleased(first.recognizer) {
+		t.Error("Expected in-use recognizer to stay alive")
+	}
+	models.done(first)
+	if !fake.isReleased(first.recognizer) {
+		t.Error("Expected evicted recognizer to be released after use")
+	}
+	if len(fake.built) != 2 {
+		t.Errorf("Expected 2 builds, got %d", len(fake.built))
+	}
+
+	// 关闭后释放所有识别器
+	models.done(second)
+	models.close()
+	if !fake.isReleased(second.recognizer) {
+		t.Error("Expected recognizer to be released on close")
+	}
+	if _, err := models.acquire(ctx, beta, true); err == nil {
+		t.Error("Expected error after close")
+	}
+}
+
+func TestHotwordModels_Prepare(t *testing.T) {
+	models, _ := newFakeHotwordModels(2)
+	key := hotwordKey{hotwords: "alpha", score: 1.5}
+
+	models.prepare(key)
+	deadline := time.Now().Add(time.Second)
+	for !models.cached(key) {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected prepared recognizer to be cached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	entry, err := models.acquire(context.Background(), key, false)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	models.done(entry)
+
+	disabled, _ := newFakeHotwordModels(0)
+	if _, err := disabled.acquire(context.Background(), key, true); !errors.Is(err, ErrHotwordsDisabled) {
+		t.Errorf("Expected ErrHotwordsDisabled, got %v", err)
+	}
+}
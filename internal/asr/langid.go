@@ -0,0 +1,165 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// LanguageIdentifier 语种识别接口
+type LanguageIdentifier interface {
+	Identify(ctx context.Context, audio []byte) (string, error)
+	Close() error
+}
+
+// minDetectWindow 参与置信度估计的最短窗口（秒）
+const minDetectWindow = 2.0
+
+// DetectLanguage 识别整段音频（16kHz PCM 16-bit）的语种，并在最多maxWindows个不重叠的时间窗口上分别识别。
+// sherpa-onnx语种识别不输出概率，置信度为各次结果与整段结果一致的比例；
+// 音频短于两个最短窗口时只识别整段，置信度为1
+func DetectLanguage(ctx context.Context, identifier LanguageIdentifier, audio []byte, maxDuration float64, maxWindows int) (*config.LanguageDetection, error) {
+	lang, err := identifier.Identify(ctx, audio)
+	if err != nil {
+		return nil, err
+	}
+	detection := &config.LanguageDetection{Language: normalizeLanguage(lang), Votes: 1}
+	agree := 1
+
+	duration := float64(len(audio)/2) / modelSampleRate
+	size := duration / float64(maxWindows)
+	if maxDuration > 0 && size > maxDuration {
+		size = maxDuration
+	}
+	if size < minDetectWindow {
+		size = minDetectWindow
+	}
+	n := int(duration / size)
+	if n > maxWindows {
+		n = maxWindows
+	}
+	if n >= 2 {
+		// 窗口均匀分布在整段音频上
+		step := (duration - size) / float64(n-1)
+		for i := 0; i < n; i++ {
+			start := step * float64(i)
+			from := int(start*modelSampleRate) * 2
+			to := from + int(size*modelSampleRate)*2
+			if to > len(audio) {
+				to = len(audio) &^ 1
+			}
+			lang, err := identifier.Identify(ctx, audio[from:to])
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				logger.Warnf("Language identification failed for window %.2f-%.2fs: %v", start, start+size, err)
+				continue
+			}
+			window := config.LanguageWindow{
+				Start:    math.Round(start*1000) / 1000,
+				End:      math.Round(float64(to/2)/modelSampleRate*1000) / 1000,
+				Language: normalizeLanguage(lang),
+			}
+			detection.Windows = append(detection.Windows, window)
+			detection.Votes++
+			if window.Language == detection.Language {
+				agree++
+			}
+		}
+	}
+	detection.Confidence = math.Round(float64(agree)/float64(detection.Votes)*1000) / 1000
+	return detection, nil
+}
+
+// SherpaLanguageIdentifier 基于sherpa-onnx Whisper模型的语种识别
+type SherpaLanguageIdentifier struct {
+	slots    chan *sherpa.SpokenLanguageIdentification
+	maxBytes int // 参与识别的最大音频字节数
+}
+
+// NewLanguageIdentifier 创建语种识别器，按pool_size创建多个实例以支持并发
+func NewLanguageIdentifier(cfg *config.LanguageIDConfig) (*SherpaLanguageIdentifier, error) {
+	for _, path := range []string{cfg.EncoderPath, cfg.DecoderPath} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("language ID model file not found: %s", path)
+		}
+	}
+
+	size := cfg.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	identifier := &SherpaLanguageIdentifier{
+		slots:    make(chan *sherpa.SpokenLanguageIdentification, size),
+		maxBytes: int(cfg.MaxDuration * modelSampleRate * 2),
+	}
+	for i := 0; i < size; i++ {
+		slid := sherpa.NewSpokenLanguageIdentification(&sherpa.SpokenLanguageIdentificationConfig{
+			Whisper: sherpa.SpokenLanguageIdentificationWhisperConfig{
+				Encoder:      cfg.EncoderPath,
+				Decoder:      cfg.DecoderPath,
+				TailPaddings: cfg.TailPaddings,
+			},
+			NumThreads: cfg.Provider.NumThreads,
+			Provider:   config.GetProvider(&cfg.Provider),
+		})
+		if slid == nil {
+			identifier.Close()
+			return nil, fmt.Errorf("failed to create spoken language identification")
+		}
+		identifier.slots <- slid
+	}
+	return identifier, nil
+}
+
+// Identify 识别音频（16kHz PCM 16-bit）的语种，返回语言代码（如 "zh"、"en"）
+func (s *SherpaLanguageIdentifier) Identify(ctx context.Context, audio []byte) (string, error) {
+	if len(audio) == 0 {
+		return "", fmt.Errorf("audio data is empty")
+	}
+	if s.maxBytes > 0 && len(audio) > s.maxBytes {
+		audio = audio[:s.maxBytes&^1]
+	}
+
+	var slid *sherpa.SpokenLanguageIdentification
+	select {
+	case slid = <-s.slots:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { s.slots <- slid }()
+
+	stream := slid.CreateStream()
+	if stream == nil {
+		return "", fmt.Errorf("failed to create offline stream")
+	}
+	defer sherpa.DeleteOfflineStream(stream)
+
+	stream.AcceptWaveform(modelSampleRate, utils.SamplesInt16ToFloat(audio))
+	result := slid.Compute(stream)
+	if result == nil || result.Lang == "" {
+		return "", fmt.Errorf("language could not be identified")
+	}
+	return result.Lang, nil
+}
+
+// Close 释放全部实例
+func (s *SherpaLanguageIdentifier) Close() error {
+	for {
+		select {
+		case slid := <-s.slots:
+			sherpa.DeleteSpokenLanguageIdentification(slid)
+		default:
+			return nil
+		}
+	}
+}
@@ -2,11 +2,14 @@ package asr
 
 import (
 	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
 )
 
 // mockProvider 模拟Provider
@@ -14,6 +17,7 @@ type mockProvider struct {
 	transcribeResult string
 	transcribeError  error
 	sampleRate       int
+	tags             string // 模拟SenseVoice内联标签，非空时随结果返回
 }
 
 func (m *mockProvider) Transcribe(audio []byte) (string, error) {
@@ -23,6 +27,22 @@ func (m *mockProvider) Transcribe(audio []byte) (string, error) {
 	return m.transcribeResult, nil
 }
 
+func (m *mockProvider) TranscribeWithOptions(audio []byte, opts *config.ASROptions) (string, error) {
+	return m.Transcribe(audio)
+}
+
+func (m *mockProvider) Recognize(audio []byte, opts *config.ASROptions) (*Recognition, error) {
+	text, err := m.Transcribe(audio)
+	if err != nil {
+		return nil, err
+	}
+	result := newRecognition(&sherpa.OfflineRecognizerResult{Text: text})
+	if m.tags != "" {
+		result = newRecognition(&sherpa.OfflineRecognizerResult{Text: m.tags + text, Lang: "<|zh|>", Emotion: "<|HAPPY|>", Event: "<|Laughter|>"})
+	}
+	return result, nil
+}
+
 func (m *mockProvider) Warmup() error {
 	return nil
 }
@@ -386,7 +406,7 @@ func TestManager_GetPoolStats(t *testing.T) {
 	if poolStats == nil {
 		t.Error("GetPoolStats() returned nil")
 	}
-	
+
 	// 验证poolStats是map类型
 	if len(poolStats) == 0 {
 		t.Log("PoolStats is empty (may be valid)")
@@ -399,3 +419,132 @@ func TestManager_GetPoolStats(t *testing.T) {
 	}
 }
 
+// swapProvider 用于热切换测试的Provider，可阻塞识别并记录释放
+type swapProvider struct {
+	name     string
+	block    chan struct{}
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (p *swapProvider) Transcribe(audio []byte) (string, error) {
+	if p.block != nil {
+		p.started <- struct{}{}
+		<-p.block
+	}
+	return p.name, nil
+}
+
+func (p *swapProvider) TranscribeWithOptions(audio []byte, opts *config.ASROptions) (string, error) {
+	return p.Transcribe(audio)
+}
+
+func (p *swapProvider) Recognize(audio []byte, opts *config.ASROptions) (*Recognition, error) {
+	text, err := p.Transcribe(audio)
+	return &Recognition{ASRResult: config.ASRResult{Text: text}}, err
+}
+
+func (p *swapProvider) Warmup() error      { return nil }
+func (p *swapProvider) Reset() error       { return nil }
+func (p *swapProvider) GetSampleRate() int { return 16000 }
+
+func (p *swapProvider) Release() error {
+	close(p.released)
+	return nil
+}
+
+func TestManager_ReloadWhileInFlight(t *testing.T) {
+	oldProvider := &swapProvider{
+		name:     "old",
+		block:    make(chan struct{}),
+		started:  make(chan struct{}, 1),
+		released: make(chan struct{}),
+	}
+	newProvider := &swapProvider{name: "new", released: make(chan struct{})}
+
+	factory := func(cfg *config.ASRConfig) (Provider, error) {
+		if cfg.ModelPath == "old.onnx" {
+			return oldProvider, nil
+		}
+		return newProvider, nil
+	}
+
+	manager, err := newManager(&config.ASRConfig{ModelPath: "old.onnx"}, 1, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	defer manager.Close()
+
+	// 发起一个阻塞在旧模型上的请求
+	done := make(chan string, 1)
+	go func() {
+		text, _ := manager.Transcribe(context.Background(), []byte{0, 0})
+		done <- text
+	}()
+	<-oldProvider.started
+
+	if err := manager.Reload(&config.ASRConfig{ModelPath: "new.onnx"}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	// 新请求立即使用新模型
+	text, err := manager.Transcribe(context.Background(), []byte{0, 0})
+	if err != nil || text != "new" {
+		t.Errorf("Expected new model after reload, got %q, %v", text, err)
+	}
+
+	// 在途请求结束前旧模型不得释放
+	select {
+	case <-oldProvider.released:
+		t.Fatal("Old provider released while a request was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(oldProvider.block)
+	if text := <-done; text != "old" {
+		t.Errorf("Expected in-flight request to finish on old model, got %q", text)
+	}
+
+	select {
+	case <-oldProvider.released:
+	case <-time.After(time.Second):
+		t.Fatal("Old provider was not released after in-flight request finished")
+	}
+
+	status := manager.GetModelStatus()
+	if status.ModelPath != "new.onnx" || status.Generation != 1 || status.LastReloadError != "" {
+		t.Errorf("Unexpected model status: %+v", status)
+	}
+}
+
+func TestManager_ReloadFailureKeepsModel(t *testing.T) {
+	factory := func(cfg *config.ASRConfig) (Provider, error) {
+		if cfg.ModelPath == "broken.onnx" {
+			return nil, errors.New("model file not found")
+		}
+		return &mockProvider{transcribeResult: "old"}, nil
+	}
+
+	manager, err := newManager(&config.ASRConfig{ModelPath: "old.onnx"}, 2, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	defer manager.Close()
+
+	if err := manager.Reload(&config.ASRConfig{ModelPath: "broken.onnx"}); err == nil {
+		t.Fatal("Expected reload of broken model to fail")
+	}
+
+	text, err := manager.Transcribe(context.Background(), []byte{0, 0})
+	if err != nil || text != "old" {
+		t.Errorf("Expected old model to keep serving, got %q, %v", text, err)
+	}
+
+	status := manager.GetModelStatus()
+	if status.ModelPath != "old.onnx" || status.Generation != 0 || status.LastReloadError == "" {
+		t.Errorf("Unexpected model status: %+v", status)
+	}
+	if manager.GetModelConfig().ModelPath != "old.onnx" {
+		t.Error("Expected model config to remain unchanged")
+	}
+}
@@ -0,0 +1,166 @@
+package asr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// ErrUnsupportedOption 模型不支持请求的解码参数
+var ErrUnsupportedOption = errors.New("unsupported option")
+
+// senseVoiceLanguages SenseVoice支持的识别语言
+var senseVoiceLanguages = map[string]bool{
+	"auto": true, "zh": true, "en": true, "ja": true, "ko": true, "yue": true,
+}
+
+// decodeSettings 识别器实际使用的解码参数（可比较，用于判断是否需要更新识别器配置）
+type decodeSettings struct {
+	Language       string
+	ITN            bool
+	DecodingMethod string
+	MaxActivePaths int
+}
+
+// resolveSettings 将单次请求的参数合并到模型配置上，模型不支持的参数返回ErrUnsupportedOption。
+// 语言对不支持指定语言的模型（paraformer/transducer）不生效，SenseVoice不支持的语言按auto处理
+func resolveSettings(cfg *config.ASRConfig, opts *config.ASROptions) (decodeSettings, error) {
+	settings := decodeSettings{
+		Language:       cfg.Language,
+		ITN:            cfg.UseITN == nil || *cfg.UseITN,
+		DecodingMethod: cfg.DecodingMethod,
+		MaxActivePaths: cfg.MaxActivePaths,
+	}
+	if settings.DecodingMethod == "" {
+		settings.DecodingMethod = config.DecodingGreedySearch
+	}
+	if settings.MaxActivePaths == 0 {
+		settings.MaxActivePaths = 4
+	}
+	if opts == nil {
+		return settings, nil
+	}
+
+	if err := opts.Validate(); err != nil {
+		return settings, err
+	}
+	if opts.Language != "" {
+		settings.Language = opts.Language
+	}
+	if opts.ITN != nil {
+		if cfg.ModelType != config.ASRModelSenseVoice && cfg.ModelType != "" {
+			return settings, fmt.Errorf("%w: itn is not supported by %s models", ErrUnsupportedOption, cfg.ModelType)
+		}
+		settings.ITN = *opts.ITN
+	}
+	if keepTags(opts) && cfg.ModelType != config.ASRModelSenseVoice && cfg.ModelType != "" {
+		return settings, fmt.Errorf("%w: keep_tags is only supported by %s models", ErrUnsupportedOption, config.ASRModelSenseVoice)
+	}
+	if opts.DecodingMethod != "" {
+		if opts.DecodingMethod == config.DecodingModifiedBeamSearch && cfg.ModelType != config.ASRModelTransducer {
+			return settings, fmt.Errorf("%w: %s is only supported by transducer models", ErrUnsupportedOption, config.DecodingModifiedBeamSearch)
+		}
+		settings.DecodingMethod = opts.DecodingMethod
+	}
+	if opts.MaxActivePaths > 0 {
+		settings.MaxActivePaths = opts.MaxActivePaths
+	}
+	if len(opts.Hotwords) > 0 || len(opts.SetHotwords) > 0 {
+		// 热词只在transducer模型的modified_beam_search中生效
+		if cfg.ModelType != config.ASRModelTransducer {
+			return settings, fmt.Errorf("%w: hotwords are only supported by transducer models", ErrUnsupportedOption)
+		}
+		if opts.DecodingMethod == config.DecodingGreedySearch {
+			return settings, fmt.Errorf("%w: hotwords require %s", ErrUnsupportedOption, config.DecodingModifiedBeamSearch)
+		}
+		settings.DecodingMethod = config.DecodingModifiedBeamSearch
+	}
+
+	// 语言统一为模型使用的形式
+	language := baseLanguage(normalizeLanguage(settings.Language))
+	switch cfg.ModelType {
+	case config.ASRModelSenseVoice, "":
+		if !senseVoiceLanguages[language] {
+			language = ""
+		}
+	case config.ASRModelWhisper:
+	default:
+		language = cfg.Language
+	}
+	settings.Language = language
+	return settings, nil
+}
+
+// requestHotwords 计算单次请求热词对应的识别器缓存键：合并直接指定的热词和热词集展开的热词（去重排序）。
+// 与模型全局热词和热词加分一致时返回空键，直接使用基础识别器；
+// freeForm表示包含不来自热词集的热词，这类热词只能使用已缓存的识别器
+func requestHotwords(cfg *config.ASRConfig, opts *config.ASROptions) (key hotwordKey, freeForm bool) {
+	if opts == nil || (len(opts.Hotwords) == 0 && len(opts.SetHotwords) == 0) {
+		return hotwordKey{}, false
+	}
+
+	score := cfg.HotwordsScore
+	if opts.HotwordsScore > 0 {
+		// 加分按0.1取整，避免相近的取值产生不同的缓存键
+		score = float32(math.Round(float64(opts.HotwordsScore)*10) / 10)
+	}
+
+	global := make(map[string]bool, len(cfg.Hotwords))
+	for _, word := range cfg.Hotwords {
+		global[strings.TrimSpace(word)] = true
+	}
+	fromSets := make(map[string]bool, len(opts.SetHotwords))
+	for _, word := range opts.SetHotwords {
+		fromSets[strings.TrimSpace(word)] = true
+	}
+
+	seen := make(map[string]bool)
+	var words []string
+	add := func(word string) {
+		if word == "" || seen[word] || (global[word] && score == cfg.HotwordsScore) {
+			return
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	for _, word := range opts.Hotwords {
+		word = strings.TrimSpace(word)
+		if word != "" && !fromSets[word] && !global[word] {
+			freeForm = true
+		}
+		add(word)
+	}
+	for _, word := range opts.SetHotwords {
+		add(strings.TrimSpace(word))
+	}
+	if len(words) == 0 {
+		return hotwordKey{}, false
+	}
+
+	sort.Strings(words)
+	return hotwordKey{hotwords: strings.Join(words, "\n"), score: score}, freeForm
+}
+
+// applySettings 按解码参数生成识别器配置
+func applySettings(base sherpa.OfflineRecognizerConfig, settings decodeSettings) sherpa.OfflineRecognizerConfig {
+	cfg := base
+	cfg.DecodingMethod = settings.DecodingMethod
+	cfg.MaxActivePaths = settings.MaxActivePaths
+	if cfg.ModelConfig.SenseVoice.Model != "" {
+		cfg.ModelConfig.SenseVoice.Language = settings.Language
+		cfg.ModelConfig.SenseVoice.UseInverseTextNormalization = 0
+		if settings.ITN {
+			cfg.ModelConfig.SenseVoice.UseInverseTextNormalization = 1
+		}
+	}
+	if cfg.ModelConfig.Whisper.Encoder != "" {
+		cfg.ModelConfig.Whisper.Language = settings.Language
+	}
+	return cfg
+}
@@ -0,0 +1,157 @@
+package asr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+func TestResolveSettings(t *testing.T) {
+	off := false
+	senseVoice := &config.ASRConfig{ModelType: config.ASRModelSenseVoice, Language: "zh"}
+	whisper := &config.ASRConfig{ModelType: config.ASRModelWhisper}
+	transducer := &config.ASRConfig{ModelType: config.ASRModelTransducer, DecodingMethod: config.DecodingGreedySearch, MaxActivePaths: 4}
+
+	tests := []struct {
+		name    string
+		cfg     *config.ASRConfig
+		opts    *config.ASROptions
+		want    decodeSettings
+		wantErr error
+	}{
+		{
+			name: "model defaults",
+			cfg:  senseVoice,
+			want: decodeSettings{Language: "zh", ITN: true, DecodingMethod: config.DecodingGreedySearch, MaxActivePaths: 4},
+		},
+		{
+			name: "sense voice language and itn",
+			cfg:  senseVoice,
+			opts: &config.ASROptions{Language: "EN-us", ITN: &off},
+			want: decodeSettings{Language: "en", DecodingMethod: config.DecodingGreedySearch, MaxActivePaths: 4},
+		},
+		{
+			name: "sense voice unsupported language falls back to auto",
+			cfg:  senseVoice,
+			opts: &config.ASROptions{Language: "fr"},
+			want: decodeSettings{ITN: true, DecodingMethod: config.DecodingGreedySearch, MaxActivePaths: 4},
+		},
+		{
+			name: "whisper language",
+			cfg:  whisper,
+			opts: &config.ASROptions{Language: "fr"},
+			want: decodeSettings{Language: "fr", ITN: true, DecodingMethod: config.DecodingGreedySearch, MaxActivePaths: 4},
+		},
+		{
+			name:    "whisper itn",
+			cfg:     whisper,
+			opts:    &config.ASROptions{ITN: &off},
+			wantErr: ErrUnsupportedOption,
+		},
+		{
+			name: "transducer beam search",
+			cfg:  transducer,
+			opts: &config.ASROptions{DecodingMethod: config.DecodingModifiedBeamSearch, MaxActivePaths: 8},
+			want: decodeSettings{ITN: true, DecodingMethod: config.DecodingModifiedBeamSearch, MaxActivePaths: 8},
+		},
+		{
+			name: "transducer hotwords switch to beam search",
+			cfg:  transducer,
+			opts: &config.ASROptions{Hotwords: []string{"sherpa onnx"}},
+			want: decodeSettings{ITN: true, DecodingMethod: config.DecodingModifiedBeamSearch, MaxActivePaths: 4},
+		},
+		{
+			name:    "transducer hotwords with greedy search",
+			cfg:     transducer,
+			opts:    &config.ASROptions{Hotwords: []string{"sherpa onnx"}, DecodingMethod: config.DecodingGreedySearch},
+			wantErr: ErrUnsupportedOption,
+		},
+		{
+			name:    "sense voice hotwords",
+			cfg:     senseVoice,
+			opts:    &config.ASROptions{Hotwords: []string{"sherpa onnx"}},
+			wantErr: ErrUnsupportedOption,
+		},
+		{
+			name:    "sense voice beam search",
+			cfg:     senseVoice,
+			opts:    &config.ASROptions{DecodingMethod: config.DecodingModifiedBeamSearch},
+			wantErr: ErrUnsupportedOption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSettings(tt.cfg, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSettings() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := resolveSettings(senseVoice, &config.ASROptions{DecodingMethod: "beam"}); err == nil {
+		t.Error("Expected error for invalid decoding method")
+	}
+	if _, err := resolveSettings(transducer, &config.ASROptions{MaxActivePaths: config.MaxActivePathsLimit + 1}); err == nil {
+		t.Error("Expected error for max_active_paths over limit")
+	}
+}
+
+func TestRequestHotwords(t *testing.T) {
+	cfg := &config.ASRConfig{ModelType: config.ASRModelTransducer, Hotwords: []string{"alpha", "beta"}, HotwordsScore: 1.5}
+
+	if key, _ := requestHotwords(cfg, nil); key.hotwords != "" {
+		t.Errorf("Expected no key without request hotwords, got %+v", key)
+	}
+
+	// 全局热词已构建到基础识别器中，加分相同时不需要热词识别器
+	if key, _ := requestHotwords(cfg, &config.ASROptions{Hotwords: []string{" alpha "}}); key.hotwords != "" {
+		t.Errorf("Expected global hotwords to use the base recognizer, got %+v", key)
+	}
+
+	// 热词集展开的热词去重排序，不算直接指定
+	key, freeForm := requestHotwords(cfg, &config.ASROptions{SetHotwords: []string{"zeta", "gamma", "alpha", "gamma"}})
+	if key.hotwords != "gamma\nzeta" || key.score != 1.5 || freeForm {
+		t.Errorf("requestHotwords() = %+v, freeForm %v", key, freeForm)
+	}
+
+	// 直接指定热词集之外的热词
+	key, freeForm = requestHotwords(cfg, &config.ASROptions{Hotwords: []string{"delta", "zeta"}, SetHotwords: []string{"zeta"}})
+	if key.hotwords != "delta\nzeta" || !freeForm {
+		t.Errorf("requestHotwords() = %+v, freeForm %v", key, freeForm)
+	}
+
+	// 请求加分按0.1取整，与模型不同时全局热词也进入缓存键
+	key, _ = requestHotwords(cfg, &config.ASROptions{SetHotwords: []string{"alpha", "gamma"}, HotwordsScore: 2.04})
+	if key.hotwords != "alpha\ngamma" || key.score != 2.0 {
+		t.Errorf("requestHotwords() = %+v", key)
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	base := sherpa.OfflineRecognizerConfig{}
+	base.ModelConfig.SenseVoice.Model = "model.onnx"
+
+	cfg := applySettings(base, decodeSettings{Language: "en", ITN: false, DecodingMethod: config.DecodingGreedySearch, MaxActivePaths: 4})
+	if cfg.ModelConfig.SenseVoice.Language != "en" || cfg.ModelConfig.SenseVoice.UseInverseTextNormalization != 0 {
+		t.Errorf("Unexpected SenseVoice config: %+v", cfg.ModelConfig.SenseVoice)
+	}
+	if cfg.DecodingMethod != config.DecodingGreedySearch || cfg.MaxActivePaths != 4 {
+		t.Errorf("Unexpected decoding config: %s/%d", cfg.DecodingMethod, cfg.MaxActivePaths)
+	}
+	if base.ModelConfig.SenseVoice.Language != "" {
+		t.Error("applySettings() must not modify the base config")
+	}
+}
@@ -19,6 +19,22 @@ type Pool struct {
 	stats       *PoolStats
 	ctx         context.Context
 	cancel      context.CancelFunc
+	factory     providerFactory
+	inflight    sync.WaitGroup // 在途请求，热切换后等待其结束再关闭
+	postprocess *PostProcessChain // 识别结果后处理，与Provider使用同一份模型配置
+	hotwords    *hotwordModels    // 请求热词识别器缓存，随资源池热切换
+}
+
+// providerFactory 创建Provider的函数（测试时可替换）
+type providerFactory func(cfg *config.ASRConfig) (Provider, error)
+
+// defaultProviderFactory 创建sherpa-onnx Provider
+func defaultProviderFactory(cfg *config.ASRConfig) (Provider, error) {
+	provider, err := NewASRProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
 }
 
 // PoolStats 资源池统计信息
@@ -33,6 +49,11 @@ type PoolStats struct {
 
 // NewPool 创建ASR资源池
 func NewPool(cfg *config.ASRConfig, size int) (*Pool, error) {
+	return newPool(cfg, size, defaultProviderFactory)
+}
+
+// newPool 使用指定的Provider工厂创建资源池
+func newPool(cfg *config.ASRConfig, size int, factory providerFactory) (*Pool, error) {
 	if size <= 0 {
 		size = 1
 	}
@@ -46,8 +67,10 @@ func NewPool(cfg *config.ASRConfig, size int) (*Pool, error) {
 		stats: &PoolStats{
 			CurrentActive: 0,
 		},
-		ctx:    ctx,
-		cancel: cancel,
+		ctx:      ctx,
+		cancel:   cancel,
+		factory:  factory,
+		hotwords: newHotwordModels(cfg),
 	}
 
 	// 并行初始化Provider
@@ -60,7 +83,7 @@ func NewPool(cfg *config.ASRConfig, size int) (*Pool, error) {
 		go func(index int) {
 			defer wg.Done()
 
-			provider, err := NewASRProvider(cfg)
+			provider, err := factory(cfg)
 			if err != nil {
 				logger.Warnf("Failed to create ASR provider %d: %v", index, err)
 				return
@@ -95,6 +118,13 @@ func NewPool(cfg *config.ASRConfig, size int) (*Pool, error) {
 		return nil, fmt.Errorf("failed to create any ASR provider")
 	}
 
+	postprocess, err := NewPostProcessChain(cfg)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	pool.postprocess = postprocess
+
 	logger.Infof("ASR pool initialized with %d/%d providers", successCount, size)
 
 	return pool, nil
@@ -135,7 +165,7 @@ func (p *Pool) Get(ctx context.Context) (Provider, error) {
 
 // createTemporaryProvider 创建临时Provider
 func (p *Pool) createTemporaryProvider() (Provider, error) {
-	provider, err := NewASRProvider(p.config)
+	provider, err := p.factory(p.config)
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +204,11 @@ func (p *Pool) Put(provider Provider) {
 	}
 }
 
+// Available 获取池中空闲的Provider数量
+func (p *Pool) Available() int {
+	return len(p.providers)
+}
+
 // GetUsage 获取资源池使用率
 func (p *Pool) GetUsage() float64 {
 	p.mu.RLock()
@@ -204,6 +239,12 @@ func (p *Pool) GetStats() map[string]interface{} {
 	}
 }
 
+// Drain 等待在途请求结束后关闭资源池（用于模型热切换后释放旧模型）
+func (p *Pool) Drain() error {
+	p.inflight.Wait()
+	return p.Close()
+}
+
 // Close 关闭资源池
 func (p *Pool) Close() error {
 	p.cancel()
@@ -218,6 +259,10 @@ func (p *Pool) Close() error {
 		p.stats.TotalDestroyed++
 		p.stats.mu.Unlock()
 	}
+	p.hotwords.close()
+	if err := p.postprocess.Close(); err != nil {
+		logger.Warnf("Failed to release ASR post-processing: %v", err)
+	}
 
 	logger.Info("ASR pool closed")
 	return nil
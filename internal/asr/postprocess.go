@@ -0,0 +1,79 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+)
+
+// TextProcessor 识别结果的文本后处理步骤
+type TextProcessor interface {
+	// Name 处理步骤名称（用于日志）
+	Name() string
+	// Enabled 按单次请求的参数判断是否执行，opts为nil时使用模型配置
+	Enabled(opts *config.ASROptions) bool
+	// Process 处理识别文本
+	Process(ctx context.Context, text string) (string, error)
+	Close() error
+}
+
+// PostProcessChain 按顺序执行的文本后处理链，随资源池创建（模型热切换时一并替换）
+type PostProcessChain struct {
+	processors []TextProcessor
+}
+
+// NewPostProcessChain 按模型配置创建后处理链，未配置任何后处理时返回空链
+func NewPostProcessChain(cfg *config.ASRConfig) (*PostProcessChain, error) {
+	chain := &PostProcessChain{}
+	if cfg.Punctuation.ModelPath != "" {
+		punctuator, err := NewPunctuator(&cfg.Punctuation, &cfg.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create punctuation model: %w", err)
+		}
+		chain.processors = append(chain.processors, punctuator)
+	}
+	return chain, nil
+}
+
+// validatePostProcess 检查模型是否支持请求的后处理参数
+func validatePostProcess(cfg *config.ASRConfig, opts *config.ASROptions) error {
+	if opts != nil && opts.Punctuate != nil && *opts.Punctuate && cfg.Punctuation.ModelPath == "" {
+		return fmt.Errorf("%w: punctuation model is not configured", ErrUnsupportedOption)
+	}
+	return nil
+}
+
+// Process 依次执行启用的处理步骤。某一步失败时记录日志并跳过，不影响识别结果
+func (c *PostProcessChain) Process(ctx context.Context, text string, opts *config.ASROptions) string {
+	if c == nil || text == "" {
+		return text
+	}
+	for _, processor := range c.processors {
+		if !processor.Enabled(opts) {
+			continue
+		}
+		processed, err := processor.Process(ctx, text)
+		if err != nil {
+			logger.Warnf("ASR post-processing %s failed: %v", processor.Name(), err)
+			continue
+		}
+		text = processed
+	}
+	return text
+}
+
+// Close 释放全部处理步骤
+func (c *PostProcessChain) Close() error {
+	if c == nil {
+		return nil
+	}
+	var firstErr error
+	for _, processor := range c.processors {
+		if err := processor.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
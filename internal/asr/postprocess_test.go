@@ -0,0 +1,115 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// fakeProcessor 模拟后处理步骤：在文本后追加后缀
+type fakeProcessor struct {
+	suffix  string
+	enabled bool
+	err     error
+	closed  bool
+}
+
+func (p *fakeProcessor) Name() string { return "fake" + p.suffix }
+
+func (p *fakeProcessor) Enabled(opts *config.ASROptions) bool {
+	if opts != nil && opts.Punctuate != nil {
+		return *opts.Punctuate
+	}
+	return p.enabled
+}
+
+func (p *fakeProcessor) Process(ctx context.Context, text string) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	return text + p.suffix, nil
+}
+
+func (p *fakeProcessor) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestPostProcessChain_Process(t *testing.T) {
+	first := &fakeProcessor{suffix: "。", enabled: true}
+	failing := &fakeProcessor{suffix: "!", enabled: true, err: errors.New("model error")}
+	last := &fakeProcessor{suffix: "?", enabled: true}
+	chain := &PostProcessChain{processors: []TextProcessor{first, failing, last}}
+
+	if got := chain.Process(context.Background(), "你好", nil); got != "你好。?" {
+		t.Errorf("Process() = %q, want %q", got, "你好。?")
+	}
+
+	off := false
+	if got := chain.Process(context.Background(), "你好", &config.ASROptions{Punctuate: &off}); got != "你好" {
+		t.Errorf("Process() with processors disabled = %q, want %q", got, "你好")
+	}
+	if got := chain.Process(context.Background(), "", nil); got != "" {
+		t.Errorf("Process() on empty text = %q", got)
+	}
+
+	var empty *PostProcessChain
+	if got := empty.Process(context.Background(), "你好", nil); got != "你好" {
+		t.Errorf("nil chain Process() = %q", got)
+	}
+
+	chain.Close()
+	if !first.closed || !failing.closed || !last.closed {
+		t.Error("Close() must release all processors")
+	}
+}
+
+func TestValidatePostProcess(t *testing.T) {
+	on := true
+	opts := &config.ASROptions{Punctuate: &on}
+
+	if err := validatePostProcess(&config.ASRConfig{}, opts); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption without punctuation model, got %v", err)
+	}
+	withModel := &config.ASRConfig{Punctuation: config.PunctuationConfig{ModelPath: "punct.onnx"}}
+	if err := validatePostProcess(withModel, opts); err != nil {
+		t.Errorf("validatePostProcess() error = %v", err)
+	}
+	off := false
+	if err := validatePostProcess(&config.ASRConfig{}, &config.ASROptions{Punctuate: &off}); err != nil {
+		t.Errorf("Disabling punctuation must always be allowed, got %v", err)
+	}
+}
+
+func TestManager_PostProcess(t *testing.T) {
+	factory := func(cfg *config.ASRConfig) (Provider, error) {
+		return &mockProvider{transcribeResult: "你好世界"}, nil
+	}
+	manager, err := newManager(&config.ASRConfig{}, 1, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	defer manager.Close()
+	manager.pool.postprocess = &PostProcessChain{processors: []TextProcessor{&fakeProcessor{suffix: "。", enabled: true}}}
+
+	result, err := manager.Transcribe(context.Background(), []byte("audio"))
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if result != "你好世界。" {
+		t.Errorf("Transcribe() = %q, want %q", result, "你好世界。")
+	}
+
+	off := false
+	result, err = manager.TranscribeWithOptions(context.Background(), []byte("audio"), &config.ASROptions{Punctuate: &off})
+	if err != nil || result != "你好世界" {
+		t.Errorf("TranscribeWithOptions() = %q, %v, want unpunctuated text", result, err)
+	}
+
+	on := true
+	if _, err := manager.TranscribeWithOptions(context.Background(), []byte("audio"), &config.ASROptions{Punctuate: &on}); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption for model without punctuation, got %v", err)
+	}
+}
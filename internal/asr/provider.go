@@ -13,40 +13,96 @@ import (
 // Provider ASR Provider接口
 type Provider interface {
 	Transcribe(audio []byte) (string, error)
+	TranscribeWithOptions(audio []byte, opts *config.ASROptions) (string, error)
+	Recognize(audio []byte, opts *config.ASROptions) (*Recognition, error)
 	Warmup() error
 	Reset() error
 	Release() error
 	GetSampleRate() int
 }
 
+// modelSampleRate 离线识别模型的输入采样率
+const modelSampleRate = 16000
+
 // ASRProvider sherpa-onnx ASR Provider实现
 type ASRProvider struct {
 	recognizer *sherpa.OfflineRecognizer
 	config     *config.ASRConfig
 	sampleRate int
+	base       sherpa.OfflineRecognizerConfig // 加载模型时的识别器配置
+	settings   decodeSettings                 // 识别器当前的解码参数
+	tempFile   string                         // 合并全局热词生成的热词文件，释放时删除
 }
 
 // NewASRProvider 创建ASR Provider
+// 模型配置的热词文件和全局热词直接构建到识别器中，请求热词由资源池的热词识别器缓存处理
 func NewASRProvider(cfg *config.ASRConfig) (*ASRProvider, error) {
+	recognizerConfig, err := newRecognizerConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := resolveSettings(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 全局热词与热词文件合并写入临时文件（识别器更新配置时可能重新读取，Provider释放时删除）
+	var tempFile string
+	if len(cfg.Hotwords) > 0 {
+		content, err := hotwordsContent(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if tempFile, err = writeHotwordsFile(content); err != nil {
+			return nil, err
+		}
+		recognizerConfig.HotwordsFile = tempFile
+	}
+
+	base := recognizerConfig
+	recognizerConfig = applySettings(base, settings)
+
+	// 创建识别器
+	recognizer := sherpa.NewOfflineRecognizer(&recognizerConfig)
+	if recognizer == nil {
+		if tempFile != "" {
+			os.Remove(tempFile)
+		}
+		return nil, fmt.Errorf("failed to create offline recognizer")
+	}
+
+	provider := &ASRProvider{
+		recognizer: recognizer,
+		config:     cfg,
+		sampleRate: modelSampleRate,
+		base:       base,
+		settings:   settings,
+		tempFile:   tempFile,
+	}
+
+	return provider, nil
+}
+
+// newRecognizerConfig 检查模型文件并构建识别器配置（热词使用模型配置的热词文件）
+func newRecognizerConfig(cfg *config.ASRConfig) (sherpa.OfflineRecognizerConfig, error) {
 	// 检查模型文件是否存在
 	if cfg.ModelPath != "" {
 		if _, err := os.Stat(cfg.ModelPath); os.IsNotExist(err) {
-			return nil, fmt.Errorf("model file not found: %s (please check if the model file exists or download models using scripts/download_models.sh)", cfg.ModelPath)
+			return sherpa.OfflineRecognizerConfig{}, fmt.Errorf("model file not found: %s (please check if the model file exists or download models using scripts/download_models.sh)", cfg.ModelPath)
 		}
 	}
 	
 	// 检查tokens文件是否存在
 	if cfg.TokensPath != "" {
 		if _, err := os.Stat(cfg.TokensPath); os.IsNotExist(err) {
-			return nil, fmt.Errorf("tokens file not found: %s (please check if the tokens file exists or download models using scripts/download_models.sh)", cfg.TokensPath)
+			return sherpa.OfflineRecognizerConfig{}, fmt.Errorf("tokens file not found: %s (please check if the tokens file exists or download models using scripts/download_models.sh)", cfg.TokensPath)
 		}
 	}
 	
 	// 构建sherpa-onnx配置
-	sampleRate := 16000 // 默认采样率
 	recognizerConfig := sherpa.OfflineRecognizerConfig{
 		FeatConfig: sherpa.FeatureConfig{
-			SampleRate: sampleRate,
+			SampleRate: modelSampleRate,
 			FeatureDim: 80,
 		},
 		ModelConfig: sherpa.OfflineModelConfig{
@@ -55,71 +111,109 @@ func NewASRProvider(cfg *config.ASRConfig) (*ASRProvider, error) {
 			Debug: 0,
 			Provider: config.GetProvider(&cfg.Provider),
 		},
-		DecodingMethod: "greedy_search",
-		MaxActivePaths: 4,
 	}
 
-	// 根据模型类型设置配置
-	// 这里使用SenseVoice作为默认模型
-	recognizerConfig.ModelConfig.SenseVoice = sherpa.OfflineSenseVoiceModelConfig{
-		Model: cfg.ModelPath,
-		Language: cfg.Language,
-		UseInverseTextNormalization: 1,
+	// 根据模型类型设置配置，未指定时使用SenseVoice
+	if err := setModelConfig(&recognizerConfig.ModelConfig, cfg); err != nil {
+		return sherpa.OfflineRecognizerConfig{}, err
 	}
+	recognizerConfig.HotwordsFile = cfg.HotwordsFile
+	recognizerConfig.HotwordsScore = cfg.HotwordsScore
+	return recognizerConfig, nil
+}
 
-	// 创建识别器
-	recognizer := sherpa.NewOfflineRecognizer(&recognizerConfig)
-	if recognizer == nil {
-		return nil, fmt.Errorf("failed to create offline recognizer")
+// setModelConfig 按模型类型填充sherpa-onnx模型配置
+func setModelConfig(modelConfig *sherpa.OfflineModelConfig, cfg *config.ASRConfig) error {
+	switch cfg.ModelType {
+	case config.ASRModelSenseVoice, "":
+		modelConfig.SenseVoice = sherpa.OfflineSenseVoiceModelConfig{
+			Model:    cfg.ModelPath,
+			Language: cfg.Language,
+		}
+	case config.ASRModelWhisper:
+		modelConfig.Whisper = sherpa.OfflineWhisperModelConfig{
+			Encoder:  cfg.EncoderPath,
+			Decoder:  cfg.DecoderPath,
+			Language: cfg.Language,
+			Task:     "transcribe",
+		}
+	case config.ASRModelParaformer:
+		modelConfig.Paraformer = sherpa.OfflineParaformerModelConfig{
+			Model: cfg.ModelPath,
+		}
+	case config.ASRModelTransducer:
+		modelConfig.Transducer = sherpa.OfflineTransducerModelConfig{
+			Encoder: cfg.EncoderPath,
+			Decoder: cfg.DecoderPath,
+			Joiner:  cfg.JoinerPath,
+		}
+		modelConfig.ModelType = "transducer"
+		modelConfig.ModelingUnit = cfg.ModelingUnit
+		modelConfig.BpeVocab = cfg.BpeVocab
+	default:
+		return fmt.Errorf("unsupported ASR model type: %s", cfg.ModelType)
 	}
+	return nil
+}
 
-	provider := &ASRProvider{
-		recognizer: recognizer,
-		config:     cfg,
-		sampleRate: 16000, // 默认采样率
+// Transcribe 识别音频
+func (p *ASRProvider) Transcribe(audio []byte) (string, error) {
+	return p.TranscribeWithOptions(audio, nil)
+}
+
+// TranscribeWithOptions 使用单次请求的解码参数识别音频，返回去除内联标签的文本
+func (p *ASRProvider) TranscribeWithOptions(audio []byte, opts *config.ASROptions) (string, error) {
+	result, err := p.Recognize(audio, opts)
+	if err != nil {
+		return "", err
 	}
+	return result.Text, nil
+}
 
-	return provider, nil
+// Recognize 使用单次请求的解码参数识别音频，返回文本及SenseVoice的语言/情感/事件标签。
+// Provider在资源池中独占使用，参数与当前不同时通过SetConfig更新识别器配置；
+// 请求热词不在这里处理，由Manager使用资源池的热词识别器识别
+func (p *ASRProvider) Recognize(audio []byte, opts *config.ASROptions) (*Recognition, error) {
+	settings, err := resolveSettings(p.config, opts)
+	if err != nil {
+		return nil, err
+	}
+	if settings != p.settings {
+		recognizerConfig := applySettings(p.base, settings)
+		p.recognizer.SetConfig(&recognizerConfig)
+		p.settings = settings
+	}
+
+	return decodeAudio(p.recognizer, p.sampleRate, audio)
 }
 
-// Transcribe 识别音频
-func (p *ASRProvider) Transcribe(audio []byte) (string, error) {
+// decodeAudio 使用识别器识别16位PCM音频
+func decodeAudio(recognizer *sherpa.OfflineRecognizer, sampleRate int, audio []byte) (*Recognition, error) {
 	if len(audio) == 0 {
-		return "", fmt.Errorf("audio data is empty")
+		return nil, fmt.Errorf("audio data is empty")
 	}
 
 	// 转换音频数据
 	samples := utils.SamplesInt16ToFloat(audio)
 	if samples == nil {
-		return "", fmt.Errorf("failed to convert audio data")
+		return nil, fmt.Errorf("failed to convert audio data")
 	}
 
 	// 创建识别流
-	stream := sherpa.NewOfflineStream(p.recognizer)
+	stream := sherpa.NewOfflineStream(recognizer)
 	if stream == nil {
-		return "", fmt.Errorf("failed to create offline stream")
+		return nil, fmt.Errorf("failed to create offline stream")
 	}
 	defer sherpa.DeleteOfflineStream(stream)
 
 	// 接受音频数据
-	stream.AcceptWaveform(p.sampleRate, samples)
+	stream.AcceptWaveform(sampleRate, samples)
 
 	// 执行识别
-	p.recognizer.Decode(stream)
+	recognizer.Decode(stream)
 
-	// 获取结果
-	result := stream.GetResult()
-	if result == nil {
-		// result为nil可能是正常情况（例如空音频或静音），返回空字符串而不是错误
-		return "", nil
-	}
-	
-	// 检查result.Text是否为空
-	if result.Text == "" {
-		return "", nil // 空结果也是有效结果
-	}
-	
-	return result.Text, nil
+	// 获取结果（result为nil可能是正常情况，例如空音频或静音，返回空结果而不是错误）
+	return newRecognition(stream.GetResult()), nil
 }
 
 // Warmup 预热模型
@@ -146,6 +240,10 @@ func (p *ASRProvider) Release() error {
 		sherpa.DeleteOfflineRecognizer(p.recognizer)
 		p.recognizer = nil
 	}
+	if p.tempFile != "" {
+		os.Remove(p.tempFile)
+		p.tempFile = ""
+	}
 	return nil
 }
 
@@ -0,0 +1,90 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Punctuator 基于sherpa-onnx CT-Transformer模型的标点恢复
+type Punctuator struct {
+	slots   chan *sherpa.OfflinePunctuation
+	enabled bool // 请求未指定punctuate时是否执行
+}
+
+// NewPunctuator 创建标点恢复处理步骤，按pool_size创建多个实例以支持并发
+func NewPunctuator(cfg *config.PunctuationConfig, provider *config.ProviderConfig) (*Punctuator, error) {
+	if _, err := os.Stat(cfg.ModelPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("punctuation model file not found: %s", cfg.ModelPath)
+	}
+
+	size := cfg.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	punctuator := &Punctuator{
+		slots:   make(chan *sherpa.OfflinePunctuation, size),
+		enabled: cfg.Enabled == nil || *cfg.Enabled,
+	}
+	// sherpa-onnx-go中NumThreads的类型为C.int，包外无法赋值，线程数使用sherpa-onnx的默认值，
+	// 不跟随识别模型的num_threads
+	punctConfig := sherpa.OfflinePunctuationConfig{
+		Model: sherpa.OfflinePunctuationModelConfig{
+			CtTransformer: cfg.ModelPath,
+			Provider:      config.GetProvider(provider),
+		},
+	}
+
+	for i := 0; i < size; i++ {
+		punct := sherpa.NewOfflinePunctuation(&punctConfig)
+		if punct == nil {
+			punctuator.Close()
+			return nil, fmt.Errorf("failed to create offline punctuation")
+		}
+		punctuator.slots <- punct
+	}
+	return punctuator, nil
+}
+
+// Name 处理步骤名称
+func (p *Punctuator) Name() string {
+	return "punctuation"
+}
+
+// Enabled 请求指定punctuate时按请求，否则按模型配置
+func (p *Punctuator) Enabled(opts *config.ASROptions) bool {
+	if opts != nil && opts.Punctuate != nil {
+		return *opts.Punctuate
+	}
+	return p.enabled
+}
+
+// Process 为识别文本添加标点
+func (p *Punctuator) Process(ctx context.Context, text string) (string, error) {
+	var punct *sherpa.OfflinePunctuation
+	select {
+	case punct = <-p.slots:
+	case <-ctx.Done():
+		return text, ctx.Err()
+	}
+	defer func() { p.slots <- punct }()
+
+	return punct.AddPunct(text), nil
+}
+
+// Close 释放全部实例
+func (p *Punctuator) Close() error {
+	for {
+		select {
+		case punct := <-p.slots:
+			sherpa.DeleteOfflinePunc(punct)
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package asr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// newTestModel 使用模拟Provider创建识别模型
+func newTestModel(t *testing.T, cfg *config.ASRConfig, text string) *Manager {
+	t.Helper()
+	manager, err := newManager(cfg, 1, func(cfg *config.ASRConfig) (Provider, error) {
+		return &mockProvider{transcribeResult: text}, nil
+	})
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	return manager
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	defer r.Close()
+
+	zh := newTestModel(t, &config.ASRConfig{ModelType: config.ASRModelSenseVoice, Language: "zh", Provider: config.ProviderConfig{Provider: "cpu"}}, "你好")
+	en := newTestModel(t, &config.ASRConfig{ModelType: config.ASRModelWhisper, Languages: []string{"en"}}, "hello")
+	if err := r.Register("sensevoice-zh", zh); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.Register("whisper-en", en); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.Register("whisper-en", en); err == nil {
+		t.Error("Expected error for duplicate model name")
+	}
+
+	// 第一个注册的模型为默认模型
+	if m, err := r.Get(""); err != nil || m != zh {
+		t.Errorf("Expected default model sensevoice-zh, got %v, %v", m, err)
+	}
+	if m, err := r.Get("whisper-en"); err != nil || m != en {
+		t.Errorf("Expected whisper-en, got %v, %v", m, err)
+	}
+	if _, err := r.Get("missing"); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+
+	if err := r.SetDefault("whisper-en"); err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+	if r.Default() != en || r.DefaultName() != "whisper-en" {
+		t.Error("Expected whisper-en to be the default model")
+	}
+	if err := r.SetDefault("missing"); err == nil {
+		t.Error("Expected error for unknown default model")
+	}
+
+	infos := r.List()
+	if len(infos) != 2 || infos[0].Name != "sensevoice-zh" || infos[1].Name != "whisper-en" {
+		t.Fatalf("Unexpected model list: %+v", infos)
+	}
+	if infos[0].Type != config.ASRModelSenseVoice || len(infos[0].Languages) != 1 || infos[0].Languages[0] != "zh" {
+		t.Errorf("Unexpected info for sensevoice-zh: %+v", infos[0])
+	}
+	if !infos[1].Default || infos[0].Default {
+		t.Error("Expected only whisper-en to be marked as default")
+	}
+	if infos[1].SampleRate != 16000 || infos[1].Pool == nil {
+		t.Errorf("Unexpected info for whisper-en: %+v", infos[1])
+	}
+}
@@ -0,0 +1,154 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+)
+
+// Route 路由结果
+type Route struct {
+	Model    string `json:"model"`              // 使用的模型名称
+	Language string `json:"language,omitempty"` // 使用的语言，未知时为空
+	Detected bool   `json:"detected"`           // 语言是否由语种识别得到
+}
+
+// Router 按语言在识别模型之间路由：
+// 请求声明的语言优先，未声明时使用语种识别（如已启用），
+// 语言先匹配routing.rules，再匹配模型的languages，都不匹配时使用fallback模型
+type Router struct {
+	registry   *Registry
+	identifier LanguageIdentifier // 为nil时不做语种识别
+	rules      map[string]string
+	fallback   string
+	detect     config.LanguageIDConfig // 语种检测的音频长度和窗口数
+}
+
+// NewRouter 创建语言路由器，identifier可以为nil
+func NewRouter(registry *Registry, routing *config.ASRRoutingConfig, identifier LanguageIdentifier) *Router {
+	rules := make(map[string]string, len(routing.Rules))
+	for lang, model := range routing.Rules {
+		rules[normalizeLanguage(lang)] = model
+	}
+	return &Router{
+		registry:   registry,
+		identifier: identifier,
+		rules:      rules,
+		fallback:   routing.Fallback,
+		detect:     routing.LanguageID,
+	}
+}
+
+// Route 为请求选择模型，language为客户端声明的语言（为空或 "auto" 时自动识别）
+func (r *Router) Route(ctx context.Context, language string, audio []byte) (Route, error) {
+	route := Route{Language: normalizeLanguage(language)}
+
+	if route.Language == "" && r.identifier != nil && len(audio) > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		lang, err := r.identifier.Identify(ctx, audio)
+		if err != nil {
+			// 语种识别失败不影响识别，使用fallback模型
+			logger.Warnf("Language identification failed, using fallback model: %v", err)
+		} else {
+			route.Language = normalizeLanguage(lang)
+			route.Detected = true
+		}
+	}
+
+	route.Model = r.match(route.Language)
+	if _, err := r.registry.Get(route.Model); err != nil {
+		return route, err
+	}
+	return route, nil
+}
+
+// CanDetect 是否启用了语种识别
+func (r *Router) CanDetect() bool {
+	return r.identifier != nil
+}
+
+// Detect 检测音频的语种和置信度（见DetectLanguage）
+func (r *Router) Detect(ctx context.Context, audio []byte) (*config.LanguageDetection, error) {
+	if r.identifier == nil {
+		return nil, fmt.Errorf("language identification is not enabled")
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("audio data is empty")
+	}
+	windows := r.detect.DetectWindows
+	if windows <= 0 {
+		windows = 3
+	}
+	return DetectLanguage(ctx, r.identifier, audio, r.detect.MaxDuration, windows)
+}
+
+// Recognize 路由并识别音频
+func (r *Router) Recognize(ctx context.Context, language string, audio []byte) (string, Route, error) {
+	route, err := r.Route(ctx, language, audio)
+	if err != nil {
+		return "", route, err
+	}
+	manager, err := r.registry.Get(route.Model)
+	if err != nil {
+		return "", route, err
+	}
+	text, err := manager.Transcribe(ctx, audio)
+	if err != nil {
+		return "", route, fmt.Errorf("model %s: %w", route.Model, err)
+	}
+	return text, route, nil
+}
+
+// match 按规则、模型语言、fallback的顺序选择模型
+func (r *Router) match(language string) string {
+	if language != "" {
+		candidates := []string{language}
+		if base := baseLanguage(language); base != language {
+			candidates = append(candidates, base)
+		}
+		for _, lang := range candidates {
+			if model, ok := r.rules[lang]; ok {
+				return model
+			}
+		}
+		for _, lang := range candidates {
+			if model, ok := r.registry.FindByLanguage(lang); ok {
+				return model
+			}
+		}
+	}
+	if r.fallback != "" {
+		return r.fallback
+	}
+	return r.registry.DefaultName()
+}
+
+// Close 释放语种识别器
+func (r *Router) Close() error {
+	if r.identifier != nil {
+		return r.identifier.Close()
+	}
+	return nil
+}
+
+// normalizeLanguage 规范化语言代码："auto"视为未指定，统一小写，"_"替换为"-"
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "auto" {
+		return ""
+	}
+	return strings.ReplaceAll(language, "_", "-")
+}
+
+// baseLanguage 返回主语言代码（如 "zh-cn" 返回 "zh"）
+func baseLanguage(language string) string {
+	if i := strings.Index(language, "-"); i > 0 {
+		return language[:i]
+	}
+	return language
+}
@@ -0,0 +1,179 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// fakeIdentifier 返回固定结果的语种识别器
+type fakeIdentifier struct {
+	lang  string
+	err   error
+	calls int
+}
+
+func (f *fakeIdentifier) Identify(ctx context.Context, audio []byte) (string, error) {
+	f.calls++
+	return f.lang, f.err
+}
+
+func (f *fakeIdentifier) Close() error {
+	return nil
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	t.Cleanup(func() { r.Close() })
+
+	models := []struct {
+		name string
+		cfg  *config.ASRConfig
+	}{
+		{"sensevoice", &config.ASRConfig{Languages: []string{"zh", "en", "yue"}}},
+		{"whisper-en", &config.ASRConfig{Languages: []string{"en"}}},
+		{"paraformer-zh", &config.ASRConfig{Language: "zh"}},
+	}
+	for _, m := range models {
+		if err := r.Register(m.name, newTestModel(t, m.cfg, m.name)); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	return r
+}
+
+func TestRouter_Route(t *testing.T) {
+	registry := newTestRegistry(t)
+	router := NewRouter(registry, &config.ASRRoutingConfig{
+		Rules:    map[string]string{"EN": "whisper-en", "zh_TW": "sensevoice"},
+		Fallback: "paraformer-zh",
+	}, nil)
+
+	tests := []struct {
+		language string
+		want     Route
+	}{
+		{"en", Route{Model: "whisper-en", Language: "en"}},       // 规则优先
+		{"en-US", Route{Model: "whisper-en", Language: "en-us"}}, // 主语言匹配规则
+		{"zh-TW", Route{Model: "sensevoice", Language: "zh-tw"}}, // 规则键同样规范化
+		{"yue", Route{Model: "sensevoice", Language: "yue"}},     // 匹配模型languages
+		{"zh", Route{Model: "sensevoice", Language: "zh"}},       // 按注册顺序
+		{"fr", Route{Model: "paraformer-zh", Language: "fr"}},    // 无匹配时fallback
+		{"auto", Route{Model: "paraformer-zh"}},                  // 未知语言使用fallback
+		{"", Route{Model: "paraformer-zh"}},
+	}
+	for _, tt := range tests {
+		got, err := router.Route(context.Background(), tt.language, []byte{0, 0})
+		if err != nil {
+			t.Fatalf("Route(%q) error = %v", tt.language, err)
+		}
+		if got != tt.want {
+			t.Errorf("Route(%q) = %+v, want %+v", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestRouter_LanguageIdentification(t *testing.T) {
+	registry := newTestRegistry(t)
+	identifier := &fakeIdentifier{lang: "en"}
+	router := NewRouter(registry, &config.ASRRoutingConfig{}, identifier)
+
+	text, route, err := router.Recognize(context.Background(), "", []byte{0, 0})
+	if err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+	if route != (Route{Model: "sensevoice", Language: "en", Detected: true}) || text != "sensevoice" {
+		t.Errorf("Unexpected route %+v, text %q", route, text)
+	}
+
+	// 声明语言时不做语种识别
+	if route, _ := router.Route(context.Background(), "zh", []byte{0, 0}); route.Detected || identifier.calls != 1 {
+		t.Errorf("Expected declared language to skip identification, got %+v (calls=%d)", route, identifier.calls)
+	}
+
+	// 语种识别失败时使用默认模型
+	identifier.err = errors.New("boom")
+	route, err = router.Route(context.Background(), "", []byte{0, 0})
+	if err != nil || route != (Route{Model: "sensevoice"}) {
+		t.Errorf("Expected fallback to default model, got %+v, %v", route, err)
+	}
+}
+
+func TestRouter_UnknownModel(t *testing.T) {
+	router := NewRouter(newTestRegistry(t), &config.ASRRoutingConfig{Fallback: "missing"}, nil)
+	if _, err := router.Route(context.Background(), "fr", nil); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+}
+
+// byteIdentifier 按音频首字节返回语种
+type byteIdentifier struct {
+	langs map[byte]string
+}
+
+func (b *byteIdentifier) Identify(ctx context.Context, audio []byte) (string, error) {
+	if lang, ok := b.langs[audio[0]]; ok {
+		return lang, nil
+	}
+	return "", errors.New("language could not be identified")
+}
+
+func (b *byteIdentifier) Close() error {
+	return nil
+}
+
+func TestRouter_Detect(t *testing.T) {
+	registry := newTestRegistry(t)
+	identifier := &byteIdentifier{langs: map[byte]string{1: "zh", 2: "EN"}}
+	routing := &config.ASRRoutingConfig{LanguageID: config.LanguageIDConfig{MaxDuration: 10, DetectWindows: 3}}
+	router := NewRouter(registry, routing, identifier)
+	if !router.CanDetect() {
+		t.Fatal("Expected router with identifier to detect languages")
+	}
+
+	// 9秒音频：前6秒中文，后3秒英文
+	second := modelSampleRate * 2
+	audio := make([]byte, 9*second)
+	for i := range audio {
+		if i < 6*second {
+			audio[i] = 1
+		} else {
+			audio[i] = 2
+		}
+	}
+	detection, err := router.Detect(context.Background(), audio)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if detection.Language != "zh" || detection.Votes != 4 || detection.Confidence != 0.75 || len(detection.Windows) != 3 {
+		t.Errorf("Unexpected detection: %+v", detection)
+	}
+	if last := detection.Windows[2]; last.Start != 6 || last.End != 9 || last.Language != "en" {
+		t.Errorf("Unexpected last window: %+v", last)
+	}
+
+	// 短音频只识别整段
+	detection, err = router.Detect(context.Background(), audio[:3*second])
+	if err != nil || detection.Votes != 1 || detection.Confidence != 1 || detection.Windows != nil {
+		t.Errorf("Unexpected detection for short audio: %+v, %v", detection, err)
+	}
+
+	// 无法识别的窗口不参与估计
+	for i := 3 * second; i < 6*second; i++ {
+		audio[i] = 9
+	}
+	detection, err = router.Detect(context.Background(), audio)
+	if err != nil || detection.Votes != 3 || detection.Confidence != 0.667 {
+		t.Errorf("Unexpected detection with failed window: %+v, %v", detection, err)
+	}
+
+	if _, err := router.Detect(context.Background(), []byte{9, 0}); err == nil {
+		t.Error("Expected error when language cannot be identified")
+	}
+	if _, err := NewRouter(registry, routing, nil).Detect(context.Background(), audio); err == nil {
+		t.Error("Expected error without identifier")
+	}
+}
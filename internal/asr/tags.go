@@ -0,0 +1,55 @@
+package asr
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Recognition Provider的识别结果，Tags为SenseVoice原始的内联标签（如 "<|zh|><|NEUTRAL|><|Speech|>"）
+type Recognition struct {
+	config.ASRResult
+	Tags string
+}
+
+// inlineTagPattern SenseVoice的内联标签
+var inlineTagPattern = regexp.MustCompile(`<\|[^|<>]*\|>`)
+
+// newRecognition 转换sherpa-onnx识别结果：文本去除内联标签，标签转为小写的纯值
+func newRecognition(result *sherpa.OfflineRecognizerResult) *Recognition {
+	if result == nil {
+		// result为nil可能是正常情况（例如空音频或静音）
+		return &Recognition{}
+	}
+	return &Recognition{
+		ASRResult: config.ASRResult{
+			Text:    stripTags(result.Text),
+			Lang:    tagValue(result.Lang),
+			Emotion: tagValue(result.Emotion),
+			Event:   tagValue(result.Event),
+		},
+		Tags: result.Lang + result.Emotion + result.Event,
+	}
+}
+
+// stripTags 去除文本中的内联标签
+func stripTags(text string) string {
+	if !strings.Contains(text, "<|") {
+		return text
+	}
+	return strings.TrimSpace(inlineTagPattern.ReplaceAllString(text, ""))
+}
+
+// tagValue 将 "<|NEUTRAL|>" 形式的标签转为 "neutral"
+func tagValue(tag string) string {
+	tag = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(tag), "<|"), "|>")
+	return strings.ToLower(tag)
+}
+
+// keepTags 判断是否在结果文本中保留内联标签
+func keepTags(opts *config.ASROptions) bool {
+	return opts != nil && opts.KeepTags != nil && *opts.KeepTags
+}
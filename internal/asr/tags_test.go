@@ -0,0 +1,71 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+func TestNewRecognition(t *testing.T) {
+	got := newRecognition(&sherpa.OfflineRecognizerResult{
+		Text:    "<|zh|><|HAPPY|><|Laughter|>哈哈哈你好",
+		Lang:    "<|zh|>",
+		Emotion: "<|HAPPY|>",
+		Event:   "<|Laughter|>",
+	})
+	want := config.ASRResult{Text: "哈哈哈你好", Lang: "zh", Emotion: "happy", Event: "laughter"}
+	if got.ASRResult != want {
+		t.Errorf("newRecognition() = %+v, want %+v", got.ASRResult, want)
+	}
+	if got.Tags != "<|zh|><|HAPPY|><|Laughter|>" {
+		t.Errorf("Unexpected tags: %q", got.Tags)
+	}
+
+	// 其他模型没有标签，文本保持不变
+	plain := newRecognition(&sherpa.OfflineRecognizerResult{Text: "a <b> c"})
+	if plain.Text != "a <b> c" || plain.Lang != "" || plain.Tags != "" {
+		t.Errorf("Unexpected plain recognition: %+v", plain)
+	}
+	if empty := newRecognition(nil); empty.Text != "" || empty.Tags != "" {
+		t.Errorf("Expected empty recognition for nil result, got %+v", empty)
+	}
+}
+
+func TestManager_RecognizeTags(t *testing.T) {
+	factory := func(cfg *config.ASRConfig) (Provider, error) {
+		return &mockProvider{transcribeResult: "你好", tags: "<|zh|><|HAPPY|><|Laughter|>"}, nil
+	}
+	manager, err := newManager(&config.ASRConfig{ModelType: config.ASRModelSenseVoice}, 1, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	defer manager.Close()
+	manager.pool.postprocess = &PostProcessChain{processors: []TextProcessor{&fakeProcessor{suffix: "。", enabled: true}}}
+
+	result, err := manager.Recognize(context.Background(), []byte("audio"), nil)
+	if err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+	want := config.ASRResult{Text: "你好。", Lang: "zh", Emotion: "happy", Event: "laughter"}
+	if *result != want {
+		t.Errorf("Recognize() = %+v, want %+v", *result, want)
+	}
+
+	// 保留标签时标签不参与后处理
+	keep := true
+	result, err = manager.Recognize(context.Background(), []byte("audio"), &config.ASROptions{KeepTags: &keep})
+	if err != nil {
+		t.Fatalf("Recognize() error = %v", err)
+	}
+	if result.Text != "<|zh|><|HAPPY|><|Laughter|>你好。" {
+		t.Errorf("Recognize() with keep_tags text = %q", result.Text)
+	}
+
+	if err := (&Manager{config: &config.ASRConfig{ModelType: config.ASRModelParaformer}}).ValidateOptions(&config.ASROptions{KeepTags: &keep}); !errors.Is(err, ErrUnsupportedOption) {
+		t.Errorf("Expected ErrUnsupportedOption for keep_tags on paraformer, got %v", err)
+	}
+}
@@ -2,25 +2,47 @@ package bootstrap
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/asr"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config/hotreload"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/hotwords"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/jobs"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/middleware"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/speakers"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/enhance"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/kws"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/speaker"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/tagging"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/tts"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/vad"
 	"time"
 )
 
 // AppDependencies 应用依赖
 type AppDependencies struct {
-	Config         *config.UnifiedConfig
-	ASRManager     *asr.Manager
-	TTSManager     *tts.Manager
-	SessionManager *session.Manager
-	RateLimiter    *middleware.RateLimiter
-	HotReloadMgr   *hotreload.HotReloadManager
+	Config          *config.UnifiedConfig
+	ASRManager      *asr.Manager // 默认识别模型
+	TTSManager      *tts.Manager // 默认合成模型
+	ASRModels       *asr.Registry
+	TTSModels       *tts.Registry
+	ASRRouter       *asr.Router        // 按语言选择识别模型
+	Hotwords        *hotwords.Store    // 命名热词集，未配置hotwords.dir时为nil
+	Diarizer        *speaker.Diarizer  // 说话人分离，未启用时为nil
+	Speakers        *speakers.Store    // 注册说话人，未启用说话人验证时为nil
+	SpeakerEmbedder *speaker.Extractor // 说话人向量提取，未启用说话人验证时为nil
+	KeywordSpotter  *kws.Spotter       // 关键词检测，未启用时为nil
+	AudioTagger     *tagging.Tagger    // 音频事件标注，未启用时为nil
+	Enhancer        *enhance.Denoiser  // 语音增强（降噪），未启用时为nil
+	VoiceDetector   *vad.Detector      // 语音活动检测（语音对话和STT会话分句），未加载VAD模型时为nil
+	SessionManager  *session.Manager
+	RateLimiter     *middleware.RateLimiter
+	Authenticator   *middleware.Authenticator
+	JobManager      *jobs.Manager
+	HotReloadMgr    *hotreload.HotReloadManager
 }
 
 // InitApp 初始化应用
@@ -33,14 +55,12 @@ func InitApp(cfg *config.UnifiedConfig) (*AppDependencies, error) {
 
 	// 初始化配置热加载管理器
 	logger.Info("Initializing hot reload manager...")
-	hotReloadMgr, err := hotreload.NewHotReloadManager()
+	hotReloadMgr, err := hotreload.NewHotReloadManager(cfg)
 	if err != nil {
 		logger.Errorf("Failed to initialize hot reload manager: %v", err)
 		// 热重载失败不影响主流程
 	} else {
 		deps.HotReloadMgr = hotReloadMgr
-		// 注册配置变更回调
-		registerHotReloadCallbacks(hotReloadMgr)
 	}
 
 	// 初始化限流器
@@ -54,57 +74,333 @@ func InitApp(cfg *config.UnifiedConfig) (*AppDependencies, error) {
 	)
 	deps.RateLimiter = rateLimiter
 
-	// 初始化ASR管理器
-	if cfg.STT != nil {
-		logger.Info("Initializing ASR manager...")
-		poolSize := 4 // 默认池大小
-		asrManager, err := asr.NewManager(cfg.STT, poolSize)
+	// 初始化认证器
+	logger.Infof("Initializing authenticator... enabled=%v", cfg.Auth.Enabled)
+	authenticator, err := middleware.NewAuthenticator(&cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create authenticator: %w", err)
+	}
+	deps.Authenticator = authenticator
+
+	// 初始化ASR模型（每个模型一个管理器，默认模型同时作为ASRManager）
+	if asrModels := cfg.ASRModels(); len(asrModels) > 0 {
+		logger.Infof("Initializing %d ASR model(s)...", len(asrModels))
+		registry, err := asr.NewRegistryFromConfig(asrModels, cfg.Models.DefaultSTT)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create ASR manager: %w", err)
 		}
-		deps.ASRManager = asrManager
-		logger.Info("ASR manager initialized")
+		deps.ASRModels = registry
+		deps.ASRManager = registry.Default()
+		logger.Infof("ASR models initialized: %v (default: %s)", registry.Names(), registry.DefaultName())
+
+		router, err := initASRRouter(registry, &cfg.Models.Routing)
+		if err != nil {
+			registry.Close()
+			return nil, fmt.Errorf("failed to create ASR router: %w", err)
+		}
+		deps.ASRRouter = router
+	}
+
+	// 初始化命名热词集
+	if cfg.Hotwords.Dir != "" {
+		logger.Infof("Initializing hotword sets... dir=%s", cfg.Hotwords.Dir)
+		store, err := hotwords.NewStore(cfg.Hotwords.Dir, cfg.Hotwords.MaxWords)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create hotword store: %w", err)
+		}
+		deps.Hotwords = store
+		logger.Infof("Hotword sets loaded: %d", len(store.List()))
+
+		// 在后台为热词集预先构建热词识别器，避免首个请求等待模型加载
+		if deps.ASRModels != nil {
+			for _, name := range deps.ASRModels.Names() {
+				manager, err := deps.ASRModels.Get(name)
+				if err != nil {
+					continue
+				}
+				for _, set := range store.List() {
+					manager.PrepareHotwords(set.Words, 0)
+				}
+			}
+		}
+	}
+
+	// 初始化说话人分离
+	if cfg.Speaker.Diarization.Enabled {
+		logger.Infof("Initializing speaker diarization... segmentation=%s, embedding=%s",
+			cfg.Speaker.Diarization.SegmentationModel, cfg.Speaker.EmbeddingModel)
+		diarizer, err := speaker.NewDiarizer(&cfg.Speaker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create speaker diarizer: %w", err)
+		}
+		deps.Diarizer = diarizer
 	}
 
-	// 初始化TTS管理器
-	if cfg.TTS != nil {
-		logger.Info("Initializing TTS manager...")
-		poolSize := 5 // TTS默认池大小较小
-		ttsManager, err := tts.NewManager(cfg.TTS, poolSize)
+	// 初始化说话人验证
+	if cfg.Speaker.Verification.Enabled {
+		logger.Infof("Initializing speaker verification... embedding=%s, store=%s",
+			cfg.Speaker.EmbeddingModel, cfg.Speaker.Verification.StoreDir)
+		store, err := speakers.NewStore(cfg.Speaker.Verification.StoreDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create speaker store: %w", err)
+		}
+		extractor, err := speaker.NewExtractor(&cfg.Speaker)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create speaker embedding extractor: %w", err)
+		}
+		deps.Speakers = store
+		deps.SpeakerEmbedder = extractor
+		logger.Infof("Speakers loaded: %d (embedding dim: %d)", len(store.List()), extractor.Dim())
+	}
+
+	// 初始化关键词检测
+	if cfg.KWS.Enabled {
+		logger.Infof("Initializing keyword spotter... encoder=%s, pool_size=%d", cfg.KWS.EncoderPath, cfg.KWS.PoolSize)
+		spotter, err := kws.NewSpotter(&cfg.KWS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create keyword spotter: %w", err)
+		}
+		deps.KeywordSpotter = spotter
+		logger.Infof("Keyword spotter initialized: %d global keyword(s)", len(spotter.Keywords()))
+	}
+
+	// 初始化音频事件标注
+	if cfg.AudioTagging.Enabled {
+		logger.Infof("Initializing audio tagging... model=%s, pool_size=%d", cfg.AudioTagging.ModelPath, cfg.AudioTagging.PoolSize)
+		tagger, err := tagging.NewTagger(&cfg.AudioTagging)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create audio tagger: %w", err)
+		}
+		deps.AudioTagger = tagger
+	}
+
+	// 初始化语音增强
+	if cfg.Enhancement.Enabled {
+		logger.Infof("Initializing speech enhancement... model=%s, pool_size=%d", cfg.Enhancement.ModelPath, cfg.Enhancement.PoolSize)
+		denoiser, err := enhance.NewDenoiser(&cfg.Enhancement)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create speech denoiser: %w", err)
+		}
+		deps.Enhancer = denoiser
+	}
+
+	// 初始化语音活动检测（语音对话必需，STT会话可通过start消息开启）
+	if cfg.Voice.Enabled || (cfg.VAD.Enabled && cfg.VAD.ModelPath != "") {
+		logger.Infof("Initializing voice activity detector... type=%s, model=%s", cfg.VAD.Provider, cfg.VAD.ModelPath)
+		detector, err := vad.NewDetector(&cfg.VAD)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create voice activity detector: %w", err)
+		}
+		deps.VoiceDetector = detector
+	}
+
+	// 初始化TTS模型
+	if ttsModels := cfg.TTSModels(); len(ttsModels) > 0 {
+		logger.Infof("Initializing %d TTS model(s)...", len(ttsModels))
+		registry, err := tts.NewRegistryFromConfig(ttsModels, cfg.Models.DefaultTTS)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create TTS manager: %w", err)
 		}
-		deps.TTSManager = ttsManager
-		logger.Info("TTS manager initialized")
+		deps.TTSModels = registry
+		deps.TTSManager = registry.Default()
+		logger.Infof("TTS models initialized: %v (default: %s)", registry.Names(), registry.DefaultName())
 	}
 
 	// 初始化会话管理器
 	logger.Info("Initializing session manager...")
-	sessionManager := session.NewManager(1000, 30*time.Minute)
+	sessionManager := session.NewManager(cfg.Session.MaxSessions, time.Duration(cfg.Session.Timeout)*time.Second)
 	deps.SessionManager = sessionManager
 
+	// 初始化异步任务管理器
+	if cfg.Jobs.Enabled {
+		logger.Infof("Initializing job manager... store_dir=%s, workers=%d", cfg.Jobs.StoreDir, cfg.Jobs.Workers)
+		jobManager, err := initJobManager(cfg, deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create job manager: %w", err)
+		}
+		deps.JobManager = jobManager
+	}
+
+	// 注册配置变更回调
+	registerHotReloadCallbacks(deps.HotReloadMgr, deps)
+
 	logger.Info("All components initialized successfully")
 	return deps, nil
 }
 
+// initASRRouter 创建识别模型的语言路由器，启用语种识别时加载语种识别模型
+func initASRRouter(registry *asr.Registry, routing *config.ASRRoutingConfig) (*asr.Router, error) {
+	var identifier asr.LanguageIdentifier
+	if routing.LanguageID.Enabled {
+		logger.Infof("Initializing language identification... encoder=%s, pool_size=%d",
+			routing.LanguageID.EncoderPath, routing.LanguageID.PoolSize)
+		slid, err := asr.NewLanguageIdentifier(&routing.LanguageID)
+		if err != nil {
+			return nil, err
+		}
+		identifier = slid
+	}
+	return asr.NewRouter(registry, routing, identifier), nil
+}
+
+// initJobManager 创建任务管理器并注册各类任务的处理器
+func initJobManager(cfg *config.UnifiedConfig, deps *AppDependencies) (*jobs.Manager, error) {
+	store, err := jobs.NewFileStore(cfg.Jobs.StoreDir)
+	if err != nil {
+		return nil, err
+	}
+
+	jobManager, err := jobs.NewManager(&cfg.Jobs, store)
+	if err != nil {
+		return nil, err
+	}
+
+	if deps.ASRManager != nil {
+		models := func(name string) (jobs.Transcriber, error) {
+			manager, err := deps.ASRModels.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			return manager, nil
+		}
+		jobManager.RegisterProcessor(jobs.TypeSTT, jobs.NewSTTProcessor(deps.ASRManager, models, cfg.Audio.SampleRate, cfg.Jobs.ChunkSeconds))
+	}
+	if deps.TTSManager != nil {
+		models := func(name string) (jobs.Synthesizer, error) {
+			manager, err := deps.TTSModels.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			return manager, nil
+		}
+		jobManager.RegisterProcessor(jobs.TypeTTS, jobs.NewTTSProcessor(deps.TTSManager, models, &cfg.Jobs.TTS))
+	}
+
+	jobManager.Start()
+	return jobManager, nil
+}
+
 // registerHotReloadCallbacks 注册配置热加载回调
-func registerHotReloadCallbacks(hotReloadMgr *hotreload.HotReloadManager) {
+// 只有这里（以及启动时额外注册）的配置项支持热加载，其他配置项变更需要重启
+func registerHotReloadCallbacks(hotReloadMgr *hotreload.HotReloadManager, deps *AppDependencies) {
 	if hotReloadMgr == nil {
 		return
 	}
 
-	hotReloadMgr.RegisterCallback("logging.level", func() {
-		logger.Info("Log level changed, reloading...")
-	})
-	hotReloadMgr.RegisterCallback("rate_limit", func() {
-		logger.Info("Rate limit configuration changed")
-	})
-	hotReloadMgr.RegisterCallback("session", func() {
-		logger.Info("Session configuration changed")
+	hotReloadMgr.RegisterCallback([]string{"logging.level", "logging.format"}, func(cfg *config.UnifiedConfig) error {
+		if err := logger.SetLevel(cfg.Logging.Level); err != nil {
+			return err
+		}
+		if err := logger.SetFormat(cfg.Logging.Format); err != nil {
+			return err
+		}
+		logger.Infof("Logging updated: level=%s, format=%s", cfg.Logging.Level, cfg.Logging.Format)
+		return nil
 	})
+
+	if deps.RateLimiter != nil {
+		hotReloadMgr.RegisterCallback([]string{
+			"rate_limit.requests_per_second",
+			"rate_limit.burst_size",
+			"rate_limit.max_connections",
+		}, func(cfg *config.UnifiedConfig) error {
+			rl := cfg.RateLimit
+			if rl.RequestsPerSecond < 0 || rl.BurstSize < 0 || rl.MaxConnections < 0 {
+				return fmt.Errorf("rate limit values must not be negative")
+			}
+			deps.RateLimiter.UpdateLimits(rl.RequestsPerSecond, rl.BurstSize, rl.MaxConnections)
+			logger.Infof("Rate limit updated: requests_per_second=%d, burst_size=%d, max_connections=%d",
+				rl.RequestsPerSecond, rl.BurstSize, rl.MaxConnections)
+			return nil
+		})
+	}
+
+	if deps.SessionManager != nil {
+		hotReloadMgr.RegisterCallback([]string{"session.max_sessions", "session.session_timeout"}, func(cfg *config.UnifiedConfig) error {
+			if cfg.Session.MaxSessions < 0 || cfg.Session.Timeout < 0 {
+				return fmt.Errorf("session limits must not be negative")
+			}
+			deps.SessionManager.SetLimits(cfg.Session.MaxSessions, time.Duration(cfg.Session.Timeout)*time.Second)
+			logger.Infof("Session limits updated: max_sessions=%d, timeout=%ds", cfg.Session.MaxSessions, cfg.Session.Timeout)
+			return nil
+		})
+	}
+
+	// 模型配置变更：后台加载并预热新模型后无缝切换，失败时保留当前模型
+	if deps.ASRModels != nil {
+		keys, _ := hotreload.SectionKeys(deps.Config, "stt")
+		hotReloadMgr.RegisterCallback(append(keys, "models.stt"), func(cfg *config.UnifiedConfig) error {
+			return reloadASRModels(deps.ASRModels, cfg.ASRModels())
+		})
+	}
+
+	if deps.TTSModels != nil {
+		keys, _ := hotreload.SectionKeys(deps.Config, "tts")
+		hotReloadMgr.RegisterCallback(append(keys, "models.tts"), func(cfg *config.UnifiedConfig) error {
+			return reloadTTSModels(deps.TTSModels, cfg.TTSModels())
+		})
+	}
+
 	logger.Info("Hot reload callbacks registered")
 }
 
+// reloadASRModels 热切换配置发生变化的识别模型，增删模型需要重启
+func reloadASRModels(registry *asr.Registry, models []*config.ASRConfig) error {
+	if err := checkModelNames(registry.Names(), len(models), func(i int) string { return models[i].Name }); err != nil {
+		return err
+	}
+	for _, cfg := range models {
+		manager, err := registry.Get(cfg.Name)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(manager.GetModelConfig(), cfg) {
+			continue
+		}
+		if err := manager.Reload(cfg); err != nil {
+			return fmt.Errorf("model %s: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// reloadTTSModels 热切换配置发生变化的合成模型，增删模型需要重启
+func reloadTTSModels(registry *tts.Registry, models []*config.TTSModelConfig) error {
+	if err := checkModelNames(registry.Names(), len(models), func(i int) string { return models[i].Name }); err != nil {
+		return err
+	}
+	for _, cfg := range models {
+		manager, err := registry.Get(cfg.Name)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(manager.GetModelConfig(), cfg) {
+			continue
+		}
+		if err := manager.Reload(cfg); err != nil {
+			return fmt.Errorf("model %s: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkModelNames 检查配置中的模型集合与已加载的一致
+func checkModelNames(loaded []string, count int, name func(i int) string) error {
+	names := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		names[name(i)] = true
+	}
+	if len(names) != len(loaded) {
+		return fmt.Errorf("adding or removing models requires a restart")
+	}
+	for _, n := range loaded {
+		if !names[n] {
+			return fmt.Errorf("adding or removing models requires a restart")
+		}
+	}
+	return nil
+}
+
 // Close 关闭应用
 func (d *AppDependencies) Close() error {
 	logger.Info("Shutting down application...")
@@ -114,17 +410,69 @@ func (d *AppDependencies) Close() error {
 		d.HotReloadMgr.Stop()
 	}
 
-	// 关闭ASR管理器
-	if d.ASRManager != nil {
-		if err := d.ASRManager.Close(); err != nil {
-			logger.Errorf("Failed to close ASR manager: %v", err)
+	// 关闭认证器
+	if d.Authenticator != nil {
+		d.Authenticator.Close()
+	}
+
+	// 关闭任务管理器（执行中的任务会在重启后继续）
+	if d.JobManager != nil {
+		if err := d.JobManager.Close(); err != nil {
+			logger.Errorf("Failed to close job manager: %v", err)
+		}
+	}
+
+	// 关闭语种识别
+	if d.ASRRouter != nil {
+		if err := d.ASRRouter.Close(); err != nil {
+			logger.Errorf("Failed to close ASR router: %v", err)
+		}
+	}
+
+	// 关闭说话人分离和说话人向量提取
+	if d.Diarizer != nil {
+		if err := d.Diarizer.Close(); err != nil {
+			logger.Errorf("Failed to close speaker diarizer: %v", err)
+		}
+	}
+	if d.SpeakerEmbedder != nil {
+		if err := d.SpeakerEmbedder.Close(); err != nil {
+			logger.Errorf("Failed to close speaker embedding extractor: %v", err)
+		}
+	}
+
+	// 关闭关键词检测
+	if d.KeywordSpotter != nil {
+		if err := d.KeywordSpotter.Close(); err != nil {
+			logger.Errorf("Failed to close keyword spotter: %v", err)
+		}
+	}
+
+	// 关闭音频事件标注
+	if d.AudioTagger != nil {
+		if err := d.AudioTagger.Close(); err != nil {
+			logger.Errorf("Failed to close audio tagger: %v", err)
+		}
+	}
+
+	// 关闭语音增强
+	if d.Enhancer != nil {
+		if err := d.Enhancer.Close(); err != nil {
+			logger.Errorf("Failed to close speech denoiser: %v", err)
+		}
+	}
+
+	// 关闭ASR模型
+	if d.ASRModels != nil {
+		if err := d.ASRModels.Close(); err != nil {
+			logger.Errorf("Failed to close ASR models: %v", err)
 		}
 	}
 
-	// 关闭TTS管理器
-	if d.TTSManager != nil {
-		if err := d.TTSManager.Close(); err != nil {
-			logger.Errorf("Failed to close TTS manager: %v", err)
+	// 关闭TTS模型
+	if d.TTSModels != nil {
+		if err := d.TTSModels.Close(); err != nil {
+			logger.Errorf("Failed to close TTS models: %v", err)
 		}
 	}
 
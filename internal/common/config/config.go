@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -18,25 +21,368 @@ type ProviderConfig struct {
 
 // ASRConfig ASR配置
 type ASRConfig struct {
-	ModelPath  string          `mapstructure:"model_path" json:"model_path"`
-	TokensPath string          `mapstructure:"tokens_path" json:"tokens_path"`
-	Language   string          `mapstructure:"language" json:"language"`
-	Provider   ProviderConfig  `mapstructure:"provider" json:"provider"`
-	Debug      bool            `mapstructure:"debug" json:"debug"`
+	Name        string   `mapstructure:"name" json:"name,omitempty"`             // 模型名称（多模型时用于按请求选择）
+	ModelType   string   `mapstructure:"model_type" json:"model_type,omitempty"` // "sense_voice"（默认）, "whisper", "paraformer", "transducer"
+	ModelPath   string   `mapstructure:"model_path" json:"model_path"`
+	EncoderPath string   `mapstructure:"encoder_path" json:"encoder_path,omitempty"` // whisper/transducer编码器
+	DecoderPath string   `mapstructure:"decoder_path" json:"decoder_path,omitempty"` // whisper/transducer解码器
+	JoinerPath  string   `mapstructure:"joiner_path" json:"joiner_path,omitempty"`   // transducer联合网络
+	TokensPath  string   `mapstructure:"tokens_path" json:"tokens_path"`
+	Language    string   `mapstructure:"language" json:"language"`
+	Languages   []string `mapstructure:"languages" json:"languages,omitempty"` // 模型支持的语言
+	UseITN      *bool    `mapstructure:"use_itn" json:"use_itn,omitempty"`     // 逆文本正则化（SenseVoice），默认开启
+	// 解码方法："greedy_search"（默认）或 "modified_beam_search"（仅transducer）
+	DecodingMethod string `mapstructure:"decoding_method" json:"decoding_method,omitempty"`
+	MaxActivePaths int    `mapstructure:"max_active_paths" json:"max_active_paths,omitempty"` // modified_beam_search的路径数，默认4
+	// 热词（仅transducer，使用modified_beam_search）：全局热词列表与sherpa-onnx热词文件
+	Hotwords         []string       `mapstructure:"hotwords" json:"hotwords,omitempty"`
+	HotwordsFile     string         `mapstructure:"hotwords_file" json:"hotwords_file,omitempty"`
+	HotwordsScore    float32        `mapstructure:"hotwords_score" json:"hotwords_score,omitempty"`         // 默认1.5
+	MaxHotwordModels int            `mapstructure:"max_hotword_models" json:"max_hotword_models,omitempty"` // 请求热词识别器的缓存数（每个加载一份模型），默认4
+	ModelingUnit     string         `mapstructure:"modeling_unit" json:"modeling_unit,omitempty"`           // 热词编码方式："cjkchar", "bpe", "cjkchar+bpe"
+	BpeVocab         string         `mapstructure:"bpe_vocab" json:"bpe_vocab,omitempty"`                   // modeling_unit包含bpe时需要
+	PoolSize         int            `mapstructure:"pool_size" json:"pool_size,omitempty"`                   // 资源池大小，默认4
+	Provider         ProviderConfig `mapstructure:"provider" json:"provider"`
+	Debug            bool           `mapstructure:"debug" json:"debug"`
+	// 识别结果后处理：标点恢复
+	Punctuation PunctuationConfig `mapstructure:"punctuation" json:"punctuation"`
+}
+
+// ASR模型类型
+const (
+	ASRModelSenseVoice = "sense_voice"
+	ASRModelWhisper    = "whisper"
+	ASRModelParaformer = "paraformer"
+	ASRModelTransducer = "transducer"
+)
+
+// 解码方法
+const (
+	DecodingGreedySearch       = "greedy_search"
+	DecodingModifiedBeamSearch = "modified_beam_search"
+)
+
+// MaxActivePathsLimit max_active_paths的上限
+const MaxActivePathsLimit = 64
+
+// ASROptions 单次识别（或单个WebSocket会话）的解码参数，零值字段使用模型配置
+type ASROptions struct {
+	Language       string   `json:"language,omitempty"`         // 识别语言（SenseVoice/Whisper），"auto"为自动
+	ITN            *bool    `json:"itn,omitempty"`              // 逆文本正则化（SenseVoice）
+	DecodingMethod string   `json:"decoding_method,omitempty"`  // greedy_search或modified_beam_search
+	MaxActivePaths int      `json:"max_active_paths,omitempty"` // modified_beam_search的路径数
+	Hotwords       []string `json:"hotwords,omitempty"`         // 本次请求的热词（与模型全局热词合并）
+	HotwordSets    []string `json:"hotword_sets,omitempty"`     // 使用的命名热词集，由处理器展开到SetHotwords
+	HotwordsScore  float32  `json:"hotwords_score,omitempty"`   // 本次请求热词的加分，默认使用模型配置
+	SetHotwords    []string `json:"-"`                          // 热词集展开后的热词（由处理器填充）
+	Punctuate      *bool    `json:"punctuate,omitempty"`        // 标点恢复（需要模型配置标点模型）
+	KeepTags       *bool    `json:"keep_tags,omitempty"`        // 在文本开头保留SenseVoice的语言/情感/事件标签（如 "<|zh|><|NEUTRAL|><|Speech|>"），默认去除
+}
+
+// ASRResult 结构化识别结果。Lang、Emotion、Event 为SenseVoice输出的语言、情感和音频事件标签
+// （小写、不含 "<|" "|>"，如 "zh"、"happy"、"laughter"），其他模型为空
+type ASRResult struct {
+	Text    string `json:"text"`
+	Lang    string `json:"lang,omitempty"`
+	Emotion string `json:"emotion,omitempty"`
+	Event   string `json:"event,omitempty"`
+}
+
+// LanguageDetection 语种检测结果。sherpa-onnx语种识别不输出概率，Confidence为
+// 整段音频和各时间窗口的识别结果中与Language一致的比例
+type LanguageDetection struct {
+	Language   string           `json:"language"`
+	Confidence float64          `json:"confidence"`
+	Votes      int              `json:"votes"` // 参与估计置信度的识别次数（整段 + 窗口）
+	Windows    []LanguageWindow `json:"windows,omitempty"`
+}
+
+// LanguageWindow 时间窗口的语种识别结果
+type LanguageWindow struct {
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Language string  `json:"language"`
+}
+
+// 热词限制
+const (
+	MaxHotwordLength      = 100 // 单个热词的最大字符数
+	MaxHotwordsPerRequest = 100 // 单次请求直接指定的最大热词数（不含热词集展开的热词）
+	MaxHotwordsScore      = 10  // hotwords_score的上限
+)
+
+// ValidateHotwords 检查热词：非空、不超过长度限制、不包含换行和 "/"（sherpa-onnx的热词分隔符）
+func ValidateHotwords(words []string) error {
+	for _, word := range words {
+		w := strings.TrimSpace(word)
+		if w == "" {
+			return fmt.Errorf("hotword must not be empty")
+		}
+		if len([]rune(w)) > MaxHotwordLength {
+			return fmt.Errorf("hotword %q exceeds %d characters", w, MaxHotwordLength)
+		}
+		if strings.ContainsAny(w, "/\r\n") {
+			return fmt.Errorf("hotword %q must not contain '/' or line breaks", w)
+		}
+	}
+	return nil
+}
+
+// Validate 检查参数取值（与模型是否支持无关）
+func (o *ASROptions) Validate() error {
+	switch o.DecodingMethod {
+	case "", DecodingGreedySearch, DecodingModifiedBeamSearch:
+	default:
+		return fmt.Errorf("invalid decoding_method: %s, must be %s or %s", o.DecodingMethod, DecodingGreedySearch, DecodingModifiedBeamSearch)
+	}
+	if o.MaxActivePaths < 0 || o.MaxActivePaths > MaxActivePathsLimit {
+		return fmt.Errorf("invalid max_active_paths: %d, must be between 1 and %d", o.MaxActivePaths, MaxActivePathsLimit)
+	}
+	if o.HotwordsScore != 0 {
+		if o.HotwordsScore < 0.1 || o.HotwordsScore > MaxHotwordsScore {
+			return fmt.Errorf("invalid hotwords_score: %g, must be between 0.1 and %d", o.HotwordsScore, MaxHotwordsScore)
+		}
+		if len(o.Hotwords) == 0 && len(o.HotwordSets) == 0 && len(o.SetHotwords) == 0 {
+			return fmt.Errorf("hotwords_score requires hotwords or hotword_sets")
+		}
+	}
+	return ValidateHotwords(o.Hotwords)
+}
+
+// ParseASROptions 从请求参数（表单字段、查询参数等）读取解码参数，get返回参数值，不存在时返回空字符串
+func ParseASROptions(get func(key string) string) (ASROptions, error) {
+	opts := ASROptions{
+		Language:       get("language"),
+		DecodingMethod: get("decoding_method"),
+		Hotwords:       splitList(get("hotwords")),
+		HotwordSets:    splitList(get("hotword_sets")),
+	}
+	if v := get("itn"); v != "" {
+		itn, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid itn: %s, must be true or false", v)
+		}
+		opts.ITN = &itn
+	}
+	if v := get("keep_tags"); v != "" {
+		keepTags, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid keep_tags: %s, must be true or false", v)
+		}
+		opts.KeepTags = &keepTags
+	}
+	if v := get("punctuate"); v != "" {
+		punctuate, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid punctuate: %s, must be true or false", v)
+		}
+		opts.Punctuate = &punctuate
+	}
+	if v := get("max_active_paths"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid max_active_paths: %s", v)
+		}
+		opts.MaxActivePaths = n
+	}
+	if v := get("hotwords_score"); v != "" {
+		score, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return opts, fmt.Errorf("invalid hotwords_score: %s", v)
+		}
+		opts.HotwordsScore = float32(score)
+	}
+	if len(opts.Hotwords) > MaxHotwordsPerRequest {
+		return opts, fmt.Errorf("too many hotwords: %d, max %d", len(opts.Hotwords), MaxHotwordsPerRequest)
+	}
+	return opts, opts.Validate()
+}
+
+// splitList 拆分逗号分隔的参数值，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// HotwordsConfig 命名热词集配置
+type HotwordsConfig struct {
+	Dir      string `mapstructure:"dir" json:"dir"`             // 热词集持久化目录，为空时不启用命名热词集
+	MaxWords int    `mapstructure:"max_words" json:"max_words"` // 单个热词集的最大热词数，默认1000
 }
 
 // TTSModelConfig TTS模型配置
 type TTSModelConfig struct {
+	Name       string         `mapstructure:"name" json:"name,omitempty"` // 模型名称（多模型时用于按请求选择）
 	ModelPath  string         `mapstructure:"model_path" json:"model_path"`
 	VoicesPath string         `mapstructure:"voices_path" json:"voices_path"`
 	TokensPath string         `mapstructure:"tokens_path" json:"tokens_path"`
 	DataDir    string         `mapstructure:"data_dir" json:"data_dir"`
 	DictDir    string         `mapstructure:"dict_dir" json:"dict_dir"`
 	Lexicon    string         `mapstructure:"lexicon" json:"lexicon"` // 逗号分隔的lexicon文件路径
+	Languages  []string       `mapstructure:"languages" json:"languages,omitempty"` // 模型支持的语言
+	PoolSize   int            `mapstructure:"pool_size" json:"pool_size,omitempty"` // 资源池大小，默认5
 	Provider   ProviderConfig `mapstructure:"provider" json:"provider"`
 	Debug      bool           `mapstructure:"debug" json:"debug"`
 }
 
+// ModelsConfig 多模型配置，与stt/tts配置块中的模型一起注册
+type ModelsConfig struct {
+	DefaultSTT string           `mapstructure:"default_stt" json:"default_stt"` // 默认识别模型名称，为空时使用第一个
+	DefaultTTS string           `mapstructure:"default_tts" json:"default_tts"` // 默认合成模型名称，为空时使用第一个
+	STT        []ASRConfig      `mapstructure:"stt" json:"stt,omitempty"`
+	TTS        []TTSModelConfig `mapstructure:"tts" json:"tts,omitempty"`
+	Routing    ASRRoutingConfig `mapstructure:"routing" json:"routing"`
+}
+
+// ASRRoutingConfig 按语言在识别模型之间路由（请求未指定模型时生效）
+type ASRRoutingConfig struct {
+	Rules      map[string]string `mapstructure:"rules" json:"rules,omitempty"`       // 语言 -> 模型名称，优先于模型的languages
+	Fallback   string            `mapstructure:"fallback" json:"fallback,omitempty"` // 无匹配模型或语言未知时使用的模型，默认为默认模型
+	LanguageID LanguageIDConfig  `mapstructure:"language_id" json:"language_id"`
+}
+
+// PunctuationConfig 标点恢复模型配置（sherpa-onnx CT-Transformer），配置model_path后启用
+type PunctuationConfig struct {
+	ModelPath string `mapstructure:"model_path" json:"model_path,omitempty"`
+	Enabled   *bool  `mapstructure:"enabled" json:"enabled,omitempty"`     // 未指定punctuate的请求是否加标点，默认开启
+	PoolSize  int    `mapstructure:"pool_size" json:"pool_size,omitempty"` // 默认2，使用识别模型的provider（num_threads除外）
+}
+
+// LanguageIDConfig 语种识别模型配置（sherpa-onnx Whisper语种识别）
+type LanguageIDConfig struct {
+	Enabled       bool           `mapstructure:"enabled" json:"enabled"` // 请求未声明语言时自动识别
+	EncoderPath   string         `mapstructure:"encoder_path" json:"encoder_path"`
+	DecoderPath   string         `mapstructure:"decoder_path" json:"decoder_path"`
+	TailPaddings  int            `mapstructure:"tail_paddings" json:"tail_paddings"`
+	MaxDuration   float64        `mapstructure:"max_duration" json:"max_duration"` // 用于识别的最长音频（秒），默认10
+	PoolSize      int            `mapstructure:"pool_size" json:"pool_size"`       // 默认2
+	Provider      ProviderConfig `mapstructure:"provider" json:"provider"`
+	DetectWindows int            `mapstructure:"detect_windows" json:"detect_windows"` // 语种检测时用于估计置信度的最大窗口数（1-10），默认3
+}
+
+// KWSConfig 关键词检测配置（sherpa-onnx流式transducer关键词检测模型）
+// 关键词使用sherpa-onnx的分词格式，如 "n ǐ h ǎo @你好"，可附加 ":加分" 和 "#触发阈值"
+type KWSConfig struct {
+	Enabled           bool           `mapstructure:"enabled" json:"enabled"`
+	EncoderPath       string         `mapstructure:"encoder_path" json:"encoder_path"`
+	DecoderPath       string         `mapstructure:"decoder_path" json:"decoder_path"`
+	JoinerPath        string         `mapstructure:"joiner_path" json:"joiner_path"`
+	TokensPath        string         `mapstructure:"tokens_path" json:"tokens_path"`
+	KeywordsFile      string         `mapstructure:"keywords_file" json:"keywords_file"`           // 全局关键词文件，每行一个
+	Keywords          []string       `mapstructure:"keywords" json:"keywords"`                     // 全局关键词，与关键词文件合并
+	KeywordsScore     float32        `mapstructure:"keywords_score" json:"keywords_score"`         // 默认关键词加分，默认1.0
+	KeywordsThreshold float32        `mapstructure:"keywords_threshold" json:"keywords_threshold"` // 默认触发阈值，默认0.25
+	MaxActivePaths    int            `mapstructure:"max_active_paths" json:"max_active_paths"`     // 默认4
+	PoolSize          int            `mapstructure:"pool_size" json:"pool_size"`                   // 关键词检测器实例数，会话按轮转分配，默认2
+	Provider          ProviderConfig `mapstructure:"provider" json:"provider"`
+}
+
+// MaxKeywordsPerConnection 单个连接额外指定的最大关键词数
+const MaxKeywordsPerConnection = 100
+
+// ValidateKeywords 检查关键词：非空、不包含换行和 "/"（sherpa-onnx的关键词分隔符）
+func ValidateKeywords(keywords []string) error {
+	for _, keyword := range keywords {
+		k := strings.TrimSpace(keyword)
+		if k == "" {
+			return fmt.Errorf("keyword must not be empty")
+		}
+		if strings.ContainsAny(k, "/\r\n") {
+			return fmt.Errorf("keyword %q must not contain '/' or line breaks", k)
+		}
+	}
+	return nil
+}
+
+// EnhancementConfig 语音增强（降噪）配置（sherpa-onnx GTCRN模型）
+type EnhancementConfig struct {
+	Enabled        bool           `mapstructure:"enabled" json:"enabled"`
+	ModelPath      string         `mapstructure:"model_path" json:"model_path"`
+	ApplyByDefault bool           `mapstructure:"apply_by_default" json:"apply_by_default"` // 识别请求未指定enhance时是否降噪
+	PoolSize       int            `mapstructure:"pool_size" json:"pool_size"`               // 默认2
+	Provider       ProviderConfig `mapstructure:"provider" json:"provider"`
+}
+
+// AudioTaggingConfig 音频事件标注配置（sherpa-onnx AudioSet标注模型，CED或zipformer）
+type AudioTaggingConfig struct {
+	Enabled    bool           `mapstructure:"enabled" json:"enabled"`
+	ModelType  string         `mapstructure:"model_type" json:"model_type"` // ced或zipformer，默认ced
+	ModelPath  string         `mapstructure:"model_path" json:"model_path"`
+	LabelsPath string         `mapstructure:"labels_path" json:"labels_path"` // AudioSet标签文件（class_labels_indices.csv）
+	TopK       int            `mapstructure:"top_k" json:"top_k"`             // 默认返回的标签数，默认5
+	PoolSize   int            `mapstructure:"pool_size" json:"pool_size"`     // 默认2
+	Provider   ProviderConfig `mapstructure:"provider" json:"provider"`
+}
+
+// 音频标注请求限制
+const (
+	MaxAudioTagTopK    = 50  // 单次标注最多返回的标签数
+	MinAudioTagWindow  = 0.5 // 最短时间窗口（秒）
+	MaxAudioTagWindows = 720 // 单次标注最多的时间窗口数
+)
+
+// AudioTagOptions 音频标注请求参数
+type AudioTagOptions struct {
+	TopK   int     `json:"top_k,omitempty"`  // 返回的标签数，0为按配置
+	Window float64 `json:"window,omitempty"` // 按时间窗口（秒）分别标注，0为只标注整段音频
+}
+
+// AudioLabel 音频事件标签
+type AudioLabel struct {
+	Name  string  `json:"name"`
+	Index int     `json:"index"` // AudioSet类别序号
+	Score float32 `json:"score"`
+}
+
+// AudioTagWindow 时间窗口的标注结果
+type AudioTagWindow struct {
+	Start  float64      `json:"start"`
+	End    float64      `json:"end"`
+	Labels []AudioLabel `json:"labels"`
+}
+
+// AudioTagResult 音频标注结果
+type AudioTagResult struct {
+	Duration float64          `json:"duration"`
+	Labels   []AudioLabel     `json:"labels"`
+	Windows  []AudioTagWindow `json:"windows,omitempty"`
+}
+
+// SpeakerConfig 说话人相关模型配置（sherpa-onnx说话人向量模型）
+type SpeakerConfig struct {
+	EmbeddingModel string             `mapstructure:"embedding_model" json:"embedding_model"` // 说话人向量模型（如3D-Speaker、WeSpeaker）
+	Provider       ProviderConfig     `mapstructure:"provider" json:"provider"`
+	Diarization    DiarizationConfig  `mapstructure:"diarization" json:"diarization"`
+	Verification   VerificationConfig `mapstructure:"verification" json:"verification"`
+}
+
+// VerificationConfig 说话人注册、验证和辨认配置
+type VerificationConfig struct {
+	Enabled   bool    `mapstructure:"enabled" json:"enabled"`
+	StoreDir  string  `mapstructure:"store_dir" json:"store_dir"` // 注册说话人向量的存储目录，默认data/speakers
+	Threshold float32 `mapstructure:"threshold" json:"threshold"` // 余弦相似度阈值，默认0.5
+	PoolSize  int     `mapstructure:"pool_size" json:"pool_size"` // 说话人向量提取实例数，默认1
+}
+
+// DiarizationConfig 说话人分离配置（pyannote分割模型 + 说话人向量聚类）
+type DiarizationConfig struct {
+	Enabled           bool    `mapstructure:"enabled" json:"enabled"`
+	SegmentationModel string  `mapstructure:"segmentation_model" json:"segmentation_model"`
+	NumSpeakers       int     `mapstructure:"num_speakers" json:"num_speakers"`         // 说话人数，0为按阈值自动聚类
+	Threshold         float32 `mapstructure:"threshold" json:"threshold"`               // 聚类阈值，越小说话人越多，默认0.5
+	MinDurationOn     float32 `mapstructure:"min_duration_on" json:"min_duration_on"`   // 最短语音段（秒），默认0.3
+	MinDurationOff    float32 `mapstructure:"min_duration_off" json:"min_duration_off"` // 合并间隔小于该值的语音段（秒），默认0.5
+	PoolSize          int     `mapstructure:"pool_size" json:"pool_size"`               // 默认1
+}
+
+// DefaultModelName stt/tts配置块中未命名模型的名称
+const DefaultModelName = "default"
+
 // AudioConfig 音频配置
 type AudioConfig struct {
 	SampleRate     int     `mapstructure:"sample_rate" json:"sample_rate"`
@@ -65,6 +411,11 @@ type WebSocketConfig struct {
 type SessionConfig struct {
 	SendQueueSize int `mapstructure:"send_queue_size" json:"send_queue_size"`
 	MaxSendErrors int `mapstructure:"max_send_errors" json:"max_send_errors"`
+	MaxSessions   int `mapstructure:"max_sessions" json:"max_sessions"`       // 最大并发会话数
+	Timeout       int `mapstructure:"session_timeout" json:"session_timeout"` // 会话空闲超时（秒）
+	// 会话恢复：WebSocket断开后保留会话等待客户端重连的时长（秒），0为不支持恢复
+	ResumeTimeout    int `mapstructure:"resume_timeout" json:"resume_timeout"`
+	ResumeBufferSize int `mapstructure:"resume_buffer_size" json:"resume_buffer_size"` // 为恢复保留的未确认消息数
 }
 
 // LoggingConfig 日志配置
@@ -89,10 +440,94 @@ type RateLimitConfig struct {
 
 // VADConfig VAD配置
 type VADConfig struct {
-	Enabled   bool    `mapstructure:"enabled" json:"enabled"`
-	Provider  string  `mapstructure:"provider" json:"provider"` // "silero", "ten", etc.
-	PoolSize  int     `mapstructure:"pool_size" json:"pool_size"`
-	Threshold float32 `mapstructure:"threshold" json:"threshold"`
+	Enabled            bool    `mapstructure:"enabled" json:"enabled"`
+	Provider           string  `mapstructure:"provider" json:"provider"`     // "silero", "ten", etc.
+	ModelPath          string  `mapstructure:"model_path" json:"model_path"` // VAD模型文件（语音对话使用）
+	PoolSize           int     `mapstructure:"pool_size" json:"pool_size"`
+	Threshold          float32 `mapstructure:"threshold" json:"threshold"`
+	MinSilenceDuration float32 `mapstructure:"min_silence_duration" json:"min_silence_duration"` // 判定语句结束的静音时长（秒），默认0.5
+	MinSpeechDuration  float32 `mapstructure:"min_speech_duration" json:"min_speech_duration"`   // 最短语音时长（秒），默认0.25
+	MaxSpeechDuration  float32 `mapstructure:"max_speech_duration" json:"max_speech_duration"`   // 单句最长时长（秒），超过时强制切分，默认20
+}
+
+// VoiceConfig 全双工语音对话配置（/ws/voice），需要同时启用vad
+type VoiceConfig struct {
+	Enabled       bool    `mapstructure:"enabled" json:"enabled"`
+	DialogURL     string  `mapstructure:"dialog_url" json:"dialog_url"`         // 对话回调地址，POST每句识别结果，响应回复文本
+	DialogTimeout int     `mapstructure:"dialog_timeout" json:"dialog_timeout"` // 对话回调超时（秒），默认10
+	SpeakerID     int     `mapstructure:"speaker_id" json:"speaker_id"`         // 回复的默认说话人
+	Speed         float32 `mapstructure:"speed" json:"speed"`                   // 回复的默认语速，默认1.0
+}
+
+// BatchConfig 批量识别配置
+type BatchConfig struct {
+	InputDir    string `mapstructure:"input_dir" json:"input_dir"`         // 允许按路径读取的输入目录，为空时仅支持上传
+	MaxItems    int    `mapstructure:"max_items" json:"max_items"`         // 单次请求最大条目数
+	MaxFileSize int64  `mapstructure:"max_file_size" json:"max_file_size"` // 单个文件最大字节数
+	Concurrency int    `mapstructure:"concurrency" json:"concurrency"`     // 并发识别数
+}
+
+// WebhookConfig 任务完成回调配置
+type WebhookConfig struct {
+	Secret         string   `mapstructure:"secret" json:"secret"`                   // HMAC-SHA256签名密钥，为空时不签名
+	Timeout        int      `mapstructure:"timeout" json:"timeout"`                 // 单次请求超时（秒）
+	MaxRetries     int      `mapstructure:"max_retries" json:"max_retries"`         // 最大重试次数
+	InitialBackoff int      `mapstructure:"initial_backoff" json:"initial_backoff"` // 首次重试间隔（秒），之后指数增长
+	MaxBackoff     int      `mapstructure:"max_backoff" json:"max_backoff"`         // 最大重试间隔（秒）
+	AllowedHosts   []string `mapstructure:"allowed_hosts" json:"allowed_hosts"`     // 允许回调的主机，为空时不限制
+	// 允许回调回环、私有、链路本地和未指定地址，默认禁止（防止通过回调访问内网服务）
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks" json:"allow_private_networks"`
+}
+
+// TTSJobConfig 异步合成任务配置
+type TTSJobConfig struct {
+	Concurrency        int `mapstructure:"concurrency" json:"concurrency"`                   // 单个任务内并行合成的段落数
+	ParagraphSilenceMs int `mapstructure:"paragraph_silence_ms" json:"paragraph_silence_ms"` // 段落间静音时长（毫秒）
+	MaxParagraphLength int `mapstructure:"max_paragraph_length" json:"max_paragraph_length"` // 单段最大字符数，超出时按句切分
+	MaxTextLength      int `mapstructure:"max_text_length" json:"max_text_length"`           // 单个任务最大字符数
+}
+
+// JobsConfig 异步任务配置
+type JobsConfig struct {
+	Enabled        bool          `mapstructure:"enabled" json:"enabled"`
+	StoreDir       string        `mapstructure:"store_dir" json:"store_dir"`             // 任务状态与音频的持久化目录
+	Workers        int           `mapstructure:"workers" json:"workers"`                 // 并发处理任务数
+	QueueSize      int           `mapstructure:"queue_size" json:"queue_size"`           // 最大排队任务数
+	MaxFileSize    int64         `mapstructure:"max_file_size" json:"max_file_size"`     // 上传文件最大字节数
+	ChunkSeconds   int           `mapstructure:"chunk_seconds" json:"chunk_seconds"`     // 长音频分段识别的段长（秒）
+	RetentionHours int           `mapstructure:"retention_hours" json:"retention_hours"` // 已结束任务的保留时长（小时）
+	TTS            TTSJobConfig  `mapstructure:"tts" json:"tts"`
+	Webhook        WebhookConfig `mapstructure:"webhook" json:"webhook"`
+}
+
+// APIKeyConfig API密钥配置（密钥以SHA-256十六进制摘要形式存储）
+type APIKeyConfig struct {
+	Name    string   `mapstructure:"name" json:"name"`
+	KeyHash string   `mapstructure:"key_hash" json:"key_hash"`
+	Scopes  []string `mapstructure:"scopes" json:"scopes"` // "stt", "tts", "admin"
+}
+
+// JWTConfig JWT/OIDC令牌验证配置
+type JWTConfig struct {
+	Enabled         bool   `mapstructure:"enabled" json:"enabled"`
+	JWKSFile        string `mapstructure:"jwks_file" json:"jwks_file"`               // 本地JWKS文件
+	JWKSURL         string `mapstructure:"jwks_url" json:"jwks_url"`                 // 远程JWKS地址
+	RefreshInterval int    `mapstructure:"refresh_interval" json:"refresh_interval"` // JWKS刷新间隔（秒）
+	Issuer          string `mapstructure:"issuer" json:"issuer"`
+	Audience        string `mapstructure:"audience" json:"audience"`
+	TenantClaim     string `mapstructure:"tenant_claim" json:"tenant_claim"` // 默认 tenant_id
+	ScopeClaim      string `mapstructure:"scope_claim" json:"scope_claim"`   // 默认 scope
+	ClockSkew       int    `mapstructure:"clock_skew" json:"clock_skew"`     // 允许的时钟偏差（秒）
+}
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	Enabled    bool           `mapstructure:"enabled" json:"enabled"`
+	HeaderName string         `mapstructure:"header_name" json:"header_name"` // 默认 X-API-Key
+	QueryParam string         `mapstructure:"query_param" json:"query_param"` // 默认 token（浏览器WebSocket使用）
+	KeyFile    string         `mapstructure:"key_file" json:"key_file"`       // 可选的密钥文件（JSON）
+	Keys       []APIKeyConfig `mapstructure:"keys" json:"keys"`
+	JWT        JWTConfig      `mapstructure:"jwt" json:"jwt"`
 }
 
 // STTConfig STT服务配置
@@ -104,6 +539,8 @@ type STTConfig struct {
 	Session   SessionConfig   `mapstructure:"session" json:"session"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit" json:"rate_limit"`
 	VAD       VADConfig       `mapstructure:"vad" json:"vad"`
+	Batch     BatchConfig     `mapstructure:"batch" json:"batch"`
+	Auth      AuthConfig      `mapstructure:"auth" json:"auth"`
 	Logging   LoggingConfig   `mapstructure:"logging" json:"logging"`
 }
 
@@ -115,21 +552,34 @@ type TTSConfig struct {
 	WebSocket WebSocketConfig `mapstructure:"websocket" json:"websocket"`
 	Session   SessionConfig   `mapstructure:"session" json:"session"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit" json:"rate_limit"`
+	Auth      AuthConfig      `mapstructure:"auth" json:"auth"`
 	Logging   LoggingConfig   `mapstructure:"logging" json:"logging"`
 }
 
 // UnifiedConfig 统一配置（同时支持STT和TTS）
+
+
 type UnifiedConfig struct {
-	Mode      string          `mapstructure:"mode" json:"mode"` // "unified" 或 "separated"
-	Server    ServerConfig    `mapstructure:"server" json:"server"`
-	STT       *ASRConfig      `mapstructure:"stt" json:"stt,omitempty"`
-	TTS       *TTSModelConfig `mapstructure:"tts" json:"tts,omitempty"`
-	Audio     AudioConfig     `mapstructure:"audio" json:"audio"`
-	WebSocket WebSocketConfig `mapstructure:"websocket" json:"websocket"`
-	Session   SessionConfig   `mapstructure:"session" json:"session"`
-	RateLimit RateLimitConfig `mapstructure:"rate_limit" json:"rate_limit"`
-	VAD       VADConfig       `mapstructure:"vad" json:"vad"`
-	Logging   LoggingConfig   `mapstructure:"logging" json:"logging"`
+	Mode         string             `mapstructure:"mode" json:"mode"` // "unified" 或 "separated"
+	Server       ServerConfig       `mapstructure:"server" json:"server"`
+	STT          *ASRConfig         `mapstructure:"stt" json:"stt,omitempty"`
+	TTS          *TTSModelConfig    `mapstructure:"tts" json:"tts,omitempty"`
+	Models       ModelsConfig       `mapstructure:"models" json:"models"`
+	Audio        AudioConfig        `mapstructure:"audio" json:"audio"`
+	WebSocket    WebSocketConfig    `mapstructure:"websocket" json:"websocket"`
+	Session      SessionConfig      `mapstructure:"session" json:"session"`
+	RateLimit    RateLimitConfig    `mapstructure:"rate_limit" json:"rate_limit"`
+	VAD          VADConfig          `mapstructure:"vad" json:"vad"`
+	Batch        BatchConfig        `mapstructure:"batch" json:"batch"`
+	Jobs         JobsConfig         `mapstructure:"jobs" json:"jobs"`
+	Hotwords     HotwordsConfig     `mapstructure:"hotwords" json:"hotwords"`
+	Speaker      SpeakerConfig      `mapstructure:"speaker" json:"speaker"`
+	KWS          KWSConfig          `mapstructure:"kws" json:"kws"`
+	AudioTagging AudioTaggingConfig `mapstructure:"audio_tagging" json:"audio_tagging"`
+	Enhancement  EnhancementConfig  `mapstructure:"enhancement" json:"enhancement"`
+	Voice        VoiceConfig        `mapstructure:"voice" json:"voice"`
+	Auth         AuthConfig         `mapstructure:"auth" json:"auth"`
+	Logging      LoggingConfig      `mapstructure:"logging" json:"logging"`
 }
 
 // GlobalConfig 全局配置（STT或TTS）
@@ -259,10 +709,21 @@ func setSTTDefaults(config *STTConfig) {
 	if config.Session.SendQueueSize == 0 {
 		config.Session.SendQueueSize = 500
 	}
+	if config.Session.MaxSessions == 0 {
+		config.Session.MaxSessions = 1000
+	}
+	if config.Session.Timeout == 0 {
+		config.Session.Timeout = 1800
+	}
+	if config.Session.ResumeBufferSize == 0 {
+		config.Session.ResumeBufferSize = 1000
+	}
 	if config.Session.MaxSendErrors == 0 {
 		config.Session.MaxSendErrors = 10
 	}
 
+	SetBatchDefaults(&config.Batch)
+
 	if config.Logging.Level == "" {
 		config.Logging.Level = "info"
 	}
@@ -277,6 +738,65 @@ func setSTTDefaults(config *STTConfig) {
 	}
 }
 
+// SetBatchDefaults 设置批量识别配置默认值
+func SetBatchDefaults(batch *BatchConfig) {
+	if batch.MaxItems == 0 {
+		batch.MaxItems = 50
+	}
+	if batch.MaxFileSize == 0 {
+		batch.MaxFileSize = 50 * 1024 * 1024 // 50MB
+	}
+	if batch.Concurrency == 0 {
+		batch.Concurrency = 4
+	}
+}
+
+// SetJobsDefaults 设置异步任务配置默认值
+func SetJobsDefaults(jobs *JobsConfig) {
+	if jobs.StoreDir == "" {
+		jobs.StoreDir = "data/jobs"
+	}
+	if jobs.Workers == 0 {
+		jobs.Workers = 2
+	}
+	if jobs.QueueSize == 0 {
+		jobs.QueueSize = 100
+	}
+	if jobs.MaxFileSize == 0 {
+		jobs.MaxFileSize = 500 * 1024 * 1024 // 500MB
+	}
+	if jobs.ChunkSeconds == 0 {
+		jobs.ChunkSeconds = 30
+	}
+	if jobs.RetentionHours == 0 {
+		jobs.RetentionHours = 24
+	}
+	if jobs.TTS.Concurrency == 0 {
+		jobs.TTS.Concurrency = 4
+	}
+	if jobs.TTS.ParagraphSilenceMs == 0 {
+		jobs.TTS.ParagraphSilenceMs = 500
+	}
+	if jobs.TTS.MaxParagraphLength == 0 {
+		jobs.TTS.MaxParagraphLength = 500
+	}
+	if jobs.TTS.MaxTextLength == 0 {
+		jobs.TTS.MaxTextLength = 1000000
+	}
+	if jobs.Webhook.Timeout == 0 {
+		jobs.Webhook.Timeout = 10
+	}
+	if jobs.Webhook.MaxRetries == 0 {
+		jobs.Webhook.MaxRetries = 5
+	}
+	if jobs.Webhook.InitialBackoff == 0 {
+		jobs.Webhook.InitialBackoff = 1
+	}
+	if jobs.Webhook.MaxBackoff == 0 {
+		jobs.Webhook.MaxBackoff = 60
+	}
+}
+
 // setTTSDefaults 设置TTS配置默认值
 func setTTSDefaults(config *TTSConfig) {
 	if config.Server.Host == "" {
@@ -317,6 +837,15 @@ func setTTSDefaults(config *TTSConfig) {
 	if config.Session.SendQueueSize == 0 {
 		config.Session.SendQueueSize = 500
 	}
+	if config.Session.MaxSessions == 0 {
+		config.Session.MaxSessions = 1000
+	}
+	if config.Session.Timeout == 0 {
+		config.Session.Timeout = 1800
+	}
+	if config.Session.ResumeBufferSize == 0 {
+		config.Session.ResumeBufferSize = 1000
+	}
 
 	if config.Logging.Level == "" {
 		config.Logging.Level = "info"
@@ -356,6 +885,11 @@ func validateSTTConfig(config *STTConfig) error {
 		return fmt.Errorf("invalid provider: %s, must be cpu, cuda, or auto", config.ASR.Provider.Provider)
 	}
 
+	// 验证认证配置
+	if err := ValidateAuthConfig(&config.Auth); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -377,6 +911,11 @@ func validateTTSConfig(config *TTSConfig) error {
 		return fmt.Errorf("invalid provider: %s, must be cpu, cuda, or auto", config.TTS.Provider.Provider)
 	}
 
+	// 验证认证配置
+	if err := ValidateAuthConfig(&config.Auth); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -439,20 +978,36 @@ func GetNumThreads(provider *ProviderConfig) int {
 
 // LoadUnifiedConfig 加载统一配置
 func LoadUnifiedConfig(configPath string) (*UnifiedConfig, error) {
-	viper.SetConfigFile(configPath)
-	viper.SetConfigType("json")
+	config, err := parseUnifiedConfig(viper.GetViper(), configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	GlobalConfig = config
+	return config, nil
+}
+
+// ParseUnifiedConfig 解析统一配置但不修改全局配置（用于热加载时与运行中的配置比较）
+func ParseUnifiedConfig(configPath string) (*UnifiedConfig, error) {
+	return parseUnifiedConfig(viper.New(), configPath)
+}
+
+// parseUnifiedConfig 读取配置文件并设置默认值、验证
+func parseUnifiedConfig(v *viper.Viper, configPath string) (*UnifiedConfig, error) {
+	v.SetConfigFile(configPath)
+	v.SetConfigType("json")
 
 	// 支持环境变量
-	viper.SetEnvPrefix("SPEECH")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
+	v.SetEnvPrefix("SPEECH")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
 
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	var config UnifiedConfig
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
@@ -465,18 +1020,42 @@ func LoadUnifiedConfig(configPath string) (*UnifiedConfig, error) {
 	}
 
 	// Provider自动选择
-	if config.STT != nil {
-		if err := resolveProvider(&config.STT.Provider); err != nil {
-			return nil, fmt.Errorf("failed to resolve STT provider: %w", err)
+	for _, m := range config.ASRModels() {
+		if err := resolveProvider(&m.Provider); err != nil {
+			return nil, fmt.Errorf("failed to resolve STT provider for model %s: %w", m.Name, err)
 		}
 	}
-	if config.TTS != nil {
-		if err := resolveProvider(&config.TTS.Provider); err != nil {
-			return nil, fmt.Errorf("failed to resolve TTS provider: %w", err)
+	for _, m := range config.TTSModels() {
+		if err := resolveProvider(&m.Provider); err != nil {
+			return nil, fmt.Errorf("failed to resolve TTS provider for model %s: %w", m.Name, err)
+		}
+	}
+	if config.Models.Routing.LanguageID.Enabled {
+		if err := resolveProvider(&config.Models.Routing.LanguageID.Provider); err != nil {
+			return nil, fmt.Errorf("failed to resolve language ID provider: %w", err)
+		}
+	}
+	if config.KWS.Enabled {
+		if err := resolveProvider(&config.KWS.Provider); err != nil {
+			return nil, fmt.Errorf("failed to resolve KWS provider: %w", err)
+		}
+	}
+	if config.Speaker.Diarization.Enabled || config.Speaker.Verification.Enabled {
+		if err := resolveProvider(&config.Speaker.Provider); err != nil {
+			return nil, fmt.Errorf("failed to resolve speaker provider: %w", err)
+		}
+	}
+	if config.AudioTagging.Enabled {
+		if err := resolveProvider(&config.AudioTagging.Provider); err != nil {
+			return nil, fmt.Errorf("failed to resolve audio tagging provider: %w", err)
+		}
+	}
+	if config.Enhancement.Enabled {
+		if err := resolveProvider(&config.Enhancement.Provider); err != nil {
+			return nil, fmt.Errorf("failed to resolve enhancement provider: %w", err)
 		}
 	}
 
-	GlobalConfig = &config
 	return &config, nil
 }
 
@@ -496,32 +1075,35 @@ func setUnifiedDefaults(config *UnifiedConfig) {
 		config.Server.ReadTimeout = 20
 	}
 
-	// STT默认值
-	if config.STT != nil {
-		if config.STT.Provider.Provider == "" {
-			config.STT.Provider.Provider = "cpu"
-		}
-		if config.STT.Provider.NumThreads == 0 {
-			if config.STT.Provider.Provider == "cuda" {
-				config.STT.Provider.NumThreads = 1
-			} else {
-				config.STT.Provider.NumThreads = runtime.NumCPU()
-			}
-		}
+	// 模型默认值
+	if config.STT != nil && config.STT.Name == "" {
+		config.STT.Name = DefaultModelName
 	}
-
-	// TTS默认值
-	if config.TTS != nil {
-		if config.TTS.Provider.Provider == "" {
-			config.TTS.Provider.Provider = "cpu"
-		}
-		if config.TTS.Provider.NumThreads == 0 {
-			if config.TTS.Provider.Provider == "cuda" {
-				config.TTS.Provider.NumThreads = 1
-			} else {
-				config.TTS.Provider.NumThreads = 4
-			}
-		}
+	if config.TTS != nil && config.TTS.Name == "" {
+		config.TTS.Name = DefaultModelName
+	}
+	asrModels := config.ASRModels()
+	for _, m := range asrModels {
+		setASRModelDefaults(m)
+	}
+	if config.Models.DefaultSTT == "" && len(asrModels) > 0 {
+		config.Models.DefaultSTT = asrModels[0].Name
+	}
+	ttsModels := config.TTSModels()
+	for _, m := range ttsModels {
+		setTTSModelDefaults(m)
+	}
+	if config.Models.DefaultTTS == "" && len(ttsModels) > 0 {
+		config.Models.DefaultTTS = ttsModels[0].Name
+	}
+	setLanguageIDDefaults(&config.Models.Routing.LanguageID)
+	setSpeakerDefaults(&config.Speaker)
+	setKWSDefaults(&config.KWS)
+	setAudioTaggingDefaults(&config.AudioTagging)
+	setEnhancementDefaults(&config.Enhancement)
+	setVoiceDefaults(&config.Voice)
+	if config.Hotwords.MaxWords == 0 {
+		config.Hotwords.MaxWords = 1000
 	}
 
 	// 音频配置默认值
@@ -556,6 +1138,15 @@ func setUnifiedDefaults(config *UnifiedConfig) {
 	if config.Session.SendQueueSize == 0 {
 		config.Session.SendQueueSize = 500
 	}
+	if config.Session.MaxSessions == 0 {
+		config.Session.MaxSessions = 1000
+	}
+	if config.Session.Timeout == 0 {
+		config.Session.Timeout = 1800
+	}
+	if config.Session.ResumeBufferSize == 0 {
+		config.Session.ResumeBufferSize = 1000
+	}
 	if config.Session.MaxSendErrors == 0 {
 		config.Session.MaxSendErrors = 10
 	}
@@ -578,6 +1169,44 @@ func setUnifiedDefaults(config *UnifiedConfig) {
 	if config.VAD.Threshold == 0 {
 		config.VAD.Threshold = 0.5
 	}
+	if config.VAD.Provider == "" {
+		config.VAD.Provider = "silero"
+	}
+	if config.VAD.MinSilenceDuration == 0 {
+		config.VAD.MinSilenceDuration = 0.5
+	}
+	if config.VAD.MinSpeechDuration == 0 {
+		config.VAD.MinSpeechDuration = 0.25
+	}
+	if config.VAD.MaxSpeechDuration == 0 {
+		config.VAD.MaxSpeechDuration = 20
+	}
+
+	// 批量识别配置默认值
+	SetBatchDefaults(&config.Batch)
+
+	// 异步任务配置默认值
+	SetJobsDefaults(&config.Jobs)
+
+	// 认证配置默认值
+	if config.Auth.HeaderName == "" {
+		config.Auth.HeaderName = "X-API-Key"
+	}
+	if config.Auth.QueryParam == "" {
+		config.Auth.QueryParam = "token"
+	}
+	if config.Auth.JWT.TenantClaim == "" {
+		config.Auth.JWT.TenantClaim = "tenant_id"
+	}
+	if config.Auth.JWT.ScopeClaim == "" {
+		config.Auth.JWT.ScopeClaim = "scope"
+	}
+	if config.Auth.JWT.RefreshInterval == 0 {
+		config.Auth.JWT.RefreshInterval = 300
+	}
+	if config.Auth.JWT.ClockSkew == 0 {
+		config.Auth.JWT.ClockSkew = 60
+	}
 
 	// 日志配置默认值
 	if config.Logging.Level == "" {
@@ -603,58 +1232,655 @@ func validateUnifiedConfig(config *UnifiedConfig) error {
 
 	// 验证STT配置
 	if config.STT != nil {
-		if config.STT.ModelPath == "" {
-			return fmt.Errorf("stt.model_path is required")
+		if err := validateASRModel("stt", config.STT); err != nil {
+			return err
+		}
+	}
+	for i := range config.Models.STT {
+		if err := validateASRModel(fmt.Sprintf("models.stt[%d]", i), &config.Models.STT[i]); err != nil {
+			return err
+		}
+	}
+
+	// 验证TTS配置
+	if config.TTS != nil {
+		if err := validateTTSModel("tts", config.TTS); err != nil {
+			return err
+		}
+	}
+	for i := range config.Models.TTS {
+		if err := validateTTSModel(fmt.Sprintf("models.tts[%d]", i), &config.Models.TTS[i]); err != nil {
+			return err
+		}
+	}
+
+	// 验证模型名称唯一且默认模型存在
+	asrNames := make([]string, 0)
+	for _, m := range config.ASRModels() {
+		asrNames = append(asrNames, m.Name)
+	}
+	if err := validateModelNames("stt", asrNames, config.Models.DefaultSTT); err != nil {
+		return err
+	}
+	ttsNames := make([]string, 0)
+	for _, m := range config.TTSModels() {
+		ttsNames = append(ttsNames, m.Name)
+	}
+	if err := validateModelNames("tts", ttsNames, config.Models.DefaultTTS); err != nil {
+		return err
+	}
+	if err := validateASRRouting(&config.Models.Routing, asrNames); err != nil {
+		return err
+	}
+	if err := validateSpeaker(&config.Speaker); err != nil {
+		return err
+	}
+	if err := validateKWS(&config.KWS); err != nil {
+		return err
+	}
+	if err := validateAudioTagging(&config.AudioTagging); err != nil {
+		return err
+	}
+	if err := validateEnhancement(&config.Enhancement); err != nil {
+		return err
+	}
+	if err := validateVAD(&config.VAD); err != nil {
+		return err
+	}
+	if err := validateVoice(&config.Voice, &config.VAD); err != nil {
+		return err
+	}
+
+	// 统一模式必须同时配置STT和TTS
+	if config.Mode == "unified" {
+		if len(asrNames) == 0 {
+			return fmt.Errorf("stt config is required in unified mode")
 		}
-		if config.STT.TokensPath == "" {
-			return fmt.Errorf("stt.tokens_path is required")
+		if len(ttsNames) == 0 {
+			return fmt.Errorf("tts config is required in unified mode")
 		}
+	}
+
+	// 验证认证配置
+	if err := ValidateAuthConfig(&config.Auth); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ASRModels 返回全部识别模型配置：stt配置块（如有）在前，之后是models.stt
+func (c *UnifiedConfig) ASRModels() []*ASRConfig {
+	models := make([]*ASRConfig, 0, len(c.Models.STT)+1)
+	if c.STT != nil {
+		models = append(models, c.STT)
+	}
+	for i := range c.Models.STT {
+		models = append(models, &c.Models.STT[i])
+	}
+	return models
+}
+
+// TTSModels 返回全部合成模型配置：tts配置块（如有）在前，之后是models.tts
+func (c *UnifiedConfig) TTSModels() []*TTSModelConfig {
+	models := make([]*TTSModelConfig, 0, len(c.Models.TTS)+1)
+	if c.TTS != nil {
+		models = append(models, c.TTS)
+	}
+	for i := range c.Models.TTS {
+		models = append(models, &c.Models.TTS[i])
+	}
+	return models
+}
 
-		// 检查模型文件是否存在
-		if _, err := os.Stat(config.STT.ModelPath); os.IsNotExist(err) {
-			return fmt.Errorf("stt model file not found: %s", config.STT.ModelPath)
+// setASRModelDefaults 设置识别模型默认值
+func setASRModelDefaults(m *ASRConfig) {
+	if m.ModelType == "" {
+		m.ModelType = ASRModelSenseVoice
+	}
+	if m.DecodingMethod == "" {
+		m.DecodingMethod = DecodingGreedySearch
+		if m.ModelType == ASRModelTransducer && (len(m.Hotwords) > 0 || m.HotwordsFile != "") {
+			m.DecodingMethod = DecodingModifiedBeamSearch // 热词需要modified_beam_search
 		}
-		if _, err := os.Stat(config.STT.TokensPath); os.IsNotExist(err) {
-			return fmt.Errorf("stt tokens file not found: %s", config.STT.TokensPath)
+	}
+	if m.HotwordsScore == 0 {
+		m.HotwordsScore = 1.5
+	}
+	if m.MaxHotwordModels == 0 {
+		m.MaxHotwordModels = 4
+	}
+	if m.MaxActivePaths == 0 {
+		m.MaxActivePaths = 4
+	}
+	if m.PoolSize == 0 {
+		m.PoolSize = 4
+	}
+	if m.Punctuation.ModelPath != "" && m.Punctuation.PoolSize == 0 {
+		m.Punctuation.PoolSize = 2
+	}
+	if m.Provider.Provider == "" {
+		m.Provider.Provider = "cpu"
+	}
+	if m.Provider.NumThreads == 0 {
+		if m.Provider.Provider == "cuda" {
+			m.Provider.NumThreads = 1
+		} else {
+			m.Provider.NumThreads = runtime.NumCPU()
 		}
+	}
+}
 
-		// 验证Provider
-		if config.STT.Provider.Provider != "cpu" &&
-			config.STT.Provider.Provider != "cuda" &&
-			config.STT.Provider.Provider != "auto" {
-			return fmt.Errorf("invalid stt provider: %s, must be cpu, cuda, or auto", config.STT.Provider.Provider)
+// setTTSModelDefaults 设置合成模型默认值
+func setTTSModelDefaults(m *TTSModelConfig) {
+	if m.PoolSize == 0 {
+		m.PoolSize = 5 // TTS默认池大小较小
+	}
+	if m.Provider.Provider == "" {
+		m.Provider.Provider = "cpu"
+	}
+	if m.Provider.NumThreads == 0 {
+		if m.Provider.Provider == "cuda" {
+			m.Provider.NumThreads = 1
+		} else {
+			m.Provider.NumThreads = 4
 		}
 	}
+}
 
-	// 验证TTS配置
-	if config.TTS != nil {
-		if config.TTS.ModelPath == "" {
-			return fmt.Errorf("tts.model_path is required")
+// validateASRModel 验证识别模型配置，field为配置项前缀（如 "stt"、"models.stt[0]"）
+func validateASRModel(field string, m *ASRConfig) error {
+	var required map[string]string
+	switch m.ModelType {
+	case ASRModelSenseVoice, ASRModelParaformer, "":
+		required = map[string]string{"model_path": m.ModelPath}
+	case ASRModelWhisper:
+		required = map[string]string{"encoder_path": m.EncoderPath, "decoder_path": m.DecoderPath}
+	case ASRModelTransducer:
+		required = map[string]string{"encoder_path": m.EncoderPath, "decoder_path": m.DecoderPath, "joiner_path": m.JoinerPath}
+	default:
+		return fmt.Errorf("invalid %s.model_type: %s", field, m.ModelType)
+	}
+	required["tokens_path"] = m.TokensPath
+
+	defaults := ASROptions{DecodingMethod: m.DecodingMethod, MaxActivePaths: m.MaxActivePaths}
+	if err := defaults.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	if m.DecodingMethod == DecodingModifiedBeamSearch && m.ModelType != ASRModelTransducer {
+		return fmt.Errorf("%s: %s is only supported by transducer models", field, DecodingModifiedBeamSearch)
+	}
+	if m.MaxHotwordModels < 0 {
+		return fmt.Errorf("%s.max_hotword_models must not be negative", field)
+	}
+	if len(m.Hotwords) > 0 || m.HotwordsFile != "" {
+		if m.ModelType != ASRModelTransducer {
+			return fmt.Errorf("%s: hotwords are only supported by transducer models", field)
+		}
+		if m.DecodingMethod != DecodingModifiedBeamSearch {
+			return fmt.Errorf("%s: hotwords require %s", field, DecodingModifiedBeamSearch)
+		}
+		if err := ValidateHotwords(m.Hotwords); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+		if m.HotwordsFile != "" {
+			if _, err := os.Stat(m.HotwordsFile); os.IsNotExist(err) {
+				return fmt.Errorf("%s hotwords file not found: %s", field, m.HotwordsFile)
+			}
 		}
+	}
+
+	if m.Punctuation.ModelPath != "" {
+		required["punctuation.model_path"] = m.Punctuation.ModelPath
+	}
 
-		// 检查模型文件是否存在
-		if _, err := os.Stat(config.TTS.ModelPath); os.IsNotExist(err) {
-			return fmt.Errorf("tts model file not found: %s", config.TTS.ModelPath)
+	keys := make([]string, 0, len(required))
+	for k := range required {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if required[k] == "" {
+			return fmt.Errorf("%s.%s is required", field, k)
 		}
+	}
 
-		// 验证Provider
-		if config.TTS.Provider.Provider != "cpu" &&
-			config.TTS.Provider.Provider != "cuda" &&
-			config.TTS.Provider.Provider != "auto" {
-			return fmt.Errorf("invalid tts provider: %s, must be cpu, cuda, or auto", config.TTS.Provider.Provider)
+	// 检查模型文件是否存在
+	for _, k := range keys {
+		if _, err := os.Stat(required[k]); os.IsNotExist(err) {
+			kind := strings.TrimSuffix(k, "_path")
+			if kind != "tokens" {
+				kind = "model"
+			}
+			return fmt.Errorf("%s %s file not found: %s", field, kind, required[k])
 		}
 	}
 
-	// 统一模式必须同时配置STT和TTS
-	if config.Mode == "unified" {
-		if config.STT == nil {
-			return fmt.Errorf("stt config is required in unified mode")
+	// 验证Provider
+	if m.Provider.Provider != "cpu" &&
+		m.Provider.Provider != "cuda" &&
+		m.Provider.Provider != "auto" {
+		return fmt.Errorf("invalid %s provider: %s, must be cpu, cuda, or auto", field, m.Provider.Provider)
+	}
+	return nil
+}
+
+// validateTTSModel 验证合成模型配置，field为配置项前缀（如 "tts"、"models.tts[0]"）
+func validateTTSModel(field string, m *TTSModelConfig) error {
+	if m.ModelPath == "" {
+		return fmt.Errorf("%s.model_path is required", field)
+	}
+
+	// 检查模型文件是否存在
+	if _, err := os.Stat(m.ModelPath); os.IsNotExist(err) {
+		return fmt.Errorf("%s model file not found: %s", field, m.ModelPath)
+	}
+
+	// 验证Provider
+	if m.Provider.Provider != "cpu" &&
+		m.Provider.Provider != "cuda" &&
+		m.Provider.Provider != "auto" {
+		return fmt.Errorf("invalid %s provider: %s, must be cpu, cuda, or auto", field, m.Provider.Provider)
+	}
+	return nil
+}
+
+// setLanguageIDDefaults 设置语种识别默认值
+func setLanguageIDDefaults(m *LanguageIDConfig) {
+	if m.MaxDuration == 0 {
+		m.MaxDuration = 10
+	}
+	if m.DetectWindows == 0 {
+		m.DetectWindows = 3
+	}
+	if m.PoolSize == 0 {
+		m.PoolSize = 2
+	}
+	if m.Provider.Provider == "" {
+		m.Provider.Provider = "cpu"
+	}
+	if m.Provider.NumThreads == 0 {
+		m.Provider.NumThreads = 1
+	}
+}
+
+// setKWSDefaults 设置关键词检测默认值
+func setKWSDefaults(k *KWSConfig) {
+	if k.KeywordsScore == 0 {
+		k.KeywordsScore = 1.0
+	}
+	if k.KeywordsThreshold == 0 {
+		k.KeywordsThreshold = 0.25
+	}
+	if k.MaxActivePaths == 0 {
+		k.MaxActivePaths = 4
+	}
+	if k.PoolSize == 0 {
+		k.PoolSize = 2
+	}
+	if k.Provider.Provider == "" {
+		k.Provider.Provider = "cpu"
+	}
+	if k.Provider.NumThreads == 0 {
+		k.Provider.NumThreads = 1
+	}
+}
+
+// validateKWS 验证关键词检测配置
+func validateKWS(k *KWSConfig) error {
+	if !k.Enabled {
+		return nil
+	}
+	for _, path := range []string{k.EncoderPath, k.DecoderPath, k.JoinerPath, k.TokensPath} {
+		if path == "" {
+			return fmt.Errorf("kws encoder_path, decoder_path, joiner_path and tokens_path are required")
 		}
-		if config.TTS == nil {
-			return fmt.Errorf("tts config is required in unified mode")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("kws model file not found: %s", path)
+		}
+	}
+	if k.KeywordsFile == "" && len(k.Keywords) == 0 {
+		return fmt.Errorf("kws keywords_file or keywords is required")
+	}
+	if k.KeywordsFile != "" {
+		if _, err := os.Stat(k.KeywordsFile); os.IsNotExist(err) {
+			return fmt.Errorf("kws keywords file not found: %s", k.KeywordsFile)
+		}
+	}
+	if err := ValidateKeywords(k.Keywords); err != nil {
+		return fmt.Errorf("invalid kws keywords: %w", err)
+	}
+	if k.KeywordsThreshold < 0 || k.KeywordsThreshold > 1 {
+		return fmt.Errorf("kws keywords_threshold must be in [0, 1]")
+	}
+	if k.Provider.Provider != "cpu" &&
+		k.Provider.Provider != "cuda" &&
+		k.Provider.Provider != "auto" {
+		return fmt.Errorf("invalid kws provider: %s, must be cpu, cuda, or auto", k.Provider.Provider)
+	}
+	return nil
+}
+
+// setAudioTaggingDefaults 设置音频标注默认值
+func setAudioTaggingDefaults(a *AudioTaggingConfig) {
+	if a.ModelType == "" {
+		a.ModelType = "ced"
+	}
+	if a.TopK == 0 {
+		a.TopK = 5
+	}
+	if a.PoolSize == 0 {
+		a.PoolSize = 2
+	}
+	if a.Provider.Provider == "" {
+		a.Provider.Provider = "cpu"
+	}
+	if a.Provider.NumThreads == 0 {
+		a.Provider.NumThreads = 1
+	}
+}
+
+// validateAudioTagging 验证音频标注配置
+func validateAudioTagging(a *AudioTaggingConfig) error {
+	if !a.Enabled {
+		return nil
+	}
+	if a.ModelType != "ced" && a.ModelType != "zipformer" {
+		return fmt.Errorf("invalid audio_tagging model_type: %s, must be ced or zipformer", a.ModelType)
+	}
+	if a.ModelPath == "" || a.LabelsPath == "" {
+		return fmt.Errorf("audio_tagging model_path and labels_path are required")
+	}
+	for _, path := range []string{a.ModelPath, a.LabelsPath} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("audio_tagging model file not found: %s", path)
 		}
 	}
+	if a.TopK < 1 || a.TopK > MaxAudioTagTopK {
+		return fmt.Errorf("audio_tagging top_k must be in [1, %d]", MaxAudioTagTopK)
+	}
+	if a.Provider.Provider != "cpu" &&
+		a.Provider.Provider != "cuda" &&
+		a.Provider.Provider != "auto" {
+		return fmt.Errorf("invalid audio_tagging provider: %s, must be cpu, cuda, or auto", a.Provider.Provider)
+	}
+	return nil
+}
+
+// setEnhancementDefaults 设置语音增强默认值
+func setEnhancementDefaults(e *EnhancementConfig) {
+	if e.PoolSize == 0 {
+		e.PoolSize = 2
+	}
+	if e.Provider.Provider == "" {
+		e.Provider.Provider = "cpu"
+	}
+	if e.Provider.NumThreads == 0 {
+		e.Provider.NumThreads = 1
+	}
+}
+
+// validateEnhancement 验证语音增强配置
+func validateEnhancement(e *EnhancementConfig) error {
+	if !e.Enabled {
+		return nil
+	}
+	if e.ModelPath == "" {
+		return fmt.Errorf("enhancement model_path is required")
+	}
+	if _, err := os.Stat(e.ModelPath); os.IsNotExist(err) {
+		return fmt.Errorf("enhancement model file not found: %s", e.ModelPath)
+	}
+	if e.Provider.Provider != "cpu" &&
+		e.Provider.Provider != "cuda" &&
+		e.Provider.Provider != "auto" {
+		return fmt.Errorf("invalid enhancement provider: %s, must be cpu, cuda, or auto", e.Provider.Provider)
+	}
+	return nil
+}
+
+// setVoiceDefaults 设置语音对话默认值
+func setVoiceDefaults(v *VoiceConfig) {
+	if v.DialogTimeout == 0 {
+		v.DialogTimeout = 10
+	}
+	if v.Speed == 0 {
+		v.Speed = 1.0
+	}
+}
+
+// validateVoice 验证语音对话配置，启用时同时验证其使用的VAD配置
+func validateVoice(v *VoiceConfig, vad *VADConfig) error {
+	if !v.Enabled {
+		return nil
+	}
+	if v.DialogURL == "" {
+		return fmt.Errorf("voice dialog_url is required")
+	}
+	if u, err := url.Parse(v.DialogURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid voice dialog_url: %s", v.DialogURL)
+	}
+	if v.DialogTimeout < 0 {
+		return fmt.Errorf("voice dialog_timeout must be positive")
+	}
+	if v.Speed < 0.5 || v.Speed > 2.0 {
+		return fmt.Errorf("voice speed must be between 0.5 and 2.0")
+	}
+	if !vad.Enabled {
+		return fmt.Errorf("vad must be enabled for voice conversation")
+	}
+	if vad.ModelPath == "" {
+		return fmt.Errorf("vad model_path is required")
+	}
+	return validateVAD(vad)
+}
+
+// validateVAD 验证VAD模型配置（启用vad且配置了model_path时加载模型）
+func validateVAD(vad *VADConfig) error {
+	if !vad.Enabled || vad.ModelPath == "" {
+		return nil
+	}
+	if vad.Provider != "silero" && vad.Provider != "ten" {
+		return fmt.Errorf("invalid vad provider: %s, must be silero or ten", vad.Provider)
+	}
+	if _, err := os.Stat(vad.ModelPath); os.IsNotExist(err) {
+		return fmt.Errorf("vad model file not found: %s", vad.ModelPath)
+	}
+	if vad.Threshold <= 0 || vad.Threshold >= 1 {
+		return fmt.Errorf("vad threshold must be between 0 and 1")
+	}
+	if vad.MinSilenceDuration < 0 || vad.MinSpeechDuration < 0 || vad.MaxSpeechDuration <= vad.MinSpeechDuration {
+		return fmt.Errorf("invalid vad durations: min_silence=%.2f, min_speech=%.2f, max_speech=%.2f",
+			vad.MinSilenceDuration, vad.MinSpeechDuration, vad.MaxSpeechDuration)
+	}
+	return nil
+}
 
+// setSpeakerDefaults 设置说话人模型默认值
+func setSpeakerDefaults(m *SpeakerConfig) {
+	if m.Provider.Provider == "" {
+		m.Provider.Provider = "cpu"
+	}
+	if m.Provider.NumThreads == 0 {
+		m.Provider.NumThreads = 1
+	}
+	d := &m.Diarization
+	if d.Threshold == 0 {
+		d.Threshold = 0.5
+	}
+	if d.MinDurationOn == 0 {
+		d.MinDurationOn = 0.3
+	}
+	if d.MinDurationOff == 0 {
+		d.MinDurationOff = 0.5
+	}
+	if d.PoolSize == 0 {
+		d.PoolSize = 1
+	}
+	v := &m.Verification
+	if v.StoreDir == "" {
+		v.StoreDir = "data/speakers"
+	}
+	if v.Threshold == 0 {
+		v.Threshold = 0.5
+	}
+	if v.PoolSize == 0 {
+		v.PoolSize = 1
+	}
+}
+
+// validateSpeaker 验证说话人模型配置，启用说话人分离时需要分割模型和说话人向量模型，
+// 启用说话人验证时需要说话人向量模型
+func validateSpeaker(m *SpeakerConfig) error {
+	d := &m.Diarization
+	v := &m.Verification
+	if !d.Enabled && !v.Enabled {
+		return nil
+	}
+	if m.EmbeddingModel == "" {
+		return fmt.Errorf("speaker.embedding_model is required")
+	}
+	paths := []string{m.EmbeddingModel}
+	if d.Enabled {
+		if d.SegmentationModel == "" {
+			return fmt.Errorf("speaker.diarization.segmentation_model is required")
+		}
+		paths = append(paths, d.SegmentationModel)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("speaker model file not found: %s", path)
+		}
+	}
+	if d.NumSpeakers < 0 || d.Threshold < 0 || d.PoolSize < 0 {
+		return fmt.Errorf("speaker.diarization num_speakers, threshold and pool_size must not be negative")
+	}
+	if v.Threshold < 0 || v.Threshold > 1 || v.PoolSize < 0 {
+		return fmt.Errorf("speaker.verification threshold must be in [0, 1] and pool_size must not be negative")
+	}
+	if m.Provider.Provider != "cpu" &&
+		m.Provider.Provider != "cuda" &&
+		m.Provider.Provider != "auto" {
+		return fmt.Errorf("invalid speaker provider: %s, must be cpu, cuda, or auto", m.Provider.Provider)
+	}
+	return nil
+}
+
+// validateASRRouting 验证语言路由配置，路由目标必须是已配置的识别模型
+func validateASRRouting(routing *ASRRoutingConfig, names []string) error {
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	languages := make([]string, 0, len(routing.Rules))
+	for lang := range routing.Rules {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	for _, lang := range languages {
+		if !known[routing.Rules[lang]] {
+			return fmt.Errorf("models.routing.rules.%s: model %q not found", lang, routing.Rules[lang])
+		}
+	}
+	if routing.Fallback != "" && !known[routing.Fallback] {
+		return fmt.Errorf("models.routing.fallback: model %q not found", routing.Fallback)
+	}
+
+	lid := &routing.LanguageID
+	if !lid.Enabled {
+		return nil
+	}
+	if lid.EncoderPath == "" || lid.DecoderPath == "" {
+		return fmt.Errorf("models.routing.language_id.encoder_path and decoder_path are required")
+	}
+	for _, path := range []string{lid.EncoderPath, lid.DecoderPath} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return fmt.Errorf("models.routing.language_id model file not found: %s", path)
+		}
+	}
+	if lid.MaxDuration < 0 || lid.PoolSize < 0 {
+		return fmt.Errorf("models.routing.language_id max_duration and pool_size must not be negative")
+	}
+	if lid.DetectWindows < 1 || lid.DetectWindows > 10 {
+		return fmt.Errorf("models.routing.language_id.detect_windows must be in [1, 10]")
+	}
+	if lid.Provider.Provider != "cpu" &&
+		lid.Provider.Provider != "cuda" &&
+		lid.Provider.Provider != "auto" {
+		return fmt.Errorf("invalid models.routing.language_id provider: %s, must be cpu, cuda, or auto", lid.Provider.Provider)
+	}
+	return nil
+}
+
+// validateModelNames 验证模型名称非空且唯一，默认模型必须存在
+func validateModelNames(kind string, names []string, defaultName string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("models.%s: every model must have a name", kind)
+		}
+		if seen[name] {
+			return fmt.Errorf("models.%s: duplicate model name %q", kind, name)
+		}
+		seen[name] = true
+	}
+	if defaultName != "" && !seen[defaultName] {
+		return fmt.Errorf("models.default_%s: model %q not found", kind, defaultName)
+	}
+	return nil
+}
+
+// ValidateAuthConfig 验证认证配置
+func ValidateAuthConfig(auth *AuthConfig) error {
+	if !auth.Enabled {
+		return nil
+	}
+
+	if auth.KeyFile != "" {
+		if _, err := os.Stat(auth.KeyFile); os.IsNotExist(err) {
+			return fmt.Errorf("auth key file not found: %s", auth.KeyFile)
+		}
+	} else if len(auth.Keys) == 0 && !auth.JWT.Enabled {
+		return fmt.Errorf("auth is enabled but no keys, key_file or jwt configured")
+	}
+
+	if auth.JWT.Enabled {
+		if auth.JWT.JWKSFile == "" && auth.JWT.JWKSURL == "" {
+			return fmt.Errorf("auth.jwt requires jwks_file or jwks_url")
+		}
+		if auth.JWT.JWKSFile != "" {
+			if _, err := os.Stat(auth.JWT.JWKSFile); os.IsNotExist(err) {
+				return fmt.Errorf("auth jwks file not found: %s", auth.JWT.JWKSFile)
+			}
+		}
+		if auth.JWT.Audience == "" {
+			return fmt.Errorf("auth.jwt.audience is required")
+		}
+	}
+
+	for i, key := range auth.Keys {
+		if err := ValidateAPIKey(&key); err != nil {
+			return fmt.Errorf("auth.keys[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAPIKey 验证单个API密钥配置
+func ValidateAPIKey(key *APIKeyConfig) error {
+	if key.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(key.KeyHash) != 64 {
+		return fmt.Errorf("key_hash of %s must be a hex encoded SHA-256 digest", key.Name)
+	}
+	for _, scope := range key.Scopes {
+		if scope != "stt" && scope != "tts" && scope != "admin" {
+			return fmt.Errorf("invalid scope %q for key %s, must be stt, tts, or admin", scope, key.Name)
+		}
+	}
 	return nil
 }
 
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -406,3 +409,477 @@ func TestResolveProviderWithCuda(t *testing.T) {
 	}
 }
 
+
+func TestValidateAuthConfig(t *testing.T) {
+	validHash := "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+
+	tests := []struct {
+		name    string
+		auth    AuthConfig
+		wantErr bool
+	}{
+		{
+			name:    "disabled",
+			auth:    AuthConfig{Enabled: false},
+			wantErr: false,
+		},
+		{
+			name:    "enabled without keys",
+			auth:    AuthConfig{Enabled: true},
+			wantErr: true,
+		},
+		{
+			name: "valid key",
+			auth: AuthConfig{
+				Enabled: true,
+				Keys:    []APIKeyConfig{{Name: "client", KeyHash: validHash, Scopes: []string{"stt", "tts"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid hash",
+			auth: AuthConfig{
+				Enabled: true,
+				Keys:    []APIKeyConfig{{Name: "client", KeyHash: "plaintext", Scopes: []string{"stt"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid scope",
+			auth: AuthConfig{
+				Enabled: true,
+				Keys:    []APIKeyConfig{{Name: "client", KeyHash: validHash, Scopes: []string{"root"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing key file",
+			auth:    AuthConfig{Enabled: true, KeyFile: "/nonexistent/keys.json"},
+			wantErr: true,
+		},
+		{
+			name:    "jwt without jwks",
+			auth:    AuthConfig{Enabled: true, JWT: JWTConfig{Enabled: true, Audience: "speech-api"}},
+			wantErr: true,
+		},
+		{
+			name:    "jwt without audience",
+			auth:    AuthConfig{Enabled: true, JWT: JWTConfig{Enabled: true, JWKSURL: "https://sso.example.com/jwks"}},
+			wantErr: true,
+		},
+		{
+			name:    "jwt only",
+			auth:    AuthConfig{Enabled: true, JWT: JWTConfig{Enabled: true, JWKSURL: "https://sso.example.com/jwks", Audience: "speech-api"}},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAuthConfig(&tt.auth)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAuthConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetJobsDefaults(t *testing.T) {
+	jobs := &JobsConfig{Workers: 8}
+	SetJobsDefaults(jobs)
+
+	if jobs.Workers != 8 {
+		t.Errorf("Expected configured workers to be kept, got %d", jobs.Workers)
+	}
+	if jobs.StoreDir != "data/jobs" {
+		t.Errorf("Expected default store dir, got %s", jobs.StoreDir)
+	}
+	if jobs.Webhook.MaxRetries != 5 || jobs.Webhook.InitialBackoff != 1 || jobs.Webhook.MaxBackoff != 60 {
+		t.Errorf("Unexpected webhook defaults: %+v", jobs.Webhook)
+	}
+	if jobs.TTS.Concurrency != 4 || jobs.TTS.ParagraphSilenceMs != 500 || jobs.TTS.MaxParagraphLength != 500 {
+		t.Errorf("Unexpected TTS job defaults: %+v", jobs.TTS)
+	}
+}
+
+func TestValidateSpeaker(t *testing.T) {
+	tmpDir := t.TempDir()
+	segmentation := filepath.Join(tmpDir, "segmentation.onnx")
+	embedding := filepath.Join(tmpDir, "embedding.onnx")
+	os.WriteFile(segmentation, []byte("fake"), 0644)
+	os.WriteFile(embedding, []byte("fake"), 0644)
+
+	m := &SpeakerConfig{}
+	setSpeakerDefaults(m)
+	if m.Diarization.Threshold != 0.5 || m.Diarization.MinDurationOn != 0.3 || m.Diarization.MinDurationOff != 0.5 || m.Diarization.PoolSize != 1 {
+		t.Errorf("Unexpected diarization defaults: %+v", m.Diarization)
+	}
+	if err := validateSpeaker(m); err != nil {
+		t.Errorf("Disabled diarization should be valid: %v", err)
+	}
+
+	m.Diarization.Enabled = true
+	if err := validateSpeaker(m); err == nil {
+		t.Error("Expected error for missing models")
+	}
+	m.Diarization.SegmentationModel = segmentation
+	m.EmbeddingModel = filepath.Join(tmpDir, "missing.onnx")
+	if err := validateSpeaker(m); err == nil {
+		t.Error("Expected error for missing embedding model")
+	}
+	m.EmbeddingModel = embedding
+	if err := validateSpeaker(m); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	m.Diarization.NumSpeakers = -1
+	if err := validateSpeaker(m); err == nil {
+		t.Error("Expected error for negative num_speakers")
+	}
+
+	m.Diarization = DiarizationConfig{}
+	m.Verification.Enabled = true
+	if err := validateSpeaker(m); err != nil {
+		t.Errorf("Verification without diarization should only need the embedding model: %v", err)
+	}
+	if m.Verification.StoreDir != "data/speakers" || m.Verification.Threshold != 0.5 {
+		t.Errorf("Unexpected verification defaults: %+v", m.Verification)
+	}
+	m.Verification.Threshold = 1.5
+	if err := validateSpeaker(m); err == nil {
+		t.Error("Expected error for threshold above 1")
+	}
+}
+
+func TestParseUnifiedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"mode": "separated", "session": {"max_sessions": 50}}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	previous := GlobalConfig
+	cfg, err := ParseUnifiedConfig(path)
+	if err != nil {
+		t.Fatalf("ParseUnifiedConfig() error = %v", err)
+	}
+	if GlobalConfig != previous {
+		t.Error("ParseUnifiedConfig should not modify GlobalConfig")
+	}
+	if cfg.Session.MaxSessions != 50 || cfg.Session.Timeout != 1800 {
+		t.Errorf("Unexpected session config: %+v", cfg.Session)
+	}
+}
+
+func TestParseUnifiedConfig_Models(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"sv.onnx", "encoder.onnx", "decoder.onnx", "tokens.txt", "kokoro.onnx"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	file := func(name string) string { return filepath.Join(dir, name) }
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		return path
+	}
+
+	content := fmt.Sprintf(`{
+  "mode": "unified",
+  "stt": {"model_path": %q, "tokens_path": %q},
+  "models": {
+    "default_stt": "whisper-en",
+    "stt": [{"name": "whisper-en", "model_type": "whisper", "encoder_path": %q, "decoder_path": %q, "tokens_path": %q, "languages": ["en"]}],
+    "tts": [{"name": "kokoro", "model_path": %q}]
+  }
+}`, file("sv.onnx"), file("tokens.txt"), file("encoder.onnx"), file("decoder.onnx"), file("tokens.txt"), file("kokoro.onnx"))
+
+	cfg, err := ParseUnifiedConfig(write(content))
+	if err != nil {
+		t.Fatalf("ParseUnifiedConfig() error = %v", err)
+	}
+
+	asrModels := cfg.ASRModels()
+	if len(asrModels) != 2 || asrModels[0].Name != DefaultModelName || asrModels[1].Name != "whisper-en" {
+		t.Fatalf("Unexpected ASR models: %+v", asrModels)
+	}
+	if asrModels[0].ModelType != ASRModelSenseVoice || asrModels[0].PoolSize != 4 || asrModels[1].Provider.Provider != "cpu" {
+		t.Errorf("Expected model defaults to be applied, got %+v / %+v", asrModels[0], asrModels[1])
+	}
+	if cfg.Models.DefaultSTT != "whisper-en" || cfg.Models.DefaultTTS != "kokoro" {
+		t.Errorf("Unexpected defaults: stt=%s tts=%s", cfg.Models.DefaultSTT, cfg.Models.DefaultTTS)
+	}
+	if tts := cfg.TTSModels(); len(tts) != 1 || tts[0].PoolSize != 5 {
+		t.Errorf("Unexpected TTS models: %+v", tts)
+	}
+
+	// transducer配置热词时默认使用modified_beam_search
+	content = fmt.Sprintf(`{"mode": "separated", "models": {"stt": [{"name": "t", "model_type": "transducer",
+  "encoder_path": %q, "decoder_path": %q, "joiner_path": %q, "tokens_path": %q, "hotwords": ["AeroSpeech"]}]}}`,
+		file("encoder.onnx"), file("decoder.onnx"), file("decoder.onnx"), file("tokens.txt"))
+	cfg, err = ParseUnifiedConfig(write(content))
+	if err != nil {
+		t.Fatalf("ParseUnifiedConfig() error = %v", err)
+	}
+	if m := cfg.Models.STT[0]; m.DecodingMethod != DecodingModifiedBeamSearch || m.HotwordsScore != 1.5 {
+		t.Errorf("Expected hotword defaults, got %s/%v", m.DecodingMethod, m.HotwordsScore)
+	}
+	if m := cfg.Models.STT[0]; m.Punctuation.PoolSize != 0 {
+		t.Errorf("Expected no punctuation defaults without model_path, got %+v", m.Punctuation)
+	}
+	if cfg.Hotwords.MaxWords != 1000 {
+		t.Errorf("Expected hotwords.max_words default 1000, got %d", cfg.Hotwords.MaxWords)
+	}
+
+	invalid := map[string]string{
+		"duplicate name": fmt.Sprintf(`{"mode": "separated", "models": {"stt": [
+  {"name": "a", "model_path": %q, "tokens_path": %q},
+  {"name": "a", "model_path": %q, "tokens_path": %q}]}}`, file("sv.onnx"), file("tokens.txt"), file("sv.onnx"), file("tokens.txt")),
+		"missing name": fmt.Sprintf(`{"mode": "separated", "models": {"stt": [{"model_path": %q, "tokens_path": %q}]}}`,
+			file("sv.onnx"), file("tokens.txt")),
+		"unknown default": fmt.Sprintf(`{"mode": "separated", "models": {"default_tts": "x", "tts": [{"name": "kokoro", "model_path": %q}]}}`,
+			file("kokoro.onnx")),
+		"whisper without decoder": fmt.Sprintf(`{"mode": "separated", "models": {"stt": [{"name": "w", "model_type": "whisper", "encoder_path": %q, "tokens_path": %q}]}}`,
+			file("encoder.onnx"), file("tokens.txt")),
+		"missing punctuation model": fmt.Sprintf(`{"mode": "separated", "models": {"stt": [{"name": "sv", "model_path": %q, "tokens_path": %q, "punctuation": {"model_path": %q}}]}}`,
+			file("sv.onnx"), file("tokens.txt"), file("missing-punct.onnx")),
+		"sense voice hotwords": fmt.Sprintf(`{"mode": "separated", "models": {"stt": [{"name": "sv", "model_path": %q, "tokens_path": %q, "hotwords": ["a"]}]}}`,
+			file("sv.onnx"), file("tokens.txt")),
+		"transducer hotwords with greedy search": fmt.Sprintf(`{"mode": "separated", "models": {"stt": [{"name": "t", "model_type": "transducer",
+  "encoder_path": %q, "decoder_path": %q, "joiner_path": %q, "tokens_path": %q, "hotwords": ["a"], "decoding_method": "greedy_search"}]}}`,
+			file("encoder.onnx"), file("decoder.onnx"), file("decoder.onnx"), file("tokens.txt")),
+	}
+	for name, content := range invalid {
+		if _, err := ParseUnifiedConfig(write(content)); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestParseASROptions(t *testing.T) {
+	params := map[string]string{"language": "en", "itn": "false", "decoding_method": DecodingModifiedBeamSearch, "max_active_paths": "8",
+		"hotwords": "sherpa onnx, AeroSpeech,", "hotword_sets": "products", "hotwords_score": "2.5", "punctuate": "true", "keep_tags": "1"}
+	opts, err := ParseASROptions(func(key string) string { return params[key] })
+	if err != nil {
+		t.Fatalf("ParseASROptions() error = %v", err)
+	}
+	if opts.Language != "en" || opts.ITN == nil || *opts.ITN || opts.DecodingMethod != DecodingModifiedBeamSearch || opts.MaxActivePaths != 8 {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+	if len(opts.Hotwords) != 2 || opts.Hotwords[1] != "AeroSpeech" || len(opts.HotwordSets) != 1 || opts.HotwordSets[0] != "products" {
+		t.Errorf("Unexpected hotwords: %v, %v", opts.Hotwords, opts.HotwordSets)
+	}
+	if opts.HotwordsScore != 2.5 {
+		t.Errorf("Expected hotwords_score 2.5, got %v", opts.HotwordsScore)
+	}
+	if opts.Punctuate == nil || !*opts.Punctuate || opts.KeepTags == nil || !*opts.KeepTags {
+		t.Errorf("Expected punctuate=true and keep_tags=true, got %v, %v", opts.Punctuate, opts.KeepTags)
+	}
+
+	opts, err = ParseASROptions(func(key string) string { return "" })
+	if err != nil || opts.ITN != nil || opts.MaxActivePaths != 0 {
+		t.Errorf("Expected empty options, got %+v, %v", opts, err)
+	}
+
+	for _, invalid := range []map[string]string{
+		{"itn": "maybe"},
+		{"punctuate": "yes please"},
+		{"keep_tags": "maybe"},
+		{"max_active_paths": "x"},
+		{"max_active_paths": "1000"},
+		{"decoding_method": "beam"},
+		{"hotwords": "a/b"},
+		{"hotwords": "a", "hotwords_score": "x"},
+		{"hotwords": "a", "hotwords_score": "20"},
+		{"hotwords_score": "2"},
+		{"hotwords": strings.Repeat("w,", MaxHotwordsPerRequest+1)},
+	} {
+		if _, err := ParseASROptions(func(key string) string { return invalid[key] }); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+}
+
+func TestValidateKWS(t *testing.T) {
+	tmpDir := t.TempDir()
+	paths := make([]string, 4)
+	for i, name := range []string{"encoder.onnx", "decoder.onnx", "joiner.onnx", "tokens.txt"} {
+		paths[i] = filepath.Join(tmpDir, name)
+		os.WriteFile(paths[i], []byte("fake"), 0644)
+	}
+
+	k := &KWSConfig{}
+	setKWSDefaults(k)
+	if k.KeywordsScore != 1.0 || k.KeywordsThreshold != 0.25 || k.MaxActivePaths != 4 || k.PoolSize != 2 {
+		t.Errorf("Unexpected KWS defaults: %+v", k)
+	}
+	if err := validateKWS(k); err != nil {
+		t.Errorf("Disabled KWS should be valid: %v", err)
+	}
+
+	k.Enabled = true
+	if err := validateKWS(k); err == nil {
+		t.Error("Expected error for missing model files")
+	}
+	k.EncoderPath, k.DecoderPath, k.JoinerPath, k.TokensPath = paths[0], paths[1], paths[2], paths[3]
+	if err := validateKWS(k); err == nil {
+		t.Error("Expected error without keywords")
+	}
+	k.Keywords = []string{"n ǐ h ǎo @你好"}
+	if err := validateKWS(k); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	k.Keywords = []string{"a/b"}
+	if err := validateKWS(k); err == nil {
+		t.Error("Expected error for keyword containing '/'")
+	}
+	k.Keywords = nil
+	k.KeywordsFile = filepath.Join(tmpDir, "missing.txt")
+	if err := validateKWS(k); err == nil {
+		t.Error("Expected error for missing keywords file")
+	}
+}
+
+func TestValidateAudioTagging(t *testing.T) {
+	tmpDir := t.TempDir()
+	model := filepath.Join(tmpDir, "model.onnx")
+	labels := filepath.Join(tmpDir, "class_labels_indices.csv")
+	os.WriteFile(model, []byte("fake"), 0644)
+	os.WriteFile(labels, []byte("fake"), 0644)
+
+	a := &AudioTaggingConfig{}
+	setAudioTaggingDefaults(a)
+	if a.ModelType != "ced" || a.TopK != 5 || a.PoolSize != 2 {
+		t.Errorf("Unexpected audio tagging defaults: %+v", a)
+	}
+	if err := validateAudioTagging(a); err != nil {
+		t.Errorf("Disabled audio tagging should be valid: %v", err)
+	}
+
+	a.Enabled = true
+	if err := validateAudioTagging(a); err == nil {
+		t.Error("Expected error for missing model files")
+	}
+	a.ModelPath, a.LabelsPath = model, labels
+	if err := validateAudioTagging(a); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	a.ModelType = "panns"
+	if err := validateAudioTagging(a); err == nil {
+		t.Error("Expected error for unknown model type")
+	}
+	a.ModelType = "zipformer"
+	a.TopK = MaxAudioTagTopK + 1
+	if err := validateAudioTagging(a); err == nil {
+		t.Error("Expected error for top_k out of range")
+	}
+	a.TopK = 5
+	a.LabelsPath = filepath.Join(tmpDir, "missing.csv")
+	if err := validateAudioTagging(a); err == nil {
+		t.Error("Expected error for missing labels file")
+	}
+}
+
+func TestValidateEnhancement(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "gtcrn_simple.onnx")
+	os.WriteFile(model, []byte("fake"), 0644)
+
+	e := &EnhancementConfig{}
+	setEnhancementDefaults(e)
+	if e.PoolSize != 2 || e.Provider.Provider != "cpu" || e.ApplyByDefault {
+		t.Errorf("Unexpected enhancement defaults: %+v", e)
+	}
+	if err := validateEnhancement(e); err != nil {
+		t.Errorf("Disabled enhancement should be valid: %v", err)
+	}
+
+	e.Enabled = true
+	if err := validateEnhancement(e); err == nil {
+		t.Error("Expected error for missing model_path")
+	}
+	e.ModelPath = model + ".missing"
+	if err := validateEnhancement(e); err == nil {
+		t.Error("Expected error for missing model file")
+	}
+	e.ModelPath = model
+	if err := validateEnhancement(e); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	e.Provider.Provider = "tpu"
+	if err := validateEnhancement(e); err == nil {
+		t.Error("Expected error for invalid provider")
+	}
+}
+
+func TestValidateVoice(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "silero_vad.onnx")
+	os.WriteFile(model, []byte("fake"), 0644)
+
+	v := &VoiceConfig{}
+	setVoiceDefaults(v)
+	if v.DialogTimeout != 10 || v.Speed != 1.0 {
+		t.Errorf("Unexpected voice defaults: %+v", v)
+	}
+	vad := &VADConfig{Provider: "silero", Threshold: 0.5, MinSilenceDuration: 0.5, MinSpeechDuration: 0.25, MaxSpeechDuration: 20}
+	if err := validateVoice(v, vad); err != nil {
+		t.Errorf("Disabled voice should be valid: %v", err)
+	}
+
+	v.Enabled = true
+	if err := validateVoice(v, vad); err == nil {
+		t.Error("Expected error for missing dialog_url")
+	}
+	v.DialogURL = "ftp://bot.local/reply"
+	if err := validateVoice(v, vad); err == nil {
+		t.Error("Expected error for non-HTTP dialog_url")
+	}
+	v.DialogURL = "http://127.0.0.1:9000/reply"
+	if err := validateVoice(v, vad); err == nil {
+		t.Error("Expected error when vad is disabled")
+	}
+	vad.Enabled = true
+	if err := validateVoice(v, vad); err == nil {
+		t.Error("Expected error for missing vad model_path")
+	}
+	vad.ModelPath = model
+	if err := validateVoice(v, vad); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+
+	vad.Provider = "webrtc"
+	if err := validateVoice(v, vad); err == nil {
+		t.Error("Expected error for invalid vad provider")
+	}
+	vad.Provider = "ten"
+	vad.MaxSpeechDuration = 0.1
+	if err := validateVoice(v, vad); err == nil {
+		t.Error("Expected error for max_speech_duration below min_speech_duration")
+	}
+	vad.MaxSpeechDuration = 20
+	v.Speed = 3
+	if err := validateVoice(v, vad); err == nil {
+		t.Error("Expected error for out-of-range speed")
+	}
+}
+
+func TestValidateVAD(t *testing.T) {
+	// 未配置model_path时不加载模型（兼容只开启enabled的旧配置）
+	vad := &VADConfig{Enabled: true, Provider: "silero", Threshold: 0.5, MinSilenceDuration: 0.5, MinSpeechDuration: 0.25, MaxSpeechDuration: 20}
+	if err := validateVAD(vad); err != nil {
+		t.Errorf("VAD without model_path should be valid: %v", err)
+	}
+	vad.ModelPath = filepath.Join(t.TempDir(), "missing.onnx")
+	if err := validateVAD(vad); err == nil {
+		t.Error("Expected error for missing vad model file")
+	}
+	os.WriteFile(vad.ModelPath, []byte("fake"), 0644)
+	if err := validateVAD(vad); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	vad.Threshold = 1
+	if err := validateVAD(vad); err == nil {
+		t.Error("Expected error for out-of-range threshold")
+	}
+}
@@ -1,80 +1,144 @@
 package hotreload
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
 )
 
+// defaultDebounce 配置文件变更防抖时间
+const defaultDebounce = 2 * time.Second
+
+// Callback 配置变更回调，参数为重新解析的完整配置
+type Callback func(cfg *config.UnifiedConfig) error
+
+// ReloadResult 配置重载结果
+type ReloadResult struct {
+	Time            time.Time         `json:"time"`
+	Success         bool              `json:"success"`          // 文件中的所有变更均已生效
+	Error           string            `json:"error,omitempty"`  // 读取或解析配置失败的原因
+	Applied         []string          `json:"applied"`          // 已生效的配置项
+	RestartRequired []string          `json:"restart_required"` // 需要重启才能生效的配置项（未应用）
+	Failed          map[string]string `json:"failed,omitempty"` // 应用失败的配置项及原因
+}
+
+// callbackEntry 回调及其负责的配置项
+type callbackEntry struct {
+	keys     []string
+	callback Callback
+}
+
 // HotReloadManager 配置热加载管理器
+// 配置文件变更时重新解析统一配置，与运行中的配置逐项比较：
+// 注册了回调的配置项立即应用，其余变更需要重启，只记录在重载结果中
 type HotReloadManager struct {
-	mu            sync.RWMutex
-	callbacks     map[string][]func()
+	mu            sync.Mutex
+	reloadMu      sync.Mutex // 串行执行重载
+	callbacks     []callbackEntry
 	watcher       *fsnotify.Watcher
+	watching      bool
+	debounce      time.Duration
 	debounceTimer *time.Timer
 	stopChan      chan struct{}
+	stopOnce      sync.Once
 	configPath    string
+	effective     map[string]interface{} // 运行中配置的扁平化表示
+	lastResult    *ReloadResult
+	parse         func(configPath string) (*config.UnifiedConfig, error)
 }
 
-// NewHotReloadManager 创建新的热加载管理器
-func NewHotReloadManager() (*HotReloadManager, error) {
+// NewHotReloadManager 创建新的热加载管理器，current为启动时加载的配置
+func NewHotReloadManager(current *config.UnifiedConfig) (*HotReloadManager, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file watcher: %w", err)
 	}
 
+	effective, err := flattenConfig(current)
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
 	manager := &HotReloadManager{
-		callbacks:  make(map[string][]func()),
-		watcher:    watcher,
-		stopChan:   make(chan struct{}),
+		watcher:   watcher,
+		debounce:  defaultDebounce,
+		stopChan:  make(chan struct{}),
+		effective: effective,
+		parse:     config.ParseUnifiedConfig,
 	}
 
 	return manager, nil
 }
 
 // RegisterCallback 注册配置变更回调
-func (m *HotReloadManager) RegisterCallback(configKey string, callback func()) {
+// keys为回调负责的配置项（如 "rate_limit.burst_size"），其中任意一项变更时调用一次回调；
+// 没有回调负责的配置项变更时视为需要重启
+func (m *HotReloadManager) RegisterCallback(keys []string, callback Callback) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.callbacks[configKey] == nil {
-		m.callbacks[configKey] = make([]func(), 0)
-	}
-	m.callbacks[configKey] = append(m.callbacks[configKey], callback)
+	m.callbacks = append(m.callbacks, callbackEntry{keys: keys, callback: callback})
+}
+
+// SetConfigPath 设置配置文件路径（不监听文件，可通过Reload手动重载）
+func (m *HotReloadManager) SetConfigPath(configPath string) {
+	m.mu.Lock()
+	m.configPath = configPath
+	m.mu.Unlock()
 }
 
 // StartWatching 开始监听配置文件
 func (m *HotReloadManager) StartWatching(configPath string) error {
-	m.configPath = configPath
+	m.SetConfigPath(configPath)
 
-	// 添加配置文件到监听列表
-	if err := m.watcher.Add(configPath); err != nil {
+	// 监听所在目录，以便捕获编辑器"写临时文件再重命名"方式的保存
+	if err := m.watcher.Add(filepath.Dir(configPath)); err != nil {
 		return fmt.Errorf("failed to watch config file: %w", err)
 	}
 
+	m.mu.Lock()
+	m.watching = true
+	m.mu.Unlock()
+
 	// 启动监听协程
-	go m.watchLoop()
+	go m.watchLoop(filepath.Clean(configPath))
 
 	logger.Infof("Started watching config file: %s", configPath)
 	return nil
 }
 
 // watchLoop 监听循环
-func (m *HotReloadManager) watchLoop() {
+func (m *HotReloadManager) watchLoop(configPath string) {
 	defer m.watcher.Close()
 
 	for {
 		select {
-		case event := <-m.watcher.Events:
-			if event.Op&fsnotify.Write == fsnotify.Write {
+		case event, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != configPath {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
 				logger.Infof("Config file changed: %s", event.Name)
 				m.handleConfigChange()
 			}
-		case err := <-m.watcher.Errors:
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
 			logger.Errorf("Config file watcher error: %v", err)
 		case <-m.stopChan:
 			logger.Info("Config file watcher stopped")
@@ -85,59 +149,169 @@ func (m *HotReloadManager) watchLoop() {
 
 // handleConfigChange 处理配置文件变更
 func (m *HotReloadManager) handleConfigChange() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// 防抖动处理
 	if m.debounceTimer != nil {
 		m.debounceTimer.Stop()
 	}
 
-	m.debounceTimer = time.AfterFunc(2*time.Second, func() {
-		m.reloadConfig()
+	m.debounceTimer = time.AfterFunc(m.debounce, func() {
+		m.Reload()
 	})
 }
 
-// reloadConfig 重新加载配置
-func (m *HotReloadManager) reloadConfig() {
+// Reload 重新加载配置文件并应用可热加载的变更
+func (m *HotReloadManager) Reload() *ReloadResult {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	m.mu.Lock()
+	configPath := m.configPath
+	callbacks := append([]callbackEntry(nil), m.callbacks...)
+	effective := m.effective
+	m.mu.Unlock()
+
+	result := &ReloadResult{
+		Time:            time.Now(),
+		Applied:         make([]string, 0),
+		RestartRequired: make([]string, 0),
+	}
+	defer m.setLastResult(result)
+
 	logger.Info("Reloading configuration...")
 
-	// 重新读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
-		logger.Errorf("Failed to read config file: %v", err)
-		return
+	if configPath == "" {
+		result.Error = "config file path is not set"
+		logger.Errorf("Failed to reload config: %s", result.Error)
+		return result
+	}
+
+	cfg, err := m.parse(configPath)
+	if err != nil {
+		result.Error = err.Error()
+		logger.Errorf("Failed to reload config, keeping running configuration: %v", err)
+		return result
 	}
 
-	logger.Info("Configuration reloaded successfully")
+	next, err := flattenConfig(cfg)
+	if err != nil {
+		result.Error = err.Error()
+		logger.Errorf("Failed to reload config: %v", err)
+		return result
+	}
 
-	// 执行回调函数
-	m.executeCallbacks()
-}
+	changed := diffKeys(effective, next)
+	if len(changed) == 0 {
+		result.Success = true
+		logger.Info("Configuration unchanged")
+		return result
+	}
 
-// executeCallbacks 执行回调函数
-func (m *HotReloadManager) executeCallbacks() {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	// 找出每个变更项对应的回调
+	covered := make(map[string]bool)
+	updated := make(map[string]interface{}, len(effective))
+	for k, v := range effective {
+		updated[k] = v
+	}
+
+	for _, entry := range callbacks {
+		var keys []string
+		for _, key := range entry.keys {
+			if changed[key] {
+				keys = append(keys, key)
+				covered[key] = true
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		if err := runCallback(entry.callback, cfg); err != nil {
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			for _, key := range keys {
+				result.Failed[key] = err.Error()
+			}
+			logger.Errorf("Failed to apply config change %v: %v", keys, err)
+			continue
+		}
+
+		for _, key := range keys {
+			result.Applied = append(result.Applied, key)
+			if v, ok := next[key]; ok {
+				updated[key] = v
+			} else {
+				delete(updated, key)
+			}
+		}
+	}
 
-	for configKey, callbacks := range m.callbacks {
-		logger.Infof("Executing callbacks for config key: %s", configKey)
-		for _, callback := range callbacks {
-			// 在goroutine中执行回调，避免阻塞
-			go func(cb func(), key string) {
-				defer func() {
-					if r := recover(); r != nil {
-						logger.Errorf("Callback panicked for key %s: %v", key, r)
-					}
-				}()
-				cb()
-			}(callback, configKey)
+	for key := range changed {
+		if !covered[key] {
+			result.RestartRequired = append(result.RestartRequired, key)
 		}
 	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.RestartRequired)
+
+	m.mu.Lock()
+	m.effective = updated
+	m.mu.Unlock()
+
+	result.Success = len(result.Failed) == 0 && len(result.RestartRequired) == 0
+	if len(result.Applied) > 0 {
+		logger.Infof("Configuration changes applied: %v", result.Applied)
+	}
+	if len(result.RestartRequired) > 0 {
+		logger.Warnf("Configuration changes require a restart and were not applied: %v", result.RestartRequired)
+	}
+	return result
+}
+
+// runCallback 执行回调，回调panic时转为错误
+func runCallback(callback Callback, cfg *config.UnifiedConfig) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("callback panicked: %v", r)
+		}
+	}()
+	return callback(cfg)
+}
+
+// setLastResult 记录最近一次重载结果
+func (m *HotReloadManager) setLastResult(result *ReloadResult) {
+	m.mu.Lock()
+	m.lastResult = result
+	m.mu.Unlock()
+}
+
+// LastResult 获取最近一次重载结果，尚未重载时返回nil
+func (m *HotReloadManager) LastResult() *ReloadResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastResult
 }
 
 // Stop 停止监听
 func (m *HotReloadManager) Stop() {
-	close(m.stopChan)
-	if m.debounceTimer != nil {
-		m.debounceTimer.Stop()
-	}
+	m.stopOnce.Do(func() {
+		close(m.stopChan)
+
+		m.mu.Lock()
+		if m.debounceTimer != nil {
+			m.debounceTimer.Stop()
+		}
+		watching := m.watching
+		m.mu.Unlock()
+
+		// 未启动监听协程时直接关闭watcher
+		if !watching {
+			m.watcher.Close()
+		}
+	})
 }
 
 // GetConfigValue 获取配置值
@@ -156,3 +330,66 @@ func (m *HotReloadManager) SaveConfig() error {
 	return viper.WriteConfig()
 }
 
+// flattenConfig 将配置按JSON字段名展开为 "section.key" 形式的扁平映射（数组作为整体比较）
+func flattenConfig(cfg *config.UnifiedConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	flat := make(map[string]interface{})
+	flattenInto(flat, "", tree)
+	return flat, nil
+}
+
+// SectionKeys 返回配置中某一节下的全部配置项（如 "stt" 返回 "stt.model_path" 等），用于为整节注册回调
+func SectionKeys(cfg *config.UnifiedConfig, section string) ([]string, error) {
+	flat, err := flattenConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	for k := range flat {
+		if strings.HasPrefix(k, section+".") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// flattenInto 递归展开嵌套对象
+func flattenInto(flat map[string]interface{}, prefix string, tree map[string]interface{}) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if child, ok := v.(map[string]interface{}); ok {
+			flattenInto(flat, key, child)
+			continue
+		}
+		flat[key] = v
+	}
+}
+
+// diffKeys 比较两份扁平配置，返回发生变化的配置项
+func diffKeys(old, next map[string]interface{}) map[string]bool {
+	changed := make(map[string]bool)
+	for k, v := range next {
+		if ov, ok := old[k]; !ok || !reflect.DeepEqual(ov, v) {
+			changed[k] = true
+		}
+	}
+	for k := range old {
+		if _, ok := next[k]; !ok {
+			changed[k] = true
+		}
+	}
+	return changed
+}
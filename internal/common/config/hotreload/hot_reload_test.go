@@ -0,0 +1,202 @@
+package hotreload
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+const baseConfig = `{
+  "mode": "separated",
+  "server": {"port": 8080},
+  "logging": {"level": "info"},
+  "rate_limit": {"requests_per_second": 100, "burst_size": 200}
+}`
+
+// writeConfig 写入配置文件
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+// newTestManager 使用临时配置文件创建热加载管理器
+func newTestManager(t *testing.T) (*HotReloadManager, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, baseConfig)
+
+	cfg, err := config.ParseUnifiedConfig(path)
+	if err != nil {
+		t.Fatalf("ParseUnifiedConfig() error = %v", err)
+	}
+	m, err := NewHotReloadManager(cfg)
+	if err != nil {
+		t.Fatalf("NewHotReloadManager() error = %v", err)
+	}
+	t.Cleanup(m.Stop)
+	m.SetConfigPath(path)
+	return m, path
+}
+
+func TestReload_Unchanged(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	result := m.Reload()
+	if !result.Success || len(result.Applied) != 0 || len(result.RestartRequired) != 0 {
+		t.Errorf("Expected no changes, got %+v", result)
+	}
+	if m.LastResult() != result {
+		t.Error("Expected LastResult to return the latest result")
+	}
+}
+
+func TestReload_AppliesAndRejects(t *testing.T) {
+	m, path := newTestManager(t)
+
+	var calls int
+	var burst int
+	m.RegisterCallback([]string{"rate_limit.requests_per_second", "rate_limit.burst_size"}, func(cfg *config.UnifiedConfig) error {
+		calls++
+		burst = cfg.RateLimit.BurstSize
+		return nil
+	})
+
+	writeConfig(t, path, `{
+  "mode": "separated",
+  "server": {"port": 9090},
+  "logging": {"level": "info"},
+  "rate_limit": {"requests_per_second": 50, "burst_size": 100}
+}`)
+
+	result := m.Reload()
+	if result.Success {
+		t.Error("Expected reload with restart-required changes to not be fully successful")
+	}
+	if calls != 1 || burst != 100 {
+		t.Errorf("Expected callback to be called once with new values, got calls=%d burst=%d", calls, burst)
+	}
+	if len(result.Applied) != 2 || result.Applied[0] != "rate_limit.burst_size" {
+		t.Errorf("Unexpected applied keys: %v", result.Applied)
+	}
+	if len(result.RestartRequired) != 1 || result.RestartRequired[0] != "server.port" {
+		t.Errorf("Unexpected restart-required keys: %v", result.RestartRequired)
+	}
+
+	// 已应用的变更不再重复应用，需要重启的变更继续报告
+	result = m.Reload()
+	if calls != 1 {
+		t.Errorf("Expected applied changes not to be re-applied, got %d calls", calls)
+	}
+	if len(result.Applied) != 0 || len(result.RestartRequired) != 1 {
+		t.Errorf("Unexpected second reload result: %+v", result)
+	}
+}
+
+func TestReload_CallbackFailure(t *testing.T) {
+	m, path := newTestManager(t)
+
+	var calls int
+	m.RegisterCallback([]string{"logging.level"}, func(cfg *config.UnifiedConfig) error {
+		calls++
+		return errors.New("invalid log level")
+	})
+
+	writeConfig(t, path, `{"mode": "separated", "server": {"port": 8080}, "logging": {"level": "verbose"},
+  "rate_limit": {"requests_per_second": 100, "burst_size": 200}}`)
+
+	result := m.Reload()
+	if result.Success || result.Failed["logging.level"] != "invalid log level" {
+		t.Errorf("Expected logging.level to fail, got %+v", result)
+	}
+
+	// 失败的变更保持未生效状态，下次重载会重试
+	m.Reload()
+	if calls != 2 {
+		t.Errorf("Expected failed change to be retried, got %d calls", calls)
+	}
+}
+
+func TestReload_InvalidConfig(t *testing.T) {
+	m, path := newTestManager(t)
+
+	called := false
+	m.RegisterCallback([]string{"logging.level"}, func(cfg *config.UnifiedConfig) error {
+		called = true
+		return nil
+	})
+
+	writeConfig(t, path, `{"mode": "bogus", "logging": {"level": "debug"}}`)
+
+	result := m.Reload()
+	if result.Error == "" || result.Success {
+		t.Errorf("Expected parse error, got %+v", result)
+	}
+	if called {
+		t.Error("Expected no callbacks for an invalid config")
+	}
+}
+
+func TestStartWatching(t *testing.T) {
+	m, path := newTestManager(t)
+	m.debounce = 10 * time.Millisecond
+
+	applied := make(chan string, 1)
+	m.RegisterCallback([]string{"logging.level"}, func(cfg *config.UnifiedConfig) error {
+		applied <- cfg.Logging.Level
+		return nil
+	})
+
+	if err := m.StartWatching(path); err != nil {
+		t.Fatalf("StartWatching() error = %v", err)
+	}
+
+	// 以"写临时文件再重命名"的方式保存
+	tmp := path + ".tmp"
+	writeConfig(t, tmp, `{"mode": "separated", "server": {"port": 8080}, "logging": {"level": "debug"},
+  "rate_limit": {"requests_per_second": 100, "burst_size": 200}}`)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("Failed to rename config: %v", err)
+	}
+
+	select {
+	case level := <-applied:
+		if level != "debug" {
+			t.Errorf("Expected level debug, got %s", level)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for config reload")
+	}
+}
+
+func TestSectionKeys(t *testing.T) {
+	cfg := &config.UnifiedConfig{
+		STT: &config.ASRConfig{ModelPath: "model.onnx"},
+	}
+
+	keys, err := SectionKeys(cfg, "stt")
+	if err != nil {
+		t.Fatalf("SectionKeys() error = %v", err)
+	}
+	found := false
+	for _, k := range keys {
+		if k == "stt.model_path" {
+			found = true
+		}
+		if len(k) < 4 || k[:4] != "stt." {
+			t.Errorf("Unexpected key outside section: %s", k)
+		}
+	}
+	if !found {
+		t.Errorf("Expected stt.model_path in %v", keys)
+	}
+
+	if keys, _ := SectionKeys(cfg, "tts"); len(keys) != 0 {
+		t.Errorf("Expected no keys for missing section, got %v", keys)
+	}
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// AudioTagger 标注PCM16单声道音频（16kHz）中的音频事件
+type AudioTagger func(ctx context.Context, audio []byte, opts *config.AudioTagOptions) (*config.AudioTagResult, error)
+
+// AudioHandler 音频分析与处理处理器
+type AudioHandler struct {
+	tagger   AudioTagger   // 音频事件标注，为nil时未启用
+	enhancer AudioEnhancer // 语音降噪，为nil时未启用
+}
+
+// NewAudioHandler 创建音频处理器
+func NewAudioHandler(tagger AudioTagger, enhancer AudioEnhancer) *AudioHandler {
+	return &AudioHandler{tagger: tagger, enhancer: enhancer}
+}
+
+// Tag 音频事件标注
+// @Summary      音频事件标注
+// @Description  使用AudioSet标注模型识别音频中的事件（如音乐、振铃、静音），返回得分最高的标签；指定window时另外按不重叠的时间窗口分别标注
+// @Tags         Audio
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        audio   formData  file    true   "音频文件（16kHz单声道PCM16或WAV）"
+// @Param        top_k   formData  int     false  "返回的标签数（1-50），默认按配置"
+// @Param        window  formData  number  false  "时间窗口长度（秒，不小于0.5）"
+// @Success      200     {object}  map[string]interface{}  "标注结果"
+// @Failure      400     {object}  map[string]interface{}  "请求参数错误"
+// @Router       /audio/tag [post]
+func (h *AudioHandler) Tag(c *gin.Context) {
+	opts, err := parseAudioTagOptions(func(key string) string {
+		return c.DefaultPostForm(key, c.Query(key))
+	})
+	if err != nil {
+		invalidOptions(c, err)
+		return
+	}
+
+	samples, err := readAudioFiles(c, audioSampleRate)
+	if err == nil && len(samples) != 1 {
+		err = fmt.Errorf("exactly one audio file is required")
+	}
+	if err != nil {
+		invalidOptions(c, err)
+		return
+	}
+	audio := samples[0]
+	if opts.Window > 0 {
+		// 按16kHz PCM16估算窗口数
+		duration := float64(len(audio)/2) / audioSampleRate
+		if n := int(duration / opts.Window); n > config.MaxAudioTagWindows {
+			invalidOptions(c, fmt.Errorf("too many windows: %d, max %d", n, config.MaxAudioTagWindows))
+			return
+		}
+	}
+
+	result, err := h.tagger(c.Request.Context(), audio, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "audio tagging failed",
+			"error": gin.H{
+				"type":    "TAGGING_ERROR",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    result,
+	})
+}
+
+// Enhance 语音增强（降噪）
+// @Summary      语音增强
+// @Description  使用GTCRN模型对音频降噪，返回与输入采样率相同的单声道音频；降噪耗时（毫秒）见响应头X-Enhance-Latency-Ms
+// @Tags         Audio
+// @Accept       multipart/form-data
+// @Produce      audio/wav
+// @Param        audio        formData  file    true   "音频文件（单声道PCM16或WAV）"
+// @Param        sample_rate  formData  int     false  "输入PCM的采样率（8000-48000），默认16000；WAV文件重采样到该采样率"
+// @Param        format       formData  string  false  "输出格式：wav（默认）或pcm"
+// @Success      200          {file}    binary  "降噪后的音频"
+// @Failure      400          {object}  map[string]interface{}  "请求参数错误"
+// @Router       /audio/enhance [post]
+func (h *AudioHandler) Enhance(c *gin.Context) {
+	get := func(key string) string {
+		return c.DefaultPostForm(key, c.Query(key))
+	}
+	sampleRate := 16000
+	if v := get("sample_rate"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 8000 || n > 48000 {
+			invalidOptions(c, fmt.Errorf("invalid sample_rate: %s, must be in [8000, 48000]", v))
+			return
+		}
+		sampleRate = n
+	}
+	format := get("format")
+	if format == "" {
+		format = "wav"
+	}
+	if format != "wav" && format != "pcm" {
+		invalidOptions(c, fmt.Errorf("invalid format: %s, must be wav or pcm", format))
+		return
+	}
+
+	samples, err := readAudioFiles(c, sampleRate)
+	if err == nil && len(samples) != 1 {
+		err = fmt.Errorf("exactly one audio file is required")
+	}
+	if err != nil {
+		invalidOptions(c, err)
+		return
+	}
+
+	enhanced, latency, err := runEnhancer(c.Request.Context(), h.enhancer, samples[0], sampleRate)
+	if err != nil {
+		enhancementFailed(c, err)
+		return
+	}
+	c.Header("X-Enhance-Latency-Ms", strconv.FormatFloat(latency, 'f', 3, 64))
+	if format == "pcm" {
+		c.Data(http.StatusOK, "application/octet-stream", enhanced)
+		return
+	}
+	c.Data(http.StatusOK, "audio/wav", utils.EncodeWAV(enhanced, sampleRate, 1))
+}
+
+// parseAudioTagOptions 解析标注参数top_k和window
+func parseAudioTagOptions(get func(string) string) (*config.AudioTagOptions, error) {
+	opts := &config.AudioTagOptions{}
+	if v := get("top_k"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > config.MaxAudioTagTopK {
+			return nil, fmt.Errorf("invalid top_k: %s, must be in [1, %d]", v, config.MaxAudioTagTopK)
+		}
+		opts.TopK = n
+	}
+	if v := get("window"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < config.MinAudioTagWindow {
+			return nil, fmt.Errorf("invalid window: %s, must be at least %g seconds", v, config.MinAudioTagWindow)
+		}
+		opts.Window = f
+	}
+	return opts, nil
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// fakeTagger 返回固定标签，按窗口参数返回单个窗口
+func fakeTagger(ctx context.Context, audio []byte, opts *config.AudioTagOptions) (*config.AudioTagResult, error) {
+	if len(audio) < 2 {
+		return nil, errors.New("invalid or empty audio data")
+	}
+	result := &config.AudioTagResult{
+		Duration: float64(len(audio)/2) / 16000,
+		Labels:   []config.AudioLabel{{Name: "Music", Index: 137, Score: 0.8}},
+	}
+	if opts.Window > 0 {
+		result.Windows = []config.AudioTagWindow{{Start: 0, End: result.Duration, Labels: result.Labels}}
+	}
+	return result, nil
+}
+
+func TestAudioHandler_Tag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/audio/tag", NewAudioHandler(fakeTagger, nil).Tag)
+
+	upload := func(files [][]byte, fields map[string]string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, f := range files {
+			part, _ := writer.CreateFormFile("audio", "call.wav")
+			part.Write(f)
+		}
+		for k, v := range fields {
+			writer.WriteField(k, v)
+		}
+		writer.Close()
+		req := httptest.NewRequest("POST", "/audio/tag", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	audio := make([]byte, 32000)
+	w := upload([][]byte{audio}, map[string]string{"top_k": "3", "window": "1"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data config.AudioTagResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Data.Duration != 1 || len(resp.Data.Labels) != 1 || resp.Data.Labels[0].Name != "Music" || len(resp.Data.Windows) != 1 {
+		t.Errorf("Unexpected result: %+v", resp.Data)
+	}
+
+	tests := []struct {
+		name   string
+		files  [][]byte
+		fields map[string]string
+		status int
+	}{
+		{"missing audio", nil, nil, http.StatusBadRequest},
+		{"multiple files", [][]byte{audio, audio}, nil, http.StatusBadRequest},
+		{"invalid top_k", [][]byte{audio}, map[string]string{"top_k": "0"}, http.StatusBadRequest},
+		{"window too short", [][]byte{audio}, map[string]string{"window": "0.1"}, http.StatusBadRequest},
+		{"too many windows", [][]byte{make([]byte, 32000*(config.MaxAudioTagWindows+1))}, map[string]string{"window": "1"}, http.StatusBadRequest},
+		{"tagging failure", [][]byte{{1}}, nil, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := upload(tt.files, tt.fields); w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// DiarizationOptions 说话人分离参数
+type DiarizationOptions struct {
+	SampleRate  int     // 输入PCM16音频的采样率
+	NumSpeakers int     // 说话人数，0为按阈值自动聚类
+	Threshold   float32 // 聚类阈值，0使用配置值
+}
+
+// SpeakerTurn 说话人分离得到的一段语音，时间单位为秒
+type SpeakerTurn struct {
+	Speaker int
+	Start   float64
+	End     float64
+}
+
+// STTDiarizer 对整段音频做说话人分离
+type STTDiarizer func(ctx context.Context, audio []byte, opts DiarizationOptions) ([]SpeakerTurn, error)
+
+// RecognizeSegment 带说话人标签的识别片段
+type RecognizeSegment struct {
+	Speaker string  `json:"speaker"` // speaker_0..N
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Text    string  `json:"text"`
+}
+
+// SetDiarizer 设置说话人分离函数，为nil时不支持diarize参数
+func (h *STTHandler) SetDiarizer(diarizer STTDiarizer) {
+	h.diarizer = diarizer
+}
+
+// parseDiarizationOptions 解析diarize、num_speakers和cluster_threshold参数，未请求分离时返回nil
+func parseDiarizationOptions(get func(string) string) (*DiarizationOptions, error) {
+	value := strings.TrimSpace(get("diarize"))
+	if value == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid diarize: %s", value)
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	opts := &DiarizationOptions{}
+	if v := strings.TrimSpace(get("num_speakers")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid num_speakers: %s", v)
+		}
+		opts.NumSpeakers = n
+	}
+	if v := strings.TrimSpace(get("cluster_threshold")); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil || f <= 0 {
+			return nil, fmt.Errorf("invalid cluster_threshold: %s", v)
+		}
+		opts.Threshold = float32(f)
+	}
+	return opts, nil
+}
+
+// recognizeTurns 逐段识别说话人分离结果，返回片段和拼接后的全文
+func recognizeTurns(manager STTManager, audio []byte, turns []SpeakerTurn, sampleRate int, opts *config.ASROptions) ([]RecognizeSegment, string, error) {
+	segments := make([]RecognizeSegment, 0, len(turns))
+	texts := make([]string, 0, len(turns))
+	for _, turn := range turns {
+		chunk := sliceSeconds(audio, sampleRate, turn.Start, turn.End)
+		if len(chunk) == 0 {
+			continue
+		}
+		result, err := manager.Recognize(nil, chunk, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		segments = append(segments, RecognizeSegment{
+			Speaker: fmt.Sprintf("speaker_%d", turn.Speaker),
+			Start:   turn.Start,
+			End:     turn.End,
+			Text:    result.Text,
+		})
+		if result.Text != "" {
+			texts = append(texts, result.Text)
+		}
+	}
+	return segments, strings.Join(texts, " "), nil
+}
+
+// sliceSeconds 按时间截取PCM16单声道音频
+func sliceSeconds(audio []byte, sampleRate int, start, end float64) []byte {
+	from := int(start*float64(sampleRate)) * 2
+	to := int(end*float64(sampleRate)) * 2
+	if from < 0 {
+		from = 0
+	}
+	if to > len(audio) {
+		to = len(audio)
+	}
+	if from >= to {
+		return nil
+	}
+	return audio[from:to]
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+func newDiarizeRequest(t *testing.T, audio []byte, fields map[string]string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("audio", "meeting.pcm")
+	part.Write(audio)
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/recognize", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestSTTHandler_RecognizeDiarized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeResult: "你好"}
+	handler := NewSTTHandler(manager, &config.STTConfig{})
+
+	var got DiarizationOptions
+	handler.SetDiarizer(func(ctx context.Context, audio []byte, opts DiarizationOptions) ([]SpeakerTurn, error) {
+		got = opts
+		return []SpeakerTurn{
+			{Speaker: 0, Start: 0, End: 0.5},
+			{Speaker: 1, Start: 0.5, End: 1.0},
+			{Speaker: 0, Start: 2.0, End: 3.0}, // 超出音频范围，跳过
+		}, nil
+	})
+	router := gin.New()
+	router.POST("/recognize", handler.Recognize)
+
+	audio := make([]byte, 32000) // 1秒16kHz PCM16
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newDiarizeRequest(t, audio, map[string]string{
+		"diarize":      "true",
+		"num_speakers": "2",
+	}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data RecognizeResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if got.NumSpeakers != 2 || got.SampleRate != 16000 {
+		t.Errorf("Unexpected diarization options: %+v", got)
+	}
+	if len(resp.Data.Segments) != 2 {
+		t.Fatalf("Expected 2 segments, got %+v", resp.Data.Segments)
+	}
+	if resp.Data.Segments[0].Speaker != "speaker_0" || resp.Data.Segments[1].Speaker != "speaker_1" {
+		t.Errorf("Unexpected speakers: %+v", resp.Data.Segments)
+	}
+	if resp.Data.Segments[1].Start != 0.5 || resp.Data.Segments[1].Text != "你好" {
+		t.Errorf("Unexpected segment: %+v", resp.Data.Segments[1])
+	}
+	if resp.Data.Text != "你好 你好" {
+		t.Errorf("Expected joined text, got %q", resp.Data.Text)
+	}
+}
+
+func TestSTTHandler_RecognizeDiarizeErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		diarizer STTDiarizer
+		fields   map[string]string
+		status   int
+	}{
+		{"not enabled", nil, map[string]string{"diarize": "true"}, http.StatusBadRequest},
+		{"invalid num_speakers", func(context.Context, []byte, DiarizationOptions) ([]SpeakerTurn, error) {
+			return nil, nil
+		}, map[string]string{"diarize": "true", "num_speakers": "-1"}, http.StatusBadRequest},
+		{"invalid threshold", func(context.Context, []byte, DiarizationOptions) ([]SpeakerTurn, error) {
+			return nil, nil
+		}, map[string]string{"diarize": "true", "cluster_threshold": "0"}, http.StatusBadRequest},
+		{"diarizer failure", func(context.Context, []byte, DiarizationOptions) ([]SpeakerTurn, error) {
+			return nil, errors.New("boom")
+		}, map[string]string{"diarize": "true"}, http.StatusInternalServerError},
+		{"disabled ignores diarizer", nil, map[string]string{"diarize": "false"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSTTHandler(&mockSTTManager{transcribeResult: "ok"}, &config.STTConfig{})
+			handler.SetDiarizer(tt.diarizer)
+			router := gin.New()
+			router.POST("/recognize", handler.Recognize)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newDiarizeRequest(t, make([]byte, 3200), tt.fields))
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
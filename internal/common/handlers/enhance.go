@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// AudioEnhancer 对PCM16单声道音频降噪，返回相同采样率的PCM16音频
+type AudioEnhancer func(ctx context.Context, audio []byte, sampleRate int) ([]byte, error)
+
+// SetEnhancer 设置识别前的语音增强，byDefault为请求未指定enhance时是否降噪
+func (h *STTHandler) SetEnhancer(enhance AudioEnhancer, byDefault bool) {
+	h.enhancer = enhance
+	h.enhanceByDefault = byDefault
+}
+
+// runEnhancer 对音频（可带WAV文件头）降噪，返回PCM16音频和耗时（毫秒）
+func runEnhancer(ctx context.Context, enhance AudioEnhancer, audio []byte, sampleRate int) ([]byte, float64, error) {
+	start := time.Now()
+	enhanced, err := enhance(ctx, utils.StripWAVHeader(audio), sampleRate)
+	if err != nil {
+		return nil, 0, err
+	}
+	return enhanced, float64(time.Since(start).Microseconds()) / 1000, nil
+}
+
+// enhancementFailed 返回降噪失败
+func enhancementFailed(c *gin.Context, err error) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": "speech enhancement failed",
+		"error": gin.H{
+			"type":    "ENHANCEMENT_ERROR",
+			"details": err.Error(),
+		},
+	})
+}
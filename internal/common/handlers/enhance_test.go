@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// fakeEnhance 把每个采样点的高字节清零模拟降噪，单字节音频返回错误
+func fakeEnhance(ctx context.Context, audio []byte, sampleRate int) ([]byte, error) {
+	if len(audio) < 2 {
+		return nil, errors.New("invalid or empty audio data")
+	}
+	out := make([]byte, len(audio))
+	for i := 0; i < len(audio); i += 2 {
+		out[i] = audio[i]
+	}
+	return out, nil
+}
+
+// postAudio 以multipart上传单个音频和表单字段
+func postAudio(router *gin.Engine, path string, audio []byte, fields map[string]string) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if audio != nil {
+		part, _ := writer.CreateFormFile("audio", "noisy.wav")
+		part.Write(audio)
+	}
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	writer.Close()
+	req := httptest.NewRequest("POST", path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSTTHandler_RecognizeEnhance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeResult: "检查左侧发动机"}
+	handler := NewSTTHandler(manager, &config.STTConfig{Audio: config.AudioConfig{SampleRate: 16000}})
+	router := gin.New()
+	router.POST("/recognize", handler.Recognize)
+
+	audio := []byte{1, 2, 3, 4}
+
+	// 未启用降噪时请求enhance返回400
+	if w := postAudio(router, "/recognize", audio, map[string]string{"enhance": "true"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without enhancer, got %d", w.Code)
+	}
+
+	handler.SetEnhancer(fakeEnhance, false)
+	decode := func(w *httptest.ResponseRecorder) RecognizeResponse {
+		t.Helper()
+		var resp struct {
+			Data RecognizeResponse `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resp.Data
+	}
+
+	w := postAudio(router, "/recognize", audio, nil)
+	if w.Code != http.StatusOK || !bytes.Equal(manager.lastAudio, audio) || bytes.Contains(w.Body.Bytes(), []byte("enhance_latency_ms")) {
+		t.Errorf("Expected original audio without enhancement, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = postAudio(router, "/recognize", audio, map[string]string{"enhance": "true"})
+	if w.Code != http.StatusOK || !bytes.Equal(manager.lastAudio, []byte{1, 0, 3, 0}) {
+		t.Errorf("Expected enhanced audio to be recognized, got %d: %v", w.Code, manager.lastAudio)
+	}
+	if resp := decode(w); resp.Text != "检查左侧发动机" || resp.EnhanceLatency == nil {
+		t.Errorf("Expected enhance latency in response, got %s", w.Body.String())
+	}
+
+	// 默认降噪，请求可关闭
+	handler.SetEnhancer(fakeEnhance, true)
+	postAudio(router, "/recognize", audio, nil)
+	if !bytes.Equal(manager.lastAudio, []byte{1, 0, 3, 0}) {
+		t.Errorf("Expected enhancement by default, got %v", manager.lastAudio)
+	}
+	postAudio(router, "/recognize", audio, map[string]string{"enhance": "false"})
+	if !bytes.Equal(manager.lastAudio, audio) {
+		t.Errorf("Expected enhancement to be disabled by request, got %v", manager.lastAudio)
+	}
+
+	if w := postAudio(router, "/recognize", audio, map[string]string{"enhance": "maybe"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid enhance, got %d", w.Code)
+	}
+	if w := postAudio(router, "/recognize", []byte{1}, nil); w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 for enhancement failure, got %d", w.Code)
+	}
+}
+
+func TestAudioHandler_Enhance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/audio/enhance", NewAudioHandler(nil, fakeEnhance).Enhance)
+
+	audio := utils.EncodeWAV([]byte{1, 2, 3, 4}, 8000, 1)
+	w := postAudio(router, "/audio/enhance", audio, map[string]string{"sample_rate": "8000"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if want := utils.EncodeWAV([]byte{1, 0, 3, 0}, 8000, 1); !bytes.Equal(w.Body.Bytes(), want) {
+		t.Errorf("Unexpected WAV output: %v", w.Body.Bytes())
+	}
+	if w.Header().Get("Content-Type") != "audio/wav" || w.Header().Get("X-Enhance-Latency-Ms") == "" {
+		t.Errorf("Unexpected headers: %v", w.Header())
+	}
+
+	w = postAudio(router, "/audio/enhance", audio, map[string]string{"sample_rate": "8000", "format": "pcm"})
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), []byte{1, 0, 3, 0}) {
+		t.Errorf("Expected raw PCM output, got %d: %v", w.Code, w.Body.Bytes())
+	}
+
+	// WAV重采样到sample_rate（默认16000）
+	w = postAudio(router, "/audio/enhance", audio, map[string]string{"format": "pcm"})
+	if w.Code != http.StatusOK || w.Body.Len() != 8 {
+		t.Errorf("Expected 8kHz WAV to be resampled to 16kHz, got %d: %v", w.Code, w.Body.Bytes())
+	}
+
+	float := utils.EncodeWAV([]byte{1, 2, 3, 4}, 16000, 1)
+	float[20], float[34] = 3, 32 // 32位浮点
+
+	tests := []struct {
+		name   string
+		audio  []byte
+		fields map[string]string
+		status int
+	}{
+		{"missing audio", nil, nil, http.StatusBadRequest},
+		{"invalid sample rate", audio, map[string]string{"sample_rate": "100"}, http.StatusBadRequest},
+		{"invalid format", audio, map[string]string{"format": "mp3"}, http.StatusBadRequest},
+		{"unsupported wav", float, nil, http.StatusBadRequest},
+		{"enhancement failure", []byte{1}, nil, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := postAudio(router, "/audio/enhance", tt.audio, tt.fields); w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
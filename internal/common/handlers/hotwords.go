@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/hotwords"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// HotwordResolver 将命名热词集展开为热词列表
+type HotwordResolver func(names []string) ([]string, error)
+
+// HotwordsHandler 命名热词集管理处理器
+type HotwordsHandler struct {
+	store *hotwords.Store
+}
+
+// NewHotwordsHandler 创建热词集处理器
+func NewHotwordsHandler(store *hotwords.Store) *HotwordsHandler {
+	return &HotwordsHandler{store: store}
+}
+
+// PutHotwordsRequest 创建或替换热词集请求
+type PutHotwordsRequest struct {
+	Words []string `json:"words" binding:"required"`
+}
+
+// List 列出热词集
+// @Summary      获取热词集列表
+// @Description  获取所有命名热词集，识别请求可通过hotword_sets参数引用
+// @Tags         STT
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "热词集列表"
+// @Router       /hotwords [get]
+func (h *HotwordsHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    h.store.List(),
+	})
+}
+
+// Get 获取热词集
+// @Summary      获取热词集
+// @Tags         STT
+// @Produce      json
+// @Param        name  path      string  true  "热词集名称"
+// @Success      200   {object}  map[string]interface{}  "热词集"
+// @Failure      404   {object}  map[string]interface{}  "热词集不存在"
+// @Router       /hotwords/{name} [get]
+func (h *HotwordsHandler) Get(c *gin.Context) {
+	set, err := h.store.Get(c.Param("name"))
+	if err != nil {
+		hotwordSetNotFound(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    set,
+	})
+}
+
+// Put 创建或替换热词集
+// @Summary      创建或替换热词集
+// @Description  保存热词集到磁盘，立即对后续识别请求生效；热词不能包含 "/" 或换行
+// @Tags         STT
+// @Accept       json
+// @Produce      json
+// @Param        name     path      string              true  "热词集名称（字母、数字、_、-）"
+// @Param        request  body      PutHotwordsRequest  true  "热词列表"
+// @Success      200      {object}  map[string]interface{}  "保存成功"
+// @Failure      400      {object}  map[string]interface{}  "请求参数错误"
+// @Failure      500      {object}  map[string]interface{}  "保存失败"
+// @Router       /hotwords/{name} [put]
+func (h *HotwordsHandler) Put(c *gin.Context) {
+	var req PutHotwordsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidOptions(c, err)
+		return
+	}
+	set, err := h.store.Put(c.Param("name"), req.Words)
+	if err != nil {
+		if errors.Is(err, hotwords.ErrInvalidSet) {
+			invalidOptions(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "failed to save hotword set",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInternalError),
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    set,
+	})
+}
+
+// Delete 删除热词集
+// @Summary      删除热词集
+// @Tags         STT
+// @Produce      json
+// @Param        name  path      string  true  "热词集名称"
+// @Success      200   {object}  map[string]interface{}  "删除成功"
+// @Failure      404   {object}  map[string]interface{}  "热词集不存在"
+// @Router       /hotwords/{name} [delete]
+func (h *HotwordsHandler) Delete(c *gin.Context) {
+	if err := h.store.Delete(c.Param("name")); err != nil {
+		if errors.Is(err, hotwords.ErrSetNotFound) {
+			hotwordSetNotFound(c, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "failed to delete hotword set",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInternalError),
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+	})
+}
+
+// hotwordSetNotFound 返回热词集不存在错误
+func hotwordSetNotFound(c *gin.Context, err error) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"code":    404,
+		"message": "hotword set not found",
+		"error": gin.H{
+			"type":    string(utils.ErrCodeNotFound),
+			"details": err.Error(),
+		},
+	})
+}
+
+// expandHotwordSets 将请求引用的热词集展开到opts.SetHotwords（热词集的识别器可按需构建）
+func expandHotwordSets(resolve HotwordResolver, opts *config.ASROptions) error {
+	if len(opts.HotwordSets) == 0 {
+		return nil
+	}
+	if resolve == nil {
+		return fmt.Errorf("hotword sets are not enabled")
+	}
+	words, err := resolve(opts.HotwordSets)
+	if err != nil {
+		return err
+	}
+	opts.SetHotwords = words
+	opts.HotwordSets = nil
+	return nil
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/hotwords"
+)
+
+func TestHotwordsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := hotwords.NewStore(t.TempDir(), 100)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	handler := NewHotwordsHandler(store)
+	router := gin.New()
+	router.GET("/hotwords", handler.List)
+	router.GET("/hotwords/:name", handler.Get)
+	router.PUT("/hotwords/:name", handler.Put)
+	router.DELETE("/hotwords/:name", handler.Delete)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("PUT", "/hotwords/products", `{"words": ["AeroSpeech", "sherpa onnx"]}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := do("GET", "/hotwords/products", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data hotwords.Set `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Data.Name != "products" || len(resp.Data.Words) != 2 {
+		t.Errorf("Unexpected hotword set: %+v", resp.Data)
+	}
+
+	if w := do("GET", "/hotwords", ""); w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"products"`)) {
+		t.Errorf("Expected products in list, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// 参数错误
+	for _, body := range []string{`{}`, `{"words": []}`, `{"words": ["a/b"]}`} {
+		if w := do("PUT", "/hotwords/products", body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, w.Code)
+		}
+	}
+	if w := do("PUT", "/hotwords/bad.name", `{"words": ["a"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid name, got %d", w.Code)
+	}
+
+	if w := do("DELETE", "/hotwords/products", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w := do("GET", "/hotwords/products", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if w := do("DELETE", "/hotwords/products", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
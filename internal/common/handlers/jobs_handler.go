@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/jobs"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/middleware"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// JobsHandler 异步任务API处理器
+type JobsHandler struct {
+	manager   *jobs.Manager
+	config    *config.JobsConfig
+	sttModels STTModelLookup // 校验识别任务指定的模型，为nil时只支持默认模型
+	ttsModels TTSModelLookup // 校验合成任务指定的模型，为nil时只支持默认模型
+}
+
+// NewJobsHandler 创建异步任务处理器
+func NewJobsHandler(manager *jobs.Manager, cfg *config.JobsConfig) *JobsHandler {
+	return &JobsHandler{
+		manager: manager,
+		config:  cfg,
+	}
+}
+
+// SetSTTModelLookup 设置按名称查找识别模型的函数，用于提交时校验model参数
+func (h *JobsHandler) SetSTTModelLookup(lookup STTModelLookup) {
+	h.sttModels = lookup
+}
+
+// SetTTSModelLookup 设置按名称查找合成模型的函数，用于提交时校验model参数
+func (h *JobsHandler) SetTTSModelLookup(lookup TTSModelLookup) {
+	h.ttsModels = lookup
+}
+
+// SubmitSTT 提交异步识别任务
+// @Summary      提交异步识别任务
+// @Description  上传音频文件创建识别任务，立即返回任务ID；可选提供webhook_url在任务结束时回调
+// @Tags         Jobs
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        audio        formData  file    true   "音频文件"
+// @Param        model        formData  string  false  "模型名称，未指定时使用默认模型"
+// @Param        webhook_url  formData  string  false  "任务结束回调地址"
+// @Success      202  {object}  map[string]interface{}  "任务已创建"
+// @Failure      400  {object}  map[string]interface{}  "请求参数错误"
+// @Failure      404  {object}  map[string]interface{}  "模型不存在"
+// @Failure      503  {object}  map[string]interface{}  "任务队列已满"
+// @Router       /jobs/stt [post]
+func (h *JobsHandler) SubmitSTT(c *gin.Context) {
+	if h.config.MaxFileSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.config.MaxFileSize+1024*1024)
+	}
+
+	file, err := c.FormFile("audio")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			jobError(c, http.StatusRequestEntityTooLarge, "file too large", utils.ErrCodeInvalidParams, "audio file exceeds the maximum allowed size")
+			return
+		}
+		jobError(c, http.StatusBadRequest, "invalid request", utils.ErrCodeInvalidParams, "audio file is required")
+		return
+	}
+	if h.config.MaxFileSize > 0 && file.Size > h.config.MaxFileSize {
+		jobError(c, http.StatusRequestEntityTooLarge, "file too large", utils.ErrCodeInvalidParams, "audio file exceeds the maximum allowed size")
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		jobError(c, http.StatusInternalServerError, "failed to open file", "FILE_ERROR", err.Error())
+		return
+	}
+	defer src.Close()
+
+	audioData, err := io.ReadAll(src)
+	if err != nil {
+		jobError(c, http.StatusInternalServerError, "failed to read file", "FILE_ERROR", err.Error())
+		return
+	}
+
+	job := &jobs.Job{
+		Type:      jobs.TypeSTT,
+		TenantID:  middleware.GetTenantID(c),
+		InputName: file.Filename,
+	}
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		job.Owner = principal.ID
+	}
+	if model := c.DefaultPostForm("model", c.Query("model")); model != "" {
+		if h.sttModels == nil {
+			modelNotFound(c, fmt.Errorf("model not found: %s", model))
+			return
+		}
+		if _, err := h.sttModels(model); err != nil {
+			modelNotFound(c, err)
+			return
+		}
+		job.Params = map[string]string{jobs.ParamModel: model}
+	}
+	if webhookURL := c.PostForm("webhook_url"); webhookURL != "" {
+		if err := h.manager.ValidateWebhookURL(webhookURL); err != nil {
+			jobError(c, http.StatusBadRequest, "invalid request", utils.ErrCodeInvalidParams, err.Error())
+			return
+		}
+		job.Webhook = &jobs.WebhookState{URL: webhookURL}
+	}
+
+	h.submit(c, job, audioData)
+}
+
+// TTSJobRequest 异步合成任务请求
+type TTSJobRequest struct {
+	jobs.TTSRequest
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
+
+// SubmitTTS 提交异步合成任务
+// @Summary      提交异步合成任务
+// @Description  提交长文本合成任务，文本按段落并行合成后拼接为一个音频文件，完成后通过 /jobs/{id}/audio 下载
+// @Tags         Jobs
+// @Accept       json
+// @Produce      json
+// @Param        request  body      TTSJobRequest  true  "合成任务请求"
+// @Success      202  {object}  map[string]interface{}  "任务已创建"
+// @Failure      400  {object}  map[string]interface{}  "请求参数错误"
+// @Failure      404  {object}  map[string]interface{}  "模型不存在"
+// @Failure      503  {object}  map[string]interface{}  "任务队列已满"
+// @Router       /jobs/tts [post]
+func (h *JobsHandler) SubmitTTS(c *gin.Context) {
+	var req TTSJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		jobError(c, http.StatusBadRequest, "invalid request", utils.ErrCodeInvalidParams, err.Error())
+		return
+	}
+	if err := req.TTSRequest.Normalize(&h.config.TTS); err != nil {
+		jobError(c, http.StatusBadRequest, "invalid request", utils.ErrCodeInvalidParams, err.Error())
+		return
+	}
+	if req.Model != "" {
+		if h.ttsModels == nil {
+			modelNotFound(c, fmt.Errorf("model not found: %s", req.Model))
+			return
+		}
+		if _, err := h.ttsModels(req.Model); err != nil {
+			modelNotFound(c, err)
+			return
+		}
+	}
+
+	input, err := json.Marshal(&req.TTSRequest)
+	if err != nil {
+		jobError(c, http.StatusInternalServerError, "failed to create job", utils.ErrCodeInternalError, err.Error())
+		return
+	}
+
+	job := &jobs.Job{
+		Type:     jobs.TypeTTS,
+		TenantID: middleware.GetTenantID(c),
+		Params:   map[string]string{"format": req.Format},
+	}
+	if req.Model != "" {
+		job.Params[jobs.ParamModel] = req.Model
+	}
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		job.Owner = principal.ID
+	}
+	if req.WebhookURL != "" {
+		if err := h.manager.ValidateWebhookURL(req.WebhookURL); err != nil {
+			jobError(c, http.StatusBadRequest, "invalid request", utils.ErrCodeInvalidParams, err.Error())
+			return
+		}
+		job.Webhook = &jobs.WebhookState{URL: req.WebhookURL}
+	}
+
+	h.submit(c, job, input)
+}
+
+// submit 提交任务并返回202
+func (h *JobsHandler) submit(c *gin.Context, job *jobs.Job, input []byte) {
+	created, err := h.manager.Submit(job, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrQueueFull), errors.Is(err, jobs.ErrManagerClosed):
+			jobError(c, http.StatusServiceUnavailable, "job queue unavailable", utils.ErrCodeResourceExhausted, err.Error())
+		default:
+			jobError(c, http.StatusInternalServerError, "failed to create job", utils.ErrCodeInternalError, err.Error())
+		}
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+created.ID)
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":    202,
+		"message": "accepted",
+		"data":    created,
+	})
+}
+
+// GetJob 查询任务状态、进度和结果
+// @Summary      查询任务
+// @Description  获取异步任务的状态、进度和结果
+// @Tags         Jobs
+// @Produce      json
+// @Param        id   path      string  true  "任务ID"
+// @Success      200  {object}  map[string]interface{}  "任务信息"
+// @Failure      404  {object}  map[string]interface{}  "任务不存在"
+// @Router       /jobs/{id} [get]
+func (h *JobsHandler) GetJob(c *gin.Context) {
+	job, ok := h.lookup(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    job,
+	})
+}
+
+// CancelJob 取消任务
+// @Summary      取消任务
+// @Description  取消排队中或执行中的异步任务
+// @Tags         Jobs
+// @Produce      json
+// @Param        id   path      string  true  "任务ID"
+// @Success      200  {object}  map[string]interface{}  "已取消或已请求取消"
+// @Failure      404  {object}  map[string]interface{}  "任务不存在"
+// @Failure      409  {object}  map[string]interface{}  "任务已结束"
+// @Router       /jobs/{id}/cancel [post]
+func (h *JobsHandler) CancelJob(c *gin.Context) {
+	if _, ok := h.lookup(c); !ok {
+		return
+	}
+
+	job, err := h.manager.Cancel(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobFinished) {
+			jobError(c, http.StatusConflict, "job has already finished", utils.ErrCodeInvalidParams, string(job.Status))
+			return
+		}
+		jobError(c, http.StatusNotFound, "job not found", utils.ErrCodeNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    job,
+	})
+}
+
+// GetJobAudio 下载合成任务的音频
+// @Summary      下载任务音频
+// @Description  下载已完成合成任务的音频文件，支持Range请求
+// @Tags         Jobs
+// @Produce      audio/wav
+// @Param        id   path      string  true  "任务ID"
+// @Success      200  {file}    binary  "音频文件"
+// @Success      206  {file}    binary  "部分内容"
+// @Failure      404  {object}  map[string]interface{}  "任务或音频不存在"
+// @Failure      409  {object}  map[string]interface{}  "任务尚未完成"
+// @Router       /jobs/{id}/audio [get]
+func (h *JobsHandler) GetJobAudio(c *gin.Context) {
+	job, ok := h.lookup(c)
+	if !ok {
+		return
+	}
+	if job.Status != jobs.StatusSucceeded {
+		jobError(c, http.StatusConflict, "job is not completed", utils.ErrCodeInvalidParams, string(job.Status))
+		return
+	}
+
+	file, info, err := h.manager.OpenOutput(job.ID)
+	if err != nil {
+		jobError(c, http.StatusNotFound, "audio not found", utils.ErrCodeNotFound, "job has no audio output")
+		return
+	}
+	defer file.Close()
+
+	modTime := time.Time{}
+	if job.FinishedAt != nil {
+		modTime = *job.FinishedAt
+	}
+	c.Header("Content-Type", info.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Filename))
+	http.ServeContent(c.Writer, c.Request, info.Filename, modTime, file)
+}
+
+// GetStats 获取任务统计信息
+func (h *JobsHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    h.manager.GetStats(),
+	})
+}
+
+// lookup 查找任务并检查访问权限，不可访问的任务一律返回404
+func (h *JobsHandler) lookup(c *gin.Context) (*jobs.Job, bool) {
+	job, err := h.manager.Get(c.Param("id"))
+	if err != nil || !canAccessJob(c, job) {
+		jobError(c, http.StatusNotFound, "job not found", utils.ErrCodeNotFound, "job not found")
+		return nil, false
+	}
+	return job, true
+}
+
+// canAccessJob 任务创建者、同租户主体和管理员可以访问任务
+func canAccessJob(c *gin.Context, job *jobs.Job) bool {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		// 未启用认证时创建的任务没有所有者
+		return job.Owner == "" && job.TenantID == ""
+	}
+	if principal.HasScope(middleware.ScopeAdmin) {
+		return true
+	}
+	if job.TenantID != "" {
+		return job.TenantID == principal.TenantID
+	}
+	return job.Owner == principal.ID
+}
+
+// jobError 返回统一格式的错误响应
+func jobError(c *gin.Context, status int, message string, errType utils.ErrorCode, details string) {
+	c.JSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"error": gin.H{
+			"type":    string(errType),
+			"details": details,
+		},
+	})
+}
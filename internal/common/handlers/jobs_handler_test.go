@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/jobs"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/middleware"
+)
+
+func newTestJobsRouter(t *testing.T, principal *middleware.Principal) (*gin.Engine, *jobs.Manager) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := jobs.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	cfg := &config.JobsConfig{Workers: 1, QueueSize: 10}
+	config.SetJobsDefaults(cfg)
+
+	manager, err := jobs.NewManager(cfg, store)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	english := &mockSTTManager{transcribeResult: "hello"}
+	models := func(name string) (jobs.Transcriber, error) {
+		if name == "whisper-en" {
+			return english, nil
+		}
+		return nil, fmt.Errorf("model not found: %s", name)
+	}
+	manager.RegisterProcessor(jobs.TypeSTT, jobs.NewSTTProcessor(&mockSTTManager{transcribeResult: "测试文本"}, models, 16000, 30))
+	kokoro := &mockTTSManager{synthesizeResult: make([]byte, 2400)}
+	synthesizers := func(name string) (jobs.Synthesizer, error) {
+		if name == "kokoro-en" {
+			return kokoro, nil
+		}
+		return nil, fmt.Errorf("model not found: %s", name)
+	}
+	manager.RegisterProcessor(jobs.TypeTTS, jobs.NewTTSProcessor(&mockTTSManager{synthesizeResult: make([]byte, 4800)}, synthesizers, &cfg.TTS))
+	manager.Start()
+	t.Cleanup(func() { manager.Close() })
+
+	handler := NewJobsHandler(manager, cfg)
+	handler.SetSTTModelLookup(func(name string) (STTManager, error) {
+		if name == "whisper-en" {
+			return english, nil
+		}
+		return nil, fmt.Errorf("model not found: %s", name)
+	})
+	handler.SetTTSModelLookup(func(name string) (TTSManager, error) {
+		if name == "kokoro-en" {
+			return kokoro, nil
+		}
+		return nil, fmt.Errorf("model not found: %s", name)
+	})
+	router := gin.New()
+	if principal != nil {
+		router.Use(func(c *gin.Context) {
+			c.Set(middleware.ContextKeyPrincipal, principal)
+			if principal.TenantID != "" {
+				c.Set(middleware.ContextKeyTenantID, principal.TenantID)
+			}
+		})
+	}
+	router.POST("/jobs/stt", handler.SubmitSTT)
+	router.POST("/jobs/tts", handler.SubmitTTS)
+	router.GET("/jobs/:id", handler.GetJob)
+	router.GET("/jobs/:id/audio", handler.GetJobAudio)
+	router.POST("/jobs/:id/cancel", handler.CancelJob)
+	return router, manager
+}
+
+func newJobUpload(t *testing.T, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("audio", "long.wav")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(make([]byte, 3200))
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func decodeJob(t *testing.T, w *httptest.ResponseRecorder) *jobs.Job {
+	t.Helper()
+	var resp struct {
+		Data jobs.Job `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return &resp.Data
+}
+
+func TestJobsHandler_SubmitAndPoll(t *testing.T) {
+	router, _ := newTestJobsRouter(t, nil)
+
+	body, contentType := newJobUpload(t, nil)
+	req := httptest.NewRequest("POST", "/jobs/stt", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	job := decodeJob(t, w)
+	if w.Header().Get("Location") != "/api/v1/jobs/"+job.ID {
+		t.Errorf("Unexpected Location header: %s", w.Header().Get("Location"))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		req = httptest.NewRequest("GET", "/jobs/"+job.ID, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if polled := decodeJob(t, w); polled.Status == jobs.StatusSucceeded {
+			var result jobs.STTResult
+			json.Unmarshal(polled.Result, &result)
+			if result.Text != "测试文本" {
+				t.Errorf("Unexpected result: %s", polled.Result)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for job to finish")
+}
+
+func TestJobsHandler_SubmitSTTWithModel(t *testing.T) {
+	router, manager := newTestJobsRouter(t, nil)
+
+	body, contentType := newJobUpload(t, map[string]string{"model": "whisper-en"})
+	req := httptest.NewRequest("POST", "/jobs/stt", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	job := waitForJob(t, manager, decodeJob(t, w).ID)
+	var result jobs.STTResult
+	json.Unmarshal(job.Result, &result)
+	if job.Status != jobs.StatusSucceeded || result.Text != "hello" {
+		t.Errorf("Expected result from whisper-en, got %s: %s", job.Status, job.Result)
+	}
+
+	// 未知模型在提交时返回404
+	body, contentType = newJobUpload(t, map[string]string{"model": "missing"})
+	req = httptest.NewRequest("POST", "/jobs/stt", body)
+	req.Header.Set("Content-Type", contentType)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+	}
+}
+
+// waitForJob 等待任务结束
+func waitForJob(t *testing.T, manager *jobs.Manager, id string) *jobs.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, _ := manager.Get(id); job.Status.IsFinal() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for job to finish")
+	return nil
+}
+
+func TestJobsHandler_TTSJobAudioDownload(t *testing.T) {
+	router, manager := newTestJobsRouter(t, nil)
+
+	req := httptest.NewRequest("POST", "/jobs/tts", bytes.NewBufferString(`{"text": "第一段\n第二段", "paragraph_silence_ms": 0}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	job := waitForJob(t, manager, decodeJob(t, w).ID)
+	if job.Status != jobs.StatusSucceeded {
+		t.Fatalf("Expected job to succeed, got %s (%s)", job.Status, job.Error)
+	}
+
+	// 完整下载：44字节WAV头 + 两段各4800字节
+	req = httptest.NewRequest("GET", "/jobs/"+job.ID+"/audio", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.Len() != 44+9600 {
+		t.Fatalf("Expected full download, got status %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if w.Header().Get("Content-Type") != "audio/wav" {
+		t.Errorf("Unexpected Content-Type: %s", w.Header().Get("Content-Type"))
+	}
+
+	// Range请求
+	req = httptest.NewRequest("GET", "/jobs/"+job.ID+"/audio", nil)
+	req.Header.Set("Range", "bytes=0-43")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.Len() != 44 {
+		t.Errorf("Expected partial content of 44 bytes, got status %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if string(w.Body.Bytes()[0:4]) != "RIFF" {
+		t.Error("Expected WAV header in first range")
+	}
+}
+
+func TestJobsHandler_SubmitTTSWithModel(t *testing.T) {
+	router, manager := newTestJobsRouter(t, nil)
+
+	req := httptest.NewRequest("POST", "/jobs/tts", bytes.NewBufferString(`{"text": "hello", "model": "kokoro-en"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	job := waitForJob(t, manager, decodeJob(t, w).ID)
+	if job.Status != jobs.StatusSucceeded || job.Params[jobs.ParamModel] != "kokoro-en" {
+		t.Fatalf("Expected job to succeed with kokoro-en, got %s (%s), params %v", job.Status, job.Error, job.Params)
+	}
+	if job.Output == nil || job.Output.Size != 44+2400 {
+		t.Errorf("Expected output synthesized by kokoro-en, got %+v", job.Output)
+	}
+
+	// 未知模型在提交时返回404
+	req = httptest.NewRequest("POST", "/jobs/tts", bytes.NewBufferString(`{"text": "hello", "model": "missing"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+	}
+}
+
+func TestJobsHandler_TTSInvalidRequest(t *testing.T) {
+	router, _ := newTestJobsRouter(t, nil)
+
+	for _, body := range []string{`{}`, `{"text": "你好", "format": "mp3"}`, `not json`} {
+		req := httptest.NewRequest("POST", "/jobs/tts", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Body %s: expected status %d, got %d", body, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestJobsHandler_AudioForSTTJob(t *testing.T) {
+	router, manager := newTestJobsRouter(t, nil)
+
+	job, err := manager.Submit(&jobs.Job{Type: jobs.TypeSTT}, make([]byte, 3200))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitForJob(t, manager, job.ID)
+
+	req := httptest.NewRequest("GET", "/jobs/"+job.ID+"/audio", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestJobsHandler_MissingAudio(t *testing.T) {
+	router, _ := newTestJobsRouter(t, nil)
+
+	req := httptest.NewRequest("POST", "/jobs/stt", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestJobsHandler_InvalidWebhook(t *testing.T) {
+	router, _ := newTestJobsRouter(t, nil)
+
+	body, contentType := newJobUpload(t, map[string]string{"webhook_url": "ftp://example.com/cb"})
+	req := httptest.NewRequest("POST", "/jobs/stt", body)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestJobsHandler_NotFound(t *testing.T) {
+	router, _ := newTestJobsRouter(t, nil)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/jobs/missing", nil),
+		httptest.NewRequest("POST", "/jobs/missing/cancel", nil),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected status %d, got %d", req.Method, req.URL.Path, http.StatusNotFound, w.Code)
+		}
+	}
+}
+
+func TestJobsHandler_CancelFinished(t *testing.T) {
+	router, manager := newTestJobsRouter(t, nil)
+
+	job, err := manager.Submit(&jobs.Job{Type: jobs.TypeSTT}, make([]byte, 3200))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if j, _ := manager.Get(job.ID); j.Status.IsFinal() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	req := httptest.NewRequest("POST", "/jobs/"+job.ID+"/cancel", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestJobsHandler_OwnerIsolation(t *testing.T) {
+	router, manager := newTestJobsRouter(t, &middleware.Principal{ID: "client-b", Scopes: []string{middleware.ScopeSTT}})
+
+	job, err := manager.Submit(&jobs.Job{Type: jobs.TypeSTT, Owner: "client-a"}, make([]byte, 3200))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/jobs/"+job.ID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected other owner's job to be hidden, got status %d", w.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// LanguageDetector 检测PCM16单声道音频（16kHz）的语种
+type LanguageDetector func(ctx context.Context, audio []byte) (*config.LanguageDetection, error)
+
+// SetLanguageDetector 设置语种检测函数
+func (h *STTHandler) SetLanguageDetector(detect LanguageDetector) {
+	h.detector = detect
+}
+
+// DetectLanguage 语种检测
+// @Summary      语种检测
+// @Description  使用Whisper语种识别模型检测音频的语种。sherpa-onnx不输出概率，confidence为整段音频和各时间窗口的识别结果中与language一致的比例
+// @Tags         STT
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        audio  formData  file  true  "音频文件（16kHz单声道PCM16或WAV）"
+// @Success      200    {object}  map[string]interface{}  "检测结果"
+// @Failure      400    {object}  map[string]interface{}  "请求参数错误"
+// @Failure      500    {object}  map[string]interface{}  "检测失败"
+// @Router       /stt/language [post]
+func (h *STTHandler) DetectLanguage(c *gin.Context) {
+	if h.detector == nil {
+		invalidOptions(c, fmt.Errorf("language identification is not enabled"))
+		return
+	}
+	samples, err := readAudioFiles(c, audioSampleRate)
+	if err == nil && len(samples) != 1 {
+		err = fmt.Errorf("exactly one audio file is required")
+	}
+	if err == nil && len(samples[0]) < 2 {
+		err = fmt.Errorf("audio data is empty")
+	}
+	if err != nil {
+		invalidOptions(c, err)
+		return
+	}
+
+	detection, err := h.detector(c.Request.Context(), samples[0])
+	if err != nil {
+		languageDetectionFailed(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    detection,
+	})
+}
+
+// languageDetectionFailed 返回语种检测失败
+func languageDetectionFailed(c *gin.Context, err error) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": "language identification failed",
+		"error": gin.H{
+			"type":    "LANGUAGE_ID_ERROR",
+			"details": err.Error(),
+		},
+	})
+}
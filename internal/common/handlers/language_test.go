@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// fakeDetector 全部判定为英语，奇数字节的音频返回错误
+func fakeDetector(ctx context.Context, audio []byte) (*config.LanguageDetection, error) {
+	if len(audio)%2 != 0 {
+		return nil, errors.New("invalid or empty audio data")
+	}
+	return &config.LanguageDetection{
+		Language:   "en",
+		Confidence: 0.75,
+		Votes:      4,
+		Windows:    []config.LanguageWindow{{Start: 0, End: 2, Language: "en"}},
+	}, nil
+}
+
+func TestSTTHandler_DetectLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewSTTHandler(&mockSTTManager{}, &config.STTConfig{Audio: config.AudioConfig{SampleRate: 16000}})
+	router := gin.New()
+	router.POST("/language", handler.DetectLanguage)
+
+	audio := []byte{1, 2, 3, 4}
+	if w := postAudio(router, "/language", audio, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without detector, got %d", w.Code)
+	}
+
+	handler.SetLanguageDetector(fakeDetector)
+	w := postAudio(router, "/language", audio, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data config.LanguageDetection `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Data.Language != "en" || resp.Data.Confidence != 0.75 || resp.Data.Votes != 4 || len(resp.Data.Windows) != 1 {
+		t.Errorf("Unexpected detection: %+v", resp.Data)
+	}
+
+	// 缺少音频
+	if w := postAudio(router, "/language", nil, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without audio, got %d", w.Code)
+	}
+
+	// 检测失败
+	w = postAudio(router, "/language", []byte{1, 2, 3}, nil)
+	if w.Code != http.StatusInternalServerError || !json.Valid(w.Body.Bytes()) {
+		t.Errorf("Expected status 500 on detection error, got %d", w.Code)
+	}
+}
+
+func TestSTTHandler_RecognizeDetectLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeResult: "check the left engine"}
+	handler := NewSTTHandler(manager, &config.STTConfig{Audio: config.AudioConfig{SampleRate: 16000}})
+	router := gin.New()
+	router.POST("/recognize", handler.Recognize)
+
+	audio := []byte{1, 2, 3, 4}
+
+	// 未启用语种识别时请求detect_language返回400
+	if w := postAudio(router, "/recognize", audio, map[string]string{"detect_language": "true"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without detector, got %d", w.Code)
+	}
+
+	handler.SetLanguageDetector(fakeDetector)
+	decode := func(body []byte) RecognizeResponse {
+		t.Helper()
+		var resp struct {
+			Data RecognizeResponse `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resp.Data
+	}
+
+	// 默认不检测
+	w := postAudio(router, "/recognize", audio, nil)
+	if w.Code != http.StatusOK || decode(w.Body.Bytes()).Detected != nil {
+		t.Errorf("Expected no detection by default, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// 未声明语言时按检测结果识别
+	w = postAudio(router, "/recognize", audio, map[string]string{"detect_language": "true"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	data := decode(w.Body.Bytes())
+	if data.Detected == nil || data.Detected.Language != "en" || data.Language != "en" || manager.lastOptions.Language != "en" {
+		t.Errorf("Expected detected language en, got %+v", data)
+	}
+
+	// 声明的语言优先于检测结果
+	w = postAudio(router, "/recognize", audio, map[string]string{"detect_language": "true", "language": "zh"})
+	data = decode(w.Body.Bytes())
+	if w.Code != http.StatusOK || data.Detected == nil || data.Language != "zh" {
+		t.Errorf("Expected declared language zh with detection, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := postAudio(router, "/recognize", audio, map[string]string{"detect_language": "maybe"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid detect_language, got %d", w.Code)
+	}
+
+	if w := postAudio(router, "/recognize", []byte{1, 2, 3}, map[string]string{"detect_language": "true"}); w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 on detection error, got %d", w.Code)
+	}
+}
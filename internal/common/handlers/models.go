@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// STTModelSwapper STT模型热切换接口（由asr.Manager实现）
+type STTModelSwapper interface {
+	GetModelConfig() *config.ASRConfig
+	Reload(cfg *config.ASRConfig) error
+}
+
+// TTSModelSwapper TTS模型热切换接口（由tts.Manager实现）
+type TTSModelSwapper interface {
+	GetModelConfig() *config.TTSModelConfig
+	Reload(cfg *config.TTSModelConfig) error
+}
+
+// STTModelLookup 按名称查找识别模型，名称为空时返回默认模型
+type STTModelLookup func(name string) (STTManager, error)
+
+// TTSModelLookup 按名称查找合成模型，名称为空时返回默认模型
+type TTSModelLookup func(name string) (TTSManager, error)
+
+// ModelCatalog 返回已加载模型的信息列表
+type ModelCatalog func() interface{}
+
+// ModelsHandler 模型列表处理器
+// @Summary      获取模型列表
+// @Description  获取已加载的识别和合成模型，包括类型、语言、采样率和资源池状态
+// @Tags         系统
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "模型列表"
+// @Router       /models [get]
+func ModelsHandler(stt, tts ModelCatalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data := gin.H{
+			"stt": []interface{}{},
+			"tts": []interface{}{},
+		}
+		if stt != nil {
+			data["stt"] = stt()
+		}
+		if tts != nil {
+			data["tts"] = tts()
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "success",
+			"data":    data,
+		})
+	}
+}
+
+// modelNotFound 返回模型不存在错误
+func modelNotFound(c *gin.Context, err error) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"code":    404,
+		"message": "model not found",
+		"error": gin.H{
+			"type":    string(utils.ErrCodeNotFound),
+			"details": err.Error(),
+		},
+	})
+}
+
+// STTModelReloadHandler STT模型热切换处理器
+// @Summary      热切换STT模型
+// @Description  加载并预热新模型后无缝切换，在途请求在旧模型上完成；请求体为可选的stt配置（覆盖当前配置中的字段），为空时按当前配置重新加载
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Param        model    query     string  false  "模型名称，默认为默认模型"
+// @Param        request  body      object  false  "stt模型配置"
+// @Success      200      {object}  map[string]interface{}  "切换成功"
+// @Failure      400      {object}  map[string]interface{}  "请求参数错误"
+// @Failure      404      {object}  map[string]interface{}  "模型不存在"
+// @Failure      500      {object}  map[string]interface{}  "加载失败，保留当前模型"
+// @Router       /models/stt/reload [post]
+func STTModelReloadHandler(lookup func(name string) (STTModelSwapper, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		swapper, err := lookup(c.Query("model"))
+		if err != nil {
+			modelNotFound(c, err)
+			return
+		}
+
+		cfg := swapper.GetModelConfig()
+		name := cfg.Name
+		if !bindModelConfig(c, cfg) {
+			return
+		}
+		cfg.Name = name // 模型名称不可修改
+		respondModelReload(c, cfg, swapper.Reload(cfg))
+	}
+}
+
+// TTSModelReloadHandler TTS模型热切换处理器
+// @Summary      热切换TTS模型
+// @Description  加载并预热新模型后无缝切换，在途请求在旧模型上完成；请求体为可选的tts配置（覆盖当前配置中的字段），为空时按当前配置重新加载
+// @Tags         系统
+// @Accept       json
+// @Produce      json
+// @Param        model    query     string  false  "模型名称，默认为默认模型"
+// @Param        request  body      object  false  "tts模型配置"
+// @Success      200      {object}  map[string]interface{}  "切换成功"
+// @Failure      400      {object}  map[string]interface{}  "请求参数错误"
+// @Failure      404      {object}  map[string]interface{}  "模型不存在"
+// @Failure      500      {object}  map[string]interface{}  "加载失败，保留当前模型"
+// @Router       /models/tts/reload [post]
+func TTSModelReloadHandler(lookup func(name string) (TTSModelSwapper, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		swapper, err := lookup(c.Query("model"))
+		if err != nil {
+			modelNotFound(c, err)
+			return
+		}
+
+		cfg := swapper.GetModelConfig()
+		name := cfg.Name
+		if !bindModelConfig(c, cfg) {
+			return
+		}
+		cfg.Name = name // 模型名称不可修改
+		respondModelReload(c, cfg, swapper.Reload(cfg))
+	}
+}
+
+// bindModelConfig 将可选的请求体合并到当前模型配置上
+func bindModelConfig(c *gin.Context, cfg interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	if err == nil && len(body) > 0 {
+		err = json.Unmarshal(body, cfg)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "invalid request",
+			"error": gin.H{
+				"type":    "INVALID_PARAMS",
+				"details": err.Error(),
+			},
+		})
+		return false
+	}
+	return true
+}
+
+// respondModelReload 返回模型热切换结果
+func respondModelReload(c *gin.Context, cfg interface{}, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "model reload failed, current model kept",
+			"error": gin.H{
+				"type":    "MODEL_RELOAD_FAILED",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    cfg,
+	})
+}
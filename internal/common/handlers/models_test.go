@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// mockSTTSwapper 模拟STT模型热切换
+type mockSTTSwapper struct {
+	current  config.ASRConfig
+	reloaded *config.ASRConfig
+	err      error
+}
+
+func (m *mockSTTSwapper) GetModelConfig() *config.ASRConfig {
+	cfg := m.current
+	return &cfg
+}
+
+func (m *mockSTTSwapper) Reload(cfg *config.ASRConfig) error {
+	m.reloaded = cfg
+	if m.err != nil {
+		return m.err
+	}
+	m.current = *cfg
+	return nil
+}
+
+func TestSTTModelReloadHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	swapper := &mockSTTSwapper{current: config.ASRConfig{ModelPath: "old.onnx", TokensPath: "tokens.txt"}}
+
+	router := gin.New()
+	router.POST("/models/stt/reload", STTModelReloadHandler(func(name string) (STTModelSwapper, error) {
+		if name != "" && name != "default" {
+			return nil, errors.New("model not found: " + name)
+		}
+		return swapper, nil
+	}))
+
+	w := httptest.NewRecorder()
+	body := `{"model_path": "new.onnx"}`
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/models/stt/reload", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	// 请求体只覆盖提供的字段
+	if swapper.reloaded.ModelPath != "new.onnx" || swapper.reloaded.TokensPath != "tokens.txt" {
+		t.Errorf("Unexpected reload config: %+v", swapper.reloaded)
+	}
+
+	// 空请求体按当前配置重新加载
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/models/stt/reload", nil))
+	if w.Code != http.StatusOK || swapper.reloaded.ModelPath != "new.onnx" {
+		t.Errorf("Expected reload with current config, got %d %+v", w.Code, swapper.reloaded)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/models/stt/reload", strings.NewReader("{")))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid body, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/models/stt/reload?model=whisper-en", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown model, got %d", w.Code)
+	}
+}
+
+func TestTTSModelReloadHandler_Failure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	swapper := &mockTTSSwapper{err: errors.New("warmup failed")}
+
+	router := gin.New()
+	router.POST("/models/tts/reload", TTSModelReloadHandler(func(name string) (TTSModelSwapper, error) {
+		return swapper, nil
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/models/tts/reload", strings.NewReader(`{"model_path": "bad.onnx"}`)))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", w.Code)
+	}
+
+	var resp struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Error.Type != "MODEL_RELOAD_FAILED" {
+		t.Errorf("Expected MODEL_RELOAD_FAILED, got %q", resp.Error.Type)
+	}
+}
+
+// mockTTSSwapper 模拟TTS模型热切换
+type mockTTSSwapper struct {
+	current config.TTSModelConfig
+	err     error
+}
+
+func (m *mockTTSSwapper) GetModelConfig() *config.TTSModelConfig {
+	cfg := m.current
+	return &cfg
+}
+
+func (m *mockTTSSwapper) Reload(cfg *config.TTSModelConfig) error {
+	return m.err
+}
+
+func TestModelsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/models", ModelsHandler(func() interface{} {
+		return []gin.H{{"name": "sensevoice-zh", "default": true}}
+	}, nil))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/models", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var resp struct {
+		Data struct {
+			STT []map[string]interface{} `json:"stt"`
+			TTS []map[string]interface{} `json:"tts"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Data.STT) != 1 || resp.Data.STT[0]["name"] != "sensevoice-zh" {
+		t.Errorf("Unexpected stt models: %v", resp.Data.STT)
+	}
+	if resp.Data.TTS == nil || len(resp.Data.TTS) != 0 {
+		t.Errorf("Expected empty tts list, got %v", resp.Data.TTS)
+	}
+}
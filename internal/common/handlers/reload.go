@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config/hotreload"
+)
+
+// ConfigReloader 配置重载接口（由hotreload.HotReloadManager实现）
+type ConfigReloader interface {
+	Reload() *hotreload.ReloadResult
+	LastResult() *hotreload.ReloadResult
+}
+
+// ReloadStatusHandler 最近一次配置重载结果处理器
+// @Summary      获取配置重载结果
+// @Description  获取最近一次配置热加载的结果，包括已生效和需要重启的配置项
+// @Tags         系统
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "重载结果，尚未重载时data为null"
+// @Router       /config/reload [get]
+func ReloadStatusHandler(reloader ConfigReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "success",
+			"data":    reloader.LastResult(),
+		})
+	}
+}
+
+// ReloadHandler 立即重载配置处理器
+// @Summary      重载配置
+// @Description  立即重新读取配置文件并应用可热加载的变更
+// @Tags         系统
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "重载结果"
+// @Failure      500  {object}  map[string]interface{}  "读取或解析配置失败"
+// @Router       /config/reload [post]
+func ReloadHandler(reloader ConfigReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result := reloader.Reload()
+		if result.Error != "" {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    500,
+				"message": "config reload failed",
+				"data":    result,
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "success",
+			"data":    result,
+		})
+	}
+}
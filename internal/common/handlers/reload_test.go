@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config/hotreload"
+)
+
+// mockReloader 模拟配置重载
+type mockReloader struct {
+	result *hotreload.ReloadResult
+	last   *hotreload.ReloadResult
+}
+
+func (m *mockReloader) Reload() *hotreload.ReloadResult {
+	m.last = m.result
+	return m.result
+}
+
+func (m *mockReloader) LastResult() *hotreload.ReloadResult {
+	return m.last
+}
+
+func TestReloadHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reloader := &mockReloader{result: &hotreload.ReloadResult{
+		Applied:         []string{"logging.level"},
+		RestartRequired: []string{"server.port"},
+	}}
+
+	router := gin.New()
+	router.GET("/config/reload", ReloadStatusHandler(reloader))
+	router.POST("/config/reload", ReloadHandler(reloader))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/config/reload", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp struct {
+		Data *hotreload.ReloadResult `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Data != nil {
+		t.Errorf("Expected no result before reload, got %+v", resp.Data)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/config/reload", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/config/reload", nil))
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Data == nil || len(resp.Data.RestartRequired) != 1 || resp.Data.RestartRequired[0] != "server.port" {
+		t.Errorf("Unexpected last result: %+v", resp.Data)
+	}
+}
+
+func TestReloadHandler_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reloader := &mockReloader{result: &hotreload.ReloadResult{Error: "config validation failed"}}
+
+	router := gin.New()
+	router.POST("/config/reload", ReloadHandler(reloader))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/config/reload", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/speakers"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// maxEnrollSamples 单次注册最多上传的样本数
+const maxEnrollSamples = 20
+
+// SpeakerEmbedder 提取PCM16音频的说话人向量
+type SpeakerEmbedder func(ctx context.Context, audio []byte) ([]float32, error)
+
+// SpeakersHandler 说话人注册、验证和辨认处理器
+type SpeakersHandler struct {
+	store     *speakers.Store
+	embed     SpeakerEmbedder
+	threshold float32 // 默认余弦相似度阈值
+}
+
+// NewSpeakersHandler 创建说话人处理器
+func NewSpeakersHandler(store *speakers.Store, embed SpeakerEmbedder, threshold float32) *SpeakersHandler {
+	return &SpeakersHandler{store: store, embed: embed, threshold: threshold}
+}
+
+// UpdateSpeakerRequest 修改说话人请求
+type UpdateSpeakerRequest struct {
+	Name string `json:"name"`
+}
+
+// SpeakerMatchResponse 验证/辨认结果
+type SpeakerMatchResponse struct {
+	ID        string  `json:"id,omitempty"`
+	Name      string  `json:"name,omitempty"`
+	Score     float32 `json:"score"`     // 余弦相似度
+	Threshold float32 `json:"threshold"` // 判定使用的阈值
+	Accepted  bool    `json:"accepted"`  // score >= threshold
+}
+
+// List 列出已注册的说话人
+// @Summary      获取说话人列表
+// @Tags         Speaker
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "说话人列表"
+// @Router       /speakers [get]
+func (h *SpeakersHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    h.store.List(),
+	})
+}
+
+// Get 获取说话人
+// @Summary      获取说话人
+// @Tags         Speaker
+// @Produce      json
+// @Param        id   path      string  true  "说话人ID"
+// @Success      200  {object}  map[string]interface{}  "说话人"
+// @Failure      404  {object}  map[string]interface{}  "说话人不存在"
+// @Router       /speakers/{id} [get]
+func (h *SpeakersHandler) Get(c *gin.Context) {
+	profile, err := h.store.Get(c.Param("id"))
+	if err != nil {
+		h.storeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    profile,
+	})
+}
+
+// Enroll 注册说话人
+// @Summary      注册说话人
+// @Description  上传一个或多个音频样本（16kHz单声道PCM16或WAV）注册说话人；已注册时默认把新样本并入，replace=true时重新注册
+// @Tags         Speaker
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id       path      string  true   "说话人ID（字母、数字、_、-）"
+// @Param        audio    formData  file    true   "音频样本（可重复）"
+// @Param        name     formData  string  false  "显示名称"
+// @Param        replace  formData  bool    false  "是否替换已有注册"
+// @Success      200      {object}  map[string]interface{}  "注册成功"
+// @Failure      400      {object}  map[string]interface{}  "请求参数错误"
+// @Router       /speakers/{id}/enroll [post]
+func (h *SpeakersHandler) Enroll(c *gin.Context) {
+	id := c.Param("id")
+	if err := speakers.ValidateID(id); err != nil {
+		invalidOptions(c, err)
+		return
+	}
+	replace := false
+	if v := c.PostForm("replace"); v != "" {
+		var err error
+		if replace, err = strconv.ParseBool(v); err != nil {
+			invalidOptions(c, fmt.Errorf("invalid replace: %s", v))
+			return
+		}
+	}
+
+	samples, err := readAudioFiles(c, audioSampleRate)
+	if err != nil {
+		invalidOptions(c, err)
+		return
+	}
+	if len(samples) > maxEnrollSamples {
+		invalidOptions(c, fmt.Errorf("too many samples: %d, max %d", len(samples), maxEnrollSamples))
+		return
+	}
+
+	embeddings := make([][]float32, 0, len(samples))
+	for _, audio := range samples {
+		embedding, err := h.embed(c.Request.Context(), audio)
+		if err != nil {
+			embeddingFailed(c, err)
+			return
+		}
+		embeddings = append(embeddings, embedding)
+	}
+
+	profile, err := h.store.Enroll(id, strings.TrimSpace(c.PostForm("name")), embeddings, replace)
+	if err != nil {
+		h.storeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    profile,
+	})
+}
+
+// Update 修改说话人名称
+// @Summary      修改说话人
+// @Tags         Speaker
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "说话人ID"
+// @Param        request  body      UpdateSpeakerRequest  true  "说话人信息"
+// @Success      200      {object}  map[string]interface{}  "修改成功"
+// @Failure      404      {object}  map[string]interface{}  "说话人不存在"
+// @Router       /speakers/{id} [patch]
+func (h *SpeakersHandler) Update(c *gin.Context) {
+	var req UpdateSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidOptions(c, err)
+		return
+	}
+	profile, err := h.store.Rename(c.Param("id"), strings.TrimSpace(req.Name))
+	if err != nil {
+		h.storeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    profile,
+	})
+}
+
+// Delete 删除说话人
+// @Summary      删除说话人
+// @Tags         Speaker
+// @Produce      json
+// @Param        id   path      string  true  "说话人ID"
+// @Success      200  {object}  map[string]interface{}  "删除成功"
+// @Failure      404  {object}  map[string]interface{}  "说话人不存在"
+// @Router       /speakers/{id} [delete]
+func (h *SpeakersHandler) Delete(c *gin.Context) {
+	if err := h.store.Delete(c.Param("id")); err != nil {
+		h.storeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+	})
+}
+
+// Verify 验证音频是否属于指定说话人
+// @Summary      说话人验证
+// @Tags         Speaker
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id         path      string  true   "说话人ID"
+// @Param        audio      formData  file    true   "音频样本"
+// @Param        threshold  formData  number  false  "相似度阈值（0-1），默认按配置"
+// @Success      200        {object}  map[string]interface{}  "验证结果"
+// @Failure      404        {object}  map[string]interface{}  "说话人不存在"
+// @Router       /speakers/{id}/verify [post]
+func (h *SpeakersHandler) Verify(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.store.Get(id); err != nil {
+		h.storeError(c, err)
+		return
+	}
+	threshold, embedding, ok := h.readSample(c)
+	if !ok {
+		return
+	}
+	score, err := h.store.Verify(id, embedding)
+	if err != nil {
+		h.storeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data": SpeakerMatchResponse{
+			ID:        id,
+			Score:     score,
+			Threshold: threshold,
+			Accepted:  score >= threshold,
+		},
+	})
+}
+
+// Identify 辨认音频最接近的已注册说话人
+// @Summary      说话人辨认
+// @Description  返回相似度最高的已注册说话人；低于阈值时accepted为false，没有注册说话人时不返回id
+// @Tags         Speaker
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        audio      formData  file    true   "音频样本"
+// @Param        threshold  formData  number  false  "相似度阈值（0-1），默认按配置"
+// @Success      200        {object}  map[string]interface{}  "辨认结果"
+// @Router       /speakers/identify [post]
+func (h *SpeakersHandler) Identify(c *gin.Context) {
+	threshold, embedding, ok := h.readSample(c)
+	if !ok {
+		return
+	}
+	resp := SpeakerMatchResponse{Threshold: threshold}
+	if match := h.store.Identify(embedding); match != nil {
+		resp.ID = match.Profile.ID
+		resp.Name = match.Profile.Name
+		resp.Score = match.Score
+		resp.Accepted = match.Score >= threshold
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+// readSample 读取阈值和单个音频样本并提取说话人向量，失败时已写入响应
+func (h *SpeakersHandler) readSample(c *gin.Context) (float32, []float32, bool) {
+	threshold := h.threshold
+	if v := c.DefaultPostForm("threshold", c.Query("threshold")); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil || f < 0 || f > 1 {
+			invalidOptions(c, fmt.Errorf("invalid threshold: %s", v))
+			return 0, nil, false
+		}
+		threshold = float32(f)
+	}
+
+	samples, err := readAudioFiles(c, audioSampleRate)
+	if err != nil {
+		invalidOptions(c, err)
+		return 0, nil, false
+	}
+	if len(samples) != 1 {
+		invalidOptions(c, fmt.Errorf("exactly one audio sample is required"))
+		return 0, nil, false
+	}
+	embedding, err := h.embed(c.Request.Context(), samples[0])
+	if err != nil {
+		embeddingFailed(c, err)
+		return 0, nil, false
+	}
+	return threshold, embedding, true
+}
+
+// 音频上传限制（说话人注册、音频标注/增强和语种识别共用）
+const (
+	maxAudioFiles    = maxEnrollSamples // 单次请求的最大音频数
+	maxAudioFileSize = 20 * 1024 * 1024 // 单个音频的最大字节数（16kHz PCM16约10分钟）
+	audioSampleRate  = 16000            // 说话人、标注和语种识别模型的输入采样率
+)
+
+// readAudioFiles 读取multipart字段audio中的全部音频，转换为sampleRate采样率的单声道PCM16：
+// WAV文件去除文件头并按需混合声道、重采样，非16位PCM的WAV返回错误，非WAV数据视为已是该格式
+func readAudioFiles(c *gin.Context, sampleRate int) ([][]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAudioFiles*maxAudioFileSize+batchUploadOverhead)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, fmt.Errorf("request body exceeds %d bytes", maxBytesErr.Limit)
+		}
+		return nil, fmt.Errorf("audio file is required")
+	}
+	files := form.File["audio"]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("audio file is required")
+	}
+	if len(files) > maxAudioFiles {
+		return nil, fmt.Errorf("too many audio files: %d, max %d", len(files), maxAudioFiles)
+	}
+
+	samples := make([][]byte, 0, len(files))
+	for _, fh := range files {
+		if fh.Size > maxAudioFileSize {
+			return nil, fmt.Errorf("%s: %w: %d > %d bytes", fh.Filename, utils.ErrFileTooLarge, fh.Size, maxAudioFileSize)
+		}
+		src, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", fh.Filename, err)
+		}
+		data, err := io.ReadAll(src)
+		src.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", fh.Filename, err)
+		}
+		pcm, err := utils.WAVToPCM16(data, sampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+		}
+		samples = append(samples, pcm)
+	}
+	return samples, nil
+}
+
+// storeError 将说话人存储错误映射为HTTP响应
+func (h *SpeakersHandler) storeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, speakers.ErrSpeakerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": "speaker not found",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeNotFound),
+				"details": err.Error(),
+			},
+		})
+	case errors.Is(err, speakers.ErrInvalidSpeaker):
+		invalidOptions(c, err)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "speaker store error",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInternalError),
+				"details": err.Error(),
+			},
+		})
+	}
+}
+
+// embeddingFailed 返回说话人向量提取失败（如音频过短）
+func embeddingFailed(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"code":    400,
+		"message": "failed to extract speaker embedding",
+		"error": gin.H{
+			"type":    string(utils.ErrCodeAudioFormatError),
+			"details": err.Error(),
+		},
+	})
+}
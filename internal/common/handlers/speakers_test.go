@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/speakers"
+)
+
+// fakeEmbed 按音频首字节返回固定的说话人向量
+func fakeEmbed(ctx context.Context, audio []byte) ([]float32, error) {
+	switch audio[0] {
+	case 'a':
+		return []float32{1, 0.1}, nil
+	case 'b':
+		return []float32{0.1, 1}, nil
+	}
+	return nil, errors.New("audio is too short")
+}
+
+func TestSpeakersHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := speakers.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	handler := NewSpeakersHandler(store, fakeEmbed, 0.8)
+	router := gin.New()
+	router.GET("/speakers", handler.List)
+	router.POST("/speakers/identify", handler.Identify)
+	router.GET("/speakers/:id", handler.Get)
+	router.PATCH("/speakers/:id", handler.Update)
+	router.DELETE("/speakers/:id", handler.Delete)
+	router.POST("/speakers/:id/enroll", handler.Enroll)
+	router.POST("/speakers/:id/verify", handler.Verify)
+
+	upload := func(path string, samples []string, fields map[string]string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, s := range samples {
+			part, _ := writer.CreateFormFile("audio", "sample.wav")
+			part.Write([]byte(s))
+		}
+		for k, v := range fields {
+			writer.WriteField(k, v)
+		}
+		writer.Close()
+		req := httptest.NewRequest("POST", path, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	match := func(w *httptest.ResponseRecorder) SpeakerMatchResponse {
+		t.Helper()
+		var resp struct {
+			Data SpeakerMatchResponse `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return resp.Data
+	}
+
+	// 没有注册说话人时辨认不到
+	if w := upload("/speakers/identify", []string{"aaaa"}, nil); w.Code != http.StatusOK || match(w).ID != "" {
+		t.Errorf("Expected empty match, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := upload("/speakers/alice/enroll", []string{"aaaa", "aaab"}, map[string]string{"name": "Alice"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := upload("/speakers/bob/enroll", []string{"bbbb"}, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := upload("/speakers/alice/verify", []string{"aaaa"}, nil)
+	if m := match(w); w.Code != http.StatusOK || !m.Accepted || m.Score < 0.99 || m.Threshold != 0.8 {
+		t.Errorf("Expected alice to be verified, got %d: %s", w.Code, w.Body.String())
+	}
+	w = upload("/speakers/alice/verify", []string{"bbbb"}, nil)
+	if m := match(w); w.Code != http.StatusOK || m.Accepted {
+		t.Errorf("Expected bob's sample to be rejected for alice, got %d: %s", w.Code, w.Body.String())
+	}
+	w = upload("/speakers/alice/verify", []string{"bbbb"}, map[string]string{"threshold": "0.1"})
+	if m := match(w); !m.Accepted || m.Threshold != 0.1 {
+		t.Errorf("Expected request threshold to be used, got %s", w.Body.String())
+	}
+
+	w = upload("/speakers/identify", []string{"bbbb"}, nil)
+	if m := match(w); w.Code != http.StatusOK || m.ID != "bob" || !m.Accepted {
+		t.Errorf("Expected bob to be identified, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// 修改名称并列出
+	req := httptest.NewRequest("PATCH", "/speakers/bob", bytes.NewBufferString(`{"name": "Bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"name":"Bob"`)) {
+		t.Errorf("Expected rename, got %d: %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/speakers", nil))
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"alice"`)) || bytes.Contains(w.Body.Bytes(), []byte(`embedding`)) {
+		t.Errorf("Expected speaker list without embeddings, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/speakers/bob", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/speakers/bob", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSpeakersHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, _ := speakers.NewStore(t.TempDir())
+	handler := NewSpeakersHandler(store, fakeEmbed, 0.5)
+	router := gin.New()
+	router.POST("/speakers/:id/enroll", handler.Enroll)
+	router.POST("/speakers/:id/verify", handler.Verify)
+
+	upload := func(path string, samples []string, fields map[string]string) int {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, s := range samples {
+			part, _ := writer.CreateFormFile("audio", "sample.wav")
+			part.Write([]byte(s))
+		}
+		for k, v := range fields {
+			writer.WriteField(k, v)
+		}
+		writer.Close()
+		req := httptest.NewRequest("POST", path, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		samples []string
+		fields  map[string]string
+		status  int
+	}{
+		{"invalid id", "/speakers/a.b/enroll", []string{"aaaa"}, nil, http.StatusBadRequest},
+		{"missing audio", "/speakers/alice/enroll", nil, nil, http.StatusBadRequest},
+		{"embedding failure", "/speakers/alice/enroll", []string{"x"}, nil, http.StatusBadRequest},
+		{"invalid replace", "/speakers/alice/enroll", []string{"aaaa"}, map[string]string{"replace": "maybe"}, http.StatusBadRequest},
+		{"verify unknown speaker", "/speakers/carol/verify", []string{"aaaa"}, nil, http.StatusNotFound},
+		{"too many samples", "/speakers/alice/enroll", make([]string, maxAudioFiles+1), nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := upload(tt.path, tt.samples, tt.fields); code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, code)
+			}
+		})
+	}
+
+	// 已注册说话人：阈值无效、样本数错误
+	upload("/speakers/alice/enroll", []string{"aaaa"}, nil)
+	if code := upload("/speakers/alice/verify", []string{"aaaa"}, map[string]string{"threshold": "2"}); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid threshold, got %d", code)
+	}
+	if code := upload("/speakers/alice/verify", []string{"aaaa", "aaaa"}, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for multiple samples, got %d", code)
+	}
+}
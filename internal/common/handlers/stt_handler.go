@@ -1,18 +1,28 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
 	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
 )
 
 // STTManager STT管理器接口
 type STTManager interface {
 	Transcribe(ctx interface{}, audio []byte) (string, error)
+	Recognize(ctx interface{}, audio []byte, opts *config.ASROptions) (*config.ASRResult, error)
+	ValidateOptions(opts *config.ASROptions) error
 	GetStats() interface{}
 	GetAvgLatency() interface{}
 	GetPoolUsage() float64
@@ -21,10 +31,26 @@ type STTManager interface {
 
 // STTHandler STT API处理器
 type STTHandler struct {
-	manager STTManager
-	config  *config.STTConfig
+	manager          STTManager
+	models           STTModelLookup    // 按名称选择模型，为nil时只使用manager
+	router           STTLanguageRouter // 未指定模型时按语言选择模型，为nil时使用默认模型
+	hotwords         HotwordResolver   // 展开请求引用的热词集，为nil时不支持hotword_sets
+	diarizer         STTDiarizer       // 说话人分离，为nil时不支持diarize
+	enhancer         AudioEnhancer     // 识别前降噪，为nil时不支持enhance
+	enhanceByDefault bool              // 请求未指定enhance时是否降噪
+	detector         LanguageDetector  // 语种检测，为nil时不支持detect_language
+	config           *config.STTConfig
 }
 
+// STTRoute 识别请求使用的模型和语言
+type STTRoute struct {
+	Model    string
+	Language string
+}
+
+// STTLanguageRouter 按客户端声明的语言（为空时可能对音频做语种识别）选择模型
+type STTLanguageRouter func(ctx context.Context, language string, audio []byte) (STTRoute, error)
+
 // NewSTTHandler 创建STT处理器
 func NewSTTHandler(manager STTManager, cfg *config.STTConfig) *STTHandler {
 	return &STTHandler{
@@ -33,6 +59,45 @@ func NewSTTHandler(manager STTManager, cfg *config.STTConfig) *STTHandler {
 	}
 }
 
+// SetModelLookup 设置按名称选择模型的查找函数
+func (h *STTHandler) SetModelLookup(lookup STTModelLookup) {
+	h.models = lookup
+}
+
+// SetLanguageRouter 设置按语言选择模型的路由函数
+func (h *STTHandler) SetLanguageRouter(router STTLanguageRouter) {
+	h.router = router
+}
+
+// SetHotwordResolver 设置命名热词集的展开函数
+func (h *STTHandler) SetHotwordResolver(resolve HotwordResolver) {
+	h.hotwords = resolve
+}
+
+// resolveManager 选择识别模型：显式指定的模型优先，否则按语言路由
+func (h *STTHandler) resolveManager(ctx context.Context, model, language string, audio []byte) (STTManager, STTRoute, error) {
+	route := STTRoute{Model: model, Language: language}
+	if model == "" && h.router != nil {
+		var err error
+		if route, err = h.router(ctx, language, audio); err != nil {
+			return nil, route, err
+		}
+	}
+	manager, err := h.selectManager(route.Model)
+	return manager, route, err
+}
+
+// selectManager 按名称选择模型，名称为空时使用默认模型
+func (h *STTHandler) selectManager(name string) (STTManager, error) {
+	if name == "" {
+		return h.manager, nil
+	}
+	if h.models == nil {
+		return nil, fmt.Errorf("model not found: %s", name)
+	}
+	return h.models(name)
+}
+
 // RecognizeRequest 识别请求
 type RecognizeRequest struct {
 	Audio []byte `json:"audio,omitempty"`
@@ -41,8 +106,16 @@ type RecognizeRequest struct {
 
 // RecognizeResponse 识别响应
 type RecognizeResponse struct {
-	Text      string `json:"text"`
-	Timestamp int64  `json:"timestamp"`
+	Text           string                    `json:"text"`
+	Model          string                    `json:"model,omitempty"`              // 使用的模型
+	Language       string                    `json:"language,omitempty"`           // 使用的语言（声明或自动识别）
+	Lang           string                    `json:"lang,omitempty"`               // 模型输出的语言标签（SenseVoice）
+	Emotion        string                    `json:"emotion,omitempty"`            // 情感标签（SenseVoice）
+	Event          string                    `json:"event,omitempty"`              // 音频事件标签（SenseVoice，如laughter、applause、bgm）
+	Segments       []RecognizeSegment        `json:"segments,omitempty"`           // 说话人分离片段（diarize=true时）
+	EnhanceLatency *float64                  `json:"enhance_latency_ms,omitempty"` // 识别前降噪增加的耗时（enhance=true时）
+	Detected       *config.LanguageDetection `json:"detected_language,omitempty"`  // 语种检测结果（detect_language=true时）
+	Timestamp      int64                     `json:"timestamp"`
 }
 
 // Recognize 文件上传识别
@@ -51,12 +124,66 @@ type RecognizeResponse struct {
 // @Tags         STT
 // @Accept       multipart/form-data
 // @Produce      json
-// @Param        audio  formData  file  true  "音频文件"
-// @Success      200    {object}  map[string]interface{}  "识别成功"
-// @Failure      400    {object}  map[string]interface{}  "请求参数错误"
-// @Failure      500    {object}  map[string]interface{}  "服务器错误"
+// @Param        audio             formData  file    true   "音频文件"
+// @Param        model             formData  string  false  "模型名称，未指定时按语言路由"
+// @Param        language          formData  string  false  "音频语言（如zh、en），为空或auto时自动识别"
+// @Param        itn               formData  bool    false  "逆文本正则化（SenseVoice），默认按模型配置"
+// @Param        decoding_method   formData  string  false  "解码方法：greedy_search或modified_beam_search（仅transducer）"
+// @Param        max_active_paths  formData  int     false  "modified_beam_search的路径数（1-64）"
+// @Param        hotwords          formData  string  false  "热词，逗号分隔（仅transducer，须与已构建的热词集一致）"
+// @Param        hotword_sets      formData  string  false  "命名热词集，逗号分隔（仅transducer）"
+// @Param        hotwords_score    formData  number  false  "热词加分（0.1-10，默认使用模型配置）"
+// @Param        punctuate         formData  bool    false  "标点恢复（需要模型配置标点模型），默认按模型配置"
+// @Param        keep_tags         formData  bool    false  "在文本开头保留SenseVoice的语言/情感/事件标签，默认去除"
+// @Param        diarize           formData  bool    false  "说话人分离，按说话人分段识别"
+// @Param        num_speakers      formData  int     false  "说话人数（diarize时），0或不填按阈值自动聚类"
+// @Param        cluster_threshold formData  number  false  "聚类阈值（diarize时），越小说话人越多，默认按配置"
+// @Param        enhance           formData  bool    false  "识别前降噪（需要启用enhancement），默认按配置"
+// @Param        detect_language   formData  bool    false  "检测语种并在响应中返回置信度，未声明language时按检测结果路由"
+// @Success      200               {object}  map[string]interface{}  "识别成功"
+// @Failure      400               {object}  map[string]interface{}  "请求参数错误"
+// @Failure      404               {object}  map[string]interface{}  "模型不存在"
+// @Failure      500               {object}  map[string]interface{}  "服务器错误"
 // @Router       /stt/recognize [post]
 func (h *STTHandler) Recognize(c *gin.Context) {
+	model := c.DefaultPostForm("model", c.Query("model"))
+	get := func(key string) string {
+		return c.DefaultPostForm(key, c.Query(key))
+	}
+	opts, err := config.ParseASROptions(get)
+	if err == nil {
+		err = expandHotwordSets(h.hotwords, &opts)
+	}
+	var diarize *DiarizationOptions
+	if err == nil {
+		diarize, err = parseDiarizationOptions(get)
+	}
+	if err == nil && diarize != nil && h.diarizer == nil {
+		err = fmt.Errorf("speaker diarization is not enabled")
+	}
+	enhance := false
+	if err == nil {
+		enhance, err = parseFlag(get, "enhance", h.enhancer != nil && h.enhanceByDefault)
+	}
+	if err == nil && enhance && h.enhancer == nil {
+		err = fmt.Errorf("speech enhancement is not enabled")
+	}
+	detect := false
+	if err == nil {
+		detect, err = parseFlag(get, "detect_language", false)
+	}
+	if err == nil && detect && h.detector == nil {
+		err = fmt.Errorf("language identification is not enabled")
+	}
+	if err != nil {
+		invalidOptions(c, err)
+		return
+	}
+	if _, err := h.selectManager(model); err != nil {
+		modelNotFound(c, err)
+		return
+	}
+
 	// 从multipart form获取文件
 	file, err := c.FormFile("audio")
 	if err != nil {
@@ -100,8 +227,52 @@ func (h *STTHandler) Recognize(c *gin.Context) {
 		return
 	}
 
+	var enhanceLatency *float64
+	if enhance {
+		var latency float64
+		if audioData, latency, err = runEnhancer(c.Request.Context(), h.enhancer, audioData, h.sampleRate()); err != nil {
+			enhancementFailed(c, err)
+			return
+		}
+		enhanceLatency = &latency
+	}
+
+	var detected *config.LanguageDetection
+	if detect {
+		if detected, err = h.detector(c.Request.Context(), utils.StripWAVHeader(audioData)); err != nil {
+			languageDetectionFailed(c, err)
+			return
+		}
+		// 未声明语言时按检测结果路由，不再重复识别
+		if opts.Language == "" || opts.Language == "auto" {
+			opts.Language = detected.Language
+		}
+	}
+
+	manager, route, err := h.resolveManager(c.Request.Context(), model, opts.Language, audioData)
+	if err != nil {
+		modelNotFound(c, err)
+		return
+	}
+	opts.Language = route.Language
+	if err := manager.ValidateOptions(&opts); err != nil {
+		invalidOptions(c, err)
+		return
+	}
+
+	resp := RecognizeResponse{
+		Model:          route.Model,
+		Language:       route.Language,
+		EnhanceLatency: enhanceLatency,
+		Detected:       detected,
+	}
+	if diarize != nil {
+		h.recognizeDiarized(c, manager, audioData, diarize, &opts, resp)
+		return
+	}
+
 	// 执行识别
-	result, err := h.manager.Transcribe(nil, audioData)
+	result, err := manager.Recognize(nil, audioData, &opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -115,89 +286,351 @@ func (h *STTHandler) Recognize(c *gin.Context) {
 	}
 
 	// 返回结果
+	resp.Text = result.Text
+	resp.Lang = result.Lang
+	resp.Emotion = result.Emotion
+	resp.Event = result.Event
+	resp.Timestamp = time.Now().Unix()
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "success",
-		"data": RecognizeResponse{
-			Text:      result,
-			Timestamp: time.Now().Unix(),
+		"data":    resp,
+	})
+}
+
+// recognizeDiarized 先做说话人分离，再逐段识别，resp为已填好模型和预处理信息的响应
+func (h *STTHandler) recognizeDiarized(c *gin.Context, manager STTManager, audio []byte, diarize *DiarizationOptions, opts *config.ASROptions, resp RecognizeResponse) {
+	diarize.SampleRate = h.sampleRate()
+	turns, err := h.diarizer(c.Request.Context(), audio, *diarize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "diarization failed",
+			"error": gin.H{
+				"type":    "DIARIZATION_ERROR",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	segments, text, err := recognizeTurns(manager, audio, turns, diarize.SampleRate, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "recognition failed",
+			"error": gin.H{
+				"type":    "RECOGNITION_ERROR",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	resp.Text = text
+	resp.Segments = segments
+	resp.Timestamp = time.Now().Unix()
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "success",
+		"data":    resp,
+	})
+}
+
+// sampleRate 上传音频的采样率
+func (h *STTHandler) sampleRate() int {
+	if h.config.Audio.SampleRate > 0 {
+		return h.config.Audio.SampleRate
+	}
+	return 16000
+}
+
+// parseFlag 解析布尔参数，未指定时返回byDefault
+func parseFlag(get func(string) string, name string, byDefault bool) (bool, error) {
+	v := get(name)
+	if v == "" {
+		return byDefault, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %s", name, v)
+	}
+	return b, nil
+}
+
+// invalidOptions 返回解码参数错误
+func invalidOptions(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"code":    400,
+		"message": "invalid request",
+		"error": gin.H{
+			"type":    string(utils.ErrCodeInvalidParams),
+			"details": err.Error(),
 		},
 	})
 }
 
-// BatchRecognizeRequest 批量识别请求
+// BatchRecognizeRequest 批量识别请求（JSON方式，路径相对于配置的batch.input_dir）
 type BatchRecognizeRequest struct {
-	Files []string `json:"files" binding:"required"`
+	Files    []string `json:"files" binding:"required"`
+	Model    string   `json:"model,omitempty"`    // 模型名称，未指定时按语言路由
+	Language string   `json:"language,omitempty"` // 音频语言，为空时自动识别
+}
+
+// BatchItemError 批量识别单项错误
+type BatchItemError struct {
+	Type    string `json:"type"`
+	Details string `json:"details"`
+}
+
+// BatchItemResult 批量识别单项结果
+type BatchItemResult struct {
+	Index     int             `json:"index"`
+	Source    string          `json:"source"`
+	Text      string          `json:"text"`
+	Model     string          `json:"model,omitempty"`
+	Language  string          `json:"language,omitempty"`
+	Lang      string          `json:"lang,omitempty"`
+	Emotion   string          `json:"emotion,omitempty"`
+	Event     string          `json:"event,omitempty"`
+	Error     *BatchItemError `json:"error,omitempty"`
+	Timestamp int64           `json:"timestamp"`
 }
 
 // BatchRecognizeResponse 批量识别响应
 type BatchRecognizeResponse struct {
-	Results []RecognizeResponse `json:"results"`
+	Results   []BatchItemResult `json:"results"`
+	Total     int               `json:"total"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// batchItem 待识别的批量条目
+type batchItem struct {
+	source string
+	load   func() ([]byte, error)
 }
 
 // BatchRecognize 批量识别
 // @Summary      批量识别
-// @Description  批量识别多个音频文件
+// @Description  批量识别多个音频文件：multipart上传（字段files，可重复），或JSON提交batch.input_dir目录内的相对路径
 // @Tags         STT
-// @Accept       json
+// @Accept       json,multipart/form-data
 // @Produce      json
-// @Param        request  body      BatchRecognizeRequest  true  "批量识别请求"
-// @Success      200      {object}  map[string]interface{}  "识别成功"
-// @Failure      400      {object}  map[string]interface{}  "请求参数错误"
-// @Failure      500      {object}  map[string]interface{}  "服务器错误"
+// @Param        request   body      BatchRecognizeRequest  false  "批量识别请求（JSON方式）"
+// @Param        files     formData  file                   false  "音频文件（multipart方式，可重复）"
+// @Param        model     query     string                 false  "模型名称，未指定时按语言路由"
+// @Param        language  query     string                 false  "音频语言，为空或auto时逐项自动识别"
+// @Success      200       {object}  map[string]interface{}  "识别完成（逐项包含结果或错误）"
+// @Failure      400       {object}  map[string]interface{}  "请求参数错误"
+// @Failure      404       {object}  map[string]interface{}  "模型不存在"
 // @Router       /stt/batch [post]
 func (h *STTHandler) BatchRecognize(c *gin.Context) {
-	var req BatchRecognizeRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	batchCfg := h.config.Batch
+	config.SetBatchDefaults(&batchCfg)
+
+	var items []batchItem
+	var model, language string
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		items, err = h.collectUploadedItems(c, &batchCfg)
+		model, language = c.PostForm("model"), c.PostForm("language")
+	} else {
+		var req *BatchRecognizeRequest
+		items, req, err = h.collectPathItems(c, &batchCfg)
+		if req != nil {
+			model, language = req.Model, req.Language
+		}
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"code":    413,
+			"message": "request too large",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInvalidParams),
+				"details": fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit),
+			},
+		})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
 			"message": "invalid request",
 			"error": gin.H{
-				"type":    "INVALID_PARAMS",
+				"type":    string(utils.ErrCodeInvalidParams),
 				"details": err.Error(),
 			},
 		})
 		return
 	}
 
-	// 批量识别（简化实现）
-	results := make([]RecognizeResponse, 0, len(req.Files))
-	for _, filePath := range req.Files {
-		// 读取文件
-		audioData, err := utils.ReadFile(filePath)
-		if err != nil {
-			results = append(results, RecognizeResponse{
-				Text:      "",
-				Timestamp: time.Now().Unix(),
-			})
-			continue
-		}
+	if len(items) > batchCfg.MaxItems {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "invalid request",
+			"error": gin.H{
+				"type":    string(utils.ErrCodeInvalidParams),
+				"details": fmt.Sprintf("too many items: %d, max %d", len(items), batchCfg.MaxItems),
+			},
+		})
+		return
+	}
 
-		// 执行识别
-		result, err := h.manager.Transcribe(nil, audioData)
-		if err != nil {
-			results = append(results, RecognizeResponse{
-				Text:      "",
-				Timestamp: time.Now().Unix(),
-			})
-			continue
-		}
+	if model == "" {
+		model = c.Query("model")
+	}
+	if language == "" {
+		language = c.Query("language")
+	}
+	if _, err := h.selectManager(model); err != nil {
+		modelNotFound(c, err)
+		return
+	}
 
-		results = append(results, RecognizeResponse{
-			Text:      result,
-			Timestamp: time.Now().Unix(),
-		})
+	results := h.processBatch(c.Request.Context(), model, language, items, batchCfg.Concurrency)
+
+	response := BatchRecognizeResponse{
+		Results: results,
+		Total:   len(results),
+	}
+	for _, r := range results {
+		if r.Error != nil {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
 		"message": "success",
-		"data": BatchRecognizeResponse{
-			Results: results,
-		},
+		"data":    response,
 	})
 }
 
+// batchUploadOverhead 批量上传请求体中文件以外部分（multipart边界、表单字段）的余量
+const batchUploadOverhead = 1024 * 1024
+
+// collectUploadedItems 收集multipart上传的文件，请求体不超过 max_items*max_file_size 加余量
+func (h *STTHandler) collectUploadedItems(c *gin.Context, batchCfg *config.BatchConfig) ([]batchItem, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(batchCfg.MaxItems)*batchCfg.MaxFileSize+batchUploadOverhead)
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse multipart form: %w", err)
+	}
+
+	files := form.File["files"]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("at least one file is required in field 'files'")
+	}
+
+	items := make([]batchItem, 0, len(files))
+	for _, fh := range files {
+		fh := fh
+		items = append(items, batchItem{
+			source: fh.Filename,
+			load: func() ([]byte, error) {
+				if fh.Size > batchCfg.MaxFileSize {
+					return nil, fmt.Errorf("%w: %d > %d bytes", utils.ErrFileTooLarge, fh.Size, batchCfg.MaxFileSize)
+				}
+				src, err := fh.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer src.Close()
+				return io.ReadAll(src)
+			},
+		})
+	}
+	return items, nil
+}
+
+// collectPathItems 收集JSON请求中沙箱目录内的文件路径
+func (h *STTHandler) collectPathItems(c *gin.Context, batchCfg *config.BatchConfig) ([]batchItem, *BatchRecognizeRequest, error) {
+	var req BatchRecognizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, nil, err
+	}
+
+	if len(req.Files) > 0 && batchCfg.InputDir == "" {
+		return nil, nil, fmt.Errorf("server-side file paths are disabled, upload files with multipart/form-data instead")
+	}
+
+	items := make([]batchItem, 0, len(req.Files))
+	for _, name := range req.Files {
+		name := name
+		items = append(items, batchItem{
+			source: name,
+			load: func() ([]byte, error) {
+				return utils.ReadFileInDir(batchCfg.InputDir, name, batchCfg.MaxFileSize)
+			},
+		})
+	}
+	return items, &req, nil
+}
+
+// processBatch 并发识别批量条目，结果顺序与输入一致；未指定模型时逐项按语言路由
+func (h *STTHandler) processBatch(ctx context.Context, model, language string, items []batchItem, concurrency int) []BatchItemResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]BatchItemResult, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(index int, item batchItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result := BatchItemResult{Index: index, Source: item.source}
+			defer func() {
+				result.Timestamp = time.Now().Unix()
+				results[index] = result
+			}()
+
+			audioData, err := item.load()
+			if err != nil {
+				// 除大小超限外不回显原始错误，避免泄露服务器上的绝对路径
+				switch {
+				case errors.Is(err, utils.ErrPathOutsideDir):
+					result.Error = &BatchItemError{Type: string(utils.ErrCodeForbidden), Details: utils.ErrPathOutsideDir.Error()}
+				case errors.Is(err, os.ErrNotExist):
+					result.Error = &BatchItemError{Type: string(utils.ErrCodeNotFound), Details: "file not found"}
+				case errors.Is(err, utils.ErrFileTooLarge):
+					result.Error = &BatchItemError{Type: "FILE_ERROR", Details: err.Error()}
+				default:
+					logger.Errorf("Batch item %s: failed to read file: %v", item.source, err)
+					result.Error = &BatchItemError{Type: "FILE_ERROR", Details: "failed to read file"}
+				}
+				return
+			}
+
+			manager, route, err := h.resolveManager(ctx, model, language, audioData)
+			if err != nil {
+				result.Error = &BatchItemError{Type: string(utils.ErrCodeNotFound), Details: err.Error()}
+				return
+			}
+			result.Model, result.Language = route.Model, route.Language
+
+			recognized, err := manager.Recognize(ctx, audioData, &config.ASROptions{Language: route.Language})
+			if err != nil {
+				result.Error = &BatchItemError{Type: string(utils.ErrCodeRecognitionError), Details: err.Error()}
+				return
+			}
+			result.Text, result.Lang, result.Emotion, result.Event = recognized.Text, recognized.Lang, recognized.Emotion, recognized.Event
+		}(i, item)
+	}
+
+	wg.Wait()
+	return results
+}
+
 // GetConfig 获取配置
 // @Summary      获取STT配置
 // @Description  获取语音识别服务的配置信息
@@ -2,10 +2,17 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -21,6 +28,10 @@ type mockSTTManager struct {
 	avgLatency       interface{}
 	poolUsage        float64
 	poolStats        map[string]interface{}
+	validateError    error
+	lastOptions      *config.ASROptions
+	lastAudio        []byte
+	tags             config.ASRResult // Recognize返回的语言/情感/事件标签
 }
 
 func (m *mockSTTManager) Transcribe(ctx interface{}, audio []byte) (string, error) {
@@ -30,6 +41,22 @@ func (m *mockSTTManager) Transcribe(ctx interface{}, audio []byte) (string, erro
 	return m.transcribeResult, nil
 }
 
+func (m *mockSTTManager) Recognize(ctx interface{}, audio []byte, opts *config.ASROptions) (*config.ASRResult, error) {
+	m.lastOptions = opts
+	m.lastAudio = audio
+	text, err := m.Transcribe(ctx, audio)
+	if err != nil {
+		return nil, err
+	}
+	result := m.tags
+	result.Text = text
+	return &result, nil
+}
+
+func (m *mockSTTManager) ValidateOptions(opts *config.ASROptions) error {
+	return m.validateError
+}
+
 func (m *mockSTTManager) GetStats() interface{} {
 	return m.stats
 }
@@ -151,30 +178,216 @@ func TestSTTHandler_BatchRecognize(t *testing.T) {
 		transcribeResult: "测试文本",
 	}
 
-	cfg := &config.STTConfig{}
+	// 创建沙箱输入目录
+	inputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(inputDir, "test-audio.wav"), []byte("fake audio data"), 0644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	cfg := &config.STTConfig{
+		Batch: config.BatchConfig{InputDir: inputDir},
+	}
 
 	handler := NewSTTHandler(manager, cfg)
 
 	router := gin.New()
 	router.POST("/batch", handler.BatchRecognize)
 
-	// 创建临时文件
-	tmpFile, err := os.CreateTemp("", "test-audio-*.wav")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+	reqBody := `{"files": ["test-audio.wav"]}`
+	req := httptest.NewRequest("POST", "/batch", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	resp := decodeBatchResponse(t, w)
+	if resp.Succeeded != 1 || resp.Results[0].Text != "测试文本" {
+		t.Errorf("Unexpected batch response: %+v", resp)
+	}
+}
+
+// decodeBatchResponse 解析批量识别响应
+func decodeBatchResponse(t *testing.T, w *httptest.ResponseRecorder) BatchRecognizeResponse {
+	t.Helper()
+	var body struct {
+		Data BatchRecognizeResponse `json:"data"`
 	}
-	defer os.Remove(tmpFile.Name())
-	tmpFile.Write([]byte("fake audio data"))
-	tmpFile.Close()
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return body.Data
+}
+
+func TestSTTHandler_BatchRecognizeRejectsPathsWithoutInputDir(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewSTTHandler(&mockSTTManager{}, &config.STTConfig{})
 
-	reqBody := `{"files": ["` + tmpFile.Name() + `"]}`
+	router := gin.New()
+	router.POST("/batch", handler.BatchRecognize)
+
+	reqBody := `{"files": ["/etc/passwd"]}`
+	req := httptest.NewRequest("POST", "/batch", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestSTTHandler_BatchRecognizePathEscape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeResult: "测试文本"}
+	cfg := &config.STTConfig{
+		Batch: config.BatchConfig{InputDir: t.TempDir()},
+	}
+	handler := NewSTTHandler(manager, cfg)
+
+	router := gin.New()
+	router.POST("/batch", handler.BatchRecognize)
+
+	reqBody := `{"files": ["/etc/passwd", "../../etc/passwd"]}`
 	req := httptest.NewRequest("POST", "/batch", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
-		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	resp := decodeBatchResponse(t, w)
+	if resp.Failed != 2 {
+		t.Fatalf("Expected 2 failed items, got %+v", resp)
+	}
+	for _, r := range resp.Results {
+		if r.Error == nil || r.Error.Type != "FORBIDDEN" {
+			t.Errorf("Expected FORBIDDEN error for %s, got %+v", r.Source, r.Error)
+		}
+	}
+}
+
+func TestSTTHandler_BatchRecognizeMultipart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeResult: "测试文本"}
+	handler := NewSTTHandler(manager, &config.STTConfig{})
+
+	router := gin.New()
+	router.POST("/batch", handler.BatchRecognize)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, name := range []string{"a.wav", "b.wav", "c.wav"} {
+		part, err := writer.CreateFormFile("files", name)
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		part.Write([]byte("fake audio data"))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/batch", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	resp := decodeBatchResponse(t, w)
+	if resp.Total != 3 || resp.Succeeded != 3 {
+		t.Errorf("Unexpected batch response: %+v", resp)
+	}
+	for i, name := range []string{"a.wav", "b.wav", "c.wav"} {
+		if resp.Results[i].Index != i || resp.Results[i].Source != name {
+			t.Errorf("Expected result %d to be %s, got %+v", i, name, resp.Results[i])
+		}
+	}
+}
+
+func TestSTTHandler_BatchRecognizeMultipartTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeResult: "测试文本"}
+	handler := NewSTTHandler(manager, &config.STTConfig{
+		Batch: config.BatchConfig{MaxItems: 1, MaxFileSize: 1024},
+	})
+
+	router := gin.New()
+	router.POST("/batch", handler.BatchRecognize)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("files", "a.wav")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(make([]byte, 2*1024*1024))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/batch", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status code %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
+
+func TestSTTHandler_BatchRecognizeRecognitionError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeError: errors.New("decode failed")}
+	handler := NewSTTHandler(manager, &config.STTConfig{})
+
+	router := gin.New()
+	router.POST("/batch", handler.BatchRecognize)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("files", "a.wav")
+	part.Write([]byte("fake audio data"))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/batch", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	resp := decodeBatchResponse(t, w)
+	if resp.Failed != 1 || resp.Results[0].Error == nil || resp.Results[0].Error.Type != "RECOGNITION_ERROR" {
+		t.Errorf("Expected RECOGNITION_ERROR item, got %+v", resp)
+	}
+}
+
+func TestSTTHandler_BatchRecognizeTooManyItems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.STTConfig{
+		Batch: config.BatchConfig{InputDir: t.TempDir(), MaxItems: 1},
+	}
+	handler := NewSTTHandler(&mockSTTManager{}, cfg)
+
+	router := gin.New()
+	router.POST("/batch", handler.BatchRecognize)
+
+	reqBody := `{"files": ["a.wav", "b.wav"]}`
+	req := httptest.NewRequest("POST", "/batch", bytes.NewBufferString(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
 	}
 }
 
@@ -318,23 +531,58 @@ func TestSTTHandler_BatchRecognizeFileNotFound(t *testing.T) {
 	manager := &mockSTTManager{
 		transcribeResult: "测试文本",
 	}
-	cfg := &config.STTConfig{}
+	cfg := &config.STTConfig{
+		Batch: config.BatchConfig{InputDir: t.TempDir()},
+	}
 
 	handler := NewSTTHandler(manager, cfg)
 
 	router := gin.New()
 	router.POST("/batch", handler.BatchRecognize)
 
-	reqBody := `{"files": ["/nonexistent/file.wav"]}`
+	reqBody := `{"files": ["nonexistent/file.wav"]}`
 	req := httptest.NewRequest("POST", "/batch", bytes.NewBufferString(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	// 应该返回200，但结果中可能包含错误
+	// 应该返回200，但结果中包含逐项错误
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
 	}
+
+	resp := decodeBatchResponse(t, w)
+	if resp.Failed != 1 || resp.Results[0].Error == nil || resp.Results[0].Error.Type != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND item error, got %+v", resp)
+	}
+}
+
+func TestSTTHandler_BatchRecognizeReadErrorHidesPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	inputDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(inputDir, "dir.wav"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	handler := NewSTTHandler(&mockSTTManager{transcribeResult: "测试文本"}, &config.STTConfig{
+		Batch: config.BatchConfig{InputDir: inputDir},
+	})
+
+	router := gin.New()
+	router.POST("/batch", handler.BatchRecognize)
+
+	req := httptest.NewRequest("POST", "/batch", bytes.NewBufferString(`{"files": ["dir.wav"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	resp := decodeBatchResponse(t, w)
+	if resp.Failed != 1 || resp.Results[0].Error == nil || resp.Results[0].Error.Details != "failed to read file" {
+		t.Errorf("Expected generic read error, got %+v", resp)
+	}
+	if strings.Contains(w.Body.String(), inputDir) {
+		t.Errorf("Response must not contain the server path: %s", w.Body.String())
+	}
 }
 
 func TestSTTHandler_GetConfigWithGPU(t *testing.T) {
@@ -395,3 +643,223 @@ func TestSTTHandler_GetStatsWithNilStats(t *testing.T) {
 	}
 }
 
+
+func TestSTTHandler_RecognizeWithModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewSTTHandler(&mockSTTManager{transcribeResult: "你好"}, &config.STTConfig{})
+	handler.SetModelLookup(func(name string) (STTManager, error) {
+		if name == "whisper-en" {
+			return &mockSTTManager{transcribeResult: "hello"}, nil
+		}
+		return nil, fmt.Errorf("model not found: %s", name)
+	})
+
+	router := gin.New()
+	router.POST("/recognize", handler.Recognize)
+
+	recognize := func(model string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("audio", "test.wav")
+		part.Write([]byte("fake audio data"))
+		if model != "" {
+			writer.WriteField("model", model)
+		}
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/recognize", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for model, want := range map[string]string{"": "你好", "whisper-en": "hello"} {
+		w := recognize(model)
+		var resp struct {
+			Data RecognizeResponse `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code != http.StatusOK || resp.Data.Text != want {
+			t.Errorf("model %q: expected %q, got %d %q", model, want, w.Code, resp.Data.Text)
+		}
+	}
+
+	if w := recognize("missing"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown model, got %d", w.Code)
+	}
+}
+
+func TestSTTHandler_RecognizeWithLanguageRouting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	models := map[string]STTManager{
+		"sensevoice": &mockSTTManager{transcribeResult: "你好"},
+		"whisper-en": &mockSTTManager{transcribeResult: "hello"},
+	}
+	handler := NewSTTHandler(models["sensevoice"], &config.STTConfig{})
+	handler.SetModelLookup(func(name string) (STTManager, error) {
+		if m, ok := models[name]; ok {
+			return m, nil
+		}
+		return nil, fmt.Errorf("model not found: %s", name)
+	})
+	handler.SetLanguageRouter(func(ctx context.Context, language string, audio []byte) (STTRoute, error) {
+		if language == "" {
+			language = "zh" // 模拟语种识别
+		}
+		if language == "en" {
+			return STTRoute{Model: "whisper-en", Language: language}, nil
+		}
+		return STTRoute{Model: "sensevoice", Language: language}, nil
+	})
+
+	router := gin.New()
+	router.POST("/recognize", handler.Recognize)
+
+	tests := []struct {
+		model, language string
+		want            RecognizeResponse
+	}{
+		{"", "en", RecognizeResponse{Text: "hello", Model: "whisper-en", Language: "en"}},
+		{"", "", RecognizeResponse{Text: "你好", Model: "sensevoice", Language: "zh"}},
+		{"sensevoice", "en", RecognizeResponse{Text: "你好", Model: "sensevoice", Language: "en"}}, // 显式模型优先
+	}
+	for _, tt := range tests {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("audio", "test.wav")
+		part.Write([]byte("fake audio data"))
+		writer.WriteField("model", tt.model)
+		writer.WriteField("language", tt.language)
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/recognize", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Data RecognizeResponse `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		resp.Data.Timestamp = 0
+		if w.Code != http.StatusOK || !reflect.DeepEqual(resp.Data, tt.want) {
+			t.Errorf("model=%q language=%q: expected %+v, got %d %+v", tt.model, tt.language, tt.want, w.Code, resp.Data)
+		}
+	}
+}
+
+func TestSTTHandler_RecognizeWithOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{transcribeResult: "hello"}
+	handler := NewSTTHandler(manager, &config.STTConfig{})
+	router := gin.New()
+	router.POST("/recognize", handler.Recognize)
+
+	recognize := func(fields map[string]string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("audio", "test.wav")
+		part.Write([]byte("fake audio data"))
+		for k, v := range fields {
+			writer.WriteField(k, v)
+		}
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/recognize", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := recognize(map[string]string{"language": "en", "itn": "false", "decoding_method": "greedy_search", "max_active_paths": "8", "punctuate": "true"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	opts := manager.lastOptions
+	if opts == nil || opts.Language != "en" || opts.ITN == nil || *opts.ITN || opts.DecodingMethod != "greedy_search" || opts.MaxActivePaths != 8 {
+		t.Errorf("Unexpected options passed to manager: %+v", opts)
+	}
+	if opts.Punctuate == nil || !*opts.Punctuate {
+		t.Errorf("Expected punctuate=true, got %v", opts.Punctuate)
+	}
+
+	// 参数取值错误
+	if w := recognize(map[string]string{"itn": "maybe"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid itn, got %d", w.Code)
+	}
+
+	// 模型不支持的参数
+	manager.validateError = errors.New("unsupported option: modified_beam_search is only supported by transducer models")
+	if w := recognize(map[string]string{"decoding_method": "modified_beam_search"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unsupported option, got %d", w.Code)
+	}
+	manager.validateError = nil
+
+	// 热词集未启用
+	if w := recognize(map[string]string{"hotword_sets": "products"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without hotword resolver, got %d", w.Code)
+	}
+
+	// 热词集展开到SetHotwords，与直接指定的热词分开传递
+	handler.SetHotwordResolver(func(names []string) ([]string, error) {
+		if names[0] != "products" {
+			return nil, errors.New("hotword set not found: " + names[0])
+		}
+		return []string{"AeroSpeech"}, nil
+	})
+	if w := recognize(map[string]string{"hotwords": "sherpa onnx", "hotword_sets": "products"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	opts = manager.lastOptions
+	if len(opts.Hotwords) != 1 || opts.Hotwords[0] != "sherpa onnx" || len(opts.SetHotwords) != 1 || opts.SetHotwords[0] != "AeroSpeech" || len(opts.HotwordSets) != 0 {
+		t.Errorf("Unexpected hotwords passed to manager: %+v", opts)
+	}
+	if w := recognize(map[string]string{"hotword_sets": "missing"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown hotword set, got %d", w.Code)
+	}
+}
+
+func TestSTTHandler_RecognizeTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &mockSTTManager{
+		transcribeResult: "哈哈你好",
+		tags:             config.ASRResult{Lang: "zh", Emotion: "happy", Event: "laughter"},
+	}
+	handler := NewSTTHandler(manager, &config.STTConfig{})
+	router := gin.New()
+	router.POST("/recognize", handler.Recognize)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("audio", "test.wav")
+	part.Write([]byte("fake audio data"))
+	writer.WriteField("keep_tags", "true")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/recognize", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Data RecognizeResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Data.Text != "哈哈你好" || resp.Data.Lang != "zh" || resp.Data.Emotion != "happy" || resp.Data.Event != "laughter" {
+		t.Errorf("Unexpected response: %+v", resp.Data)
+	}
+	if manager.lastOptions == nil || manager.lastOptions.KeepTags == nil || !*manager.lastOptions.KeepTags {
+		t.Errorf("Expected keep_tags to be passed to manager, got %+v", manager.lastOptions)
+	}
+}
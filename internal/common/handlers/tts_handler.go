@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
@@ -20,6 +21,7 @@ type TTSManager interface {
 // TTSHandler TTS API处理器
 type TTSHandler struct {
 	manager TTSManager
+	models  TTSModelLookup // 按名称选择模型，为nil时只使用manager
 	config  *config.TTSConfig
 }
 
@@ -31,11 +33,28 @@ func NewTTSHandler(manager TTSManager, cfg *config.TTSConfig) *TTSHandler {
 	}
 }
 
+// SetModelLookup 设置按名称选择模型的查找函数
+func (h *TTSHandler) SetModelLookup(lookup TTSModelLookup) {
+	h.models = lookup
+}
+
+// selectManager 按名称选择模型，名称为空时使用默认模型
+func (h *TTSHandler) selectManager(name string) (TTSManager, error) {
+	if name == "" {
+		return h.manager, nil
+	}
+	if h.models == nil {
+		return nil, fmt.Errorf("model not found: %s", name)
+	}
+	return h.models(name)
+}
+
 // SynthesizeRequest 合成请求
 type SynthesizeRequest struct {
 	Text      string  `json:"text" binding:"required"`
 	SpeakerID int     `json:"speaker_id,omitempty"`
 	Speed    float32 `json:"speed,omitempty"`
+	Model     string  `json:"model,omitempty"` // 模型名称，默认为默认模型
 }
 
 // Synthesize 文本合成
@@ -47,6 +66,7 @@ type SynthesizeRequest struct {
 // @Param        request  body      SynthesizeRequest  true  "合成请求"
 // @Success      200      {file}    binary            "音频文件"
 // @Failure      400      {object}  map[string]interface{}  "请求参数错误"
+// @Failure      404      {object}  map[string]interface{}  "模型不存在"
 // @Failure      500      {object}  map[string]interface{}  "服务器错误"
 // @Router       /tts/synthesize [post]
 func (h *TTSHandler) Synthesize(c *gin.Context) {
@@ -67,8 +87,14 @@ func (h *TTSHandler) Synthesize(c *gin.Context) {
 		req.Speed = 1.0
 	}
 
+	manager, err := h.selectManager(req.Model)
+	if err != nil {
+		modelNotFound(c, err)
+		return
+	}
+
 	// 执行合成
-	audio, err := h.manager.Synthesize(nil, req.Text, req.SpeakerID, req.Speed)
+	audio, err := manager.Synthesize(nil, req.Text, req.SpeakerID, req.Speed)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -88,6 +114,7 @@ func (h *TTSHandler) Synthesize(c *gin.Context) {
 // BatchSynthesizeRequest 批量合成请求
 type BatchSynthesizeRequest struct {
 	Texts []SynthesizeRequest `json:"texts" binding:"required"`
+	Model string              `json:"model,omitempty"` // 未单独指定模型的条目使用的模型
 }
 
 // BatchSynthesize 批量合成
@@ -135,7 +162,20 @@ func (h *TTSHandler) BatchSynthesize(c *gin.Context) {
 			textReq.Speed = 1.0
 		}
 
-		audio, err := h.manager.Synthesize(nil, textReq.Text, textReq.SpeakerID, textReq.Speed)
+		model := textReq.Model
+		if model == "" {
+			model = req.Model
+		}
+		manager, err := h.selectManager(model)
+		if err != nil {
+			results = append(results, map[string]interface{}{
+				"text":  textReq.Text,
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		audio, err := manager.Synthesize(nil, textReq.Text, textReq.SpeakerID, textReq.Speed)
 		if err != nil {
 			results = append(results, map[string]interface{}{
 				"text":  textReq.Text,
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -44,6 +45,10 @@ func (m *mockTTSManager) GetPoolStats() map[string]interface{} {
 	return m.poolStats
 }
 
+func (m *mockTTSManager) GetSampleRate() int {
+	return 24000
+}
+
 func TestTTSHandler_Synthesize(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -456,3 +461,36 @@ func TestTTSHandler_GetStatsWithNilStats(t *testing.T) {
 	}
 }
 
+
+func TestTTSHandler_SynthesizeWithModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewTTSHandler(&mockTTSManager{synthesizeResult: []byte("default")}, &config.TTSConfig{})
+	handler.SetModelLookup(func(name string) (TTSManager, error) {
+		if name == "aishell3" {
+			return &mockTTSManager{synthesizeResult: []byte("aishell3")}, nil
+		}
+		return nil, fmt.Errorf("model not found: %s", name)
+	})
+
+	router := gin.New()
+	router.POST("/synthesize", handler.Synthesize)
+
+	synthesize := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/synthesize", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := synthesize(`{"text": "你好", "model": "aishell3"}`); w.Code != http.StatusOK || w.Body.String() != "aishell3" {
+		t.Errorf("Expected audio from aishell3, got %d %q", w.Code, w.Body.String())
+	}
+	if w := synthesize(`{"text": "你好"}`); w.Body.String() != "default" {
+		t.Errorf("Expected audio from default model, got %q", w.Body.String())
+	}
+	if w := synthesize(`{"text": "你好", "model": "missing"}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown model, got %d", w.Code)
+	}
+}
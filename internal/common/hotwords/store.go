@@ -0,0 +1,255 @@
+package hotwords
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// 热词集文件后缀
+const fileSuffix = ".txt"
+
+// 热词集错误
+var (
+	ErrSetNotFound = errors.New("hotword set not found")
+	ErrInvalidSet  = errors.New("invalid hotword set")
+)
+
+// namePattern 热词集名称只允许字母、数字、下划线和连字符（同时作为文件名）
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Set 命名热词集
+type Set struct {
+	Name      string    `json:"name"`
+	Words     []string  `json:"words"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store 命名热词集存储
+// 每个热词集对应目录下的一个 <name>.txt 文件（每行一个热词），启动时加载到内存，
+// 修改时先写临时文件再重命名，无需重启即可生效
+type Store struct {
+	dir      string
+	maxWords int
+
+	mu   sync.RWMutex
+	sets map[string]*Set
+}
+
+// NewStore 创建热词集存储并加载目录中已有的热词集（目录不存在时自动创建）
+func NewStore(dir string, maxWords int) (*Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("hotwords directory is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hotwords directory: %w", err)
+	}
+	s := &Store{dir: dir, maxWords: maxWords, sets: make(map[string]*Set)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ValidateName 检查热词集名称
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: name %q (allowed: letters, digits, '_' and '-', up to 64 characters)", ErrInvalidSet, name)
+	}
+	return nil
+}
+
+// load 加载目录中的热词集
+func (s *Store) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read hotwords directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), fileSuffix)
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSuffix) || ValidateName(name) != nil {
+			continue
+		}
+		set, err := s.readSet(name)
+		if err != nil {
+			return err
+		}
+		s.sets[name] = set
+	}
+	return nil
+}
+
+// readSet 读取热词集文件，忽略空行和以 # 开头的注释行
+func (s *Store) readSet(name string) (*Set, error) {
+	f, err := os.Open(s.path(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hotword set %s: %w", name, err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hotword set %s: %w", name, err)
+	}
+	words, err = s.normalize(words)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hotword set %s: %w", name, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &Set{Name: name, Words: words, UpdatedAt: info.ModTime()}, nil
+}
+
+// path 获取热词集文件路径
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+fileSuffix)
+}
+
+// normalize 去除空白、去重并检查热词
+func (s *Store) normalize(words []string) ([]string, error) {
+	unique := make([]string, 0, len(words))
+	seen := make(map[string]bool, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		unique = append(unique, word)
+	}
+	if err := config.ValidateHotwords(unique); err != nil {
+		return nil, err
+	}
+	if s.maxWords > 0 && len(unique) > s.maxWords {
+		return nil, fmt.Errorf("too many hotwords: %d, max %d", len(unique), s.maxWords)
+	}
+	return unique, nil
+}
+
+// List 列出所有热词集（按名称排序）
+func (s *Store) List() []*Set {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sets := make([]*Set, 0, len(s.sets))
+	for _, set := range s.sets {
+		sets = append(sets, set)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Name < sets[j].Name })
+	return sets
+}
+
+// Get 获取热词集
+func (s *Store) Get(name string) (*Set, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set, ok := s.sets[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSetNotFound, name)
+	}
+	return set, nil
+}
+
+// Put 创建或替换热词集并持久化，返回保存后的热词集
+func (s *Store) Put(name string, words []string) (*Set, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	words, err := s.normalize(words)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSet, err)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%w: at least one word is required", ErrInvalidSet)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeFileAtomic(s.path(name), []byte(strings.Join(words, "\n")+"\n")); err != nil {
+		return nil, fmt.Errorf("failed to save hotword set %s: %w", name, err)
+	}
+	set := &Set{Name: name, Words: words, UpdatedAt: time.Now()}
+	s.sets[name] = set
+	return set, nil
+}
+
+// Delete 删除热词集
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sets[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrSetNotFound, name)
+	}
+	if err := os.Remove(s.path(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete hotword set %s: %w", name, err)
+	}
+	delete(s.sets, name)
+	return nil
+}
+
+// Resolve 展开多个热词集为去重后的热词列表，任一热词集不存在时返回ErrSetNotFound
+func (s *Store) Resolve(names []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var words []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		set, ok := s.sets[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrSetNotFound, name)
+		}
+		for _, word := range set.Words {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words, nil
+}
+
+// writeFileAtomic 先写临时文件再重命名，避免写入中断留下不完整的文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package hotwords
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStore_PutGetDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, 10)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	set, err := store.Put("products", []string{" AeroSpeech ", "sherpa onnx", "AeroSpeech"})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if want := []string{"AeroSpeech", "sherpa onnx"}; !reflect.DeepEqual(set.Words, want) {
+		t.Errorf("Put() words = %v, want %v", set.Words, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "products.txt"))
+	if err != nil {
+		t.Fatalf("Expected hotword set file: %v", err)
+	}
+	if string(data) != "AeroSpeech\nsherpa onnx\n" {
+		t.Errorf("Unexpected file content: %q", data)
+	}
+
+	// 重新加载后内容一致
+	reloaded, err := NewStore(dir, 10)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	got, err := reloaded.Get("products")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Words, set.Words) {
+		t.Errorf("Reloaded words = %v, want %v", got.Words, set.Words)
+	}
+
+	if err := reloaded.Delete("products"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := reloaded.Get("products"); !errors.Is(err, ErrSetNotFound) {
+		t.Errorf("Expected ErrSetNotFound, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "products.txt")); !os.IsNotExist(err) {
+		t.Error("Expected hotword set file to be removed")
+	}
+	if err := reloaded.Delete("products"); !errors.Is(err, ErrSetNotFound) {
+		t.Errorf("Expected ErrSetNotFound, got %v", err)
+	}
+}
+
+func TestStore_Validation(t *testing.T) {
+	store, err := NewStore(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		set   string
+		words []string
+	}{
+		{name: "invalid name", set: "../etc", words: []string{"a"}},
+		{name: "empty set", set: "empty", words: nil},
+		{name: "empty word", set: "blank", words: []string{"a", " "}},
+		{name: "separator", set: "slash", words: []string{"a/b"}},
+		{name: "too many words", set: "large", words: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.Put(tt.set, tt.words); !errors.Is(err, ErrInvalidSet) {
+				t.Errorf("Expected ErrInvalidSet, got %v", err)
+			}
+		})
+	}
+	if len(store.List()) != 0 {
+		t.Error("Invalid sets must not be stored")
+	}
+}
+
+func TestStore_LoadAndResolve(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "names.txt"), []byte("# 人名\n张三\n\n李四\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "places.txt"), []byte("北京\n张三\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("ignored"), 0644)
+
+	store, err := NewStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	sets := store.List()
+	if len(sets) != 2 || sets[0].Name != "names" || sets[1].Name != "places" {
+		t.Fatalf("Unexpected sets: %+v", sets)
+	}
+
+	words, err := store.Resolve([]string{"names", "places"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if want := []string{"张三", "李四", "北京"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("Resolve() = %v, want %v", words, want)
+	}
+
+	if _, err := store.Resolve([]string{"names", "missing"}); !errors.Is(err, ErrSetNotFound) {
+		t.Errorf("Expected ErrSetNotFound, got %v", err)
+	}
+}
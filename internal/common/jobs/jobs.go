@@ -0,0 +1,759 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+)
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// IsFinal 是否为终止状态
+func (s Status) IsFinal() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+// 任务相关错误
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrQueueFull      = errors.New("job queue is full")
+	ErrJobFinished    = errors.New("job has already finished")
+	ErrUnknownJobType = errors.New("unknown job type")
+	ErrManagerClosed  = errors.New("job manager is closed")
+	ErrNoOutput       = errors.New("job has no output")
+)
+
+// WebhookState 回调投递状态
+type WebhookState struct {
+	URL           string     `json:"url"`
+	Delivered     bool       `json:"delivered"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+}
+
+// OutputInfo 任务输出文件信息
+type OutputInfo struct {
+	ContentType string `json:"content_type"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+}
+
+// Job 异步任务
+type Job struct {
+	ID             string            `json:"id"`
+	Type           string            `json:"type"`
+	Status         Status            `json:"status"`
+	Progress       float64           `json:"progress"`                  // 0.0 - 1.0
+	CompletedSteps int               `json:"completed_steps,omitempty"` // 已完成的分段/段落数
+	TotalSteps     int               `json:"total_steps,omitempty"`     // 分段/段落总数
+	Owner          string            `json:"owner,omitempty"`
+	TenantID       string            `json:"tenant_id,omitempty"`
+	Params         map[string]string `json:"params,omitempty"`
+	InputName      string            `json:"input_name,omitempty"`
+	InputSize      int64             `json:"input_size"`
+	Result         json.RawMessage   `json:"result,omitempty"`
+	Output         *OutputInfo       `json:"output,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	Webhook        *WebhookState     `json:"webhook,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	StartedAt      *time.Time        `json:"started_at,omitempty"`
+	FinishedAt     *time.Time        `json:"finished_at,omitempty"`
+
+	rev uint64 // 内存中的修改版本，用于丢弃过期的持久化快照
+}
+
+// clone 复制任务（用于对外返回和持久化，避免数据竞争）
+func (j *Job) clone() *Job {
+	c := *j
+	if j.Webhook != nil {
+		w := *j.Webhook
+		c.Webhook = &w
+	}
+	if j.Output != nil {
+		o := *j.Output
+		c.Output = &o
+	}
+	return &c
+}
+
+// Task 交给处理器执行的任务
+type Task struct {
+	Job   *Job   // 提交时的任务快照
+	Input []byte // 任务输入数据
+
+	progress   func(completed, total int, progress float64)
+	saveOutput func(info OutputInfo, write func(f *os.File) error) error
+}
+
+// ReportProgress 上报处理进度（0.0 - 1.0）
+func (t *Task) ReportProgress(progress float64) {
+	if t.progress != nil {
+		t.progress(0, 0, progress)
+	}
+}
+
+// ReportSteps 按分段上报进度：已完成completed段，共total段
+func (t *Task) ReportSteps(completed, total int) {
+	if t.progress == nil || total <= 0 {
+		return
+	}
+	t.progress(completed, total, float64(completed)/float64(total))
+}
+
+// WriteOutput 流式写入任务输出文件，完成后可通过Manager.OpenOutput下载
+// write返回错误时不会保留任何输出
+func (t *Task) WriteOutput(contentType, filename string, write func(f *os.File) error) error {
+	if t.saveOutput == nil {
+		return fmt.Errorf("output is not supported for this task")
+	}
+	return t.saveOutput(OutputInfo{ContentType: contentType, Filename: filename}, write)
+}
+
+// Processor 任务处理器，返回值会以JSON形式保存为任务结果
+type Processor func(ctx context.Context, task *Task) (interface{}, error)
+
+// Manager 异步任务管理器
+type Manager struct {
+	config     *config.JobsConfig
+	store      *FileStore
+	webhook    *WebhookSender
+	processors map[string]Processor
+	jobs       map[string]*Job
+	running    map[string]context.CancelFunc
+	queue      chan string
+	recovered  []string
+	mu         sync.RWMutex
+	saveMu     sync.Mutex        // 串行化任务元数据写入
+	savedRev   map[string]uint64 // 已写入存储的任务版本（受saveMu保护）
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	started    bool
+	closed     bool
+}
+
+// NewManager 创建任务管理器，并从存储中恢复已有任务
+func NewManager(cfg *config.JobsConfig, store *FileStore) (*Manager, error) {
+	if store == nil {
+		return nil, fmt.Errorf("job store is required")
+	}
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 100
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		config:     cfg,
+		store:      store,
+		webhook:    NewWebhookSender(&cfg.Webhook),
+		processors: make(map[string]Processor),
+		jobs:       make(map[string]*Job),
+		running:    make(map[string]context.CancelFunc),
+		savedRev:   make(map[string]uint64),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	loaded, errs := store.LoadAll()
+	for _, err := range errs {
+		logger.Warnf("Skipping job: %v", err)
+	}
+	for _, job := range loaded {
+		// 上次退出时未完成的任务重新排队
+		if !job.Status.IsFinal() {
+			job.Status = StatusQueued
+			job.Progress = 0
+			job.CompletedSteps = 0
+			job.Output = nil
+			job.StartedAt = nil
+			m.recovered = append(m.recovered, job.ID)
+		}
+		m.jobs[job.ID] = job
+	}
+	if len(loaded) > 0 {
+		logger.Infof("Recovered %d jobs from %s (%d pending)", len(loaded), store.Dir(), len(m.recovered))
+	}
+
+	m.queue = make(chan string, queueSize+len(m.recovered))
+	return m, nil
+}
+
+// RegisterProcessor 注册任务类型对应的处理器（需在Start之前调用）
+func (m *Manager) RegisterProcessor(jobType string, processor Processor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processors[jobType] = processor
+}
+
+// Start 启动工作协程，恢复未完成的任务和未投递的回调
+func (m *Manager) Start() {
+	m.mu.Lock()
+	if m.started || m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.started = true
+
+	recovered := make([]*Job, 0, len(m.recovered))
+	for _, id := range m.recovered {
+		recovered = append(recovered, m.snapshot(m.jobs[id]))
+		m.queue <- id
+	}
+	m.recovered = nil
+
+	var pending []*Job
+	for _, job := range m.jobs {
+		if job.Status.IsFinal() && m.webhookPending(job) {
+			pending = append(pending, job.clone())
+		}
+	}
+
+	workers := m.config.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	// 持锁登记工作协程和清理协程，保证Close的wg.Wait能等待到它们
+	m.wg.Add(workers + 1)
+	m.mu.Unlock()
+
+	for _, job := range recovered {
+		m.persist(job)
+	}
+
+	for i := 0; i < workers; i++ {
+		go m.worker()
+	}
+
+	for _, job := range pending {
+		m.dispatchWebhook(job)
+	}
+
+	go m.cleanupLoop()
+
+	logger.Infof("Job manager started: workers=%d, queue_size=%d, store=%s", workers, cap(m.queue), m.store.Dir())
+}
+
+// ValidateWebhookURL 校验回调地址
+func (m *Manager) ValidateWebhookURL(rawURL string) error {
+	return m.webhook.ValidateURL(rawURL)
+}
+
+// Submit 提交任务
+// job中需设置Type，可选设置Owner、TenantID、Params、InputName和Webhook
+func (m *Manager) Submit(job *Job, input []byte) (*Job, error) {
+	m.mu.RLock()
+	_, ok := m.processors[job.Type]
+	closed := m.closed
+	m.mu.RUnlock()
+	if closed {
+		return nil, ErrManagerClosed
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJobType, job.Type)
+	}
+
+	if job.Webhook != nil {
+		if err := m.webhook.ValidateURL(job.Webhook.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	job = job.clone()
+	job.ID = id
+	job.Status = StatusQueued
+	job.Progress = 0
+	job.InputSize = int64(len(input))
+	job.CreatedAt = time.Now()
+
+	if err := m.store.SaveInput(id, input); err != nil {
+		return nil, fmt.Errorf("failed to persist job input: %w", err)
+	}
+	if err := m.store.Save(job); err != nil {
+		m.store.Delete(id)
+		return nil, fmt.Errorf("failed to persist job: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		m.store.Delete(id)
+		return nil, ErrManagerClosed
+	}
+
+	select {
+	case m.queue <- id:
+	default:
+		m.store.Delete(id)
+		return nil, ErrQueueFull
+	}
+	m.jobs[id] = job
+
+	logger.Infof("Job %s (%s) queued, input=%d bytes", id, job.Type, job.InputSize)
+	return job.clone(), nil
+}
+
+// Get 获取任务
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job.clone(), nil
+}
+
+// Cancel 取消任务
+// 排队中的任务立即取消；执行中的任务会中断处理，状态由工作协程更新
+func (m *Manager) Cancel(id string) (*Job, error) {
+	m.mu.Lock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	if job.Status.IsFinal() {
+		snapshot := job.clone()
+		m.mu.Unlock()
+		return snapshot, ErrJobFinished
+	}
+
+	if cancel, running := m.running[id]; running {
+		cancel()
+		snapshot := job.clone()
+		m.mu.Unlock()
+		logger.Infof("Job %s cancellation requested", id)
+		return snapshot, nil
+	}
+
+	now := time.Now()
+	job.Status = StatusCancelled
+	job.FinishedAt = &now
+	snapshot := m.snapshot(job)
+	m.mu.Unlock()
+
+	m.persist(snapshot)
+	m.store.RemoveInput(id)
+	logger.Infof("Job %s cancelled", id)
+	m.dispatchWebhook(snapshot)
+	return snapshot, nil
+}
+
+// worker 工作协程
+func (m *Manager) worker() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case id := <-m.queue:
+			m.run(id)
+		}
+	}
+}
+
+// run 执行单个任务
+func (m *Manager) run(id string) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok || job.Status != StatusQueued {
+		// 已取消或已删除
+		m.mu.Unlock()
+		return
+	}
+	processor := m.processors[job.Type]
+
+	now := time.Now()
+	job.Status = StatusRunning
+	job.StartedAt = &now
+	jobCtx, cancel := context.WithCancel(m.ctx)
+	m.running[id] = cancel
+	snapshot := m.snapshot(job)
+	m.mu.Unlock()
+
+	m.persist(snapshot)
+
+	var result interface{}
+	input, err := m.store.LoadInput(id)
+	if err != nil {
+		err = fmt.Errorf("failed to load job input: %w", err)
+	} else if processor == nil {
+		err = fmt.Errorf("%w: %s", ErrUnknownJobType, snapshot.Type)
+	} else {
+		task := &Task{
+			Job:   snapshot,
+			Input: input,
+			progress: func(completed, total int, p float64) {
+				m.updateProgress(id, completed, total, p)
+			},
+			saveOutput: func(info OutputInfo, write func(f *os.File) error) error {
+				return m.saveOutput(id, info, write)
+			},
+		}
+		result, err = processor(jobCtx, task)
+	}
+
+	m.mu.Lock()
+	delete(m.running, id)
+	cancelled := jobCtx.Err() != nil
+	cancel()
+
+	if m.ctx.Err() != nil {
+		// 服务关闭导致的中断：恢复为排队状态，重启后继续处理
+		job.Status = StatusQueued
+		job.Progress = 0
+		job.CompletedSteps = 0
+		job.Output = nil
+		job.StartedAt = nil
+		snapshot = m.snapshot(job)
+		m.mu.Unlock()
+
+		m.persist(snapshot)
+		logger.Infof("Job %s interrupted by shutdown, will resume after restart", id)
+		return
+	}
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	switch {
+	case cancelled:
+		job.Status = StatusCancelled
+		job.Output = nil
+	case err != nil:
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		job.Output = nil
+	default:
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			job.Status = StatusFailed
+			job.Error = fmt.Sprintf("failed to encode result: %v", marshalErr)
+		} else {
+			job.Status = StatusSucceeded
+			job.Progress = 1
+			job.CompletedSteps = job.TotalSteps
+			job.Result = data
+		}
+	}
+	if job.Output == nil {
+		m.store.RemoveOutput(id)
+	}
+	snapshot = m.snapshot(job)
+	m.mu.Unlock()
+
+	m.persist(snapshot)
+	m.store.RemoveInput(id)
+	logger.Infof("Job %s finished: status=%s, duration=%v", id, snapshot.Status, finished.Sub(now))
+	m.dispatchWebhook(snapshot)
+}
+
+// updateProgress 更新任务进度（total为0时只更新进度比例）
+func (m *Manager) updateProgress(id string, completed, total int, progress float64) {
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok || job.Status != StatusRunning {
+		m.mu.Unlock()
+		return
+	}
+	job.Progress = progress
+	if total > 0 {
+		job.CompletedSteps = completed
+		job.TotalSteps = total
+	}
+	snapshot := m.snapshot(job)
+	m.mu.Unlock()
+
+	m.persist(snapshot)
+}
+
+// saveOutput 保存任务输出并记录文件信息
+func (m *Manager) saveOutput(id string, info OutputInfo, write func(f *os.File) error) error {
+	size, err := m.store.WriteOutput(id, write)
+	if err != nil {
+		return fmt.Errorf("failed to write job output: %w", err)
+	}
+	info.Size = size
+
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil
+	}
+	job.Output = &info
+	snapshot := m.snapshot(job)
+	m.mu.Unlock()
+
+	m.persist(snapshot)
+	return nil
+}
+
+// OpenOutput 打开已完成任务的输出文件
+func (m *Manager) OpenOutput(id string) (*os.File, *OutputInfo, error) {
+	m.mu.RLock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.RUnlock()
+		return nil, nil, ErrJobNotFound
+	}
+	if job.Status != StatusSucceeded || job.Output == nil {
+		m.mu.RUnlock()
+		return nil, nil, ErrNoOutput
+	}
+	info := *job.Output
+	m.mu.RUnlock()
+
+	file, err := m.store.OpenOutput(id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open job output: %w", err)
+	}
+	return file, &info, nil
+}
+
+// snapshot 递增任务版本并返回快照，释放锁后交给persist写入（调用方需持有锁）
+func (m *Manager) snapshot(job *Job) *Job {
+	job.rev++
+	return job.clone()
+}
+
+// persist 持久化任务快照（调用方不能持有m.mu）
+// 并发写入时丢弃比已写入版本更旧的快照，已删除的任务不再写回
+func (m *Manager) persist(job *Job) {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	if job.rev <= m.savedRev[job.ID] {
+		return
+	}
+	m.mu.RLock()
+	_, exists := m.jobs[job.ID]
+	m.mu.RUnlock()
+	if !exists {
+		return
+	}
+
+	if err := m.store.Save(job); err != nil {
+		logger.Errorf("Failed to persist job %s: %v", job.ID, err)
+		return
+	}
+	m.savedRev[job.ID] = job.rev
+}
+
+// webhookPending 回调是否仍需投递（调用方需持有锁）
+func (m *Manager) webhookPending(job *Job) bool {
+	return job.Webhook != nil && !job.Webhook.Delivered && job.Webhook.Attempts <= m.config.Webhook.MaxRetries
+}
+
+// dispatchWebhook 异步投递任务完成回调
+func (m *Manager) dispatchWebhook(job *Job) {
+	if job.Webhook == nil || job.Webhook.Delivered {
+		return
+	}
+
+	// 与Close互斥：关闭后不再启动投递协程，未投递的回调在重启后恢复
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.deliverWebhook(job)
+	}()
+}
+
+// deliverWebhook 投递回调，失败时按指数退避重试
+func (m *Manager) deliverWebhook(job *Job) {
+	event := "job." + string(job.Status)
+	payload, err := json.Marshal(WebhookPayload{
+		Event: event,
+		Job:   job,
+	})
+	if err != nil {
+		logger.Errorf("Failed to encode webhook payload for job %s: %v", job.ID, err)
+		return
+	}
+
+	for attempt := job.Webhook.Attempts; attempt <= m.config.Webhook.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-m.ctx.Done():
+				// 服务关闭，重启后继续投递
+				return
+			case <-time.After(m.webhook.Backoff(attempt)):
+			}
+		}
+
+		sendErr := m.webhook.Send(m.ctx, job.Webhook.URL, event, job.ID, payload)
+
+		m.mu.Lock()
+		stored, ok := m.jobs[job.ID]
+		if !ok || stored.Webhook == nil {
+			m.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		stored.Webhook.Attempts = attempt + 1
+		stored.Webhook.LastAttemptAt = &now
+		if sendErr == nil {
+			stored.Webhook.Delivered = true
+			stored.Webhook.LastError = ""
+		} else {
+			stored.Webhook.LastError = sendErr.Error()
+		}
+		snapshot := m.snapshot(stored)
+		m.mu.Unlock()
+
+		m.persist(snapshot)
+
+		if sendErr == nil {
+			logger.Infof("Webhook for job %s delivered (attempt %d)", job.ID, attempt+1)
+			return
+		}
+		if m.ctx.Err() != nil {
+			return
+		}
+		logger.Warnf("Webhook for job %s failed (attempt %d): %v", job.ID, attempt+1, sendErr)
+	}
+
+	logger.Errorf("Webhook for job %s abandoned after %d attempts", job.ID, m.config.Webhook.MaxRetries+1)
+}
+
+// cleanupLoop 定期清理过期的已结束任务
+func (m *Manager) cleanupLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.cleanupExpired()
+		}
+	}
+}
+
+// cleanupExpired 删除超过保留时长的已结束任务
+func (m *Manager) cleanupExpired() {
+	retention := time.Duration(m.config.RetentionHours) * time.Hour
+	if retention <= 0 {
+		return
+	}
+	deadline := time.Now().Add(-retention)
+
+	var deleted []string
+	m.mu.Lock()
+	for id, job := range m.jobs {
+		if !job.Status.IsFinal() || job.FinishedAt == nil || job.FinishedAt.After(deadline) {
+			continue
+		}
+		if m.webhookPending(job) {
+			continue
+		}
+		delete(m.jobs, id)
+		deleted = append(deleted, id)
+	}
+	m.mu.Unlock()
+
+	// 持有saveMu删除文件，避免与进行中的persist交错写回
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	for _, id := range deleted {
+		delete(m.savedRev, id)
+		if err := m.store.Delete(id); err != nil {
+			logger.Errorf("Failed to delete expired job %s: %v", id, err)
+		}
+	}
+}
+
+// GetStats 获取统计信息
+func (m *Manager) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := map[Status]int{}
+	for _, job := range m.jobs {
+		counts[job.Status]++
+	}
+
+	return map[string]interface{}{
+		"workers":      m.config.Workers,
+		"queue_length": len(m.queue),
+		"queue_size":   cap(m.queue),
+		"total_jobs":   len(m.jobs),
+		"queued":       counts[StatusQueued],
+		"running":      counts[StatusRunning],
+		"succeeded":    counts[StatusSucceeded],
+		"failed":       counts[StatusFailed],
+		"cancelled":    counts[StatusCancelled],
+	}
+}
+
+// Close 关闭任务管理器
+// 执行中的任务被中断并恢复为排队状态，重启后继续处理
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	m.mu.Unlock()
+
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+// newJobID 生成任务ID
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,361 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+func newTestManager(t *testing.T, dir string, processor Processor) *Manager {
+	t.Helper()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	cfg := &config.JobsConfig{Workers: 1, QueueSize: 10, RetentionHours: 24}
+	config.SetJobsDefaults(cfg)
+
+	m, err := NewManager(cfg, store)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.RegisterProcessor("test", processor)
+	return m
+}
+
+// waitForStatus 等待任务进入指定状态
+func waitForStatus(t *testing.T, m *Manager, id string, status Status) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	job, _ := m.Get(id)
+	t.Fatalf("Timed out waiting for status %s, got %s", status, job.Status)
+	return nil
+}
+
+func TestManager_SubmitAndComplete(t *testing.T) {
+	m := newTestManager(t, t.TempDir(), func(ctx context.Context, task *Task) (interface{}, error) {
+		task.ReportProgress(0.5)
+		return map[string]int{"size": len(task.Input)}, nil
+	})
+	m.Start()
+	defer m.Close()
+
+	job, err := m.Submit(&Job{Type: "test", Owner: "client"}, []byte("abcd"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if job.Status != StatusQueued || job.ID == "" {
+		t.Errorf("Unexpected submitted job: %+v", job)
+	}
+
+	done := waitForStatus(t, m, job.ID, StatusSucceeded)
+	if done.Progress != 1 {
+		t.Errorf("Expected progress 1, got %f", done.Progress)
+	}
+	if string(done.Result) != `{"size":4}` {
+		t.Errorf("Unexpected result: %s", done.Result)
+	}
+	if done.FinishedAt == nil || done.Owner != "client" {
+		t.Errorf("Unexpected finished job: %+v", done)
+	}
+}
+
+func TestManager_Failure(t *testing.T) {
+	m := newTestManager(t, t.TempDir(), func(ctx context.Context, task *Task) (interface{}, error) {
+		return nil, errors.New("decode failed")
+	})
+	m.Start()
+	defer m.Close()
+
+	job, err := m.Submit(&Job{Type: "test"}, []byte("x"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	done := waitForStatus(t, m, job.ID, StatusFailed)
+	if done.Error != "decode failed" {
+		t.Errorf("Expected error message, got %q", done.Error)
+	}
+}
+
+func TestManager_UnknownType(t *testing.T) {
+	m := newTestManager(t, t.TempDir(), nil)
+	defer m.Close()
+
+	if _, err := m.Submit(&Job{Type: "other"}, []byte("x")); !errors.Is(err, ErrUnknownJobType) {
+		t.Errorf("Expected ErrUnknownJobType, got %v", err)
+	}
+}
+
+func TestManager_CancelQueued(t *testing.T) {
+	// 未启动工作协程，任务保持排队状态
+	m := newTestManager(t, t.TempDir(), func(ctx context.Context, task *Task) (interface{}, error) {
+		return nil, nil
+	})
+	defer m.Close()
+
+	job, err := m.Submit(&Job{Type: "test"}, []byte("x"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	cancelled, err := m.Cancel(job.ID)
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if cancelled.Status != StatusCancelled {
+		t.Errorf("Expected cancelled, got %s", cancelled.Status)
+	}
+
+	if _, err := m.Cancel(job.ID); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("Expected ErrJobFinished, got %v", err)
+	}
+	if _, err := m.Cancel("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestManager_CancelRunning(t *testing.T) {
+	started := make(chan struct{})
+	m := newTestManager(t, t.TempDir(), func(ctx context.Context, task *Task) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	m.Start()
+	defer m.Close()
+
+	job, err := m.Submit(&Job{Type: "test"}, []byte("x"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-started
+
+	if _, err := m.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	waitForStatus(t, m, job.ID, StatusCancelled)
+}
+
+func TestManager_QueueFull(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	m, err := NewManager(&config.JobsConfig{Workers: 1, QueueSize: 1}, store)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.RegisterProcessor("test", func(ctx context.Context, task *Task) (interface{}, error) { return nil, nil })
+	defer m.Close()
+
+	if _, err := m.Submit(&Job{Type: "test"}, []byte("x")); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, err := m.Submit(&Job{Type: "test"}, []byte("x")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestManager_PersistDropsStaleSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestManager(t, dir, func(ctx context.Context, task *Task) (interface{}, error) { return nil, nil })
+	defer m.Close()
+
+	job, err := m.Submit(&Job{Type: "test"}, []byte("x"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	// 模拟两次修改的快照乱序写入：较新的先落盘，较旧的应被丢弃
+	m.mu.Lock()
+	stored := m.jobs[job.ID]
+	stored.Progress = 0.2
+	older := m.snapshot(stored)
+	stored.Progress = 0.8
+	newer := m.snapshot(stored)
+	m.mu.Unlock()
+
+	m.persist(newer)
+	m.persist(older)
+
+	loaded, errs := m.store.LoadAll()
+	if len(errs) > 0 || len(loaded) != 1 {
+		t.Fatalf("LoadAll() = %d jobs, errors %v", len(loaded), errs)
+	}
+	if loaded[0].Progress != 0.8 {
+		t.Errorf("Expected persisted progress 0.8, got %f", loaded[0].Progress)
+	}
+}
+
+func TestManager_ResumeAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	// 第一个实例：任务执行中被关闭
+	started := make(chan struct{})
+	first := newTestManager(t, dir, func(ctx context.Context, task *Task) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	first.Start()
+
+	job, err := first.Submit(&Job{Type: "test"}, []byte("persisted"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-started
+	first.Close()
+
+	// 第二个实例：从存储中恢复并完成任务
+	second := newTestManager(t, dir, func(ctx context.Context, task *Task) (interface{}, error) {
+		return string(task.Input), nil
+	})
+	second.Start()
+	defer second.Close()
+
+	done := waitForStatus(t, second, job.ID, StatusSucceeded)
+	if string(done.Result) != `"persisted"` {
+		t.Errorf("Expected recovered input to be processed, got %s", done.Result)
+	}
+}
+
+func TestManager_WebhookRetryAndSignature(t *testing.T) {
+	var calls int32
+	received := make(chan WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !VerifySignature("secret", r.Header.Get(HeaderWebhookTimestamp), body, r.Header.Get(HeaderWebhookSignature)) {
+			t.Errorf("Invalid webhook signature")
+		}
+		// 第一次返回错误以触发重试
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var payload WebhookPayload
+		json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	cfg := &config.JobsConfig{Workers: 1, QueueSize: 10}
+	cfg.Webhook.Secret = "secret"
+	cfg.Webhook.AllowPrivateNetworks = true // 测试服务监听在回环地址
+	config.SetJobsDefaults(cfg)
+	m, err := NewManager(cfg, store)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.RegisterProcessor("test", func(ctx context.Context, task *Task) (interface{}, error) {
+		return "ok", nil
+	})
+	m.Start()
+	defer m.Close()
+
+	job, err := m.Submit(&Job{Type: "test", Webhook: &WebhookState{URL: server.URL}}, []byte("x"))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload.Event != "job.succeeded" || payload.Job.ID != job.ID {
+			t.Errorf("Unexpected payload: %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for webhook")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		stored, _ := m.Get(job.ID)
+		if stored.Webhook.Delivered {
+			if stored.Webhook.Attempts != 2 {
+				t.Errorf("Expected 2 attempts, got %d", stored.Webhook.Attempts)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("Expected webhook to be marked delivered")
+}
+
+func TestWebhookSender_ValidateURL(t *testing.T) {
+	sender := NewWebhookSender(&config.WebhookConfig{AllowedHosts: []string{"hooks.example.com"}})
+
+	if err := sender.ValidateURL("https://hooks.example.com/cb"); err != nil {
+		t.Errorf("Expected allowed host to pass, got %v", err)
+	}
+	for _, u := range []string{"https://evil.example.com/cb", "file:///etc/passwd", "not a url"} {
+		if err := sender.ValidateURL(u); err == nil {
+			t.Errorf("Expected %q to be rejected", u)
+		}
+	}
+}
+
+func TestWebhookSender_PrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	sender := NewWebhookSender(&config.WebhookConfig{})
+	for _, u := range []string{"http://127.0.0.1/cb", "http://10.0.0.1/cb", "http://169.254.169.254/latest", "http://[::1]/cb", "http://0.0.0.0/cb",
+		"http://[::ffff:127.0.0.1]/cb", "http://[::ffff:10.0.0.1]/cb", "http://100.64.1.1/cb", "http://100.127.255.254/cb", "http://0.1.2.3/cb"} {
+		if err := sender.ValidateURL(u); err == nil {
+			t.Errorf("Expected %q to be rejected", u)
+		}
+	}
+	for _, u := range []string{"http://100.128.0.1/cb", "http://8.8.8.8/cb", "http://[::ffff:8.8.8.8]/cb"} {
+		if err := sender.ValidateURL(u); err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", u, err)
+		}
+	}
+	// 域名解析到回环地址时在连接时拒绝
+	localhost := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	if err := sender.Send(context.Background(), localhost, "job.succeeded", "j1", []byte("{}")); err == nil {
+		t.Error("Expected webhook to loopback address to be rejected")
+	}
+
+	sender = NewWebhookSender(&config.WebhookConfig{AllowPrivateNetworks: true})
+	if err := sender.ValidateURL(server.URL); err != nil {
+		t.Errorf("Expected private address to be allowed, got %v", err)
+	}
+	if err := sender.Send(context.Background(), server.URL, "job.succeeded", "j1", []byte("{}")); err != nil {
+		t.Errorf("Expected webhook to be delivered, got %v", err)
+	}
+}
+
+func TestWebhookSender_Backoff(t *testing.T) {
+	sender := NewWebhookSender(&config.WebhookConfig{InitialBackoff: 1, MaxBackoff: 5})
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := sender.Backoff(i + 1); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+}
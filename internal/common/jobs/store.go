@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 存储文件后缀
+const (
+	metaSuffix   = ".json"
+	inputSuffix  = ".input"
+	outputSuffix = ".output"
+)
+
+// FileStore 基于本地目录的任务存储
+// 每个任务对应一个元数据文件、一个输入文件和可选的输出文件，写入时先写临时文件再重命名，保证重启后状态完整
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore 创建文件存储（目录不存在时自动创建）
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("job store directory is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create job store directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir 获取存储目录
+func (s *FileStore) Dir() string {
+	return s.dir
+}
+
+// path 获取任务文件路径（任务ID只包含十六进制字符，不会越出存储目录）
+func (s *FileStore) path(id, suffix string) string {
+	return filepath.Join(s.dir, id+suffix)
+}
+
+// Save 保存任务元数据
+func (s *FileStore) Save(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job %s: %w", job.ID, err)
+	}
+	return writeFileAtomic(s.path(job.ID, metaSuffix), data)
+}
+
+// SaveInput 保存任务输入数据
+func (s *FileStore) SaveInput(id string, data []byte) error {
+	return writeFileAtomic(s.path(id, inputSuffix), data)
+}
+
+// LoadInput 读取任务输入数据
+func (s *FileStore) LoadInput(id string) ([]byte, error) {
+	return os.ReadFile(s.path(id, inputSuffix))
+}
+
+// WriteOutput 通过write流式写入任务输出，写入成功后原子替换输出文件，返回文件大小
+func (s *FileStore) WriteOutput(id string, write func(f *os.File) error) (int64, error) {
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	tmpName := tmp.Name()
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return 0, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return 0, err
+	}
+	if err := os.Rename(tmpName, s.path(id, outputSuffix)); err != nil {
+		os.Remove(tmpName)
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// OpenOutput 打开任务输出文件
+func (s *FileStore) OpenOutput(id string) (*os.File, error) {
+	return os.Open(s.path(id, outputSuffix))
+}
+
+// RemoveInput 删除任务输入数据（任务结束后释放磁盘空间）
+func (s *FileStore) RemoveInput(id string) error {
+	if err := os.Remove(s.path(id, inputSuffix)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// RemoveOutput 删除任务输出数据
+func (s *FileStore) RemoveOutput(id string) error {
+	if err := os.Remove(s.path(id, outputSuffix)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Delete 删除任务的所有文件
+func (s *FileStore) Delete(id string) error {
+	for _, suffix := range []string{metaSuffix, inputSuffix, outputSuffix} {
+		if err := os.Remove(s.path(id, suffix)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadAll 加载所有已持久化的任务（损坏的文件会被跳过并返回在errs中）
+func (s *FileStore) LoadAll() ([]*Job, []error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to read job store directory: %w", err)}
+	}
+
+	var jobs []*Job
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), metaSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+			errs = append(errs, fmt.Errorf("invalid job file %s: %v", entry.Name(), err))
+			continue
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, errs
+}
+
+// writeFileAtomic 先写临时文件再重命名
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
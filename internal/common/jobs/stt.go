@@ -0,0 +1,172 @@
+package jobs
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// TypeSTT 语音识别任务类型
+const TypeSTT = "stt"
+
+// ParamModel 任务参数：识别或合成模型名称（为空时使用默认模型）
+const ParamModel = "model"
+
+// Transcriber 语音识别接口（由asr.Manager实现）
+type Transcriber interface {
+	Transcribe(ctx interface{}, audio []byte) (string, error)
+}
+
+// TranscriberLookup 按名称查找识别模型
+type TranscriberLookup func(name string) (Transcriber, error)
+
+// STTSegment 分段识别结果
+type STTSegment struct {
+	Start float64 `json:"start"` // 秒
+	End   float64 `json:"end"`   // 秒
+	Text  string  `json:"text"`
+}
+
+// STTResult 识别任务结果
+type STTResult struct {
+	Text     string       `json:"text"`
+	Duration float64      `json:"duration"` // 音频时长（秒）
+	Segments []STTSegment `json:"segments"`
+}
+
+// NewSTTProcessor 创建识别任务处理器
+// 任务参数指定model时通过models查找模型，否则使用transcriber（models为nil时只支持默认模型）
+// 长音频按chunkSeconds分段识别，分段点选在段尾附近能量最低处，每段完成后按分段上报进度
+func NewSTTProcessor(transcriber Transcriber, models TranscriberLookup, sampleRate, chunkSeconds int) Processor {
+	if sampleRate <= 0 {
+		sampleRate = 16000
+	}
+	if chunkSeconds <= 0 {
+		chunkSeconds = 30
+	}
+
+	return func(ctx context.Context, task *Task) (interface{}, error) {
+		active := transcriber
+		if name := task.Job.Params[ParamModel]; name != "" {
+			if models == nil {
+				return nil, fmt.Errorf("model not found: %s", name)
+			}
+			manager, err := models(name)
+			if err != nil {
+				return nil, err
+			}
+			active = manager
+		}
+
+		// WAV按文件头中的采样率、声道数转换为sampleRate单声道PCM，不支持的编码直接使任务失败
+		pcm, err := utils.WAVToPCM16(task.Input, sampleRate)
+		if err != nil {
+			return nil, err
+		}
+		if len(pcm) < 2 {
+			return nil, fmt.Errorf("audio data is empty")
+		}
+
+		bytesPerSecond := float64(sampleRate * 2)
+		result := &STTResult{
+			Duration: float64(len(pcm)/2) / float64(sampleRate),
+			Segments: make([]STTSegment, 0),
+		}
+
+		ranges := splitPCM(pcm, sampleRate, chunkSeconds)
+		task.ReportSteps(0, len(ranges))
+		for i, r := range ranges {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			text, err := active.Transcribe(ctx, pcm[r[0]:r[1]])
+			if err != nil {
+				return nil, err
+			}
+			if text != "" {
+				result.Segments = append(result.Segments, STTSegment{
+					Start: float64(r[0]) / bytesPerSecond,
+					End:   float64(r[1]) / bytesPerSecond,
+					Text:  text,
+				})
+				result.Text = joinText(result.Text, text)
+			}
+			task.ReportSteps(i+1, len(ranges))
+		}
+
+		return result, nil
+	}
+}
+
+// splitPCM 将16位PCM数据切分为不超过chunkSeconds的分段，返回字节区间
+// 在每段最后2秒内选择能量最低的20ms帧作为切分点，尽量避免切断语音
+func splitPCM(pcm []byte, sampleRate, chunkSeconds int) [][2]int {
+	total := len(pcm) &^ 1
+	chunkBytes := sampleRate * 2 * chunkSeconds
+	if total <= chunkBytes {
+		return [][2]int{{0, total}}
+	}
+
+	frameBytes := sampleRate * 2 / 50 // 20ms
+	searchBytes := sampleRate * 2 * 2 // 2s
+	if searchBytes >= chunkBytes {
+		searchBytes = chunkBytes / 2
+	}
+
+	var ranges [][2]int
+	start := 0
+	for total-start > chunkBytes {
+		end := start + chunkBytes
+		best := end
+		bestEnergy := -1.0
+		for pos := end - searchBytes; pos+frameBytes <= end; pos += frameBytes {
+			energy := frameEnergy(pcm[pos : pos+frameBytes])
+			if bestEnergy < 0 || energy < bestEnergy {
+				bestEnergy = energy
+				best = pos + frameBytes/2
+			}
+		}
+		best &^= 1
+		ranges = append(ranges, [2]int{start, best})
+		start = best
+	}
+	ranges = append(ranges, [2]int{start, total})
+	return ranges
+}
+
+// frameEnergy 计算帧的平均能量
+func frameEnergy(frame []byte) float64 {
+	n := len(frame) / 2
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		sample := float64(int16(binary.LittleEndian.Uint16(frame[i*2:])))
+		sum += sample * sample
+	}
+	return sum / float64(n)
+}
+
+// joinText 拼接分段文本，两侧均为字母数字时插入空格
+func joinText(prev, next string) string {
+	if prev == "" {
+		return next
+	}
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	first, _ := utf8.DecodeRuneInString(next)
+	if isWordRune(last) && isWordRune(first) {
+		return prev + " " + next
+	}
+	return prev + next
+}
+
+// isWordRune 是否为需要空格分隔的字符（拉丁字母和数字）
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// mockTranscriber 记录每次识别的音频长度
+type mockTranscriber struct {
+	lengths []int
+}
+
+func (m *mockTranscriber) Transcribe(ctx interface{}, audio []byte) (string, error) {
+	m.lengths = append(m.lengths, len(audio))
+	return "hello", nil
+}
+
+// tone 生成指定秒数的非静音PCM数据
+func tone(sampleRate int, seconds float64) []byte {
+	n := int(float64(sampleRate) * seconds)
+	data := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(8000)))
+	}
+	return data
+}
+
+func TestSplitPCM_Short(t *testing.T) {
+	pcm := tone(16000, 1)
+	ranges := splitPCM(pcm, 16000, 30)
+	if len(ranges) != 1 || ranges[0] != [2]int{0, len(pcm)} {
+		t.Errorf("Expected a single range, got %v", ranges)
+	}
+}
+
+func TestSplitPCM_CutsAtSilence(t *testing.T) {
+	// 9秒语音 + 0.2秒静音 + 5秒语音，段长10秒时应在静音处切分
+	pcm := tone(16000, 9)
+	silenceStart := len(pcm)
+	pcm = append(pcm, make([]byte, 16000*2/5)...)
+	silenceEnd := len(pcm)
+	pcm = append(pcm, tone(16000, 5)...)
+
+	ranges := splitPCM(pcm, 16000, 10)
+	if len(ranges) != 2 {
+		t.Fatalf("Expected 2 ranges, got %v", ranges)
+	}
+	cut := ranges[0][1]
+	if cut < silenceStart || cut > silenceEnd {
+		t.Errorf("Expected cut inside silence [%d, %d], got %d", silenceStart, silenceEnd, cut)
+	}
+	if ranges[1][0] != cut || ranges[1][1] != len(pcm) {
+		t.Errorf("Ranges are not contiguous: %v", ranges)
+	}
+}
+
+func TestJoinText(t *testing.T) {
+	tests := []struct {
+		prev, next, want string
+	}{
+		{"", "你好", "你好"},
+		{"你好", "世界", "你好世界"},
+		{"hello", "world", "hello world"},
+		{"你好", "world", "你好world"},
+	}
+	for _, tt := range tests {
+		if got := joinText(tt.prev, tt.next); got != tt.want {
+			t.Errorf("joinText(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestSTTProcessor(t *testing.T) {
+	transcriber := &mockTranscriber{}
+	processor := NewSTTProcessor(transcriber, nil, 16000, 10)
+
+	var progress []float64
+	task := &Task{
+		Job:   &Job{ID: "test"},
+		Input: tone(16000, 25),
+		progress: func(completed, total int, p float64) {
+			progress = append(progress, p)
+		},
+	}
+
+	out, err := processor(context.Background(), task)
+	if err != nil {
+		t.Fatalf("processor error = %v", err)
+	}
+	result := out.(*STTResult)
+
+	if len(transcriber.lengths) != 3 {
+		t.Errorf("Expected 3 chunks, got %d", len(transcriber.lengths))
+	}
+	if result.Text != "hello hello hello" || len(result.Segments) != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.Duration != 25 {
+		t.Errorf("Expected duration 25, got %f", result.Duration)
+	}
+	if len(progress) != 4 || progress[0] != 0 || progress[3] != 1 {
+		t.Errorf("Unexpected progress reports: %v", progress)
+	}
+}
+
+func TestSTTProcessor_Cancelled(t *testing.T) {
+	processor := NewSTTProcessor(&mockTranscriber{}, nil, 16000, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := processor(ctx, &Task{Job: &Job{}, Input: tone(16000, 1)}); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+}
+
+func TestSTTProcessor_WAVInput(t *testing.T) {
+	processor := NewSTTProcessor(&mockTranscriber{}, nil, 16000, 10)
+
+	// 8kHz WAV按文件头采样率重采样，时长保持不变
+	out, err := processor(context.Background(), &Task{Job: &Job{}, Input: utils.EncodeWAV(tone(8000, 2), 8000, 1)})
+	if err != nil {
+		t.Fatalf("processor error = %v", err)
+	}
+	if d := out.(*STTResult).Duration; d != 2 {
+		t.Errorf("Expected duration 2, got %f", d)
+	}
+
+	// 非16位PCM编码使任务失败
+	float := utils.EncodeWAV(tone(16000, 1), 16000, 1)
+	binary.LittleEndian.PutUint16(float[20:22], 3)
+	binary.LittleEndian.PutUint16(float[34:36], 32)
+	if _, err := processor(context.Background(), &Task{Job: &Job{}, Input: float}); !errors.Is(err, utils.ErrUnsupportedWAV) {
+		t.Errorf("Expected ErrUnsupportedWAV, got %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// TypeTTS 语音合成任务类型
+const TypeTTS = "tts"
+
+// 合成任务输出格式
+const (
+	FormatWAV = "wav" // 16位PCM WAV
+	FormatPCM = "pcm" // 16位小端PCM裸数据
+)
+
+// Synthesizer 语音合成接口（由tts.Manager实现）
+type Synthesizer interface {
+	Synthesize(ctx interface{}, text string, speakerID int, speed float32) ([]byte, error)
+	GetSampleRate() int
+}
+
+// SynthesizerLookup 按名称查找合成模型
+type SynthesizerLookup func(name string) (Synthesizer, error)
+
+// TTSRequest 合成任务参数（作为任务输入持久化）
+type TTSRequest struct {
+	Text               string  `json:"text"`
+	Model              string  `json:"model,omitempty"` // 合成模型名称，为空时使用默认模型
+	SpeakerID          int     `json:"speaker_id,omitempty"`
+	Speed              float32 `json:"speed,omitempty"`
+	Format             string  `json:"format,omitempty"`               // wav（默认）或 pcm
+	SampleRate         int     `json:"sample_rate,omitempty"`          // 输出采样率，默认为模型采样率
+	ParagraphSilenceMs *int    `json:"paragraph_silence_ms,omitempty"` // 段落间静音，默认使用配置值
+}
+
+// Normalize 校验参数并填充默认值
+func (r *TTSRequest) Normalize(cfg *config.TTSJobConfig) error {
+	if strings.TrimSpace(r.Text) == "" {
+		return fmt.Errorf("text is required")
+	}
+	if cfg.MaxTextLength > 0 && utf8.RuneCountInString(r.Text) > cfg.MaxTextLength {
+		return fmt.Errorf("text exceeds the maximum length of %d characters", cfg.MaxTextLength)
+	}
+	if r.Speed == 0 {
+		r.Speed = 1.0
+	}
+	if r.Speed < 0.1 || r.Speed > 10 {
+		return fmt.Errorf("speed must be between 0.1 and 10")
+	}
+	r.Model = strings.TrimSpace(r.Model)
+	r.Format = strings.ToLower(strings.TrimSpace(r.Format))
+	if r.Format == "" {
+		r.Format = FormatWAV
+	}
+	if r.Format != FormatWAV && r.Format != FormatPCM {
+		return fmt.Errorf("unsupported format: %s, must be wav or pcm", r.Format)
+	}
+	if r.SampleRate != 0 && (r.SampleRate < 8000 || r.SampleRate > 48000) {
+		return fmt.Errorf("sample_rate must be between 8000 and 48000")
+	}
+	if r.ParagraphSilenceMs == nil {
+		silence := cfg.ParagraphSilenceMs
+		r.ParagraphSilenceMs = &silence
+	} else if *r.ParagraphSilenceMs < 0 || *r.ParagraphSilenceMs > 10000 {
+		return fmt.Errorf("paragraph_silence_ms must be between 0 and 10000")
+	}
+	return nil
+}
+
+// TTSParagraph 段落合成信息
+type TTSParagraph struct {
+	Index    int     `json:"index"`
+	Chars    int     `json:"chars"`
+	Start    float64 `json:"start"`    // 在输出音频中的起始时间（秒）
+	Duration float64 `json:"duration"` // 秒
+}
+
+// TTSResult 合成任务结果
+type TTSResult struct {
+	Format     string         `json:"format"`
+	SampleRate int            `json:"sample_rate"`
+	Duration   float64        `json:"duration"` // 秒
+	Paragraphs []TTSParagraph `json:"paragraphs"`
+}
+
+// paragraphAudio 单个段落的合成结果
+type paragraphAudio struct {
+	index int
+	pcm   []byte
+	err   error
+}
+
+// contentTypeForFormat 获取输出格式对应的Content-Type
+func contentTypeForFormat(format string) string {
+	if format == FormatPCM {
+		return "audio/L16"
+	}
+	return "audio/wav"
+}
+
+// NewTTSProcessor 创建合成任务处理器
+// 文本按段落切分后并行合成（同时进行的段落不超过并发数），按顺序拼接（段落间插入静音）并流式写入输出文件，每写入一个段落上报进度。
+// 任务指定model时通过models查找模型，否则使用synthesizer（models为nil时只支持默认模型）；
+// 模型采样率在每个任务开始时读取，模型热加载后的任务使用新模型的采样率
+func NewTTSProcessor(synthesizer Synthesizer, models SynthesizerLookup, cfg *config.TTSJobConfig) Processor {
+	return func(ctx context.Context, task *Task) (interface{}, error) {
+		var req TTSRequest
+		if err := json.Unmarshal(task.Input, &req); err != nil {
+			return nil, fmt.Errorf("invalid job input: %w", err)
+		}
+		if err := req.Normalize(cfg); err != nil {
+			return nil, err
+		}
+
+		active := synthesizer
+		if req.Model != "" {
+			if models == nil {
+				return nil, fmt.Errorf("model not found: %s", req.Model)
+			}
+			manager, err := models(req.Model)
+			if err != nil {
+				return nil, err
+			}
+			active = manager
+		}
+
+		paragraphs := SplitParagraphs(req.Text, cfg.MaxParagraphLength)
+		if len(paragraphs) == 0 {
+			return nil, fmt.Errorf("text is required")
+		}
+
+		sampleRate := active.GetSampleRate()
+		outputRate := sampleRate
+		if req.SampleRate != 0 {
+			outputRate = req.SampleRate
+		}
+		silence := make([]byte, outputRate*(*req.ParagraphSilenceMs)/1000*2)
+
+		result := &TTSResult{
+			Format:     req.Format,
+			SampleRate: outputRate,
+			Paragraphs: make([]TTSParagraph, 0, len(paragraphs)),
+		}
+
+		synthCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		audio := synthesizeParagraphs(synthCtx, active, paragraphs, &req, cfg.Concurrency)
+
+		filename := task.Job.ID + "." + req.Format
+		err := task.WriteOutput(contentTypeForFormat(req.Format), filename, func(f *os.File) error {
+			if req.Format == FormatWAV {
+				// 先写占位文件头，写完数据后回填长度
+				if _, err := f.Write(utils.WAVHeader(0, outputRate, 1)); err != nil {
+					return err
+				}
+			}
+
+			// 出错时取消合成并等待已启动的段落结束
+			abort := func(err error) error {
+				cancel()
+				for pending := range audio {
+					<-pending
+				}
+				return err
+			}
+
+			next := 0
+			written := 0
+			task.ReportSteps(0, len(paragraphs))
+
+			for pending := range audio {
+				item := <-pending
+				if item.err != nil {
+					return abort(fmt.Errorf("paragraph %d: %w", item.index, item.err))
+				}
+
+				if next > 0 && len(silence) > 0 {
+					if _, err := f.Write(silence); err != nil {
+						return abort(err)
+					}
+					written += len(silence)
+				}
+				pcm := resamplePCM(item.pcm, sampleRate, outputRate)
+				if _, err := f.Write(pcm); err != nil {
+					return abort(err)
+				}
+
+				result.Paragraphs = append(result.Paragraphs, TTSParagraph{
+					Index:    next,
+					Chars:    utf8.RuneCountInString(paragraphs[next]),
+					Start:    float64(written/2) / float64(outputRate),
+					Duration: float64(len(pcm)/2) / float64(outputRate),
+				})
+				written += len(pcm)
+				next++
+				task.ReportSteps(next, len(paragraphs))
+			}
+
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if next != len(paragraphs) {
+				return fmt.Errorf("synthesized %d of %d paragraphs", next, len(paragraphs))
+			}
+
+			if req.Format == FormatWAV {
+				if _, err := f.WriteAt(utils.WAVHeader(written, outputRate, 1), 0); err != nil {
+					return err
+				}
+			}
+			result.Duration = float64(written/2) / float64(outputRate)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	}
+}
+
+// synthesizeParagraphs 并行合成段落，按段落顺序返回各段落的结果通道
+// 同时进行中（含已完成但尚未写出）的段落不超过concurrency个，避免长文本占用过多内存
+func synthesizeParagraphs(ctx context.Context, synthesizer Synthesizer, paragraphs []string, req *TTSRequest, concurrency int) <-chan chan paragraphAudio {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	// 调用方持有一个结果通道等待时，缓冲区中最多还有concurrency-1个
+	ordered := make(chan chan paragraphAudio, concurrency-1)
+
+	go func() {
+		defer close(ordered)
+		for i := range paragraphs {
+			result := make(chan paragraphAudio, 1)
+			select {
+			case ordered <- result:
+			case <-ctx.Done():
+				return
+			}
+
+			go func(i int) {
+				if err := ctx.Err(); err != nil {
+					result <- paragraphAudio{index: i, err: err}
+					return
+				}
+				pcm, err := synthesizer.Synthesize(ctx, paragraphs[i], req.SpeakerID, req.Speed)
+				result <- paragraphAudio{index: i, pcm: pcm, err: err}
+			}(i)
+		}
+	}()
+
+	return ordered
+}
+
+// resamplePCM 重采样16位PCM数据
+func resamplePCM(pcm []byte, fromRate, toRate int) []byte {
+	if fromRate == toRate || fromRate <= 0 || len(pcm) < 2 {
+		return pcm
+	}
+	samples := utils.SamplesInt16ToFloat(pcm[:len(pcm)&^1])
+	return utils.SamplesFloatToInt16(utils.ResampleAudio(samples, fromRate, toRate))
+}
+
+// SplitParagraphs 按空行/换行切分段落，超过maxLength个字符的段落再按句子边界切分
+func SplitParagraphs(text string, maxLength int) []string {
+	var paragraphs []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if maxLength <= 0 || utf8.RuneCountInString(line) <= maxLength {
+			paragraphs = append(paragraphs, line)
+			continue
+		}
+		paragraphs = append(paragraphs, splitSentences(line, maxLength)...)
+	}
+	return paragraphs
+}
+
+// splitSentences 将过长的段落按句末标点合并为不超过maxLength的片段
+// 单句超长时按maxLength硬切分
+func splitSentences(paragraph string, maxLength int) []string {
+	var sentences []string
+	var current []rune
+	for _, r := range paragraph {
+		current = append(current, r)
+		if isSentenceEnd(r) {
+			sentences = append(sentences, string(current))
+			current = current[:0]
+		}
+	}
+	if len(current) > 0 {
+		sentences = append(sentences, string(current))
+	}
+
+	var chunks []string
+	var chunk []rune
+	flush := func() {
+		if s := strings.TrimSpace(string(chunk)); s != "" {
+			chunks = append(chunks, s)
+		}
+		chunk = chunk[:0]
+	}
+
+	for _, sentence := range sentences {
+		runes := []rune(sentence)
+		if len(chunk)+len(runes) > maxLength {
+			flush()
+		}
+		for len(runes) > maxLength {
+			chunks = append(chunks, strings.TrimSpace(string(runes[:maxLength])))
+			runes = runes[maxLength:]
+		}
+		chunk = append(chunk, runes...)
+	}
+	flush()
+	return chunks
+}
+
+// isSentenceEnd 是否为句末标点
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '。', '！', '？', '；', '.', '!', '?', ';', '…':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,329 @@
+package jobs
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// mockSynthesizer 每个字符合成10个采样，值为段落首字节；采样率默认1000
+type mockSynthesizer struct {
+	mu    sync.Mutex
+	texts []string
+	fail  string
+	rate  int
+}
+
+func (m *mockSynthesizer) GetSampleRate() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rate == 0 {
+		return 1000
+	}
+	return m.rate
+}
+
+func (m *mockSynthesizer) Synthesize(ctx interface{}, text string, speakerID int, speed float32) ([]byte, error) {
+	m.mu.Lock()
+	m.texts = append(m.texts, text)
+	m.mu.Unlock()
+
+	if m.fail != "" && text == m.fail {
+		return nil, errors.New("synthesis failed")
+	}
+	// 后面的段落先完成，验证按顺序拼接
+	time.Sleep(time.Duration(10-len(text)%10) * time.Millisecond)
+
+	pcm := make([]byte, len([]rune(text))*10*2)
+	for i := 0; i < len(pcm)/2; i++ {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(text[0]))
+	}
+	return pcm, nil
+}
+
+func newTTSTestConfig() *config.TTSJobConfig {
+	cfg := &config.JobsConfig{}
+	config.SetJobsDefaults(cfg)
+	cfg.TTS.ParagraphSilenceMs = 10
+	return &cfg.TTS
+}
+
+func TestSplitParagraphs(t *testing.T) {
+	text := "第一段。\n\n  第二段  \r\n\n第三段"
+	paragraphs := SplitParagraphs(text, 100)
+	if len(paragraphs) != 3 || paragraphs[1] != "第二段" {
+		t.Errorf("Unexpected paragraphs: %q", paragraphs)
+	}
+}
+
+func TestSplitParagraphs_LongParagraph(t *testing.T) {
+	text := "一二三四。五六七八。九十。" + strings.Repeat("长", 12)
+	paragraphs := SplitParagraphs(text, 10)
+
+	for _, p := range paragraphs {
+		if n := len([]rune(p)); n > 10 {
+			t.Errorf("Paragraph %q has %d characters", p, n)
+		}
+	}
+	if strings.Join(paragraphs, "") != text {
+		t.Errorf("Expected chunks to preserve text, got %q", paragraphs)
+	}
+	if paragraphs[0] != "一二三四。五六七八。" {
+		t.Errorf("Expected sentences to be merged up to the limit, got %q", paragraphs[0])
+	}
+}
+
+func TestTTSRequest_Normalize(t *testing.T) {
+	cfg := newTTSTestConfig()
+
+	req := &TTSRequest{Text: "你好"}
+	if err := req.Normalize(cfg); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if req.Format != FormatWAV || req.Speed != 1.0 || *req.ParagraphSilenceMs != 10 {
+		t.Errorf("Unexpected defaults: %+v", req)
+	}
+
+	upper := &TTSRequest{Text: "a", Format: " PCM "}
+	if err := upper.Normalize(cfg); err != nil || upper.Format != FormatPCM {
+		t.Errorf("Expected format pcm, got %q (err %v)", upper.Format, err)
+	}
+
+	for _, bad := range []*TTSRequest{
+		{Text: ""},
+		{Text: "a", Format: "mp3"},
+		{Text: "a", SampleRate: 100},
+		{Text: strings.Repeat("a", cfg.MaxTextLength+1)},
+	} {
+		if err := bad.Normalize(cfg); err == nil {
+			t.Errorf("Expected error for %+v", bad)
+		}
+	}
+}
+
+func TestManager_TTSJob(t *testing.T) {
+	cfg := newTTSTestConfig()
+	synth := &mockSynthesizer{}
+
+	m := newTestManager(t, t.TempDir(), nil)
+	m.RegisterProcessor(TypeTTS, NewTTSProcessor(synth, nil, cfg))
+	m.Start()
+	defer m.Close()
+
+	input, _ := json.Marshal(&TTSRequest{Text: "a\nbb\nccc"})
+	job, err := m.Submit(&Job{Type: TypeTTS}, input)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	done := waitForStatus(t, m, job.ID, StatusSucceeded)
+
+	if done.CompletedSteps != 3 || done.TotalSteps != 3 {
+		t.Errorf("Expected 3/3 steps, got %d/%d", done.CompletedSteps, done.TotalSteps)
+	}
+
+	var result TTSResult
+	if err := json.Unmarshal(done.Result, &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if len(result.Paragraphs) != 3 || result.Paragraphs[2].Start != 0.05 {
+		t.Errorf("Unexpected paragraphs: %+v", result.Paragraphs)
+	}
+
+	file, info, err := m.OpenOutput(job.ID)
+	if err != nil {
+		t.Fatalf("OpenOutput() error = %v", err)
+	}
+	defer file.Close()
+	data, _ := io.ReadAll(file)
+
+	// 3段共60个采样 + 2段静音各10个采样
+	pcmLen := (60 + 20) * 2
+	if info.ContentType != "audio/wav" || len(data) != 44+pcmLen || info.Size != int64(len(data)) {
+		t.Fatalf("Unexpected output: %+v, %d bytes", info, len(data))
+	}
+	if size := binary.LittleEndian.Uint32(data[40:44]); int(size) != pcmLen {
+		t.Errorf("Expected WAV data size %d, got %d", pcmLen, size)
+	}
+
+	// 检查拼接顺序：a(10采样) 静音(10) bb(20) 静音(10) ccc(30)
+	sample := func(i int) uint16 { return binary.LittleEndian.Uint16(data[44+i*2:]) }
+	if sample(0) != 'a' || sample(10) != 0 || sample(20) != 'b' || sample(50) != 'c' {
+		t.Errorf("Paragraphs were not concatenated in order")
+	}
+}
+
+// countingSynthesizer 记录同时进行中的合成数
+type countingSynthesizer struct {
+	mu        sync.Mutex
+	active    int
+	maxActive int
+}
+
+func (c *countingSynthesizer) Synthesize(ctx interface{}, text string, speakerID int, speed float32) ([]byte, error) {
+	c.mu.Lock()
+	c.active++
+	if c.active > c.maxActive {
+		c.maxActive = c.active
+	}
+	c.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	c.mu.Lock()
+	c.active--
+	c.mu.Unlock()
+	return make([]byte, 20), nil
+}
+
+func (c *countingSynthesizer) GetSampleRate() int { return 1000 }
+
+func TestSynthesizeParagraphs_BoundedAndOrdered(t *testing.T) {
+	synth := &countingSynthesizer{}
+	paragraphs := make([]string, 20)
+	for i := range paragraphs {
+		paragraphs[i] = "p"
+	}
+
+	next := 0
+	for pending := range synthesizeParagraphs(context.Background(), synth, paragraphs, &TTSRequest{}, 3) {
+		// 模拟较慢的写出，让合成协程有机会超前
+		time.Sleep(5 * time.Millisecond)
+		item := <-pending
+		if item.err != nil || item.index != next {
+			t.Fatalf("Expected paragraph %d, got %d (err %v)", next, item.index, item.err)
+		}
+		next++
+	}
+
+	if next != len(paragraphs) {
+		t.Errorf("Expected %d paragraphs, got %d", len(paragraphs), next)
+	}
+	if synth.maxActive > 3 {
+		t.Errorf("Expected at most 3 paragraphs in flight, got %d", synth.maxActive)
+	}
+}
+
+func TestManager_TTSJobSampleRate(t *testing.T) {
+	synth := &mockSynthesizer{}
+
+	m := newTestManager(t, t.TempDir(), nil)
+	m.RegisterProcessor(TypeTTS, NewTTSProcessor(synth, nil, newTTSTestConfig()))
+	m.Start()
+	defer m.Close()
+
+	run := func() TTSResult {
+		input, _ := json.Marshal(&TTSRequest{Text: "a"})
+		job, err := m.Submit(&Job{Type: TypeTTS}, input)
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		var result TTSResult
+		if err := json.Unmarshal(waitForStatus(t, m, job.ID, StatusSucceeded).Result, &result); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+		return result
+	}
+
+	if result := run(); result.SampleRate != 1000 {
+		t.Errorf("Expected sample rate 1000, got %d", result.SampleRate)
+	}
+
+	// 模型热加载后的任务使用新模型的采样率
+	synth.mu.Lock()
+	synth.rate = 2000
+	synth.mu.Unlock()
+	if result := run(); result.SampleRate != 2000 || result.Duration != 0.005 {
+		t.Errorf("Expected sample rate 2000 and duration 0.005, got %d, %v", result.SampleRate, result.Duration)
+	}
+}
+
+func TestManager_TTSJobWithModel(t *testing.T) {
+	english := &mockSynthesizer{rate: 2000}
+	models := func(name string) (Synthesizer, error) {
+		if name == "kokoro-en" {
+			return english, nil
+		}
+		return nil, errors.New("model not found: " + name)
+	}
+
+	m := newTestManager(t, t.TempDir(), nil)
+	m.RegisterProcessor(TypeTTS, NewTTSProcessor(&mockSynthesizer{}, models, newTTSTestConfig()))
+	m.Start()
+	defer m.Close()
+
+	input, _ := json.Marshal(&TTSRequest{Text: "hello", Model: "kokoro-en"})
+	job, err := m.Submit(&Job{Type: TypeTTS}, input)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	var result TTSResult
+	if err := json.Unmarshal(waitForStatus(t, m, job.ID, StatusSucceeded).Result, &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if len(english.texts) != 1 || result.SampleRate != 2000 {
+		t.Errorf("Expected synthesis with kokoro-en at 2000Hz, got %v at %d", english.texts, result.SampleRate)
+	}
+
+	input, _ = json.Marshal(&TTSRequest{Text: "hello", Model: "missing"})
+	job, err = m.Submit(&Job{Type: TypeTTS}, input)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitForStatus(t, m, job.ID, StatusFailed)
+}
+
+func TestManager_TTSJobFailure(t *testing.T) {
+	synth := &mockSynthesizer{fail: "bad"}
+
+	m := newTestManager(t, t.TempDir(), nil)
+	m.RegisterProcessor(TypeTTS, NewTTSProcessor(synth, nil, newTTSTestConfig()))
+	m.Start()
+	defer m.Close()
+
+	input, _ := json.Marshal(&TTSRequest{Text: "good\nbad\ngood"})
+	job, err := m.Submit(&Job{Type: TypeTTS}, input)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	done := waitForStatus(t, m, job.ID, StatusFailed)
+
+	if done.Output != nil {
+		t.Errorf("Expected no output for failed job, got %+v", done.Output)
+	}
+	if _, _, err := m.OpenOutput(job.ID); !errors.Is(err, ErrNoOutput) {
+		t.Errorf("Expected ErrNoOutput, got %v", err)
+	}
+}
+
+func TestTTSProcessor_Cancelled(t *testing.T) {
+	processor := NewTTSProcessor(&mockSynthesizer{}, nil, newTTSTestConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	input, _ := json.Marshal(&TTSRequest{Text: "a\nb"})
+	task := &Task{
+		Job:   &Job{ID: "test"},
+		Input: input,
+		saveOutput: func(info OutputInfo, write func(f *os.File) error) error {
+			f, err := os.CreateTemp(t.TempDir(), "output")
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return write(f)
+		},
+	}
+	if _, err := processor(ctx, task); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
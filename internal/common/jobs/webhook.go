@@ -0,0 +1,193 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// 回调请求头
+const (
+	HeaderWebhookEvent     = "X-AeroSpeech-Event"
+	HeaderWebhookJobID     = "X-AeroSpeech-Job-Id"
+	HeaderWebhookTimestamp = "X-AeroSpeech-Timestamp"
+	HeaderWebhookSignature = "X-AeroSpeech-Signature"
+)
+
+// WebhookPayload 回调请求体
+type WebhookPayload struct {
+	Event string `json:"event"` // job.succeeded / job.failed / job.cancelled
+	Job   *Job   `json:"job"`
+}
+
+// WebhookSender 回调发送器
+type WebhookSender struct {
+	config *config.WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookSender 创建回调发送器
+func NewWebhookSender(cfg *config.WebhookConfig) *WebhookSender {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	if !cfg.AllowPrivateNetworks {
+		// 在DNS解析之后按实际连接的地址检查，避免通过解析到内网地址的域名绕过
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("webhook address %s is not allowed", host)
+			}
+			return nil
+		}
+	}
+	return &WebhookSender{
+		config: cfg,
+		client: &http.Client{
+			Timeout: timeout,
+			// 不使用代理：代理地址会绕过按连接地址的检查
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// 不跟随重定向，避免绕过主机白名单
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// reservedNetworks 标准库未归入私有地址、但同样不可作为回调目标的网段
+var reservedNetworks = []*net.IPNet{
+	{IP: net.IPv4(0, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},     // 0.0.0.0/8 本网络
+	{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}, // 100.64.0.0/10 运营商级NAT
+}
+
+// isPrivateIP 是否为回环、私有、链路本地、运营商级NAT或本网络地址，IPv4映射的IPv6地址按IPv4检查
+func isPrivateIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateURL 校验回调地址（仅允许http/https，配置了白名单时主机必须在白名单中）。
+// 未允许内网地址时拒绝内网IP，域名在发送时按解析结果检查
+func (s *WebhookSender) ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid webhook url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook url must use http or https")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if ip := net.ParseIP(host); ip != nil && !s.config.AllowPrivateNetworks && isPrivateIP(ip) {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	if len(s.config.AllowedHosts) == 0 {
+		return nil
+	}
+	for _, allowed := range s.config.AllowedHosts {
+		if strings.ToLower(allowed) == host {
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook host %s is not allowed", host)
+}
+
+// Backoff 第attempt次重试前的等待时间（指数退避）
+func (s *WebhookSender) Backoff(attempt int) time.Duration {
+	initial := time.Duration(s.config.InitialBackoff) * time.Second
+	if initial <= 0 {
+		initial = time.Second
+	}
+	maxBackoff := time.Duration(s.config.MaxBackoff) * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+
+	backoff := initial
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
+
+// Send 发送一次回调请求，非2xx响应视为失败
+func (s *WebhookSender) Send(ctx context.Context, rawURL, event, jobID string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, event)
+	req.Header.Set(HeaderWebhookJobID, jobID)
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	if s.config.Secret != "" {
+		req.Header.Set(HeaderWebhookSignature, "sha256="+SignPayload(s.config.Secret, timestamp, payload))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignPayload 计算回调签名：HMAC-SHA256(secret, timestamp + "." + body) 的十六进制
+func SignPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature 校验回调签名（供接收方使用）
+func VerifySignature(secret, timestamp string, payload []byte, signature string) bool {
+	expected := "sha256=" + SignPayload(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -64,6 +65,7 @@ type Config struct {
 
 // defaultLogger 默认日志记录器
 type defaultLogger struct {
+	mu     sync.RWMutex
 	level  LogLevel
 	writer io.Writer
 	format string
@@ -79,6 +81,15 @@ var (
 	}
 )
 
+// ParseLevel 解析日志级别字符串
+func ParseLevel(level string) (LogLevel, error) {
+	l, ok := levelMap[level]
+	if !ok {
+		return InfoLevel, fmt.Errorf("invalid log level: %s, must be debug, info, warn or error", level)
+	}
+	return l, nil
+}
+
 // InitLogger 初始化日志系统
 func InitLogger(config Config) error {
 	level, ok := levelMap[config.Level]
@@ -149,6 +160,9 @@ func GetLogger() Logger {
 
 // log 记录日志
 func (l *defaultLogger) log(level LogLevel, message string) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
 	if level < l.level {
 		return
 	}
@@ -250,12 +264,44 @@ func (l *defaultLogger) Errorf(format string, args ...interface{}) {
 
 // SetLevel 设置日志级别
 func (l *defaultLogger) SetLevel(level LogLevel) {
+	l.mu.Lock()
 	l.level = level
+	l.mu.Unlock()
 }
 
 // SetOutput 设置输出
 func (l *defaultLogger) SetOutput(w io.Writer) {
+	l.mu.Lock()
 	l.writer = w
+	l.mu.Unlock()
+}
+
+// SetFormat 设置日志格式
+func (l *defaultLogger) SetFormat(format string) {
+	l.mu.Lock()
+	l.format = format
+	l.mu.Unlock()
+}
+
+// SetLevel 设置全局日志级别（用于配置热加载）
+func SetLevel(level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	GetLogger().SetLevel(l)
+	return nil
+}
+
+// SetFormat 设置全局日志格式（用于配置热加载）
+func SetFormat(format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid log format: %s, must be text or json", format)
+	}
+	if l, ok := GetLogger().(*defaultLogger); ok {
+		l.SetFormat(format)
+	}
+	return nil
 }
 
 // 便捷函数
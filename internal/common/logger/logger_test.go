@@ -422,3 +422,30 @@ func TestEscapeJSON(t *testing.T) {
 	}
 }
 
+
+func TestReconfigure(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := InitLogger(Config{Level: "info", Format: "text", Output: "console"}); err != nil {
+		t.Fatalf("InitLogger() error = %v", err)
+	}
+	GetLogger().SetOutput(&buf)
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	if err := SetFormat("json"); err != nil {
+		t.Fatalf("SetFormat() error = %v", err)
+	}
+	Debug("debug message")
+	if !bytes.HasPrefix(buf.Bytes(), []byte(`{"timestamp"`)) {
+		t.Errorf("Expected JSON debug output, got %q", buf.String())
+	}
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("Expected error for invalid level")
+	}
+	if err := SetFormat("xml"); err == nil {
+		t.Error("Expected error for invalid format")
+	}
+}
@@ -0,0 +1,413 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// 权限范围
+const (
+	ScopeSTT   = "stt"
+	ScopeTTS   = "tts"
+	ScopeAdmin = "admin"
+)
+
+// gin上下文中保存认证信息的键
+const (
+	ContextKeyPrincipal = "auth_principal"
+	ContextKeyTenantID  = "auth_tenant_id"
+	ContextKeyScopes    = "auth_scopes"
+	ContextKeyAuthError = "auth_error"
+)
+
+// tenantContextKey 请求context中保存租户ID的键（供net/http中间件读取）
+type tenantContextKey struct{}
+
+// Principal 认证主体
+type Principal struct {
+	ID       string   `json:"id"`
+	TenantID string   `json:"tenant_id,omitempty"`
+	Scopes   []string `json:"scopes"`
+	Method   string   `json:"method"` // "api_key" 或 "jwt"
+}
+
+// HasScope 检查是否拥有指定权限（admin拥有全部权限）
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKey 已加载的API密钥
+type apiKey struct {
+	name   string
+	hash   []byte
+	scopes []string
+}
+
+// Authenticator API密钥认证器
+type Authenticator struct {
+	enabled    bool
+	headerName string
+	queryParam string
+	keys       []apiKey
+	jwt        *JWTValidator
+	mu         sync.RWMutex
+}
+
+// keyFile 密钥文件格式
+type keyFile struct {
+	Keys []config.APIKeyConfig `json:"keys"`
+}
+
+// HashAPIKey 计算API密钥的SHA-256十六进制摘要（配置中存储的形式）
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAuthenticator 创建认证器
+func NewAuthenticator(cfg *config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		enabled:    cfg.Enabled,
+		headerName: cfg.HeaderName,
+		queryParam: cfg.QueryParam,
+	}
+	if a.headerName == "" {
+		a.headerName = "X-API-Key"
+	}
+	if a.queryParam == "" {
+		a.queryParam = "token"
+	}
+
+	keys := append([]config.APIKeyConfig{}, cfg.Keys...)
+	if cfg.KeyFile != "" {
+		fileKeys, err := LoadKeyFile(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	if err := a.SetKeys(keys); err != nil {
+		return nil, err
+	}
+
+	if cfg.Enabled && cfg.JWT.Enabled {
+		validator, err := NewJWTValidator(&cfg.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create JWT validator: %w", err)
+		}
+		validator.StartAutoRefresh()
+		a.jwt = validator
+	}
+
+	return a, nil
+}
+
+// Close 释放认证器资源
+func (a *Authenticator) Close() {
+	if a != nil && a.jwt != nil {
+		a.jwt.Stop()
+	}
+}
+
+// GetJWTValidator 获取JWT验证器（未启用时为nil）
+func (a *Authenticator) GetJWTValidator() *JWTValidator {
+	return a.jwt
+}
+
+// LoadKeyFile 从JSON文件加载API密钥
+func LoadKeyFile(path string) ([]config.APIKeyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+
+	for i := range f.Keys {
+		if err := config.ValidateAPIKey(&f.Keys[i]); err != nil {
+			return nil, fmt.Errorf("key file %s, keys[%d]: %w", path, i, err)
+		}
+	}
+
+	return f.Keys, nil
+}
+
+// SetKeys 替换当前的密钥集合
+func (a *Authenticator) SetKeys(keys []config.APIKeyConfig) error {
+	loaded := make([]apiKey, 0, len(keys))
+	for _, k := range keys {
+		hash, err := hex.DecodeString(strings.ToLower(k.KeyHash))
+		if err != nil || len(hash) != sha256.Size {
+			return fmt.Errorf("invalid key_hash for key %s", k.Name)
+		}
+		loaded = append(loaded, apiKey{
+			name:   k.Name,
+			hash:   hash,
+			scopes: append([]string{}, k.Scopes...),
+		})
+	}
+
+	a.mu.Lock()
+	a.keys = loaded
+	a.mu.Unlock()
+	return nil
+}
+
+// IsEnabled 是否启用认证
+func (a *Authenticator) IsEnabled() bool {
+	return a != nil && a.enabled
+}
+
+// lookup 根据明文密钥查找匹配的密钥（常量时间比较摘要）
+func (a *Authenticator) lookup(key string) *apiKey {
+	sum := sha256.Sum256([]byte(key))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var found *apiKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].hash) == 1 {
+			found = &a.keys[i]
+		}
+	}
+	return found
+}
+
+// extractKey 从请求头、Authorization Bearer或查询参数中提取凭证
+func (a *Authenticator) extractKey(c *gin.Context) string {
+	if key := c.GetHeader(a.headerName); key != "" {
+		return key
+	}
+	if authz := c.GetHeader("Authorization"); len(authz) > 7 && strings.EqualFold(authz[:7], "Bearer ") {
+		return strings.TrimSpace(authz[7:])
+	}
+	return c.Query(a.queryParam)
+}
+
+// isJWT 判断凭证是否为JWT格式
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// authenticate 校验凭证并返回认证主体
+func (a *Authenticator) authenticate(credential string) (*Principal, error) {
+	if a.jwt != nil && isJWT(credential) {
+		claims, err := a.jwt.Validate(credential)
+		if err != nil {
+			return nil, err
+		}
+		return &Principal{
+			ID:       claims.Subject,
+			TenantID: claims.TenantID,
+			Scopes:   claims.Scopes,
+			Method:   "jwt",
+		}, nil
+	}
+
+	matched := a.lookup(credential)
+	if matched == nil {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	return &Principal{
+		ID:     matched.name,
+		Scopes: matched.scopes,
+		Method: "api_key",
+	}, nil
+}
+
+// Middleware 认证中间件：校验请求中携带的凭证并将认证主体写入上下文
+// 未携带凭证的请求会继续传递，由RequireScope决定是否拒绝
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a.identify(c)
+		if a.rejectInvalid(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Identify 同Middleware，但凭证无效时只记录错误、不拒绝请求，由之后的RejectInvalid拒绝。
+// 两者之间可以插入限流，使携带无效凭证的请求同样受（按IP的）限流约束
+func (a *Authenticator) Identify() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a.identify(c)
+		c.Next()
+	}
+}
+
+// RejectInvalid 拒绝Identify记录了凭证错误的请求
+func (a *Authenticator) RejectInvalid() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.rejectInvalid(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// identify 校验凭证，有效时写入认证主体，无效时记录错误
+func (a *Authenticator) identify(c *gin.Context) {
+	if !a.IsEnabled() {
+		return
+	}
+	key := a.extractKey(c)
+	if key == "" {
+		return
+	}
+
+	principal, err := a.authenticate(key)
+	if err != nil {
+		c.Set(ContextKeyAuthError, err)
+		return
+	}
+
+	c.Set(ContextKeyPrincipal, principal)
+	c.Set(ContextKeyScopes, principal.Scopes)
+	if principal.TenantID != "" {
+		c.Set(ContextKeyTenantID, principal.TenantID)
+		c.Request = c.Request.WithContext(contextWithTenant(c.Request.Context(), principal.TenantID))
+	}
+}
+
+// rejectInvalid 凭证无效时写入错误响应并返回true
+func (a *Authenticator) rejectInvalid(c *gin.Context) bool {
+	value, _ := c.Get(ContextKeyAuthError)
+	err, ok := value.(error)
+	if !ok {
+		return false
+	}
+	abortWithError(c, http.StatusUnauthorized, utils.ErrCodeAuthFailed, "authentication failed", err.Error())
+	return true
+}
+
+// RequireScope 要求请求拥有指定权限
+func (a *Authenticator) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.Authorize(c, scope) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAnyScope 要求请求拥有指定权限中的任意一个（用于STT和TTS共用的只读接口）
+func (a *Authenticator) RequireAnyScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.IsEnabled() {
+			c.Next()
+			return
+		}
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, utils.ErrCodeAuthFailed, "authentication required", "missing credentials")
+			return
+		}
+		for _, scope := range scopes {
+			if principal.HasScope(scope) {
+				c.Next()
+				return
+			}
+		}
+		abortWithError(c, http.StatusForbidden, utils.ErrCodeForbidden, "forbidden", fmt.Sprintf("one of scopes %q is required", scopes))
+	}
+}
+
+// Authorize 检查请求是否拥有指定权限，失败时写入错误响应并返回false
+func (a *Authenticator) Authorize(c *gin.Context, scope string) bool {
+	if !a.IsEnabled() {
+		return true
+	}
+
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		abortWithError(c, http.StatusUnauthorized, utils.ErrCodeAuthFailed, "authentication required", "missing credentials")
+		return false
+	}
+
+	if !principal.HasScope(scope) {
+		abortWithError(c, http.StatusForbidden, utils.ErrCodeForbidden, "forbidden", fmt.Sprintf("scope %q is required", scope))
+		return false
+	}
+
+	return true
+}
+
+// GetPrincipal 从gin上下文获取认证主体
+func GetPrincipal(c *gin.Context) (*Principal, bool) {
+	value, exists := c.Get(ContextKeyPrincipal)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// GetTenantID 从gin上下文获取租户ID（未认证或无租户时为空）
+func GetTenantID(c *gin.Context) string {
+	return c.GetString(ContextKeyTenantID)
+}
+
+// GetScopes 从gin上下文获取权限范围
+func GetScopes(c *gin.Context) []string {
+	return c.GetStringSlice(ContextKeyScopes)
+}
+
+// contextWithTenant 将租户ID写入请求context
+func contextWithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext 从请求context获取租户ID
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
+
+// GetStats 获取统计信息
+func (a *Authenticator) GetStats() map[string]interface{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return map[string]interface{}{
+		"enabled":     a.enabled,
+		"header_name": a.headerName,
+		"query_param": a.queryParam,
+		"total_keys":  len(a.keys),
+		"jwt_enabled": a.jwt != nil,
+	}
+}
+
+// abortWithError 以统一格式返回错误并终止请求
+func abortWithError(c *gin.Context, status int, code utils.ErrorCode, message, details string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"code":    status,
+		"message": message,
+		"error": gin.H{
+			"type":    string(code),
+			"details": details,
+		},
+	})
+}
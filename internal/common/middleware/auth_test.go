@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	auth, err := NewAuthenticator(&config.AuthConfig{
+		Enabled: true,
+		Keys: []config.APIKeyConfig{
+			{Name: "stt-client", KeyHash: HashAPIKey("stt-secret"), Scopes: []string{ScopeSTT}},
+			{Name: "operator", KeyHash: HashAPIKey("admin-secret"), Scopes: []string{ScopeAdmin}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	return auth
+}
+
+func newAuthTestRouter(auth *Authenticator, scope string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(auth.Middleware())
+	engine.GET("/protected", auth.RequireScope(scope), func(c *gin.Context) {
+		principal, _ := GetPrincipal(c)
+		c.JSON(http.StatusOK, gin.H{"id": principal.ID})
+	})
+	return engine
+}
+
+func decodeErrorType(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		Error struct {
+			Type string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp.Error.Type
+}
+
+func TestHashAPIKey(t *testing.T) {
+	hash := HashAPIKey("secret")
+	if len(hash) != 64 {
+		t.Errorf("Expected 64 hex characters, got %d", len(hash))
+	}
+	if hash == HashAPIKey("other") {
+		t.Error("Expected different keys to produce different hashes")
+	}
+}
+
+func TestAuthenticator_Header(t *testing.T) {
+	engine := newAuthTestRouter(newTestAuthenticator(t), ScopeSTT)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("X-API-Key", "stt-secret")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAuthenticator_QueryToken(t *testing.T) {
+	engine := newAuthTestRouter(newTestAuthenticator(t), ScopeSTT)
+
+	req := httptest.NewRequest("GET", "/protected?token=stt-secret", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAuthenticator_MissingKey(t *testing.T) {
+	engine := newAuthTestRouter(newTestAuthenticator(t), ScopeSTT)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+	if got := decodeErrorType(t, w); got != "AUTH_FAILED" {
+		t.Errorf("Expected error type AUTH_FAILED, got %s", got)
+	}
+}
+
+func TestAuthenticator_InvalidKey(t *testing.T) {
+	engine := newAuthTestRouter(newTestAuthenticator(t), ScopeSTT)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("X-API-Key", "wrong")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestAuthenticator_Forbidden(t *testing.T) {
+	engine := newAuthTestRouter(newTestAuthenticator(t), ScopeTTS)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("X-API-Key", "stt-secret")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+	if got := decodeErrorType(t, w); got != "FORBIDDEN" {
+		t.Errorf("Expected error type FORBIDDEN, got %s", got)
+	}
+}
+
+func TestAuthenticator_RequireAnyScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	auth := newTestAuthenticator(t)
+	engine.Use(auth.Middleware())
+	engine.GET("/models", auth.RequireAnyScope(ScopeSTT, ScopeTTS), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"stt-secret", http.StatusOK},
+		{"admin-secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/models", nil)
+		if tt.key != "" {
+			req.Header.Set("X-API-Key", tt.key)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("key %q: expected status %d, got %d", tt.key, tt.want, w.Code)
+		}
+	}
+}
+
+func TestAuthenticator_AdminHasAllScopes(t *testing.T) {
+	engine := newAuthTestRouter(newTestAuthenticator(t), ScopeTTS)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("X-API-Key", "admin-secret")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAuthenticator_Disabled(t *testing.T) {
+	auth, err := NewAuthenticator(&config.AuthConfig{Enabled: false})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(auth.Middleware())
+	engine.GET("/protected", auth.RequireScope(ScopeAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAuthenticator_KeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `{"keys": [{"name": "tts-client", "key_hash": "` + HashAPIKey("tts-secret") + `", "scopes": ["tts"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	auth, err := NewAuthenticator(&config.AuthConfig{Enabled: true, KeyFile: path})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	engine := newAuthTestRouter(auth, ScopeTTS)
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("X-API-Key", "tts-secret")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestLoadKeyFile_InvalidScope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := `{"keys": [{"name": "bad", "key_hash": "` + HashAPIKey("x") + `", "scopes": ["root"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	if _, err := LoadKeyFile(path); err == nil {
+		t.Error("Expected error for invalid scope")
+	}
+}
@@ -0,0 +1,460 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+)
+
+// jwk JSON Web Key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// jwkSet JSON Web Key Set
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwtHeader JWT头部
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// TokenClaims 已验证的JWT声明
+type TokenClaims struct {
+	Subject  string
+	TenantID string
+	Scopes   []string
+	Raw      map[string]interface{}
+}
+
+// JWTValidator 基于JWKS的JWT验证器
+type JWTValidator struct {
+	config      config.JWTConfig
+	keys        map[string]crypto.PublicKey
+	mu          sync.RWMutex
+	lastRefresh time.Time
+	httpClient  *http.Client
+	now         func() time.Time
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
+
+// minRefreshInterval 遇到未知kid时两次刷新JWKS的最小间隔
+const minRefreshInterval = 30 * time.Second
+
+// NewJWTValidator 创建JWT验证器并加载JWKS
+func NewJWTValidator(cfg *config.JWTConfig) (*JWTValidator, error) {
+	v := &JWTValidator{
+		config:     *cfg,
+		keys:       make(map[string]crypto.PublicKey),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+		stopChan:   make(chan struct{}),
+	}
+	if v.config.TenantClaim == "" {
+		v.config.TenantClaim = "tenant_id"
+	}
+	if v.config.ScopeClaim == "" {
+		v.config.ScopeClaim = "scope"
+	}
+
+	if err := v.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Refresh 重新加载JWKS
+func (v *JWTValidator) Refresh() error {
+	var data []byte
+	var err error
+
+	if v.config.JWKSFile != "" {
+		data, err = os.ReadFile(v.config.JWKSFile)
+		if err != nil {
+			return fmt.Errorf("failed to read JWKS file %s: %w", v.config.JWKSFile, err)
+		}
+	} else if v.config.JWKSURL != "" {
+		data, err = v.fetchJWKS(v.config.JWKSURL)
+		if err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("jwks_file or jwks_url is required")
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.lastRefresh = v.now()
+	v.mu.Unlock()
+
+	logger.Infof("Loaded %d JWKS keys", len(keys))
+	return nil
+}
+
+// fetchJWKS 从URL获取JWKS
+func (v *JWTValidator) fetchJWKS(url string) ([]byte, error) {
+	resp, err := v.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: status %d", url, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS response: %w", err)
+	}
+	return data, nil
+}
+
+// StartAutoRefresh 定期刷新JWKS（仅在配置了URL时生效）
+func (v *JWTValidator) StartAutoRefresh() {
+	if v.config.JWKSURL == "" || v.config.RefreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(v.config.RefreshInterval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := v.Refresh(); err != nil {
+					logger.Warnf("Failed to refresh JWKS: %v", err)
+				}
+			case <-v.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期刷新
+func (v *JWTValidator) Stop() {
+	v.stopOnce.Do(func() {
+		close(v.stopChan)
+	})
+}
+
+// parseJWKS 解析JWKS中的RSA和EC公钥
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		pub, err := k.publicKey()
+		if err != nil {
+			logger.Warnf("Skipping JWKS key %q: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey 将JWK转换为公钥
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+// decodeBigInt 解码base64url编码的大整数
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// getKey 获取kid对应的公钥，未知kid时按需刷新JWKS
+func (v *JWTValidator) getKey(kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	if !ok && kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			key, ok = k, true
+		}
+	}
+	canRefresh := v.config.JWKSURL != "" && v.now().Sub(v.lastRefresh) > minRefreshInterval
+	v.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if canRefresh {
+		if err := v.Refresh(); err != nil {
+			return nil, err
+		}
+		v.mu.RLock()
+		key, ok = v.keys[kid]
+		v.mu.RUnlock()
+		if ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown key id: %q", kid)
+}
+
+// Validate 验证JWT签名和声明
+func (v *JWTValidator) Validate(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	var header jwtHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+
+	key, err := v.getKey(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token payload: %w", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(payloadJSON, &raw); err != nil {
+		return nil, fmt.Errorf("malformed token payload: %w", err)
+	}
+
+	if err := v.validateClaims(raw); err != nil {
+		return nil, err
+	}
+
+	claims := &TokenClaims{Raw: raw}
+	claims.Subject, _ = raw["sub"].(string)
+	claims.TenantID, _ = raw[v.config.TenantClaim].(string)
+	claims.Scopes = claimStrings(raw[v.config.ScopeClaim])
+
+	return claims, nil
+}
+
+// validateClaims 校验exp/nbf/iss/aud
+func (v *JWTValidator) validateClaims(raw map[string]interface{}) error {
+	now := v.now()
+	skew := time.Duration(v.config.ClockSkew) * time.Second
+
+	exp, ok := raw["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("token has no exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(skew)) {
+		return fmt.Errorf("token is expired")
+	}
+
+	if nbf, ok := raw["nbf"].(float64); ok {
+		if now.Add(skew).Before(time.Unix(int64(nbf), 0)) {
+			return fmt.Errorf("token is not valid yet")
+		}
+	}
+
+	if v.config.Issuer != "" {
+		if iss, _ := raw["iss"].(string); iss != v.config.Issuer {
+			return fmt.Errorf("unexpected issuer: %q", iss)
+		}
+	}
+
+	if v.config.Audience != "" {
+		matched := false
+		for _, aud := range claimStrings(raw["aud"]) {
+			if aud == v.config.Audience {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("token audience does not include %q", v.config.Audience)
+		}
+	}
+
+	return nil
+}
+
+// claimStrings 将字符串（空格分隔）或字符串数组声明转换为切片
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// ecCurves ES算法对应的曲线
+var ecCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+}
+
+// verifySignature 按算法验证签名
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+
+	digest := hashInput(hash, signingInput)
+
+	switch alg[:2] {
+	case "RS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature); err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		// 算法与曲线必须对应：ES256只能使用P-256，ES384只能使用P-384
+		if curve := ecKey.Curve.Params().Name; curve != ecCurves[alg] {
+			return fmt.Errorf("key curve %s does not match algorithm %s", curve, alg)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+	}
+
+	return nil
+}
+
+// hashInput 计算签名输入的摘要
+func hashInput(hash crypto.Hash, input string) []byte {
+	switch hash {
+	case crypto.SHA384:
+		sum := sha512.Sum384([]byte(input))
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512([]byte(input))
+		return sum[:]
+	default:
+		sum := sha256.Sum256([]byte(input))
+		return sum[:]
+	}
+}
@@ -0,0 +1,357 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// b64 base64url编码（无填充）
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// writeJWKS 将公钥写入临时JWKS文件
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatalf("Failed to marshal JWKS: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   b64(key.X.FillBytes(make([]byte, 32))),
+		"y":   b64(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// signRS256 生成RS256签名的JWT
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegments(t, map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"}, claims)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return input + "." + b64(sig)
+}
+
+// signES256 生成ES256签名的JWT
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegments(t, map[string]string{"alg": "ES256", "kid": kid}, claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return input + "." + b64(sig)
+}
+
+func encodeSegments(t *testing.T, header map[string]string, claims map[string]interface{}) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("Failed to marshal header: %v", err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Failed to marshal claims: %v", err)
+	}
+	return b64(h) + "." + b64(c)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":       "user-1",
+		"iss":       "https://sso.example.com",
+		"aud":       []string{"speech-api", "other"},
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": "tenant-a",
+		"scope":     "stt tts",
+	}
+}
+
+func newTestJWTValidator(t *testing.T) (*JWTValidator, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	path := writeJWKS(t, rsaJWK("k1", &key.PublicKey))
+
+	v, err := NewJWTValidator(&config.JWTConfig{
+		Enabled:  true,
+		JWKSFile: path,
+		Issuer:   "https://sso.example.com",
+		Audience: "speech-api",
+	})
+	if err != nil {
+		t.Fatalf("NewJWTValidator() error = %v", err)
+	}
+	return v, key
+}
+
+func TestJWTValidator_Valid(t *testing.T) {
+	v, key := newTestJWTValidator(t)
+
+	claims, err := v.Validate(signRS256(t, key, "k1", validClaims()))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if claims.Subject != "user-1" {
+		t.Errorf("Expected subject user-1, got %s", claims.Subject)
+	}
+	if claims.TenantID != "tenant-a" {
+		t.Errorf("Expected tenant tenant-a, got %s", claims.TenantID)
+	}
+	if len(claims.Scopes) != 2 || claims.Scopes[0] != "stt" || claims.Scopes[1] != "tts" {
+		t.Errorf("Unexpected scopes: %v", claims.Scopes)
+	}
+}
+
+func TestJWTValidator_Rejects(t *testing.T) {
+	v, key := newTestJWTValidator(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	wrongAud := validClaims()
+	wrongAud["aud"] = "someone-else"
+
+	wrongIss := validClaims()
+	wrongIss["iss"] = "https://evil.example.com"
+
+	noExp := validClaims()
+	delete(noExp, "exp")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", signRS256(t, key, "k1", expired)},
+		{"wrong audience", signRS256(t, key, "k1", wrongAud)},
+		{"wrong issuer", signRS256(t, key, "k1", wrongIss)},
+		{"missing exp", signRS256(t, key, "k1", noExp)},
+		{"wrong signing key", signRS256(t, otherKey, "k1", validClaims())},
+		{"unknown kid", signRS256(t, key, "k2", validClaims())},
+		{"alg none", encodeSegments(t, map[string]string{"alg": "none", "kid": "k1"}, validClaims()) + "."},
+		{"malformed", "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.Validate(tt.token); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestJWTValidator_ES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	path := writeJWKS(t, ecJWK("ec1", &key.PublicKey))
+
+	v, err := NewJWTValidator(&config.JWTConfig{Enabled: true, JWKSFile: path, Audience: "speech-api"})
+	if err != nil {
+		t.Fatalf("NewJWTValidator() error = %v", err)
+	}
+
+	if _, err := v.Validate(signES256(t, key, "ec1", validClaims())); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestJWTValidator_ECCurveMismatch(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	jwk := map[string]string{
+		"kty": "EC",
+		"kid": "ec384",
+		"crv": "P-384",
+		"x":   b64(key.X.FillBytes(make([]byte, 48))),
+		"y":   b64(key.Y.FillBytes(make([]byte, 48))),
+	}
+	path := writeJWKS(t, jwk)
+
+	v, err := NewJWTValidator(&config.JWTConfig{Enabled: true, JWKSFile: path, Audience: "speech-api"})
+	if err != nil {
+		t.Fatalf("NewJWTValidator() error = %v", err)
+	}
+
+	// P-384密钥的有效ECDSA签名，但头部声明ES256
+	input := encodeSegments(t, map[string]string{"alg": "ES256", "kid": "ec384"}, validClaims())
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	sig := append(r.FillBytes(make([]byte, 48)), s.FillBytes(make([]byte, 48))...)
+	if _, err := v.Validate(input + "." + b64(sig)); err == nil {
+		t.Error("Expected ES256 token signed with a P-384 key to be rejected")
+	}
+}
+
+func TestJWTValidator_JWKSURL(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{rsaJWK("remote", &key.PublicKey)},
+		})
+	}))
+	defer server.Close()
+
+	v, err := NewJWTValidator(&config.JWTConfig{Enabled: true, JWKSURL: server.URL, Audience: "speech-api"})
+	if err != nil {
+		t.Fatalf("NewJWTValidator() error = %v", err)
+	}
+	defer v.Stop()
+
+	if _, err := v.Validate(signRS256(t, key, "remote", validClaims())); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestAuthenticator_BearerJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	path := writeJWKS(t, rsaJWK("k1", &key.PublicKey))
+
+	auth, err := NewAuthenticator(&config.AuthConfig{
+		Enabled: true,
+		JWT: config.JWTConfig{
+			Enabled:  true,
+			JWKSFile: path,
+			Audience: "speech-api",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	defer auth.Close()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(auth.Middleware())
+	engine.GET("/stt", auth.RequireScope(ScopeSTT), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"tenant_id": GetTenantID(c),
+			"scopes":    GetScopes(c),
+			"tenant":    TenantFromContext(c.Request.Context()),
+		})
+	})
+	engine.GET("/admin", auth.RequireScope(ScopeAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	token := signRS256(t, key, "k1", validClaims())
+
+	req := httptest.NewRequest("GET", "/stt", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp["tenant_id"] != "tenant-a" || resp["tenant"] != "tenant-a" {
+		t.Errorf("Expected tenant-a in context, got %v", resp)
+	}
+
+	// 令牌中没有admin权限
+	req = httptest.NewRequest("GET", "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+
+	// WebSocket场景通过查询参数传递JWT
+	req = httptest.NewRequest("GET", "/stt?token="+token, nil)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestRateLimiter_KeysByTenant(t *testing.T) {
+	limiter := NewRateLimiter(true, 1, 1, 100)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(tenantID string) int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req = req.WithContext(contextWithTenant(req.Context(), tenantID))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send("tenant-a"); code != http.StatusOK {
+		t.Errorf("Expected first tenant-a request to pass, got %d", code)
+	}
+	if code := send("tenant-a"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected second tenant-a request to be limited, got %d", code)
+	}
+	if code := send("tenant-b"); code != http.StatusOK {
+		t.Errorf("Expected tenant-b to have its own limiter, got %d", code)
+	}
+}
@@ -11,13 +11,14 @@ import (
 
 // RateLimiter 速率限制器
 type RateLimiter struct {
-	enabled   bool
-	limiters  map[string]*rate.Limiter
-	mu        sync.RWMutex
-	r         rate.Limit
-	b         int
-	maxConns  int
-	connCount int32
+	enabled     bool
+	limiters    map[string]*rate.Limiter
+	mu          sync.RWMutex
+	r           rate.Limit
+	b           int
+	maxConns    int32 // 原子操作：支持热加载修改
+	connCount   int32
+	cleanupOnce sync.Once
 }
 
 // NewRateLimiter 创建新的速率限制器
@@ -27,10 +28,24 @@ func NewRateLimiter(enabled bool, requestsPerSecond int, burstSize int, maxConne
 		limiters: make(map[string]*rate.Limiter),
 		r:        rate.Limit(requestsPerSecond),
 		b:        burstSize,
-		maxConns: maxConnections,
+		maxConns: int32(maxConnections),
 	}
 }
 
+// UpdateLimits 更新速率和连接数限制（用于配置热加载），已有的限制器同步生效
+func (rl *RateLimiter) UpdateLimits(requestsPerSecond int, burstSize int, maxConnections int) {
+	rl.mu.Lock()
+	rl.r = rate.Limit(requestsPerSecond)
+	rl.b = burstSize
+	for _, limiter := range rl.limiters {
+		limiter.SetLimit(rl.r)
+		limiter.SetBurst(rl.b)
+	}
+	rl.mu.Unlock()
+
+	atomic.StoreInt32(&rl.maxConns, int32(maxConnections))
+}
+
 // getLimiter 获取或创建IP的限制器
 func (rl *RateLimiter) getLimiter(ip string) *rate.Limiter {
 	rl.mu.Lock()
@@ -69,13 +84,13 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 		return next
 	}
 
-	// 启动清理协程
-	rl.cleanupLimiters()
+	// 启动清理协程（只启动一次，Middleware可能被多次调用）
+	rl.cleanupOnce.Do(rl.cleanupLimiters)
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// 检查连接数限制
 		currentConns := atomic.LoadInt32(&rl.connCount)
-		if currentConns >= int32(rl.maxConns) {
+		if currentConns >= atomic.LoadInt32(&rl.maxConns) {
 			http.Error(w, "Too many connections", http.StatusTooManyRequests)
 			return
 		}
@@ -88,11 +103,14 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 			atomic.AddInt32(&rl.connCount, -1)
 		}()
 
-		// 获取客户端IP
+		// 获取客户端IP（已认证的租户按租户ID限流）
 		ip := r.RemoteAddr
 		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
 			ip = forwarded
 		}
+		if tenantID := TenantFromContext(r.Context()); tenantID != "" {
+			ip = "tenant:" + tenantID
+		}
 
 		// 检查速率限制
 		limiter := rl.getLimiter(ip)
@@ -110,18 +128,19 @@ func (rl *RateLimiter) GetStats() map[string]interface{} {
 	// 使用原子操作获取连接数
 	currentConns := atomic.LoadInt32(&rl.connCount)
 
-	// 只对limiters map使用读锁
+	// 只对limiters map和速率参数使用读锁
 	rl.mu.RLock()
 	activeLimiters := len(rl.limiters)
+	r, b := rl.r, rl.b
 	rl.mu.RUnlock()
 
 	return map[string]interface{}{
 		"enabled":             rl.enabled,
 		"active_limiters":     activeLimiters,
 		"current_connections": currentConns,
-		"max_connections":     rl.maxConns,
-		"requests_per_second": float64(rl.r),
-		"burst_size":          rl.b,
+		"max_connections":     int(atomic.LoadInt32(&rl.maxConns)),
+		"requests_per_second": float64(r),
+		"burst_size":          b,
 	}
 }
 
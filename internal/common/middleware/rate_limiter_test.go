@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestNewRateLimiter(t *testing.T) {
@@ -57,3 +58,41 @@ func TestRateLimiterGetStats(t *testing.T) {
 	}
 }
 
+
+func TestRateLimiterUpdateLimits(t *testing.T) {
+	limiter := NewRateLimiter(true, 1, 1, 1000)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func() int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if serve() != http.StatusOK || serve() != http.StatusTooManyRequests {
+		t.Fatal("Expected second request to be rate limited with burst 1")
+	}
+
+	// 已存在的限制器也应使用新的限制
+	limiter.UpdateLimits(1000, 10, 1000)
+	time.Sleep(20 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		if code := serve(); code != http.StatusOK {
+			t.Fatalf("Request %d: expected status 200 after update, got %d", i, code)
+		}
+	}
+
+	stats := limiter.GetStats()
+	if stats["burst_size"] != 10 || stats["requests_per_second"] != float64(1000) {
+		t.Errorf("Unexpected stats after update: %v", stats)
+	}
+
+	limiter.UpdateLimits(1000, 10, 0)
+	if code := serve(); code != http.StatusTooManyRequests {
+		t.Errorf("Expected connection limit to apply, got %d", code)
+	}
+}
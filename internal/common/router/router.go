@@ -9,8 +9,9 @@ import (
 
 // Router 路由管理器
 type Router struct {
-	engine      *gin.Engine
-	rateLimiter *middleware.RateLimiter
+	engine        *gin.Engine
+	rateLimiter   *middleware.RateLimiter
+	authenticator *middleware.Authenticator
 }
 
 // NewRouter 创建路由管理器
@@ -43,6 +44,12 @@ func (r *Router) SetupMiddleware() {
 	// 日志中间件
 	r.engine.Use(gin.Logger())
 
+	// 认证中间件：限流之前识别租户（以便按租户限流），凭证无效的请求在限流之后拒绝，
+	// 这类请求按IP限流，避免无效凭证的请求绕过限流
+	if r.authenticator != nil {
+		r.engine.Use(r.authenticator.Identify())
+	}
+
 	// 限流中间件
 	if r.rateLimiter != nil {
 		r.engine.Use(func(c *gin.Context) {
@@ -51,6 +58,35 @@ func (r *Router) SetupMiddleware() {
 			})).ServeHTTP(c.Writer, c.Request)
 		})
 	}
+
+	if r.authenticator != nil {
+		r.engine.Use(r.authenticator.RejectInvalid())
+	}
+}
+
+// SetAuthenticator 设置认证器（需在SetupMiddleware之前调用）
+func (r *Router) SetAuthenticator(authenticator *middleware.Authenticator) {
+	r.authenticator = authenticator
+}
+
+// GetAuthenticator 获取认证器
+func (r *Router) GetAuthenticator() *middleware.Authenticator {
+	return r.authenticator
+}
+
+// RequireScope 返回要求指定权限的中间件（未配置认证器时直接放行）
+func (r *Router) RequireScope(scope string) gin.HandlerFunc {
+	return r.authenticator.RequireScope(scope)
+}
+
+// RequireAnyScope 返回要求任意一个指定权限的中间件（未配置认证器时直接放行）
+func (r *Router) RequireAnyScope(scopes ...string) gin.HandlerFunc {
+	return r.authenticator.RequireAnyScope(scopes...)
+}
+
+// Authorize 检查请求是否拥有指定权限（用于需要动态判断权限的路由）
+func (r *Router) Authorize(c *gin.Context, scope string) bool {
+	return r.authenticator.Authorize(c, scope)
 }
 
 // SetupStaticFiles 设置静态文件服务
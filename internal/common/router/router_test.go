@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/middleware"
 )
 
 func TestNewRouter(t *testing.T) {
@@ -75,3 +77,87 @@ func TestSetupRoutesNil(t *testing.T) {
 	// 不应该panic
 }
 
+
+func TestRequireScopeWithoutAuthenticator(t *testing.T) {
+	router := NewRouter()
+	router.SetupMiddleware()
+	router.SetupRoutes(func(engine *gin.Engine) {
+		engine.GET("/admin", router.RequireScope("admin"), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/admin", nil)
+	w := httptest.NewRecorder()
+	router.engine.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestRequireScopeWithAuthenticator(t *testing.T) {
+	auth, err := middleware.NewAuthenticator(&config.AuthConfig{
+		Enabled: true,
+		Keys: []config.APIKeyConfig{
+			{Name: "client", KeyHash: middleware.HashAPIKey("secret"), Scopes: []string{"stt"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	router := NewRouter()
+	router.SetAuthenticator(auth)
+	router.SetupMiddleware()
+	router.SetupRoutes(func(engine *gin.Engine) {
+		engine.GET("/stt", router.RequireScope("stt"), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/stt", nil)
+	w := httptest.NewRecorder()
+	router.engine.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/stt?token=secret", nil)
+	w = httptest.NewRecorder()
+	router.engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestInvalidCredentialsAreRateLimited(t *testing.T) {
+	auth, err := middleware.NewAuthenticator(&config.AuthConfig{
+		Enabled: true,
+		Keys: []config.APIKeyConfig{
+			{Name: "client", KeyHash: middleware.HashAPIKey("secret"), Scopes: []string{"stt"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	router := NewRouterWithRateLimit(middleware.NewRateLimiter(true, 1, 1, 100))
+	router.SetAuthenticator(auth)
+	router.SetupMiddleware()
+	router.SetupRoutes(func(engine *gin.Engine) {
+		engine.GET("/stt", router.RequireScope("stt"), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	})
+
+	expected := []int{http.StatusUnauthorized, http.StatusTooManyRequests}
+	for i, want := range expected {
+		req := httptest.NewRequest("GET", "/stt?token=wrong", nil)
+		w := httptest.NewRecorder()
+		router.engine.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("Request %d: expected status code %d, got %d", i+1, want, w.Code)
+		}
+	}
+}
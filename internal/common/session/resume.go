@@ -0,0 +1,271 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultResumeBufferSize 默认保留的未确认消息数
+const DefaultResumeBufferSize = 1000
+
+// ResumeOptions 会话恢复参数
+type ResumeOptions struct {
+	Scope      string        // 会话所属的接口，只能在同一接口恢复
+	Grace      time.Duration // 连接断开后保留会话的时长
+	BufferSize int           // 保留的未确认消息数，超出时丢弃最早的消息
+}
+
+// Sequenced 带序号字段的消息（JSON消息），发送时由会话填入序号；
+// 二进制消息同样占用序号，但不携带序号
+type Sequenced interface {
+	WithSeq(seq uint64) interface{}
+}
+
+// queuedMessage 开启恢复的会话发送队列中的消息
+type queuedMessage struct {
+	seq     uint64
+	message interface{}
+}
+
+// resumeState 开启恢复的会话的序号和未确认消息
+type resumeState struct {
+	ResumeOptions
+	token    string
+	seq      uint64               // 最后一条入队消息的序号
+	acked    uint64               // 客户端确认收到的最后序号
+	journal  []queuedMessage      // 序号大于acked的消息，超出BufferSize时丢弃最早的
+	written  uint64               // 已写入当前连接的最后序号
+	replay   bool                 // 新连接尚未重发未确认的消息
+	greeting interface{}          // 重发之前写入新连接的消息
+	resumed  chan *websocket.Conn // 恢复的新连接，由等待恢复的读取循环接收
+	expired  bool                 // 宽限期已过，不能再恢复
+}
+
+// EnableResume 开启会话恢复，返回恢复令牌。须在发送第一条消息之前调用，
+// 之后发送的每条消息依次编号（从1开始）并保留到客户端确认
+func (s *Session) EnableResume(opts ResumeOptions) string {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultResumeBufferSize
+	}
+	token := make([]byte, 16)
+	rand.Read(token)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resume = &resumeState{
+		ResumeOptions: opts,
+		token:         hex.EncodeToString(token),
+		resumed:       make(chan *websocket.Conn, 1),
+	}
+	return s.resume.token
+}
+
+// Resumable 会话是否开启了恢复
+func (s *Session) Resumable() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resume != nil
+}
+
+// enqueue 为消息编号并放入发送队列和未确认消息，调用方持有锁
+func (r *resumeState) enqueue(s *Session, message interface{}) error {
+	seq := r.seq + 1
+	if m, ok := message.(Sequenced); ok {
+		message = m.WithSeq(seq)
+	}
+	item := queuedMessage{seq: seq, message: message}
+	select {
+	case s.SendQueue <- item:
+	default:
+		return ErrSendQueueFull
+	}
+	r.seq = seq
+	r.journal = append(r.journal, item)
+	if len(r.journal) > r.BufferSize {
+		r.journal = r.journal[len(r.journal)-r.BufferSize:]
+	}
+	return nil
+}
+
+// trim 丢弃客户端已确认的消息，调用方持有锁
+func (r *resumeState) trim(seq uint64) {
+	if seq <= r.acked {
+		return
+	}
+	r.acked = seq
+	i := 0
+	for i < len(r.journal) && r.journal[i].seq <= seq {
+		i++
+	}
+	r.journal = r.journal[i:]
+}
+
+// Ack 客户端确认收到序号不超过seq的消息，服务端不再为恢复保留这些消息
+func (s *Session) Ack(seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resume == nil {
+		return ErrResumeNotEnabled
+	}
+	if seq > s.resume.seq {
+		return ErrInvalidSeq
+	}
+	s.resume.trim(seq)
+	return nil
+}
+
+// WaitResume 当前连接conn断开后等待客户端恢复会话：返回恢复的新连接，
+// 未开启恢复、宽限期内没有恢复或会话已关闭时返回nil
+func (s *Session) WaitResume(conn *websocket.Conn) *websocket.Conn {
+	s.mu.Lock()
+	r := s.resume
+	if r == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	// 新连接可能已经接管了会话（客户端先于服务端发现断线）
+	select {
+	case next := <-r.resumed:
+		s.mu.Unlock()
+		return next
+	default:
+	}
+	if s.Conn == conn {
+		s.Conn = nil
+	}
+	s.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+
+	timer := time.NewTimer(r.Grace)
+	defer timer.Stop()
+	select {
+	case next := <-r.resumed:
+		return next
+	case <-s.closeChan:
+		return nil
+	case <-timer.C:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case next := <-r.resumed:
+		return next
+	default:
+		r.expired = true
+		return nil
+	}
+}
+
+// Resume 将会话切换到新连接：先写入greeting（seq为最后一条已发送消息的序号），
+// 再重发序号大于lastSeq的消息。原连接仍未断开时关闭原连接，由新连接接管
+func (s *Session) Resume(conn *websocket.Conn, lastSeq uint64, greeting func(seq uint64) interface{}) error {
+	s.mu.Lock()
+	r := s.resume
+	switch {
+	case r == nil:
+		s.mu.Unlock()
+		return ErrResumeNotEnabled
+	case s.Status == StatusClosed || r.expired:
+		s.mu.Unlock()
+		return ErrSessionNotFound
+	case lastSeq > r.seq:
+		s.mu.Unlock()
+		return ErrInvalidSeq
+	case lastSeq < r.acked || len(r.journal) > 0 && r.journal[0].seq > lastSeq+1 || len(r.journal) == 0 && lastSeq < r.seq:
+		s.mu.Unlock()
+		return ErrReplayUnavailable
+	}
+	r.trim(lastSeq)
+	old := s.Conn
+	s.Conn = conn
+	s.LastActive = time.Now()
+	r.written = lastSeq
+	r.replay = true
+	r.greeting = nil
+	if greeting != nil {
+		r.greeting = greeting(r.seq)
+	}
+	select {
+	case <-r.resumed:
+		// 上一个新连接尚未被读取循环接收，由本连接替代
+	default:
+	}
+	r.resumed <- conn
+	atomic.StoreInt32(&s.sendErrCount, 0)
+	s.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// writeResumable 写入开启恢复的会话的消息：新连接先写入greeting并重发未确认的消息，
+// 已重发的消息不再写入。连接断开期间消息只保留在未确认消息中，item为nil时只处理重发
+func (s *Session) writeResumable(item *queuedMessage) {
+	s.mu.Lock()
+	r, conn := s.resume, s.Conn
+	if conn == nil {
+		s.mu.Unlock()
+		return
+	}
+	var pending []interface{}
+	if r.replay {
+		r.replay = false
+		if r.greeting != nil {
+			pending = append(pending, r.greeting)
+		}
+		for _, m := range r.journal {
+			if m.seq > r.written {
+				pending = append(pending, m.message)
+				r.written = m.seq
+			}
+		}
+	}
+	if item != nil && item.seq > r.written {
+		pending = append(pending, item.message)
+		r.written = item.seq
+	}
+	s.mu.Unlock()
+
+	for _, message := range pending {
+		if err := writeMessage(conn, message); err != nil {
+			// 连接已断开，未确认的消息在恢复时重发
+			s.mu.Lock()
+			if s.Conn == conn {
+				s.Conn = nil
+			}
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+	}
+}
+
+// ResumeSession 按恢复令牌查找scope接口中开启了恢复的会话
+func (m *Manager) ResumeSession(token, scope string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, session := range m.sessions {
+		session.mu.RLock()
+		r := session.resume
+		match := r != nil && r.Scope == scope && subtle.ConstantTimeCompare([]byte(r.token), []byte(token)) == 1
+		session.mu.RUnlock()
+		if match {
+			return session, nil
+		}
+	}
+	return nil, ErrSessionNotFound
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -51,18 +52,28 @@ type Session struct {
 	closeChan     chan struct{}
 	sendErrCount  int32 // 原子操作：发送错误计数
 	maxSendErrors int32 // 原子操作：最大发送错误次数
+	audio         audioCounters
+	resume        *resumeState  // 开启会话恢复时不为nil
+	wake          chan struct{} // 恢复后通知发送循环重发未确认的消息
+}
+
+// audioCounters 音频流量计数（原子操作）
+type audioCounters struct {
+	bytesIn, pcmBytesIn   int64
+	bytesOut, pcmBytesOut int64
 }
 
 // Manager 会话管理器
 type Manager struct {
-	sessions       map[string]*Session
-	mu             sync.RWMutex
-	maxSessions    int
-	timeout        time.Duration
+	sessions    map[string]*Session
+	mu          sync.RWMutex
+	maxSessions int
+	timeout     time.Duration
 	// 统计信息
-	totalSessions  int64 // 原子操作
-	activeSessions int64 // 原子操作
-	totalMessages  int64 // 原子操作
+	totalSessions  int64         // 原子操作
+	activeSessions int64         // 原子操作
+	totalMessages  int64         // 原子操作
+	closedAudio    audioCounters // 已移除会话的音频流量
 }
 
 // NewManager 创建会话管理器
@@ -92,6 +103,7 @@ func (m *Manager) CreateSession(conn *websocket.Conn, queueSize int) (*Session,
 		LastActive:    time.Now(),
 		SendQueue:     make(chan interface{}, queueSize),
 		closeChan:     make(chan struct{}),
+		wake:          make(chan struct{}, 1),
 		sendErrCount:  0,
 		maxSendErrors: DefaultMaxSendErrors,
 	}
@@ -108,6 +120,15 @@ func (m *Manager) CreateSession(conn *websocket.Conn, queueSize int) (*Session,
 	return session, nil
 }
 
+// SetLimits 更新最大会话数和超时时间（用于配置热加载）
+// 已存在的会话不受最大会话数影响，超时时间在下次清理时生效
+func (m *Manager) SetLimits(maxSessions int, timeout time.Duration) {
+	m.mu.Lock()
+	m.maxSessions = maxSessions
+	m.timeout = timeout
+	m.mu.Unlock()
+}
+
 // GetSession 获取会话
 func (m *Manager) GetSession(sessionID string) (*Session, error) {
 	m.mu.RLock()
@@ -129,6 +150,7 @@ func (m *Manager) RemoveSession(sessionID string) {
 	session, ok := m.sessions[sessionID]
 	if ok {
 		session.Close()
+		m.retire(session)
 		delete(m.sessions, sessionID)
 		atomic.AddInt64(&m.activeSessions, -1)
 	}
@@ -167,6 +189,7 @@ func (m *Manager) CleanupTimeoutSessions() {
 		session.mu.RUnlock()
 
 		if status == StatusClosed {
+			m.retire(session)
 			delete(m.sessions, id)
 			continue
 		}
@@ -178,6 +201,7 @@ func (m *Manager) CleanupTimeoutSessions() {
 			}
 			session.mu.Unlock()
 			session.Close()
+			m.retire(session)
 			delete(m.sessions, id)
 		}
 	}
@@ -198,7 +222,10 @@ func (m *Manager) GetStats() Stats {
 		TotalMessages:  atomic.LoadInt64(&m.totalMessages),
 	}
 
+	audio := m.closedAudio
 	for _, session := range m.sessions {
+		audio.add(&session.audio)
+
 		session.mu.RLock()
 		status := session.Status
 		session.mu.RUnlock()
@@ -212,19 +239,66 @@ func (m *Manager) GetStats() Stats {
 			stats.Timeout++
 		}
 	}
+	stats.Audio = audio.stats()
 
 	return stats
 }
 
+// retire 将移除的会话的音频流量计入累计值，调用方持有锁
+func (m *Manager) retire(session *Session) {
+	m.closedAudio.add(&session.audio)
+}
+
 // Stats 统计信息
 type Stats struct {
-	Total          int   `json:"total"`
-	Active         int   `json:"active"`
-	Idle           int   `json:"idle"`
-	Timeout        int   `json:"timeout"`
-	TotalSessions  int64 `json:"total_sessions"`
-	ActiveSessions int64 `json:"active_sessions"`
-	TotalMessages  int64 `json:"total_messages"`
+	Total          int        `json:"total"`
+	Active         int        `json:"active"`
+	Idle           int        `json:"idle"`
+	Timeout        int        `json:"timeout"`
+	TotalSessions  int64      `json:"total_sessions"`
+	ActiveSessions int64      `json:"active_sessions"`
+	TotalMessages  int64      `json:"total_messages"`
+	Audio          AudioStats `json:"audio"` // 全部会话（含已移除）的音频流量
+}
+
+// AudioStats 音频流量统计。PCM字节数为同一段音频按模型格式（16位单声道PCM）
+// 传输时的大小，节省比例为1-实际字节数/PCM字节数（使用压缩编码时为正）
+type AudioStats struct {
+	BytesIn     int64   `json:"bytes_in"`      // 客户端发送的音频字节数
+	PCMBytesIn  int64   `json:"pcm_bytes_in"`  // 对应的PCM字节数
+	SavingIn    float64 `json:"saving_in"`     // 上行节省比例
+	BytesOut    int64   `json:"bytes_out"`     // 发送给客户端的音频字节数
+	PCMBytesOut int64   `json:"pcm_bytes_out"` // 对应的PCM字节数
+	SavingOut   float64 `json:"saving_out"`    // 下行节省比例
+}
+
+// add 累加另一组计数
+func (c *audioCounters) add(o *audioCounters) {
+	atomic.AddInt64(&c.bytesIn, atomic.LoadInt64(&o.bytesIn))
+	atomic.AddInt64(&c.pcmBytesIn, atomic.LoadInt64(&o.pcmBytesIn))
+	atomic.AddInt64(&c.bytesOut, atomic.LoadInt64(&o.bytesOut))
+	atomic.AddInt64(&c.pcmBytesOut, atomic.LoadInt64(&o.pcmBytesOut))
+}
+
+// stats 计数对应的统计信息
+func (c *audioCounters) stats() AudioStats {
+	stats := AudioStats{
+		BytesIn:     atomic.LoadInt64(&c.bytesIn),
+		PCMBytesIn:  atomic.LoadInt64(&c.pcmBytesIn),
+		BytesOut:    atomic.LoadInt64(&c.bytesOut),
+		PCMBytesOut: atomic.LoadInt64(&c.pcmBytesOut),
+	}
+	stats.SavingIn = saving(stats.BytesIn, stats.PCMBytesIn)
+	stats.SavingOut = saving(stats.BytesOut, stats.PCMBytesOut)
+	return stats
+}
+
+// saving 节省比例，保留4位小数，没有音频时为0
+func saving(bytes, pcmBytes int64) float64 {
+	if pcmBytes == 0 {
+		return 0
+	}
+	return math.Round((1-float64(bytes)/float64(pcmBytes))*10000) / 10000
 }
 
 // sendLoop 发送消息循环
@@ -237,11 +311,19 @@ func (s *Session) sendLoop() {
 			status := s.Status
 			s.mu.RUnlock()
 
-			if status == StatusClosed || conn == nil {
+			if status == StatusClosed {
+				return
+			}
+			if item, ok := message.(queuedMessage); ok {
+				// 开启恢复的会话，连接断开期间不退出
+				s.writeResumable(&item)
+				continue
+			}
+			if conn == nil {
 				return
 			}
 
-			if err := conn.WriteJSON(message); err != nil {
+			if err := writeMessage(conn, message); err != nil {
 				// 增加错误计数
 				errCount := atomic.AddInt32(&s.sendErrCount, 1)
 				maxErrors := atomic.LoadInt32(&s.maxSendErrors)
@@ -258,32 +340,56 @@ func (s *Session) sendLoop() {
 				atomic.StoreInt32(&s.sendErrCount, 0)
 			}
 
+		case <-s.wake:
+			s.writeResumable(nil)
+
 		case <-s.closeChan:
 			return
 		}
 	}
 }
 
+// writeMessage 写入一条消息，BinaryMessage以二进制帧发送，其余编码为JSON
+func writeMessage(conn *websocket.Conn, message interface{}) error {
+	if data, ok := message.(BinaryMessage); ok {
+		return conn.WriteMessage(websocket.BinaryMessage, data)
+	}
+	return conn.WriteJSON(message)
+}
+
 // Send 发送消息
 func (s *Session) Send(message interface{}) error {
-	s.mu.RLock()
-	status := s.Status
-	s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if status == StatusClosed {
+	if s.Status == StatusClosed {
 		return ErrSessionClosed
 	}
 
-	select {
-	case s.SendQueue <- message:
+	var err error
+	if s.resume != nil {
+		// 编号和入队在同一把锁内，保证序号与发送顺序一致
+		err = s.resume.enqueue(s, message)
+	} else {
+		select {
+		case s.SendQueue <- message:
+		default:
+			err = ErrSendQueueFull
+		}
+	}
+	if err == nil {
 		// 更新最后活动时间
-		s.mu.Lock()
 		s.LastActive = time.Now()
-		s.mu.Unlock()
-		return nil
-	default:
-		return ErrSendQueueFull
 	}
+	return err
+}
+
+// BinaryMessage 以二进制帧发送的消息（如音频数据），与JSON消息共用发送队列以保证顺序
+type BinaryMessage []byte
+
+// SendBinary 发送二进制消息
+func (s *Session) SendBinary(data []byte) error {
+	return s.Send(BinaryMessage(data))
 }
 
 // IncrementMessageCount 增加消息计数（由Manager调用）
@@ -291,6 +397,23 @@ func (m *Manager) IncrementMessageCount() {
 	atomic.AddInt64(&m.totalMessages, 1)
 }
 
+// AddAudioIn 记录收到的一段音频：bytes为实际字节数，pcmBytes为解码后的PCM字节数
+func (s *Session) AddAudioIn(bytes, pcmBytes int) {
+	atomic.AddInt64(&s.audio.bytesIn, int64(bytes))
+	atomic.AddInt64(&s.audio.pcmBytesIn, int64(pcmBytes))
+}
+
+// AddAudioOut 记录发送的一段音频：bytes为实际字节数，pcmBytes为编码前的PCM字节数
+func (s *Session) AddAudioOut(bytes, pcmBytes int) {
+	atomic.AddInt64(&s.audio.bytesOut, int64(bytes))
+	atomic.AddInt64(&s.audio.pcmBytesOut, int64(pcmBytes))
+}
+
+// AudioStats 获取会话的音频流量统计
+func (s *Session) AudioStats() AudioStats {
+	return s.audio.stats()
+}
+
 // GetSendErrorCount 获取发送错误计数
 func (s *Session) GetSendErrorCount() int32 {
 	return atomic.LoadInt32(&s.sendErrCount)
@@ -309,6 +432,11 @@ func (s *Session) Close() {
 	})
 }
 
+// Done 返回会话关闭时关闭的通道
+func (s *Session) Done() <-chan struct{} {
+	return s.closeChan
+}
+
 // GetID 获取会话ID
 func (s *Session) GetID() string {
 	return s.ID
@@ -334,6 +462,9 @@ var (
 	ErrSessionClosed       = &SessionError{Message: "session is closed"}
 	ErrMaxSessionsReached  = &SessionError{Message: "max sessions reached"}
 	ErrSendQueueFull       = &SessionError{Message: "send queue is full"}
+	ErrResumeNotEnabled    = &SessionError{Message: "session resumption is not enabled"}
+	ErrInvalidSeq          = &SessionError{Message: "sequence number is ahead of the last sent message"}
+	ErrReplayUnavailable   = &SessionError{Message: "missed messages are no longer available"}
 )
 
 // SessionError 会话错误
@@ -1,8 +1,13 @@
 package session
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 func TestNewManager(t *testing.T) {
@@ -120,6 +125,36 @@ func TestGetStats(t *testing.T) {
 	}
 }
 
+func TestAudioStats(t *testing.T) {
+	manager := NewManager(10, 30*time.Second)
+	first, _ := manager.CreateSession(nil, 100)
+	second, _ := manager.CreateSession(nil, 100)
+
+	// 上行Opus（约1/10），下行PCM
+	first.AddAudioIn(100, 1000)
+	first.AddAudioIn(60, 600)
+	first.AddAudioOut(3200, 3200)
+	second.AddAudioIn(500, 1000)
+
+	stats := first.AudioStats()
+	if stats.BytesIn != 160 || stats.PCMBytesIn != 1600 || stats.SavingIn != 0.9 {
+		t.Errorf("Unexpected inbound stats: %+v", stats)
+	}
+	if stats.BytesOut != 3200 || stats.SavingOut != 0 {
+		t.Errorf("Unexpected outbound stats: %+v", stats)
+	}
+
+	// 已移除会话的流量仍计入管理器统计
+	manager.RemoveSession(first.ID)
+	total := manager.GetStats().Audio
+	if total.BytesIn != 660 || total.PCMBytesIn != 2600 || total.BytesOut != 3200 {
+		t.Errorf("Unexpected total stats: %+v", total)
+	}
+	if total.SavingIn != 0.7462 {
+		t.Errorf("Expected saving 0.7462, got %v", total.SavingIn)
+	}
+}
+
 func TestSessionSend(t *testing.T) {
 	manager := NewManager(10, 30*time.Second)
 
@@ -139,6 +174,51 @@ func TestSessionSend(t *testing.T) {
 	}
 }
 
+func TestSessionSendBinary(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	manager := NewManager(10, 30*time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		session, err := manager.CreateSession(conn, 10)
+		if err != nil {
+			return
+		}
+		session.Send(map[string]string{"type": "start"})
+		session.SendBinary([]byte{1, 2, 3})
+		session.Send(map[string]string{"type": "end"})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	// 二进制消息与JSON消息按发送顺序到达
+	want := []struct {
+		messageType int
+		data        string
+	}{
+		{websocket.TextMessage, `{"type":"start"}`},
+		{websocket.BinaryMessage, "\x01\x02\x03"},
+		{websocket.TextMessage, `{"type":"end"}`},
+	}
+	for i, w := range want {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read message %d: %v", i, err)
+		}
+		if messageType != w.messageType || strings.TrimSpace(string(data)) != w.data {
+			t.Errorf("Message %d = (%d, %q), want (%d, %q)", i, messageType, data, w.messageType, w.data)
+		}
+	}
+}
+
 func TestSessionClose(t *testing.T) {
 	manager := NewManager(10, 30*time.Second)
 
@@ -265,6 +345,28 @@ func TestManager_CleanupTimeoutSessions(t *testing.T) {
 	}
 }
 
+func TestManager_SetLimits(t *testing.T) {
+	manager := NewManager(1, 30*time.Second)
+
+	if _, err := manager.CreateSession(nil, 10); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if _, err := manager.CreateSession(nil, 10); err != ErrMaxSessionsReached {
+		t.Fatalf("Expected ErrMaxSessionsReached, got %v", err)
+	}
+
+	manager.SetLimits(2, time.Millisecond)
+	if _, err := manager.CreateSession(nil, 10); err != nil {
+		t.Errorf("Expected session to be created after raising the limit, got %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	manager.CleanupTimeoutSessions()
+	if stats := manager.GetStats(); stats.Total != 0 {
+		t.Errorf("Expected sessions to time out with the new timeout, got %d", stats.Total)
+	}
+}
+
 func TestSessionStatus_String(t *testing.T) {
 	tests := []struct {
 		status SessionStatus
@@ -305,3 +407,124 @@ func TestSession_GetStatus(t *testing.T) {
 }
 
 
+// seqMessage 测试用的带序号消息
+type seqMessage struct {
+	Type string `json:"type"`
+	Seq  uint64 `json:"seq"`
+}
+
+func (m seqMessage) WithSeq(seq uint64) interface{} {
+	m.Seq = seq
+	return m
+}
+
+func TestSessionResume(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	conns := make(chan *websocket.Conn, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, err := upgrader.Upgrade(w, r, nil); err == nil {
+			conns <- conn
+		}
+	}))
+	defer server.Close()
+	dial := func() (*websocket.Conn, *websocket.Conn) {
+		client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		client.SetReadDeadline(time.Now().Add(2 * time.Second))
+		return client, <-conns
+	}
+	read := func(client *websocket.Conn, want string) {
+		t.Helper()
+		_, data, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", want, err)
+		}
+		if got := strings.TrimSpace(string(data)); got != want {
+			t.Fatalf("Got %q, want %q", got, want)
+		}
+	}
+
+	manager := NewManager(10, 30*time.Second)
+	client, conn := dial()
+	sess, _ := manager.CreateSession(conn, 10)
+	token := sess.EnableResume(ResumeOptions{Scope: "test", Grace: 2 * time.Second})
+
+	sess.Send(seqMessage{Type: "a"})
+	sess.SendBinary([]byte{1})
+	read(client, `{"type":"a","seq":1}`)
+	client.Close()
+
+	// 读取循环发现断线后等待恢复，期间发送的消息保留
+	resumed := make(chan *websocket.Conn, 1)
+	go func(conn *websocket.Conn) { resumed <- sess.WaitResume(conn) }(conn)
+	sess.Send(seqMessage{Type: "b"})
+
+	if _, err := manager.ResumeSession(token, "other"); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound for another scope, got %v", err)
+	}
+	found, err := manager.ResumeSession(token, "test")
+	if err != nil || found != sess {
+		t.Fatalf("ResumeSession() = %v, %v", found, err)
+	}
+	client, conn = dial()
+	defer client.Close()
+	if err := sess.Resume(conn, 5, nil); err != ErrInvalidSeq {
+		t.Errorf("Expected ErrInvalidSeq, got %v", err)
+	}
+	greeting := func(seq uint64) interface{} { return seqMessage{Type: "resumed", Seq: seq} }
+	if err := sess.Resume(conn, 1, greeting); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	read(client, `{"type":"resumed","seq":3}`)
+	read(client, "\x01")
+	read(client, `{"type":"b","seq":3}`)
+	if next := <-resumed; next != conn {
+		t.Error("WaitResume did not return the new connection")
+	}
+
+	sess.Send(seqMessage{Type: "c"})
+	read(client, `{"type":"c","seq":4}`)
+
+	// 已确认的消息不能再重发
+	if err := sess.Ack(4); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if err := sess.Resume(conn, 2, nil); err != ErrReplayUnavailable {
+		t.Errorf("Expected ErrReplayUnavailable, got %v", err)
+	}
+}
+
+func TestSessionResumeLimits(t *testing.T) {
+	manager := NewManager(10, 30*time.Second)
+
+	// 未确认的消息超出缓冲区
+	sess, _ := manager.CreateSession(nil, 10)
+	sess.EnableResume(ResumeOptions{Grace: time.Second, BufferSize: 2})
+	for i := 0; i < 4; i++ {
+		sess.Send(seqMessage{Type: "a"})
+	}
+	if err := sess.Resume(nil, 1, nil); err != ErrReplayUnavailable {
+		t.Errorf("Expected ErrReplayUnavailable, got %v", err)
+	}
+
+	// 宽限期过后不能恢复
+	sess, _ = manager.CreateSession(nil, 10)
+	sess.EnableResume(ResumeOptions{Grace: 20 * time.Millisecond})
+	if conn := sess.WaitResume(nil); conn != nil {
+		t.Error("Expected WaitResume to time out")
+	}
+	if err := sess.Resume(nil, 0, nil); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound after grace period, got %v", err)
+	}
+
+	// 未开启恢复
+	sess, _ = manager.CreateSession(nil, 10)
+	if err := sess.Ack(0); err != ErrResumeNotEnabled {
+		t.Errorf("Expected ErrResumeNotEnabled, got %v", err)
+	}
+	if conn := sess.WaitResume(nil); conn != nil {
+		t.Error("Expected nil connection without resumption")
+	}
+}
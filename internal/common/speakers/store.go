@@ -0,0 +1,346 @@
+package speakers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 说话人文件后缀
+const fileSuffix = ".json"
+
+// 说话人存储错误
+var (
+	ErrSpeakerNotFound = errors.New("speaker not found")
+	ErrInvalidSpeaker  = errors.New("invalid speaker")
+)
+
+// idPattern 说话人ID只允许字母、数字、下划线和连字符（同时作为文件名）
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Profile 注册的说话人
+// Embedding为各注册样本归一化后的说话人向量均值，不通过API返回
+type Profile struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name,omitempty"`
+	NumSamples int       `json:"num_samples"`
+	Dim        int       `json:"dim"`
+	Embedding  []float32 `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// record 说话人文件内容
+type record struct {
+	Profile
+	Embedding []float32 `json:"embedding"`
+}
+
+// Match 辨认结果
+type Match struct {
+	Profile *Profile
+	Score   float32 // 余弦相似度
+}
+
+// Store 说话人向量存储
+// 每个说话人对应目录下的一个 <id>.json 文件，启动时加载到内存，修改时先写临时文件再重命名
+type Store struct {
+	dir string
+
+	mu       sync.RWMutex
+	profiles map[string]*Profile
+}
+
+// NewStore 创建说话人存储并加载目录中已注册的说话人（目录不存在时自动创建）
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("speaker store directory is required")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create speaker store directory: %w", err)
+	}
+	s := &Store{dir: dir, profiles: make(map[string]*Profile)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ValidateID 检查说话人ID
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: id %q (allowed: letters, digits, '_' and '-', up to 64 characters)", ErrInvalidSpeaker, id)
+	}
+	return nil
+}
+
+// load 加载目录中的说话人
+func (s *Store) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read speaker store directory: %w", err)
+	}
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), fileSuffix)
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSuffix) || ValidateID(id) != nil {
+			continue
+		}
+		data, err := os.ReadFile(s.path(id))
+		if err != nil {
+			return fmt.Errorf("failed to read speaker %s: %w", id, err)
+		}
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return fmt.Errorf("invalid speaker file %s: %w", id, err)
+		}
+		if len(rec.Embedding) == 0 {
+			return fmt.Errorf("invalid speaker file %s: empty embedding", id)
+		}
+		profile := rec.Profile
+		profile.ID = id
+		profile.Embedding = rec.Embedding
+		profile.Dim = len(rec.Embedding)
+		s.profiles[id] = &profile
+	}
+	return nil
+}
+
+// path 获取说话人文件路径
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+fileSuffix)
+}
+
+// save 持久化说话人，调用方需持有写锁
+func (s *Store) save(profile *Profile) error {
+	data, err := json.MarshalIndent(record{Profile: *profile, Embedding: profile.Embedding}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path(profile.ID), data); err != nil {
+		return fmt.Errorf("failed to save speaker %s: %w", profile.ID, err)
+	}
+	return nil
+}
+
+// List 列出所有说话人（按ID排序）
+func (s *Store) List() []*Profile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profiles := make([]*Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].ID < profiles[j].ID })
+	return profiles
+}
+
+// Get 获取说话人
+func (s *Store) Get(id string) (*Profile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.profiles[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSpeakerNotFound, id)
+	}
+	return p, nil
+}
+
+// Enroll 用一个或多个样本的说话人向量注册说话人并持久化
+// 说话人已存在时，replace为false则把新样本并入已有向量，否则重新注册；name为空时保留原名称
+func (s *Store) Enroll(id, name string, embeddings [][]float32, replace bool) (*Profile, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("%w: at least one sample is required", ErrInvalidSpeaker)
+	}
+	dim := len(embeddings[0])
+	sum := make([]float64, dim)
+	for _, emb := range embeddings {
+		if len(emb) == 0 || len(emb) != dim {
+			return nil, fmt.Errorf("%w: embedding dimension mismatch", ErrInvalidSpeaker)
+		}
+		addNormalized(sum, emb, 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	profile := &Profile{ID: id, Name: name, CreatedAt: now}
+	if old, ok := s.profiles[id]; ok {
+		profile.CreatedAt = old.CreatedAt
+		if name == "" {
+			profile.Name = old.Name
+		}
+		if !replace {
+			if old.Dim != dim {
+				return nil, fmt.Errorf("%w: embedding dimension %d does not match enrolled dimension %d", ErrInvalidSpeaker, dim, old.Dim)
+			}
+			// 已有向量为样本均值，按样本数加权合并
+			addNormalized(sum, old.Embedding, float64(old.NumSamples))
+			profile.NumSamples = old.NumSamples
+		}
+	}
+	profile.NumSamples += len(embeddings)
+	profile.Dim = dim
+	profile.Embedding = normalize(sum)
+	profile.UpdatedAt = now
+
+	if err := s.save(profile); err != nil {
+		return nil, err
+	}
+	s.profiles[id] = profile
+	return profile, nil
+}
+
+// Rename 修改说话人名称
+func (s *Store) Rename(id, name string) (*Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.profiles[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSpeakerNotFound, id)
+	}
+	profile := *old
+	profile.Name = name
+	profile.UpdatedAt = time.Now()
+	if err := s.save(&profile); err != nil {
+		return nil, err
+	}
+	s.profiles[id] = &profile
+	return &profile, nil
+}
+
+// Delete 删除说话人
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.profiles[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrSpeakerNotFound, id)
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete speaker %s: %w", id, err)
+	}
+	delete(s.profiles, id)
+	return nil
+}
+
+// Verify 计算样本向量与已注册说话人的余弦相似度
+func (s *Store) Verify(id string, embedding []float32) (float32, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.profiles[id]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrSpeakerNotFound, id)
+	}
+	if len(embedding) != p.Dim {
+		return 0, fmt.Errorf("%w: embedding dimension %d does not match enrolled dimension %d", ErrInvalidSpeaker, len(embedding), p.Dim)
+	}
+	return cosine(p.Embedding, embedding), nil
+}
+
+// Identify 返回与样本向量最相似的已注册说话人，没有注册说话人时返回nil
+func (s *Store) Identify(embedding []float32) *Match {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var best *Match
+	for _, p := range s.profiles {
+		if len(embedding) != p.Dim {
+			continue
+		}
+		score := cosine(p.Embedding, embedding)
+		// 分数相同时取ID较小者，保证结果稳定
+		if best == nil || score > best.Score || (score == best.Score && p.ID < best.Profile.ID) {
+			best = &Match{Profile: p, Score: score}
+		}
+	}
+	return best
+}
+
+// addNormalized 将归一化后的向量按权重累加到sum
+func addNormalized(sum []float64, v []float32, weight float64) {
+	norm := l2norm(v)
+	if norm == 0 {
+		return
+	}
+	for i, x := range v {
+		sum[i] += weight * float64(x) / norm
+	}
+}
+
+// normalize 归一化向量
+func normalize(v []float64) []float32 {
+	var sq float64
+	for _, x := range v {
+		sq += x * x
+	}
+	norm := math.Sqrt(sq)
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	for i, x := range v {
+		out[i] = float32(x / norm)
+	}
+	return out
+}
+
+// l2norm 计算向量的L2范数
+func l2norm(v []float32) float64 {
+	var sq float64
+	for _, x := range v {
+		sq += float64(x) * float64(x)
+	}
+	return math.Sqrt(sq)
+}
+
+// cosine 计算余弦相似度
+func cosine(a, b []float32) float32 {
+	na, nb := l2norm(a), l2norm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return float32(dot / (na * nb))
+}
+
+// writeFileAtomic 先写临时文件再重命名，避免写入中断留下不完整的文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package speakers
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func approx(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestStore_EnrollGetDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	profile, err := store.Enroll("alice", "Alice", [][]float32{{2, 0, 0}, {0, 3, 0}}, false)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if profile.NumSamples != 2 || profile.Dim != 3 || profile.Name != "Alice" {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+	// 各样本先归一化再取均值
+	want := float32(1 / math.Sqrt2)
+	if !approx(profile.Embedding[0], want) || !approx(profile.Embedding[1], want) || profile.Embedding[2] != 0 {
+		t.Errorf("Unexpected embedding: %v", profile.Embedding)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "alice.json")); err != nil {
+		t.Fatalf("Expected speaker file: %v", err)
+	}
+
+	// 重新加载后内容一致
+	reloaded, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	got, err := reloaded.Get("alice")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Embedding, profile.Embedding) || got.Name != "Alice" || got.NumSamples != 2 {
+		t.Errorf("Reloaded profile = %+v, want %+v", got, profile)
+	}
+
+	if err := reloaded.Delete("alice"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := reloaded.Get("alice"); !errors.Is(err, ErrSpeakerNotFound) {
+		t.Errorf("Expected ErrSpeakerNotFound after delete, got %v", err)
+	}
+	if err := reloaded.Delete("alice"); !errors.Is(err, ErrSpeakerNotFound) {
+		t.Errorf("Expected ErrSpeakerNotFound, got %v", err)
+	}
+}
+
+func TestStore_EnrollMergeAndReplace(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	if _, err := store.Enroll("bob", "Bob", [][]float32{{1, 0}}, false); err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	merged, err := store.Enroll("bob", "", [][]float32{{0, 1}}, false)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if merged.NumSamples != 2 || merged.Name != "Bob" || !approx(merged.Embedding[0], merged.Embedding[1]) {
+		t.Errorf("Unexpected merged profile: %+v", merged)
+	}
+
+	replaced, err := store.Enroll("bob", "Robert", [][]float32{{0, 1}}, true)
+	if err != nil {
+		t.Fatalf("Enroll() error = %v", err)
+	}
+	if replaced.NumSamples != 1 || replaced.Name != "Robert" || replaced.Embedding[0] != 0 {
+		t.Errorf("Unexpected replaced profile: %+v", replaced)
+	}
+
+	if _, err := store.Enroll("bob", "", [][]float32{{1, 0, 0}}, false); !errors.Is(err, ErrInvalidSpeaker) {
+		t.Errorf("Expected ErrInvalidSpeaker for dimension mismatch, got %v", err)
+	}
+	if _, err := store.Enroll("../bob", "", [][]float32{{1, 0}}, false); !errors.Is(err, ErrInvalidSpeaker) {
+		t.Errorf("Expected ErrInvalidSpeaker for invalid id, got %v", err)
+	}
+	if _, err := store.Enroll("carol", "", nil, false); !errors.Is(err, ErrInvalidSpeaker) {
+		t.Errorf("Expected ErrInvalidSpeaker without samples, got %v", err)
+	}
+
+	renamed, err := store.Rename("bob", "Bobby")
+	if err != nil || renamed.Name != "Bobby" {
+		t.Errorf("Rename() = %+v, %v", renamed, err)
+	}
+	if _, err := store.Rename("carol", "Carol"); !errors.Is(err, ErrSpeakerNotFound) {
+		t.Errorf("Expected ErrSpeakerNotFound, got %v", err)
+	}
+}
+
+func TestStore_VerifyIdentify(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if match := store.Identify([]float32{1, 0}); match != nil {
+		t.Errorf("Expected no match in empty store, got %+v", match)
+	}
+
+	store.Enroll("alice", "", [][]float32{{1, 0}}, false)
+	store.Enroll("bob", "", [][]float32{{0, 1}}, false)
+
+	score, err := store.Verify("alice", []float32{3, 0})
+	if err != nil || !approx(score, 1) {
+		t.Errorf("Verify() = %v, %v, want 1", score, err)
+	}
+	score, err = store.Verify("alice", []float32{0, 2})
+	if err != nil || !approx(score, 0) {
+		t.Errorf("Verify() = %v, %v, want 0", score, err)
+	}
+	if _, err := store.Verify("carol", []float32{1, 0}); !errors.Is(err, ErrSpeakerNotFound) {
+		t.Errorf("Expected ErrSpeakerNotFound, got %v", err)
+	}
+	if _, err := store.Verify("alice", []float32{1, 0, 0}); !errors.Is(err, ErrInvalidSpeaker) {
+		t.Errorf("Expected ErrInvalidSpeaker for dimension mismatch, got %v", err)
+	}
+
+	match := store.Identify([]float32{0.2, 0.9})
+	if match == nil || match.Profile.ID != "bob" || match.Score < 0.9 {
+		t.Errorf("Identify() = %+v, want bob", match)
+	}
+}
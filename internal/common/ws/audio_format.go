@@ -0,0 +1,267 @@
+package ws
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 客户端音频编码
+const (
+	EncodingPCMS16LE = "pcm_s16le" // 16位有符号整数，小端序
+	EncodingPCMF32LE = "pcm_f32le" // 32位浮点，小端序
+	EncodingMulaw    = "mulaw"     // G.711 μ-law，每样本1字节
+	EncodingOpus     = "opus"      // 每个二进制消息为一个Opus包
+	EncodingOggOpus  = "ogg_opus"  // Ogg封装的Opus流
+)
+
+// 客户端音频格式的取值范围
+const (
+	minInputSampleRate = 8000
+	maxInputSampleRate = 48000
+	maxInputChannels   = 2
+)
+
+// opusSampleRates Opus支持的解码采样率
+var opusSampleRates = map[int]bool{8000: true, 12000: true, 16000: true, 24000: true, 48000: true}
+
+// OpusDecoder 单个会话的Opus解码器，输出PCM16交错音频
+type OpusDecoder interface {
+	Decode(packet []byte) ([]byte, error)
+}
+
+// OpusDecoderFactory 按采样率和声道数创建Opus解码器
+type OpusDecoderFactory func(sampleRate, channels int) (OpusDecoder, error)
+
+// OpusEncoder 单个音频流的Opus编码器，每次输入一帧PCM16交错音频，输出一个Opus包
+type OpusEncoder interface {
+	Encode(pcm []byte) ([]byte, error)
+}
+
+// OpusEncoderFactory 按采样率、声道数和码率（bps）创建Opus编码器
+type OpusEncoderFactory func(sampleRate, channels, bitrate int) (OpusEncoder, error)
+
+// opusFrameDuration 编码时每个Opus包的时长
+const opusFrameDuration = 20 * time.Millisecond
+
+// opusSampleRate 不低于rate的最小Opus采样率，用于编码其他采样率的音频
+func opusSampleRate(rate int) int {
+	for _, r := range []int{8000, 12000, 16000, 24000} {
+		if rate <= r {
+			return r
+		}
+	}
+	return 48000
+}
+
+// AudioFormat 客户端发送的音频格式
+type AudioFormat struct {
+	SampleRate int    `json:"sample_rate"`
+	Encoding   string `json:"encoding"`
+	Channels   int    `json:"channels"`
+}
+
+// validate 检查音频格式，opus为nil时不支持Opus
+func (f AudioFormat) validate(opus OpusDecoderFactory) error {
+	if f.SampleRate < minInputSampleRate || f.SampleRate > maxInputSampleRate {
+		return fmt.Errorf("sample_rate must be between %d and %d", minInputSampleRate, maxInputSampleRate)
+	}
+	if f.Channels < 1 || f.Channels > maxInputChannels {
+		return fmt.Errorf("channels must be between 1 and %d", maxInputChannels)
+	}
+	switch f.Encoding {
+	case EncodingPCMS16LE, EncodingPCMF32LE, EncodingMulaw:
+	case EncodingOpus, EncodingOggOpus:
+		if opus == nil {
+			return newProtocolError(ErrCodeNotEnabled, "opus decoding is not enabled")
+		}
+		if !opusSampleRates[f.SampleRate] {
+			return fmt.Errorf("%s sample_rate must be 8000, 12000, 16000, 24000 or 48000", f.Encoding)
+		}
+	default:
+		return fmt.Errorf("unsupported encoding: %s", f.Encoding)
+	}
+	return nil
+}
+
+// audioConverter 将客户端音频转换为模型采样率的PCM16单声道音频，
+// 保留不足一帧的字节和重采样位置，跨消息连续转换
+type audioConverter struct {
+	format    AudioFormat
+	opus      OpusDecoder    // Opus解码器，其他编码为nil
+	ogg       *oggOpusReader // Ogg解封装，ogg_opus以外为nil
+	skip      int            // 尚未丢弃的Ogg前导字节数
+	resampler *resampler     // 采样率相同时为nil
+	pending   []byte         // 不足一个采样帧的剩余字节
+}
+
+// newAudioConverter 创建音频转换器，targetRate为模型采样率
+func newAudioConverter(format AudioFormat, targetRate int, opus OpusDecoderFactory) (*audioConverter, error) {
+	c := &audioConverter{format: format}
+	if format.Encoding == EncodingOpus || format.Encoding == EncodingOggOpus {
+		decoder, err := opus(format.SampleRate, format.Channels)
+		if err != nil {
+			return nil, err
+		}
+		c.opus = decoder
+	}
+	if format.Encoding == EncodingOggOpus {
+		c.ogg = &oggOpusReader{}
+	}
+	if format.SampleRate != targetRate {
+		c.resampler = newResampler(format.SampleRate, targetRate)
+	}
+	return c, nil
+}
+
+// passthrough 输入已是模型格式，无需转换
+func (c *audioConverter) passthrough() bool {
+	return c.format.Encoding == EncodingPCMS16LE && c.format.Channels == 1 && c.resampler == nil
+}
+
+// convert 转换一个二进制消息的音频
+func (c *audioConverter) convert(data []byte) ([]byte, error) {
+	if c.passthrough() {
+		return data, nil
+	}
+	encoding := c.format.Encoding
+	if c.opus != nil {
+		pcm, err := c.decodeOpus(data)
+		if err != nil {
+			return nil, err
+		}
+		data, encoding = pcm, EncodingPCMS16LE
+	}
+
+	// 拼接上一消息剩余的字节，按整帧解码
+	sampleSize := map[string]int{EncodingPCMS16LE: 2, EncodingPCMF32LE: 4, EncodingMulaw: 1}[encoding]
+	frameSize := sampleSize * c.format.Channels
+	if len(c.pending) > 0 {
+		data = append(c.pending, data...)
+		c.pending = nil
+	}
+	if rest := len(data) % frameSize; rest > 0 {
+		c.pending = append([]byte(nil), data[len(data)-rest:]...)
+		data = data[:len(data)-rest]
+	}
+
+	samples := make([]float32, len(data)/frameSize)
+	for i := range samples {
+		var sum float32
+		for ch := 0; ch < c.format.Channels; ch++ {
+			sum += decodeSample(encoding, data[(i*c.format.Channels+ch)*sampleSize:])
+		}
+		samples[i] = sum / float32(c.format.Channels)
+	}
+	if c.resampler != nil {
+		samples = c.resampler.process(samples)
+	}
+
+	return encodePCM16(samples), nil
+}
+
+// decodeOpus 解码一个Opus包（opus）或一段Ogg Opus流（ogg_opus）为PCM16交错音频
+func (c *audioConverter) decodeOpus(data []byte) ([]byte, error) {
+	packets := [][]byte{data}
+	if c.ogg != nil {
+		var err error
+		if packets, err = c.ogg.feed(data); err != nil {
+			return nil, fmt.Errorf("invalid ogg stream: %v", err)
+		}
+		frameSize := 2 * c.format.Channels
+		c.skip += c.ogg.takePreSkip() * c.format.SampleRate / opusGranuleRate * frameSize
+	}
+
+	var pcm []byte
+	for _, packet := range packets {
+		decoded, err := c.opus.Decode(packet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid opus packet: %v", err)
+		}
+		pcm = append(pcm, decoded...)
+	}
+	if c.skip > 0 {
+		// 丢弃流开头编码器的前导样本
+		n := c.skip
+		if n > len(pcm) {
+			n = len(pcm)
+		}
+		pcm, c.skip = pcm[n:], c.skip-n
+	}
+	return pcm, nil
+}
+
+// encodePCM16 将[-1, 1]的浮点样本编码为PCM16
+func encodePCM16(samples []float32) []byte {
+	out := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		s = float32(math.Max(-1, math.Min(1, float64(s))))
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(s*32767)))
+	}
+	return out
+}
+
+// reset 丢弃跨消息的剩余状态（reset消息），Ogg流在reset后继续，解封装状态保留
+func (c *audioConverter) reset() {
+	c.pending = nil
+	if c.resampler != nil {
+		c.resampler = newResampler(c.resampler.from, c.resampler.to)
+	}
+}
+
+// decodeSample 解码一个样本为[-1, 1]的浮点值
+func decodeSample(encoding string, b []byte) float32 {
+	switch encoding {
+	case EncodingPCMF32LE:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	case EncodingMulaw:
+		return float32(mulawToLinear(b[0])) / 32768
+	default:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / 32768
+	}
+}
+
+// mulawToLinear G.711 μ-law解码为16位线性PCM
+func mulawToLinear(u byte) int16 {
+	u = ^u
+	t := (int(u&0x0f) << 3) + 0x84
+	t <<= (u & 0x70) >> 4
+	if u&0x80 != 0 {
+		return int16(0x84 - t)
+	}
+	return int16(t - 0x84)
+}
+
+// resampler 流式线性插值重采样，跨调用保持插值位置
+type resampler struct {
+	from, to int
+	step     float64   // 每个输出样本前进的输入样本数
+	pos      float64   // 下一个输出样本在buf中的位置
+	buf      []float32 // 尚未用完的输入样本
+}
+
+// newResampler 创建重采样器
+func newResampler(from, to int) *resampler {
+	return &resampler{from: from, to: to, step: float64(from) / float64(to)}
+}
+
+// process 输入一段样本，返回可以确定的输出样本
+func (r *resampler) process(in []float32) []float32 {
+	r.buf = append(r.buf, in...)
+	var out []float32
+	for r.pos+1 < float64(len(r.buf)) {
+		i := int(r.pos)
+		frac := float32(r.pos - float64(i))
+		out = append(out, r.buf[i]*(1-frac)+r.buf[i+1]*frac)
+		r.pos += r.step
+	}
+	if used := int(r.pos); used > 0 {
+		if used > len(r.buf) {
+			used = len(r.buf)
+		}
+		r.buf = append(r.buf[:0], r.buf[used:]...)
+		r.pos -= float64(used)
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package ws
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestMulawToLinear(t *testing.T) {
+	cases := map[byte]int16{0xff: 0, 0x7f: 0, 0x80: 32124, 0x00: -32124, 0xf0: 120, 0x70: -120}
+	for u, want := range cases {
+		if got := mulawToLinear(u); got != want {
+			t.Errorf("mulawToLinear(0x%02x) = %d, want %d", u, got, want)
+		}
+	}
+}
+
+func TestResampler(t *testing.T) {
+	// 分块重采样与整段重采样结果一致，长度按采样率比例
+	for _, rates := range [][2]int{{48000, 16000}, {8000, 16000}, {44100, 16000}} {
+		input := make([]float32, rates[0]/10)
+		for i := range input {
+			input[i] = float32(math.Sin(float64(i) / 10))
+		}
+		whole := newResampler(rates[0], rates[1]).process(input)
+
+		r := newResampler(rates[0], rates[1])
+		var chunked []float32
+		for i := 0; i < len(input); i += 333 {
+			end := i + 333
+			if end > len(input) {
+				end = len(input)
+			}
+			chunked = append(chunked, r.process(input[i:end])...)
+		}
+		if len(chunked) != len(whole) {
+			t.Fatalf("%v: chunked length %d != whole length %d", rates, len(chunked), len(whole))
+		}
+		for i := range whole {
+			if math.Abs(float64(whole[i]-chunked[i])) > 1e-6 {
+				t.Fatalf("%v: sample %d differs: %v != %v", rates, i, whole[i], chunked[i])
+			}
+		}
+		if want := rates[1] / 10; len(whole) < want-3 || len(whole) > want {
+			t.Errorf("%v: expected about %d samples, got %d", rates, want, len(whole))
+		}
+	}
+}
+
+func TestAudioConverter(t *testing.T) {
+	// 32位浮点立体声混合为单声道，跨消息的不完整帧拼接到下一消息
+	c, err := newAudioConverter(AudioFormat{SampleRate: 16000, Encoding: EncodingPCMF32LE, Channels: 2}, 16000, nil)
+	if err != nil {
+		t.Fatalf("Failed to create converter: %v", err)
+	}
+	frames := make([]byte, 8*4)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint32(frames[i*8:], math.Float32bits(0.5))
+		binary.LittleEndian.PutUint32(frames[i*8+4:], math.Float32bits(-0.25))
+	}
+	first, _ := c.convert(frames[:13])
+	second, _ := c.convert(frames[13:])
+	if len(first) != 2 || len(second) != 6 {
+		t.Fatalf("Expected 1+3 samples, got %d+%d bytes", len(first), len(second))
+	}
+	if got := int16(binary.LittleEndian.Uint16(second[4:])); got != 4095 { // 0.125 * 32767
+		t.Errorf("Expected mixed sample 4095, got %d", got)
+	}
+
+	// 模型格式的音频原样返回
+	c, _ = newAudioConverter(AudioFormat{SampleRate: 16000, Encoding: EncodingPCMS16LE, Channels: 1}, 16000, nil)
+	if out, _ := c.convert([]byte{1, 2, 3}); len(out) != 3 {
+		t.Errorf("Expected passthrough, got %d bytes", len(out))
+	}
+}
+
+func TestAudioFormatValidate(t *testing.T) {
+	opus := func(sampleRate, channels int) (OpusDecoder, error) { return nil, nil }
+	cases := []struct {
+		format AudioFormat
+		opus   OpusDecoderFactory
+		valid  bool
+	}{
+		{AudioFormat{SampleRate: 8000, Encoding: EncodingMulaw, Channels: 1}, nil, true},
+		{AudioFormat{SampleRate: 44100, Encoding: EncodingPCMF32LE, Channels: 2}, nil, true},
+		{AudioFormat{SampleRate: 4000, Encoding: EncodingPCMS16LE, Channels: 1}, nil, false},
+		{AudioFormat{SampleRate: 16000, Encoding: EncodingPCMS16LE, Channels: 3}, nil, false},
+		{AudioFormat{SampleRate: 16000, Encoding: "alaw", Channels: 1}, nil, false},
+		{AudioFormat{SampleRate: 48000, Encoding: EncodingOpus, Channels: 1}, nil, false},
+		{AudioFormat{SampleRate: 48000, Encoding: EncodingOpus, Channels: 1}, opus, true},
+		{AudioFormat{SampleRate: 44100, Encoding: EncodingOpus, Channels: 1}, opus, false},
+		{AudioFormat{SampleRate: 16000, Encoding: EncodingOggOpus, Channels: 1}, nil, false},
+		{AudioFormat{SampleRate: 16000, Encoding: EncodingOggOpus, Channels: 2}, opus, true},
+	}
+	for _, tc := range cases {
+		if err := tc.format.validate(tc.opus); (err == nil) != tc.valid {
+			t.Errorf("validate(%+v) = %v, want valid=%v", tc.format, err, tc.valid)
+		}
+	}
+}
+
+// fakeOpusDecoder 将包内容原样作为PCM16输出
+type fakeOpusDecoder struct{}
+
+func (fakeOpusDecoder) Decode(packet []byte) ([]byte, error) { return packet, nil }
+
+func TestAudioConverterOggOpus(t *testing.T) {
+	opus := func(sampleRate, channels int) (OpusDecoder, error) { return fakeOpusDecoder{}, nil }
+	c, err := newAudioConverter(AudioFormat{SampleRate: 16000, Encoding: EncodingOggOpus, Channels: 1}, 16000, opus)
+	if err != nil {
+		t.Fatalf("Failed to create converter: %v", err)
+	}
+
+	writer := newOggOpusWriter(1)
+	stream := writer.headers(16000, 1)
+	stream = append(stream, writer.audio([][]byte{make([]byte, 640), make([]byte, 640)}, 1920, true)...)
+
+	// 头页之后的音频去掉前导样本（312个48kHz样本即104个16kHz样本）
+	var out []byte
+	for i := 0; i < len(stream); i += 100 {
+		end := i + 100
+		if end > len(stream) {
+			end = len(stream)
+		}
+		pcm, err := c.convert(stream[i:end])
+		if err != nil {
+			t.Fatalf("convert failed: %v", err)
+		}
+		out = append(out, pcm...)
+	}
+	if want := 1280 - 104*2; len(out) != want {
+		t.Errorf("Expected %d bytes, got %d", want, len(out))
+	}
+
+	if _, err := c.convert([]byte("not ogg data at all, definitely")); err == nil {
+		t.Error("Expected error for invalid ogg data")
+	}
+}
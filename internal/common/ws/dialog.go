@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxDialogReplySize 对话回调响应的最大字节数
+const maxDialogReplySize = 1 << 20
+
+// DialogTurn 交给对话处理器的一句用户话语
+type DialogTurn struct {
+	SessionID string `json:"session_id"`
+	TurnID    int    `json:"turn_id"` // 会话内从1开始递增
+	Text      string `json:"text"`
+	Language  string `json:"language,omitempty"`
+}
+
+// DialogReply 对话处理器的回复，Text为空时不播报
+type DialogReply struct {
+	Text      string   `json:"text"`
+	SpeakerID *int     `json:"speaker_id,omitempty"` // 为nil时使用会话的说话人
+	Speed     *float32 `json:"speed,omitempty"`      // 为nil时使用会话的语速
+}
+
+// validate 校验回复指定的说话人和语速，取值范围与连接参数相同
+func (r *DialogReply) validate() error {
+	if r.SpeakerID != nil && *r.SpeakerID < 0 {
+		return fmt.Errorf("invalid speaker_id: %d", *r.SpeakerID)
+	}
+	if r.Speed != nil && (*r.Speed < 0.5 || *r.Speed > 2.0) {
+		return fmt.Errorf("invalid speed: %v, must be between 0.5 and 2.0", *r.Speed)
+	}
+	return nil
+}
+
+// DialogHandler 对话处理器，根据用户话语生成回复
+type DialogHandler interface {
+	Reply(ctx context.Context, turn DialogTurn) (*DialogReply, error)
+}
+
+// WebhookDialog 通过HTTP回调生成回复：POST DialogTurn（JSON），响应DialogReply（JSON）
+type WebhookDialog struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookDialog 创建HTTP回调对话处理器
+func NewWebhookDialog(url string, timeout time.Duration) *WebhookDialog {
+	return &WebhookDialog{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Reply 调用对话回调
+func (d *WebhookDialog) Reply(ctx context.Context, turn DialogTurn) (*DialogReply, error) {
+	payload, err := json.Marshal(turn)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("dialog webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDialogReplySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dialog reply: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("dialog webhook returned status %d", resp.StatusCode)
+	}
+
+	var reply DialogReply
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return nil, fmt.Errorf("invalid dialog reply: %w", err)
+	}
+	return &reply, nil
+}
@@ -0,0 +1,44 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookDialog(t *testing.T) {
+	var received DialogTurn
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		switch received.Text {
+		case "fail":
+			w.WriteHeader(http.StatusBadGateway)
+		case "garbage":
+			w.Write([]byte("not json"))
+		default:
+			w.Write([]byte(`{"text":"好的","speaker_id":3}`))
+		}
+	}))
+	defer server.Close()
+
+	dialog := NewWebhookDialog(server.URL, 5*time.Second)
+	reply, err := dialog.Reply(context.Background(), DialogTurn{SessionID: "s1", TurnID: 2, Text: "开灯", Language: "zh"})
+	if err != nil {
+		t.Fatalf("Reply() error = %v", err)
+	}
+	if reply.Text != "好的" || reply.SpeakerID == nil || *reply.SpeakerID != 3 || reply.Speed != nil {
+		t.Errorf("Unexpected reply: %+v", reply)
+	}
+	if received != (DialogTurn{SessionID: "s1", TurnID: 2, Text: "开灯", Language: "zh"}) {
+		t.Errorf("Unexpected webhook payload: %+v", received)
+	}
+
+	for _, text := range []string{"fail", "garbage"} {
+		if _, err := dialog.Reply(context.Background(), DialogTurn{Text: text}); err == nil {
+			t.Errorf("Expected error for %q", text)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package ws
 
 import (
+	"context"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
 )
 
 // Upgrader WebSocket升级器
@@ -44,8 +48,8 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 	}
 
 	// 设置读取限制
-	if u.MaxMessageSize > 0 {
-		conn.SetReadLimit(u.MaxMessageSize)
+	if maxMessageSize := u.GetMaxMessageSize(); maxMessageSize > 0 {
+		conn.SetReadLimit(maxMessageSize)
 	}
 
 	// 设置读取超时
@@ -61,6 +65,16 @@ func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request, responseHeade
 	return conn, nil
 }
 
+// SetMaxMessageSize 更新最大消息大小（用于配置热加载，对之后建立的连接生效）
+func (u *Upgrader) SetMaxMessageSize(size int64) {
+	atomic.StoreInt64(&u.MaxMessageSize, size)
+}
+
+// GetMaxMessageSize 获取最大消息大小
+func (u *Upgrader) GetMaxMessageSize() int64 {
+	return atomic.LoadInt64(&u.MaxMessageSize)
+}
+
 // MessageHandler 消息处理器接口
 type MessageHandler interface {
 	HandleMessage(conn *websocket.Conn, messageType int, message []byte) error
@@ -146,3 +160,29 @@ func SetPongHandler(conn *websocket.Conn, pongWait time.Duration) {
 	})
 }
 
+
+// ProviderReporter 可选接口：报告模型使用的推理设备配置（由asr.Manager和tts.Manager实现）
+type ProviderReporter interface {
+	GetProviderConfig() config.ProviderConfig
+}
+
+// modelProvider 获取会话模型的推理设备配置，管理器未实现ProviderReporter时使用fallback
+func modelProvider(manager interface{}, fallback config.ProviderConfig) config.ProviderConfig {
+	if reporter, ok := manager.(ProviderReporter); ok {
+		return reporter.GetProviderConfig()
+	}
+	return fallback
+}
+
+// sessionContext 创建会话的上下文，会话关闭（超时清理、服务关闭等）或调用cancel时取消
+func sessionContext(sess *session.Session) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-sess.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
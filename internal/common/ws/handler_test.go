@@ -636,3 +636,12 @@ func TestSetPongHandler_WithZeroTimeout(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 }
 
+
+func TestUpgrader_SetMaxMessageSize(t *testing.T) {
+	upgrader := NewUpgrader(time.Second, time.Second, time.Second, time.Second, 1024, false)
+
+	upgrader.SetMaxMessageSize(4096)
+	if got := upgrader.GetMaxMessageSize(); got != 4096 {
+		t.Errorf("Expected max message size 4096, got %d", got)
+	}
+}
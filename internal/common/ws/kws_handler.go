@@ -0,0 +1,203 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+// KWSMessage 关键词检测消息结构
+type KWSMessage struct {
+	Type      string      `json:"type"`
+	SessionID string      `json:"session_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// KeywordEvent 关键词触发事件
+type KeywordEvent struct {
+	Keyword   string  `json:"keyword"`
+	Offset    float64 `json:"offset"`    // 触发位置：检测到关键词时会话已解码音频的时长（秒，精度0.1秒）
+	Boost     float32 `json:"boost"`     // 关键词生效的加分（配置值，不是检测置信度）
+	Threshold float32 `json:"threshold"` // 关键词生效的触发阈值
+}
+
+// KeywordSpotter 关键词检测器接口
+type KeywordSpotter interface {
+	NewStream(keywords []string) (KeywordStream, error) // keywords为在全局关键词之外追加的关键词
+	Keywords() []string                                 // 全局关键词
+	SampleRate() int
+}
+
+// KeywordStream 单个会话的关键词检测流
+type KeywordStream interface {
+	Accept(audio []byte) []KeywordEvent
+	Close()
+}
+
+// KWSHandler 关键词检测WebSocket处理器
+type KWSHandler struct {
+	sessionManager *session.Manager
+	spotter        KeywordSpotter
+	config         *config.STTConfig
+}
+
+// NewKWSHandler 创建关键词检测处理器
+func NewKWSHandler(sessionManager *session.Manager, spotter KeywordSpotter, cfg *config.STTConfig) *KWSHandler {
+	return &KWSHandler{
+		sessionManager: sessionManager,
+		spotter:        spotter,
+		config:         cfg,
+	}
+}
+
+// newStream 校验会话关键词并创建检测流
+func (h *KWSHandler) newStream(keywords []string) (KeywordStream, error) {
+	if len(keywords) > config.MaxKeywordsPerConnection {
+		return nil, fmt.Errorf("too many keywords: %d, max %d", len(keywords), config.MaxKeywordsPerConnection)
+	}
+	if err := config.ValidateKeywords(keywords); err != nil {
+		return nil, err
+	}
+	return h.spotter.NewStream(keywords)
+}
+
+// HandleConnection 处理WebSocket连接，keywords为该连接在全局关键词之外追加的关键词
+func (h *KWSHandler) HandleConnection(conn *websocket.Conn, keywords []string) {
+	stream, err := h.newStream(keywords)
+	if err != nil {
+		conn.WriteJSON(KWSMessage{Type: "error", Error: err.Error()})
+		conn.Close()
+		return
+	}
+	defer func() { stream.Close() }()
+
+	// 创建会话
+	sess, err := h.sessionManager.CreateSession(conn, h.config.Session.SendQueueSize)
+	if err != nil {
+		logger.Errorf("Failed to create session: %v", err)
+		conn.Close()
+		return
+	}
+	defer h.sessionManager.RemoveSession(sess.ID)
+
+	// 发送连接确认消息
+	if err := sess.Send(KWSMessage{
+		Type:      "connection",
+		SessionID: sess.ID,
+		Data: map[string]interface{}{
+			"status":     "connected",
+			"session_id": sess.ID,
+			"config": map[string]interface{}{
+				"sample_rate":    h.spotter.SampleRate(),
+				"format":         "pcm_s16le",
+				"keywords":       h.spotter.Keywords(),
+				"extra_keywords": keywords,
+			},
+		},
+	}); err != nil {
+		logger.Errorf("Failed to send connection message: %v", err)
+		sess.Close()
+		return
+	}
+
+	// 设置Pong处理器
+	SetPongHandler(conn, time.Duration(h.config.WebSocket.ReadTimeout)*time.Second)
+
+	for {
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logger.Errorf("WebSocket error: %v", err)
+			}
+			break
+		}
+
+		// 更新会话活动时间
+		h.sessionManager.UpdateActivity(sess.ID)
+
+		switch messageType {
+		case websocket.BinaryMessage:
+			// 音频数据，逐段检测
+			for _, event := range stream.Accept(message) {
+				sess.Send(KWSMessage{
+					Type:      "keyword",
+					SessionID: sess.ID,
+					Data: map[string]interface{}{
+						"keyword":   event.Keyword,
+						"offset":    event.Offset,
+						"boost":     event.Boost,
+						"threshold": event.Threshold,
+						"timestamp": time.Now().Unix(),
+					},
+				})
+			}
+
+		case websocket.TextMessage:
+			// 文本消息（控制消息）
+			var msg KWSMessage
+			if err := json.Unmarshal(message, &msg); err != nil {
+				logger.Warnf("Failed to parse message: %v", err)
+				continue
+			}
+
+			switch msg.Type {
+			case "config":
+				// 替换连接关键词，检测状态和时间偏移重新开始
+				var update struct {
+					Keywords []string `json:"keywords"`
+				}
+				raw, err := json.Marshal(msg.Data)
+				if err == nil {
+					err = json.Unmarshal(raw, &update)
+				}
+				var next KeywordStream
+				if err == nil {
+					next, err = h.newStream(update.Keywords)
+				}
+				if err != nil {
+					sess.Send(KWSMessage{
+						Type:      "error",
+						SessionID: sess.ID,
+						Error:     fmt.Sprintf("invalid config: %v", err),
+					})
+					continue
+				}
+				stream.Close()
+				stream, keywords = next, update.Keywords
+				sess.Send(KWSMessage{
+					Type:      "config",
+					SessionID: sess.ID,
+					Data:      map[string]interface{}{"keywords": keywords},
+				})
+
+			case "reset":
+				// 重置检测状态
+				next, err := h.newStream(keywords)
+				if err != nil {
+					sess.Send(KWSMessage{Type: "error", SessionID: sess.ID, Error: err.Error()})
+					continue
+				}
+				stream.Close()
+				stream = next
+				sess.Send(KWSMessage{
+					Type:      "reset",
+					SessionID: sess.ID,
+					Data:      map[string]string{"status": "ok"},
+				})
+
+			case "ping":
+				// 心跳响应
+				sess.Send(KWSMessage{
+					Type:      "pong",
+					SessionID: sess.ID,
+				})
+			}
+		}
+	}
+}
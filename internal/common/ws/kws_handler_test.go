@@ -0,0 +1,153 @@
+package ws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+// mockKeywordSpotter 模拟关键词检测器：音频首字节非0时触发最后一个关键词
+type mockKeywordSpotter struct {
+	mu      sync.Mutex
+	streams [][]string
+}
+
+func (m *mockKeywordSpotter) NewStream(keywords []string) (KeywordStream, error) {
+	for _, k := range keywords {
+		if k == "unknown" {
+			return nil, fmt.Errorf("invalid keywords")
+		}
+	}
+	m.mu.Lock()
+	m.streams = append(m.streams, keywords)
+	m.mu.Unlock()
+	return &mockKeywordStream{keywords: append([]string{"你好"}, keywords...)}, nil
+}
+
+func (m *mockKeywordSpotter) Keywords() []string { return []string{"n ǐ h ǎo @你好"} }
+
+func (m *mockKeywordSpotter) SampleRate() int { return 16000 }
+
+type mockKeywordStream struct {
+	keywords []string
+	samples  int
+}
+
+func (s *mockKeywordStream) Accept(audio []byte) []KeywordEvent {
+	s.samples += len(audio) / 2
+	if len(audio) == 0 || audio[0] == 0 {
+		return nil
+	}
+	return []KeywordEvent{{
+		Keyword:   s.keywords[len(s.keywords)-1],
+		Offset:    float64(s.samples) / 16000,
+		Boost:     1.0,
+		Threshold: 0.25,
+	}}
+}
+
+func (s *mockKeywordStream) Close() {}
+
+func TestKWSHandler(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	spotter := &mockKeywordSpotter{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewKWSHandler(session.NewManager(100, 30*time.Second), spotter, cfg)
+		var keywords []string
+		if k := r.URL.Query().Get("keywords"); k != "" {
+			keywords = []string{k}
+		}
+		handler.HandleConnection(conn, keywords)
+	}))
+	defer server.Close()
+
+	// 无效关键词在连接时被拒绝
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:]+"?keywords=unknown", nil)
+	if err != nil {
+		t.Skipf("Skipping test: cannot connect to test server: %v", err)
+		return
+	}
+	var msg KWSMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" {
+		t.Errorf("Expected error message, got %+v, %v", msg, err)
+	}
+	conn.Close()
+
+	conn, _, err = websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v, %v", msg, err)
+	}
+
+	// 静音不触发，非静音触发关键词
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 3200))
+	audio := make([]byte, 3200)
+	audio[0] = 1
+	conn.WriteMessage(websocket.BinaryMessage, audio)
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "keyword" {
+		t.Fatalf("Expected keyword message, got %+v, %v", msg, err)
+	}
+	data, _ := msg.Data.(map[string]interface{})
+	if data["keyword"] != "你好" || data["offset"] != 0.2 || data["boost"] != 1.0 || data["threshold"] != 0.25 {
+		t.Errorf("Unexpected keyword event: %+v", data)
+	}
+
+	// 替换连接关键词
+	conn.WriteJSON(KWSMessage{Type: "config", Data: map[string]interface{}{"keywords": []string{"k āi d ēng @开灯"}}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "config" {
+		t.Fatalf("Expected config message, got %+v, %v", msg, err)
+	}
+	conn.WriteMessage(websocket.BinaryMessage, audio)
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "keyword" {
+		t.Fatalf("Expected keyword message, got %+v, %v", msg, err)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["keyword"] != "k āi d ēng @开灯" || data["offset"] != 0.1 {
+		t.Errorf("Expected keyword from new stream, got %+v", data)
+	}
+
+	// 无效关键词被拒绝，保留原关键词
+	for _, keywords := range [][]string{{"unknown"}, {"a/b"}} {
+		conn.WriteJSON(KWSMessage{Type: "config", Data: map[string]interface{}{"keywords": keywords}})
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" {
+			t.Fatalf("Expected error message for %v, got %+v, %v", keywords, msg, err)
+		}
+	}
+
+	conn.WriteJSON(KWSMessage{Type: "reset"})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "reset" {
+		t.Fatalf("Expected reset message, got %+v, %v", msg, err)
+	}
+	spotter.mu.Lock()
+	last := spotter.streams[len(spotter.streams)-1]
+	spotter.mu.Unlock()
+	if len(last) != 1 || last[0] != "k āi d ēng @开灯" {
+		t.Errorf("Expected reset to keep connection keywords, got %v", last)
+	}
+}
@@ -0,0 +1,227 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Ogg封装（RFC 3533）中的Opus流（RFC 7845）
+const (
+	oggHeaderSize     = 27   // 页头固定部分的字节数，之后为分段表
+	oggFlagContinued  = 0x01 // 页的第一个包接续上一页
+	oggFlagBOS        = 0x02 // 逻辑流的第一页
+	oggFlagEOS        = 0x04 // 逻辑流的最后一页
+	oggPacketsPerPage = 25   // 写入时每页的包数（20ms帧时为0.5秒），单帧包不超过1275字节，分段数不会超过255
+	opusGranuleRate   = 48000
+	opusPreSkip       = 312 // libopus编码器的前导样本数（48kHz）
+)
+
+// oggCRCTable Ogg页校验和（多项式0x04c11db7，不反转）
+var oggCRCTable = func() (table [256]uint32) {
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggCRC 计算页的校验和，校验和字段（第22-25字节）按0计算
+func oggCRC(page []byte) uint32 {
+	var crc uint32
+	for i, b := range page {
+		if i >= 22 && i < 26 {
+			b = 0
+		}
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// oggOpusReader 流式解析Ogg Opus流：数据可以在任意位置拆分到多个消息，
+// 返回音频包并跳过OpusHead和OpusTags头。只支持单个逻辑流，新的BOS页开始新的串接流
+type oggOpusReader struct {
+	buf     []byte
+	packet  []byte // 跨页未结束的包
+	serial  uint32
+	started bool
+	headers int // 当前逻辑流已读取的头包数
+	preSkip int // OpusHead声明的、尚未丢弃的前导样本数（48kHz）
+}
+
+// feed 输入一段数据，返回其中完整的音频包，不足一页的数据保留到下次
+func (r *oggOpusReader) feed(data []byte) ([][]byte, error) {
+	r.buf = append(r.buf, data...)
+	var packets [][]byte
+	for len(r.buf) >= oggHeaderSize {
+		if !bytes.HasPrefix(r.buf, []byte("OggS")) || r.buf[4] != 0 {
+			return packets, fmt.Errorf("invalid ogg page")
+		}
+		segments := int(r.buf[26])
+		if len(r.buf) < oggHeaderSize+segments {
+			break
+		}
+		lacing := r.buf[oggHeaderSize : oggHeaderSize+segments]
+		size := oggHeaderSize + segments
+		for _, n := range lacing {
+			size += int(n)
+		}
+		if len(r.buf) < size {
+			break
+		}
+		page := r.buf[:size]
+		if oggCRC(page) != binary.LittleEndian.Uint32(page[22:]) {
+			return packets, fmt.Errorf("ogg page checksum mismatch")
+		}
+
+		flags, serial := page[5], binary.LittleEndian.Uint32(page[14:])
+		if flags&oggFlagBOS != 0 {
+			r.serial, r.started, r.headers, r.packet = serial, true, 0, nil
+		} else if !r.started || serial != r.serial {
+			return packets, fmt.Errorf("unexpected ogg stream %d, multiplexed streams are not supported", serial)
+		}
+		if flags&oggFlagContinued == 0 {
+			r.packet = nil
+		}
+
+		body := page[oggHeaderSize+segments:]
+		for _, n := range lacing {
+			r.packet = append(r.packet, body[:n]...)
+			body = body[n:]
+			if n == 255 {
+				continue // 包在下一个分段继续
+			}
+			packet := r.packet
+			r.packet = nil
+			if err := r.accept(packet, &packets); err != nil {
+				return packets, err
+			}
+		}
+		r.buf = r.buf[size:]
+	}
+	return packets, nil
+}
+
+// accept 处理一个完整的包：前两个包为OpusHead和OpusTags，其余为音频包
+func (r *oggOpusReader) accept(packet []byte, packets *[][]byte) error {
+	switch r.headers {
+	case 0:
+		preSkip, err := parseOpusHead(packet)
+		if err != nil {
+			return err
+		}
+		r.preSkip = preSkip
+		r.headers++
+	case 1:
+		if !bytes.HasPrefix(packet, []byte("OpusTags")) {
+			return fmt.Errorf("missing OpusTags header")
+		}
+		r.headers++
+	default:
+		if len(packet) > 0 {
+			*packets = append(*packets, packet)
+		}
+	}
+	return nil
+}
+
+// takePreSkip 取出尚未丢弃的前导样本数（48kHz）
+func (r *oggOpusReader) takePreSkip() int {
+	n := r.preSkip
+	r.preSkip = 0
+	return n
+}
+
+// parseOpusHead 解析OpusHead头，返回前导样本数
+func parseOpusHead(packet []byte) (int, error) {
+	if len(packet) < 19 || !bytes.HasPrefix(packet, []byte("OpusHead")) {
+		return 0, fmt.Errorf("missing OpusHead header")
+	}
+	if packet[8]>>4 != 0 {
+		return 0, fmt.Errorf("unsupported OpusHead version %d", packet[8])
+	}
+	if channels := packet[9]; channels < 1 || channels > maxInputChannels || packet[18] != 0 {
+		return 0, fmt.Errorf("only mono and stereo ogg opus streams are supported")
+	}
+	return int(binary.LittleEndian.Uint16(packet[10:])), nil
+}
+
+// oggOpusWriter 将Opus包写成一个Ogg Opus逻辑流
+type oggOpusWriter struct {
+	serial  uint32
+	seq     uint32
+	granule int64 // 已写入的样本数（48kHz，含前导样本）
+}
+
+// newOggOpusWriter 创建逻辑流
+func newOggOpusWriter(serial uint32) *oggOpusWriter {
+	return &oggOpusWriter{serial: serial}
+}
+
+// headers OpusHead页和OpusTags页，inputRate为编码前的采样率
+func (w *oggOpusWriter) headers(inputRate, channels int) []byte {
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1
+	head[9] = byte(channels)
+	binary.LittleEndian.PutUint16(head[10:], opusPreSkip)
+	binary.LittleEndian.PutUint32(head[12:], uint32(inputRate))
+
+	vendor := "AeroSpeech"
+	tags := make([]byte, 8+4+len(vendor)+4)
+	copy(tags, "OpusTags")
+	binary.LittleEndian.PutUint32(tags[8:], uint32(len(vendor)))
+	copy(tags[12:], vendor)
+
+	w.granule = 0
+	pages := w.page([][]byte{head}, oggFlagBOS)
+	return append(pages, w.page([][]byte{tags}, 0)...)
+}
+
+// audio 写入一页音频包，samples为这些包的有效样本数（48kHz，不含末帧补齐的静音），
+// last为true时作为流的最后一页
+func (w *oggOpusWriter) audio(packets [][]byte, samples int, last bool) []byte {
+	if w.granule == 0 {
+		w.granule = opusPreSkip
+	}
+	w.granule += int64(samples)
+	var flags byte
+	if last {
+		flags = oggFlagEOS
+	}
+	return w.page(packets, flags)
+}
+
+// page 生成一页，包不跨页
+func (w *oggOpusWriter) page(packets [][]byte, flags byte) []byte {
+	var lacing, body []byte
+	for _, packet := range packets {
+		for n := len(packet); ; n -= 255 {
+			if n < 255 {
+				lacing = append(lacing, byte(n))
+				break
+			}
+			lacing = append(lacing, 255)
+		}
+		body = append(body, packet...)
+	}
+
+	page := make([]byte, oggHeaderSize, oggHeaderSize+len(lacing)+len(body))
+	copy(page, "OggS")
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], uint64(w.granule))
+	binary.LittleEndian.PutUint32(page[14:], w.serial)
+	binary.LittleEndian.PutUint32(page[18:], w.seq)
+	page[26] = byte(len(lacing))
+	page = append(append(page, lacing...), body...)
+	binary.LittleEndian.PutUint32(page[22:], oggCRC(page))
+	w.seq++
+	return page
+}
@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOggOpusRoundTrip(t *testing.T) {
+	// 包含跨多个分段（>255字节）和恰好255字节的包
+	packets := [][]byte{bytes.Repeat([]byte{1}, 60), bytes.Repeat([]byte{2}, 255), bytes.Repeat([]byte{3}, 700)}
+	for i := 0; i < 30; i++ {
+		packets = append(packets, []byte{byte(i), 0xfc})
+	}
+
+	writer := newOggOpusWriter(7)
+	stream := writer.headers(24000, 1)
+	for i := 0; i < len(packets); i += oggPacketsPerPage {
+		end := i + oggPacketsPerPage
+		if end > len(packets) {
+			end = len(packets)
+		}
+		stream = append(stream, writer.audio(packets[i:end], (end-i)*960, end == len(packets))...)
+	}
+
+	// 按任意位置拆分输入
+	r := &oggOpusReader{}
+	var got [][]byte
+	for i := 0; i < len(stream); i += 37 {
+		end := i + 37
+		if end > len(stream) {
+			end = len(stream)
+		}
+		out, err := r.feed(stream[i:end])
+		if err != nil {
+			t.Fatalf("feed failed at %d: %v", i, err)
+		}
+		got = append(got, out...)
+	}
+	if len(got) != len(packets) {
+		t.Fatalf("Expected %d packets, got %d", len(packets), len(got))
+	}
+	for i := range packets {
+		if !bytes.Equal(got[i], packets[i]) {
+			t.Fatalf("Packet %d differs", i)
+		}
+	}
+	if skip := r.takePreSkip(); skip != opusPreSkip {
+		t.Errorf("Expected pre-skip %d, got %d", opusPreSkip, skip)
+	}
+	if skip := r.takePreSkip(); skip != 0 {
+		t.Errorf("Expected pre-skip to be consumed, got %d", skip)
+	}
+}
+
+func TestOggOpusReaderErrors(t *testing.T) {
+	writer := newOggOpusWriter(1)
+	headers := writer.headers(16000, 1)
+
+	// 校验和错误
+	corrupt := append([]byte(nil), headers...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	if _, err := (&oggOpusReader{}).feed(corrupt); err == nil {
+		t.Error("Expected checksum error")
+	}
+
+	// 不是Ogg流
+	if _, err := (&oggOpusReader{}).feed(make([]byte, 64)); err == nil {
+		t.Error("Expected error for non-ogg data")
+	}
+
+	// 其他逻辑流的页
+	other := newOggOpusWriter(2)
+	other.headers(16000, 1)
+	r := &oggOpusReader{}
+	if _, err := r.feed(headers); err != nil {
+		t.Fatalf("Failed to read headers: %v", err)
+	}
+	if _, err := r.feed(other.audio([][]byte{{1}}, 960, true)); err == nil {
+		t.Error("Expected error for multiplexed stream")
+	}
+
+	// 缺少OpusHead
+	bad := newOggOpusWriter(3)
+	if _, err := (&oggOpusReader{}).feed(bad.page([][]byte{[]byte("OpusTags")}, oggFlagBOS)); err == nil {
+		t.Error("Expected error for missing OpusHead")
+	}
+}
@@ -0,0 +1,363 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+// 协议版本：客户端通过Sec-WebSocket-Protocol协商子协议，或在连接后发送hello消息
+const (
+	ProtocolVersion = 1
+	Subprotocol     = "aerospeech.v1"
+)
+
+// 错误消息的错误码（error消息的code字段）
+const (
+	ErrCodeInvalidMessage     = "INVALID_MESSAGE"     // 消息不是JSON对象或缺少type
+	ErrCodeUnknownType        = "UNKNOWN_TYPE"        // 不支持的消息类型
+	ErrCodeInvalidRequest     = "INVALID_REQUEST"     // 消息数据或参数无效
+	ErrCodeUnsupportedVersion = "UNSUPPORTED_VERSION" // hello中没有服务端支持的协议版本
+	ErrCodeUnexpectedBinary   = "UNEXPECTED_BINARY"   // 不接受二进制消息的接口收到二进制消息
+	ErrCodeNotFound           = "NOT_FOUND"           // 取消的请求不存在或已完成
+	ErrCodeQueueFull          = "QUEUE_FULL"          // 排队的请求或语句过多
+	ErrCodeNotEnabled         = "NOT_ENABLED"         // 请求的功能未启用
+	ErrCodeProcessingFailed   = "PROCESSING_FAILED"   // 识别、合成等处理失败
+	ErrCodeResumeFailed       = "RESUME_FAILED"       // 会话不存在、已过期或未确认的消息已不再保留
+)
+
+// protocolError 带错误码的错误，发送为error消息
+type protocolError struct {
+	code string
+	msg  string
+}
+
+func (e *protocolError) Error() string { return e.msg }
+
+// newProtocolError 创建带错误码的错误
+func newProtocolError(code, format string, args ...interface{}) error {
+	return &protocolError{code: code, msg: fmt.Sprintf(format, args...)}
+}
+
+// errorCode 错误对应的错误码，未指定时为defaultCode
+func errorCode(err error, defaultCode string) string {
+	if pe, ok := err.(*protocolError); ok {
+		return pe.code
+	}
+	return defaultCode
+}
+
+// HelloRequest hello消息：客户端支持的协议版本
+type HelloRequest struct {
+	Versions []int `json:"versions"`
+}
+
+// HelloData hello回复：会话使用的协议版本
+type HelloData struct {
+	Version     int    `json:"version"`
+	Subprotocol string `json:"subprotocol"`
+}
+
+// ErrorData error消息的数据，关联到具体请求时携带请求ID
+type ErrorData struct {
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// StatusData 只有状态的回复（如reset）
+type StatusData struct {
+	Status string `json:"status"`
+}
+
+// SessionStats stats消息：会话时长和音频流量，PCM字节数为同一段音频按模型格式
+// （16位单声道PCM）传输时的大小，可据此计算压缩编码节省的带宽
+type SessionStats struct {
+	DurationMs int64              `json:"duration_ms"`
+	Audio      session.AudioStats `json:"audio"`
+}
+
+// sessionStats 会话的stats消息数据
+func sessionStats(sess *session.Session) SessionStats {
+	return SessionStats{DurationMs: sess.GetDuration().Milliseconds(), Audio: sess.AudioStats()}
+}
+
+// STTConnectionData STT连接确认消息的数据
+type STTConnectionData struct {
+	Status          string              `json:"status"`
+	SessionID       string              `json:"session_id"`
+	ProtocolVersion int                 `json:"protocol_version"`
+	Config          STTConnectionConfig `json:"config"`
+	Resume          *ResumeInfo         `json:"resume,omitempty"` // 开启会话恢复时的恢复令牌
+}
+
+// STTConnectionConfig STT会话的音频格式和初始参数
+type STTConnectionConfig struct {
+	SampleRate      int               `json:"sample_rate"`
+	ChunkSize       int               `json:"chunk_size"`
+	Format          string            `json:"format"`
+	Provider        string            `json:"provider"`
+	GPUAvailable    bool              `json:"gpu_available"`
+	GPUDeviceID     int               `json:"gpu_device_id"`
+	Model           string            `json:"model"`
+	Language        string            `json:"language"`
+	Options         config.ASROptions `json:"options"`
+	IdentifySpeaker bool              `json:"identify_speaker"`
+	Enhance         bool              `json:"enhance"`
+	Encodings       []string          `json:"encodings"`     // start消息可声明的音频编码
+	VADAvailable    bool              `json:"vad_available"` // start消息是否可以开启VAD分句
+}
+
+// VADOptions 会话的VAD分句参数，为0的字段使用服务端配置
+type VADOptions struct {
+	Threshold          float32 `json:"threshold,omitempty"`            // 语音概率阈值，(0, 1)
+	MinSilenceDuration float32 `json:"min_silence_duration,omitempty"` // 判定语句结束的静音时长（秒）
+	MinSpeechDuration  float32 `json:"min_speech_duration,omitempty"`  // 最短语音时长（秒）
+	MaxSpeechDuration  float32 `json:"max_speech_duration,omitempty"`  // 单句最长时长（秒），超过时强制切分
+}
+
+// maxVADSpeechDuration 会话可设置的单句最长时长（秒）
+const maxVADSpeechDuration = 30
+
+// validate 检查VAD参数
+func (o *VADOptions) validate() error {
+	if o.Threshold < 0 || o.Threshold >= 1 {
+		return fmt.Errorf("vad threshold must be between 0 and 1")
+	}
+	if o.MinSilenceDuration < 0 || o.MinSpeechDuration < 0 || o.MaxSpeechDuration < 0 {
+		return fmt.Errorf("vad durations must not be negative")
+	}
+	if o.MaxSpeechDuration > maxVADSpeechDuration {
+		return fmt.Errorf("vad max_speech_duration must not exceed %d seconds", maxVADSpeechDuration)
+	}
+	if o.MaxSpeechDuration > 0 && o.MaxSpeechDuration <= o.MinSpeechDuration {
+		return fmt.Errorf("vad max_speech_duration must be greater than min_speech_duration")
+	}
+	return nil
+}
+
+// STTStartRequest start消息：声明之后发送的音频格式和识别方式，未出现的字段使用默认值
+type STTStartRequest struct {
+	SampleRate int         `json:"sample_rate,omitempty"` // 默认为服务端采样率
+	Encoding   string      `json:"encoding,omitempty"`    // 默认pcm_s16le，ogg_opus时二进制消息可以在任意位置拆分
+	Channels   int         `json:"channels,omitempty"`    // 默认1，多声道混合为单声道
+	Language   string      `json:"language,omitempty"`    // 为空时保持会话语言
+	Interim    bool        `json:"interim,omitempty"`     // 是否发送中间结果，需要开启VAD
+	VAD        *VADOptions `json:"vad,omitempty"`         // 开启VAD按语句识别
+}
+
+// STTStartData start消息确认的音频格式和识别方式
+type STTStartData struct {
+	AudioFormat
+	Language string      `json:"language"`
+	Interim  bool        `json:"interim"`
+	VAD      *VADOptions `json:"vad,omitempty"`
+}
+
+// STTConfigUpdate config消息：修改会话解码参数，未出现的字段保持不变
+type STTConfigUpdate struct {
+	config.ASROptions
+	IdentifySpeaker *bool `json:"identify_speaker,omitempty"`
+	Enhance         *bool `json:"enhance,omitempty"`
+}
+
+// STTSessionConfig config消息确认的会话参数
+type STTSessionConfig struct {
+	config.ASROptions
+	IdentifySpeaker bool `json:"identify_speaker"`
+	Enhance         bool `json:"enhance"`
+}
+
+// STTResult 识别结果，SenseVoice模型附带语言/情感/事件标签；
+// 开启VAD时同一语句的中间结果和最终结果segment_id相同
+type STTResult struct {
+	Text             string        `json:"text"`
+	Final            bool          `json:"final"`
+	SegmentID        int           `json:"segment_id,omitempty"`
+	Model            string        `json:"model"`
+	Language         string        `json:"language"`
+	Timestamp        int64         `json:"timestamp"`
+	Lang             string        `json:"lang,omitempty"`
+	Emotion          string        `json:"emotion,omitempty"`
+	Event            string        `json:"event,omitempty"`
+	EnhanceLatencyMs *float64      `json:"enhance_latency_ms,omitempty"`
+	Speaker          *SpeakerMatch `json:"speaker,omitempty"`
+}
+
+// TTSConnectionData TTS连接确认消息的数据
+type TTSConnectionData struct {
+	Status          string              `json:"status"`
+	SessionID       string              `json:"session_id"`
+	ProtocolVersion int                 `json:"protocol_version"`
+	Config          TTSConnectionConfig `json:"config"`
+	Resume          *ResumeInfo         `json:"resume,omitempty"` // 开启会话恢复时的恢复令牌
+}
+
+// TTSConnectionConfig TTS会话的音频格式和默认模型
+type TTSConnectionConfig struct {
+	SampleRate   int      `json:"sample_rate"`
+	Format       string   `json:"format"`
+	Provider     string   `json:"provider"`
+	GPUAvailable bool     `json:"gpu_available"`
+	GPUDeviceID  int      `json:"gpu_device_id"`
+	Model        string   `json:"model"`
+	Encodings    []string `json:"encodings"` // start消息可选择的输出编码
+}
+
+// TTSStartRequest start消息：选择会话的输出音频编码，须在第一条合成请求之前发送
+type TTSStartRequest struct {
+	Encoding string `json:"encoding,omitempty"` // 默认pcm_s16le
+	Bitrate  int    `json:"bitrate,omitempty"`  // Opus码率（bps），默认24000
+}
+
+// TTSStartData start消息确认的输出音频编码：opus时每个二进制消息为一个Opus包，
+// ogg_opus时每条请求的音频是一个完整的Ogg Opus流，每个二进制消息为若干Ogg页
+type TTSStartData struct {
+	Encoding        string `json:"encoding"`
+	Bitrate         int    `json:"bitrate,omitempty"`
+	FrameDurationMs int    `json:"frame_duration_ms,omitempty"` // 每个Opus包的时长
+}
+
+// SynthesizeRequest synthesize消息：一条合成请求
+type SynthesizeRequest struct {
+	RequestID string   `json:"request_id,omitempty"` // 为空时由服务端分配
+	Text      string   `json:"text"`
+	SpeakerID int      `json:"speaker_id,omitempty"`
+	Speed     *float32 `json:"speed,omitempty"` // 默认1.0
+	Model     string   `json:"model,omitempty"` // 为空时使用会话模型
+}
+
+// TTSRequestRef 指向一条合成请求（cancel消息，started/cancelled事件）
+type TTSRequestRef struct {
+	RequestID string `json:"request_id"`
+}
+
+// TTSQueued queued事件：请求已入队，position为排在它前面（包括正在合成）的请求数
+type TTSQueued struct {
+	RequestID string `json:"request_id"`
+	Position  int    `json:"position"`
+}
+
+// TTSComplete complete事件：started之后的chunks个二进制消息（共bytes字节）是该请求的全部音频，
+// pcm_bytes为编码前的PCM字节数
+type TTSComplete struct {
+	RequestID string `json:"request_id"`
+	Bytes     int    `json:"bytes"`
+	PCMBytes  int    `json:"pcm_bytes"`
+	Chunks    int    `json:"chunks"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// TTSClearResult clear消息的回复
+type TTSClearResult struct {
+	Cancelled int `json:"cancelled"`
+}
+
+// MessageSpec 协议中的一种JSON消息
+type MessageSpec struct {
+	Type      string
+	Direction string      // client（客户端发送）或server（服务端发送）
+	Data      interface{} // data字段的类型，为nil时没有data
+	Error     bool        // 是否为error消息（带error和code字段）
+}
+
+// STTProtocol STT WebSocket协议的JSON消息，音频为二进制消息（格式由start消息声明）
+var STTProtocol = []MessageSpec{
+	{Type: "hello", Direction: "client", Data: HelloRequest{}},
+	{Type: "start", Direction: "client", Data: STTStartRequest{}},
+	{Type: "config", Direction: "client", Data: STTConfigUpdate{}},
+	{Type: "reset", Direction: "client"},
+	{Type: "stats", Direction: "client"},
+	{Type: "ack", Direction: "client", Data: AckRequest{}},
+	{Type: "ping", Direction: "client"},
+	{Type: "connection", Direction: "server", Data: STTConnectionData{}},
+	{Type: "resumed", Direction: "server", Data: ResumedData{}},
+	{Type: "hello", Direction: "server", Data: HelloData{}},
+	{Type: "start", Direction: "server", Data: STTStartData{}},
+	{Type: "result", Direction: "server", Data: STTResult{}},
+	{Type: "config", Direction: "server", Data: STTSessionConfig{}},
+	{Type: "reset", Direction: "server", Data: StatusData{}},
+	{Type: "stats", Direction: "server", Data: SessionStats{}},
+	{Type: "pong", Direction: "server"},
+	{Type: "error", Direction: "server", Error: true},
+}
+
+// TTSProtocol TTS WebSocket协议的JSON消息，音频为服务端发送的PCM16二进制消息
+var TTSProtocol = []MessageSpec{
+	{Type: "hello", Direction: "client", Data: HelloRequest{}},
+	{Type: "start", Direction: "client", Data: TTSStartRequest{}},
+	{Type: "synthesize", Direction: "client", Data: SynthesizeRequest{}},
+	{Type: "cancel", Direction: "client", Data: TTSRequestRef{}},
+	{Type: "clear", Direction: "client"},
+	{Type: "stats", Direction: "client"},
+	{Type: "ack", Direction: "client", Data: AckRequest{}},
+	{Type: "ping", Direction: "client"},
+	{Type: "connection", Direction: "server", Data: TTSConnectionData{}},
+	{Type: "resumed", Direction: "server", Data: ResumedData{}},
+	{Type: "hello", Direction: "server", Data: HelloData{}},
+	{Type: "start", Direction: "server", Data: TTSStartData{}},
+	{Type: "queued", Direction: "server", Data: TTSQueued{}},
+	{Type: "started", Direction: "server", Data: TTSRequestRef{}},
+	{Type: "complete", Direction: "server", Data: TTSComplete{}},
+	{Type: "cancelled", Direction: "server", Data: TTSRequestRef{}},
+	{Type: "clear", Direction: "server", Data: TTSClearResult{}},
+	{Type: "stats", Direction: "server", Data: SessionStats{}},
+	{Type: "pong", Direction: "server"},
+	{Type: "error", Direction: "server", Data: ErrorData{}, Error: true},
+}
+
+// ProtocolHeader 客户端请求了支持的子协议时返回选定子协议的升级响应头，否则返回nil
+func ProtocolHeader(r *http.Request) http.Header {
+	for _, protocol := range websocket.Subprotocols(r) {
+		if protocol == Subprotocol {
+			return http.Header{"Sec-Websocket-Protocol": {Subprotocol}}
+		}
+	}
+	return nil
+}
+
+// inboundMessage 客户端发送的JSON消息，data按消息类型解码
+type inboundMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// parseInbound 解析客户端消息
+func parseInbound(message []byte) (*inboundMessage, error) {
+	var msg inboundMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, newProtocolError(ErrCodeInvalidMessage, "invalid message: %v", err)
+	}
+	if msg.Type == "" {
+		return nil, newProtocolError(ErrCodeInvalidMessage, "invalid message: type is required")
+	}
+	return &msg, nil
+}
+
+// decode 将data解码到v，没有data时保持v不变
+func (m *inboundMessage) decode(v interface{}) error {
+	if len(m.Data) == 0 || bytes.Equal(m.Data, []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(m.Data, v); err != nil {
+		return newProtocolError(ErrCodeInvalidRequest, "invalid %s data: %v", m.Type, err)
+	}
+	return nil
+}
+
+// negotiateHello 处理hello消息，客户端不支持当前协议版本时返回错误
+func negotiateHello(msg *inboundMessage) (*HelloData, error) {
+	var req HelloRequest
+	if err := msg.decode(&req); err != nil {
+		return nil, err
+	}
+	for _, version := range req.Versions {
+		if version == ProtocolVersion {
+			return &HelloData{Version: ProtocolVersion, Subprotocol: Subprotocol}, nil
+		}
+	}
+	return nil, newProtocolError(ErrCodeUnsupportedVersion, "unsupported protocol versions %v, server supports %d", req.Versions, ProtocolVersion)
+}
@@ -0,0 +1,415 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+var updateSchemas = flag.Bool("update", false, "重新生成docs/schemas/ws下的协议Schema")
+
+// schemaDir 协议Schema文件目录
+var schemaDir = filepath.Join("..", "..", "..", "docs", "schemas", "ws")
+
+func TestProtocolSchemas(t *testing.T) {
+	for name, schema := range ProtocolSchemas() {
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			t.Fatalf("Failed to marshal %s: %v", name, err)
+		}
+		data = append(data, '\n')
+		path := filepath.Join(schemaDir, name)
+		if *updateSchemas {
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", path, err)
+			}
+			continue
+		}
+		existing, err := os.ReadFile(path)
+		if err != nil || !bytes.Equal(existing, data) {
+			t.Errorf("%s is out of date, run: go test ./internal/common/ws -run TestProtocolSchemas -update", path)
+		}
+	}
+}
+
+// loadSchema 读取接口的协议Schema（经JSON往返，便于按通用类型校验）
+func loadSchema(t *testing.T, name string) map[string]interface{} {
+	t.Helper()
+	data, _ := json.Marshal(ProtocolSchemas()[name])
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to load schema %s: %v", name, err)
+	}
+	return schema
+}
+
+// validateSchema 按Schema校验JSON值，只支持协议Schema用到的关键字
+func validateSchema(root, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := root["$defs"].(map[string]interface{})[strings.TrimPrefix(ref, "#/$defs/")].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unresolved $ref %s", path, ref)
+		}
+		return validateSchema(root, def, value, path)
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		return fmt.Errorf("%s: expected %v, got %v", path, c, value)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || reflect.DeepEqual(e, value)
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	case "number", "integer":
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, value)
+		}
+		if schema["type"] == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("%s: expected integer, got %v", path, n)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+		for i, item := range items {
+			if err := validateSchema(root, schema["items"].(map[string]interface{}), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, value)
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, name := range required {
+			if _, ok := fields[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required field %s", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, field := range fields {
+			fieldSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				fieldSchema, ok = schema["additionalProperties"].(map[string]interface{})
+			}
+			if !ok {
+				return fmt.Errorf("%s: undocumented field %s", path, name)
+			}
+			if err := validateSchema(root, fieldSchema, field, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// protocolConn 协议测试连接，收到的每条JSON消息都按服务端消息的Schema校验
+type protocolConn struct {
+	t      *testing.T
+	conn   *websocket.Conn
+	schema map[string]interface{}
+	binary [][]byte // 上一条JSON消息之后收到的二进制消息
+}
+
+// send 发送文本消息
+func (c *protocolConn) send(message string) {
+	c.t.Helper()
+	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+		c.t.Fatalf("Failed to send %s: %v", message, err)
+	}
+}
+
+// read 读取下一条JSON消息并校验，之前的二进制消息保存在binary中
+func (c *protocolConn) read() map[string]interface{} {
+	c.t.Helper()
+	c.binary = nil
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.t.Fatalf("Failed to read message: %v", err)
+		}
+		if messageType == websocket.BinaryMessage {
+			c.binary = append(c.binary, data)
+			continue
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.t.Fatalf("Server sent invalid JSON %q: %v", data, err)
+		}
+		def, ok := c.schema["$defs"].(map[string]interface{})["server."+fmt.Sprint(msg["type"])].(map[string]interface{})
+		if !ok {
+			c.t.Fatalf("Server sent undocumented message type: %s", data)
+		}
+		if err := validateSchema(c.schema, def, msg, "$"); err != nil {
+			c.t.Fatalf("Message does not conform to schema: %v\n%s", err, data)
+		}
+		return msg
+	}
+}
+
+// expect 读取下一条消息，类型必须为msgType，返回消息数据
+func (c *protocolConn) expect(msgType string) map[string]interface{} {
+	c.t.Helper()
+	msg := c.read()
+	if msg["type"] != msgType {
+		c.t.Fatalf("Expected %s message, got %v", msgType, msg)
+	}
+	data, _ := msg["data"].(map[string]interface{})
+	return data
+}
+
+// expectError 读取下一条消息，必须为错误码为code的error消息
+func (c *protocolConn) expectError(code string) map[string]interface{} {
+	c.t.Helper()
+	msg := c.read()
+	if msg["type"] != "error" || msg["code"] != code {
+		c.t.Fatalf("Expected %s error, got %v", code, msg)
+	}
+	data, _ := msg["data"].(map[string]interface{})
+	return data
+}
+
+// protocolEndpoint 被测试的WebSocket接口
+type protocolEndpoint struct {
+	name   string
+	schema string
+	handle func(conn *websocket.Conn)
+}
+
+func protocolEndpoints() []protocolEndpoint {
+	sttConfig := &config.STTConfig{
+		Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 1024},
+		Session:   config.SessionConfig{SendQueueSize: 100},
+		WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		ASR:       config.ASRConfig{Provider: config.ProviderConfig{Provider: "cpu"}},
+	}
+	ttsConfig := &config.TTSConfig{
+		Audio:     config.AudioConfig{SampleRate: 24000},
+		Session:   config.SessionConfig{SendQueueSize: 100},
+		WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		TTS:       config.TTSModelConfig{Provider: config.ProviderConfig{Provider: "cpu"}},
+	}
+	return []protocolEndpoint{
+		{name: "stt", schema: "stt.v1.json", handle: func(conn *websocket.Conn) {
+			asrManager := &mockASRManager{transcribeResult: "测试文本"}
+			NewSTTHandler(session.NewManager(100, 30*time.Second), asrManager, sttConfig).HandleConnection(conn)
+		}},
+		{name: "tts", schema: "tts.v1.json", handle: func(conn *websocket.Conn) {
+			ttsManager := &mockTTSManager{synthesizeResult: make([]byte, 10000)}
+			NewTTSHandler(session.NewManager(100, 30*time.Second), ttsManager, ttsConfig).HandleConnection(conn)
+		}},
+	}
+}
+
+// dialProtocol 连接接口，返回连接和connection消息的数据
+func dialProtocol(t *testing.T, endpoint protocolEndpoint, subprotocols []string) (*protocolConn, map[string]interface{}) {
+	t.Helper()
+	upgrader := NewUpgrader(0, 0, 0, 0, 0, false)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, ProtocolHeader(r))
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		endpoint.handle(conn)
+	}))
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{Subprotocols: subprotocols}
+	conn, _, err := dialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := &protocolConn{t: t, conn: conn, schema: loadSchema(t, endpoint.schema)}
+	return c, c.expect("connection")
+}
+
+func TestProtocol_Negotiation(t *testing.T) {
+	for _, endpoint := range protocolEndpoints() {
+		t.Run(endpoint.name, func(t *testing.T) {
+			// 通过Sec-WebSocket-Protocol协商
+			c, connection := dialProtocol(t, endpoint, []string{"aerospeech.v2", Subprotocol})
+			if c.conn.Subprotocol() != Subprotocol {
+				t.Errorf("Expected subprotocol %s, got %q", Subprotocol, c.conn.Subprotocol())
+			}
+			if connection["protocol_version"] != float64(ProtocolVersion) {
+				t.Errorf("Expected protocol_version %d, got %v", ProtocolVersion, connection["protocol_version"])
+			}
+
+			// 未协商子协议时通过hello消息协商
+			c, _ = dialProtocol(t, endpoint, nil)
+			if c.conn.Subprotocol() != "" {
+				t.Errorf("Expected no subprotocol, got %q", c.conn.Subprotocol())
+			}
+			c.send(`{"type":"hello","data":{"versions":[2,1]}}`)
+			if hello := c.expect("hello"); hello["version"] != float64(ProtocolVersion) || hello["subprotocol"] != Subprotocol {
+				t.Errorf("Unexpected hello reply: %v", hello)
+			}
+			c.send(`{"type":"hello","data":{"versions":[2]}}`)
+			c.expectError(ErrCodeUnsupportedVersion)
+			c.send(`{"type":"hello","data":{"versions":"1"}}`)
+			c.expectError(ErrCodeInvalidRequest)
+		})
+	}
+}
+
+func TestProtocol_InvalidInput(t *testing.T) {
+	common := []struct {
+		message string
+		code    string
+	}{
+		{`not json`, ErrCodeInvalidMessage},
+		{`[1,2]`, ErrCodeInvalidMessage},
+		{`{"data":{}}`, ErrCodeInvalidMessage},
+		{`{"type":"bogus"}`, ErrCodeUnknownType},
+	}
+	specific := map[string][]struct {
+		message string
+		code    string
+	}{
+		"stt": {
+			{`{"type":"config","data":{"itn":"yes"}}`, ErrCodeInvalidRequest},
+			{`{"type":"config","data":{"decoding_method":"exhaustive"}}`, ErrCodeInvalidRequest},
+			{`{"type":"config","data":{"identify_speaker":true}}`, ErrCodeNotEnabled},
+			{`{"type":"config","data":{"hotword_sets":["aviation"]}}`, ErrCodeNotEnabled},
+			{`{"type":"start","data":{"encoding":"aac"}}`, ErrCodeInvalidRequest},
+			{`{"type":"start","data":{"interim":true}}`, ErrCodeInvalidRequest},
+			{`{"type":"start","data":{"vad":{}}}`, ErrCodeNotEnabled},
+			{`{"type":"start","data":{"encoding":"opus","sample_rate":48000}}`, ErrCodeNotEnabled},
+			{`{"type":"start","data":{"encoding":"ogg_opus","sample_rate":48000}}`, ErrCodeNotEnabled},
+		},
+		"tts": {
+			{`{"type":"synthesize","data":{"text":1}}`, ErrCodeInvalidRequest},
+			{`{"type":"synthesize","data":"text"}`, ErrCodeInvalidRequest},
+			{`{"type":"synthesize","data":{"text":"你好","model":"missing"}}`, ErrCodeInvalidRequest},
+			{`{"type":"cancel","data":{}}`, ErrCodeInvalidRequest},
+			{`{"type":"cancel","data":{"request_id":"missing"}}`, ErrCodeNotFound},
+			{`{"type":"start","data":{"encoding":"opus"}}`, ErrCodeNotEnabled},
+			{`{"type":"start","data":{"encoding":"flac"}}`, ErrCodeInvalidRequest},
+			{`{"type":"start","data":{"bitrate":24000}}`, ErrCodeInvalidRequest},
+		},
+	}
+
+	for _, endpoint := range protocolEndpoints() {
+		t.Run(endpoint.name, func(t *testing.T) {
+			c, _ := dialProtocol(t, endpoint, []string{Subprotocol})
+			for _, tc := range append(common, specific[endpoint.name]...) {
+				c.send(tc.message)
+				c.expectError(tc.code)
+			}
+			// 无效消息后连接仍可用
+			c.send(`{"type":"ping"}`)
+			c.expect("pong")
+		})
+	}
+}
+
+func TestProtocol_STTSession(t *testing.T) {
+	c, connection := dialProtocol(t, protocolEndpoints()[0], []string{Subprotocol})
+	if cfg, _ := connection["config"].(map[string]interface{}); cfg["format"] != "pcm_s16le" || cfg["sample_rate"] != float64(16000) {
+		t.Errorf("Unexpected connection config: %v", connection)
+	}
+
+	c.send(`{"type":"start","data":{"sample_rate":16000,"encoding":"pcm_s16le","channels":1}}`)
+	if start := c.expect("start"); start["encoding"] != "pcm_s16le" || start["interim"] != false {
+		t.Errorf("Unexpected start reply: %v", start)
+	}
+	c.send(`{"type":"config","data":{"language":"zh","hotwords":["左侧舱门"]}}`)
+	if cfg := c.expect("config"); cfg["language"] != "zh" {
+		t.Errorf("Unexpected config reply: %v", cfg)
+	}
+	c.conn.WriteMessage(websocket.BinaryMessage, make([]byte, 1024))
+	if result := c.expect("result"); result["text"] != "测试文本" || result["language"] != "zh" {
+		t.Errorf("Unexpected result: %v", result)
+	}
+	c.send(`{"type":"stats"}`)
+	if audio, _ := c.expect("stats")["audio"].(map[string]interface{}); audio["bytes_in"] != float64(1024) || audio["pcm_bytes_in"] != float64(1024) {
+		t.Errorf("Unexpected stats: %v", audio)
+	}
+	c.send(`{"type":"reset"}`)
+	if reset := c.expect("reset"); reset["status"] != "ok" {
+		t.Errorf("Unexpected reset reply: %v", reset)
+	}
+}
+
+func TestProtocol_TTSSession(t *testing.T) {
+	c, connection := dialProtocol(t, protocolEndpoints()[1], []string{Subprotocol})
+	if cfg, _ := connection["config"].(map[string]interface{}); cfg["format"] != "pcm_s16le" || cfg["sample_rate"] != float64(24000) {
+		t.Errorf("Unexpected connection config: %v", connection)
+	}
+
+	// 音频只能由服务端发送
+	c.conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2})
+	c.expectError(ErrCodeUnexpectedBinary)
+
+	c.send(`{"type":"start","data":{"encoding":"pcm_s16le"}}`)
+	if start := c.expect("start"); start["encoding"] != "pcm_s16le" {
+		t.Errorf("Unexpected start reply: %v", start)
+	}
+	c.send(`{"type":"synthesize","data":{"request_id":"a","text":"欢迎登机","speed":1.2}}`)
+	if queued := c.expect("queued"); queued["request_id"] != "a" || queued["position"] != float64(0) {
+		t.Errorf("Unexpected queued event: %v", queued)
+	}
+	c.expect("started")
+	if len(c.binary) != 0 {
+		t.Errorf("Expected no audio before started, got %d messages", len(c.binary))
+	}
+
+	// started与complete之间的二进制消息是该请求的全部音频
+	complete := c.expect("complete")
+	total := 0
+	for _, chunk := range c.binary {
+		total += len(chunk)
+	}
+	if complete["request_id"] != "a" || complete["chunks"] != float64(len(c.binary)) || complete["bytes"] != float64(total) || total != 10000 {
+		t.Errorf("complete %v does not match %d audio messages (%d bytes)", complete, len(c.binary), total)
+	}
+	c.send(`{"type":"stats"}`)
+	if audio, _ := c.expect("stats")["audio"].(map[string]interface{}); audio["bytes_out"] != float64(10000) || audio["saving_out"] != float64(0) {
+		t.Errorf("Unexpected stats: %v", audio)
+	}
+
+	c.send(`{"type":"start","data":{"encoding":"pcm_s16le"}}`)
+	c.expectError(ErrCodeInvalidRequest)
+	c.send(`{"type":"synthesize","data":{"request_id":"b"}}`)
+	if data := c.expectError(ErrCodeInvalidRequest); data["request_id"] != "b" {
+		t.Errorf("Expected error for request b, got %v", data)
+	}
+	c.send(`{"type":"clear"}`)
+	if clear := c.expect("clear"); clear["cancelled"] != float64(0) {
+		t.Errorf("Unexpected clear reply: %v", clear)
+	}
+}
@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+// 会话恢复的接口范围，令牌只能在签发它的接口上使用
+const (
+	resumeScopeSTT = "stt"
+	resumeScopeTTS = "tts"
+)
+
+// ResumeInfo 连接确认消息中的恢复参数：断线后在timeout秒内携带token重连可以恢复会话
+type ResumeInfo struct {
+	Token   string `json:"token"`
+	Timeout int    `json:"timeout"`
+}
+
+// ResumeRequest 重连时的恢复参数（查询参数resume_token和last_seq），
+// LastSeq为客户端收到的最后一条消息的序号
+type ResumeRequest struct {
+	Token   string
+	LastSeq uint64
+}
+
+// ResumedData resumed消息：会话已恢复，之后依次重发序号在(last_seq, seq]之间的消息
+type ResumedData struct {
+	SessionID string `json:"session_id"`
+	LastSeq   uint64 `json:"last_seq"`
+	Seq       uint64 `json:"seq"`
+}
+
+// AckRequest ack消息：确认收到序号不超过seq的消息
+type AckRequest struct {
+	Seq uint64 `json:"seq"`
+}
+
+// ParseResumeRequest 读取重连请求的恢复参数，没有resume_token时返回nil
+func ParseResumeRequest(r *http.Request) (*ResumeRequest, error) {
+	query := r.URL.Query()
+	token := query.Get("resume_token")
+	if token == "" {
+		return nil, nil
+	}
+	req := &ResumeRequest{Token: token}
+	if v := query.Get("last_seq"); v != "" {
+		seq, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid last_seq: %s", v)
+		}
+		req.LastSeq = seq
+	}
+	return req, nil
+}
+
+// enableResume 按配置为会话开启恢复，未开启时返回nil
+func enableResume(sess *session.Session, scope string, cfg config.SessionConfig) *ResumeInfo {
+	if cfg.ResumeTimeout <= 0 {
+		return nil
+	}
+	token := sess.EnableResume(session.ResumeOptions{
+		Scope:      scope,
+		Grace:      time.Duration(cfg.ResumeTimeout) * time.Second,
+		BufferSize: cfg.ResumeBufferSize,
+	})
+	return &ResumeInfo{Token: token, Timeout: cfg.ResumeTimeout}
+}
+
+// waitResume 连接异常断开后等待客户端恢复会话，返回恢复的新连接；
+// 客户端主动关闭、未开启恢复或宽限期内没有重连时返回nil
+func waitResume(sess *session.Session, conn *websocket.Conn, err error, readTimeout int) *websocket.Conn {
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+		return nil
+	}
+	next := sess.WaitResume(conn)
+	if next != nil {
+		SetPongHandler(next, time.Duration(readTimeout)*time.Second)
+	}
+	return next
+}
+
+// resumeSession 在新连接上恢复scope接口的会话，会话仍由原读取循环处理，
+// greeting生成resumed消息（在重发的消息之前发送）
+func resumeSession(manager *session.Manager, scope string, conn *websocket.Conn, req ResumeRequest, greeting func(data ResumedData) interface{}) error {
+	sess, err := manager.ResumeSession(req.Token, scope)
+	if err == nil {
+		err = sess.Resume(conn, req.LastSeq, func(seq uint64) interface{} {
+			return greeting(ResumedData{SessionID: sess.ID, LastSeq: req.LastSeq, Seq: seq})
+		})
+	}
+	if err != nil {
+		return newProtocolError(ErrCodeResumeFailed, "cannot resume session: %v", err)
+	}
+	return nil
+}
+
+// ackSession 处理ack消息
+func ackSession(sess *session.Session, msg *inboundMessage) error {
+	var ack AckRequest
+	if err := msg.decode(&ack); err != nil {
+		return err
+	}
+	switch err := sess.Ack(ack.Seq); err {
+	case nil:
+		return nil
+	case session.ErrResumeNotEnabled:
+		return newProtocolError(ErrCodeNotEnabled, "session resumption is not enabled")
+	default:
+		return newProtocolError(ErrCodeInvalidRequest, "invalid ack: %v", err)
+	}
+}
+
+// ResumeConnection 在新连接上恢复断开的STT会话，恢复失败时返回error消息并关闭连接
+func (h *STTHandler) ResumeConnection(conn *websocket.Conn, req ResumeRequest) {
+	err := resumeSession(h.sessionManager, resumeScopeSTT, conn, req, func(data ResumedData) interface{} {
+		return STTMessage{Type: "resumed", SessionID: data.SessionID, Data: data}
+	})
+	if err != nil {
+		conn.WriteJSON(STTMessage{Type: "error", Error: err.Error(), Code: ErrCodeResumeFailed})
+		conn.Close()
+	}
+}
+
+// ResumeConnection 在新连接上恢复断开的TTS会话，恢复失败时返回error消息并关闭连接
+func (h *TTSHandler) ResumeConnection(conn *websocket.Conn, req ResumeRequest) {
+	err := resumeSession(h.sessionManager, resumeScopeTTS, conn, req, func(data ResumedData) interface{} {
+		return TTSMessage{Type: "resumed", SessionID: data.SessionID, Data: data}
+	})
+	if err != nil {
+		conn.WriteJSON(TTSMessage{Type: "error", Error: err.Error(), Code: ErrCodeResumeFailed})
+		conn.Close()
+	}
+}
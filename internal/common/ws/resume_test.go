@@ -0,0 +1,180 @@
+package ws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+// resumeServer 开启会话恢复的测试服务：带resume_token的连接恢复会话，其余连接创建新会话
+func resumeServer(t *testing.T, handle func(conn *websocket.Conn), resume func(conn *websocket.Conn, req ResumeRequest)) string {
+	t.Helper()
+	upgrader := NewUpgrader(0, 0, 0, 0, 0, false)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := ParseResumeRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		if req != nil {
+			resume(conn, *req)
+			return
+		}
+		handle(conn)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + server.URL[4:]
+}
+
+// dialResume 连接测试服务，query为查询参数
+func dialResume(t *testing.T, url, schema, query string) *protocolConn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url+query, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &protocolConn{t: t, conn: conn, schema: loadSchema(t, schema)}
+}
+
+// drop 不发送关闭帧直接断开连接（模拟网络中断）
+func (c *protocolConn) drop() {
+	c.conn.UnderlyingConn().Close()
+}
+
+func resumeSessionConfig() config.SessionConfig {
+	return config.SessionConfig{SendQueueSize: 100, ResumeTimeout: 5, ResumeBufferSize: 100}
+}
+
+func TestResume_TTSReplaysMissedAudio(t *testing.T) {
+	cfg := &config.TTSConfig{
+		Session:   resumeSessionConfig(),
+		WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+	}
+	handler := NewTTSHandler(session.NewManager(100, 30*time.Second), &mockTTSManager{synthesizeResult: make([]byte, 10000)}, cfg)
+	url := resumeServer(t, handler.HandleConnection, handler.ResumeConnection)
+
+	c := dialResume(t, url, "tts.v1.json", "")
+	msg := c.read()
+	resume, _ := msg["data"].(map[string]interface{})["resume"].(map[string]interface{})
+	if msg["seq"] != float64(1) || resume["token"] == nil || resume["timeout"] != float64(5) {
+		t.Fatalf("Expected resume token in connection message, got %v", msg)
+	}
+	token := resume["token"].(string)
+
+	// 收到queued后断线，之后的事件和音频在恢复时重发
+	c.send(`{"type":"synthesize","data":{"request_id":"r1","text":"你好"}}`)
+	if msg := c.read(); msg["type"] != "queued" || msg["seq"] != float64(2) {
+		t.Fatalf("Expected queued with seq 2, got %v", msg)
+	}
+	c.drop()
+
+	c = dialResume(t, url, "tts.v1.json", fmt.Sprintf("?resume_token=%s&last_seq=2", token))
+	resumed := c.expect("resumed")
+	if resumed["last_seq"] != float64(2) {
+		t.Errorf("Expected last_seq 2, got %v", resumed)
+	}
+	if msg := c.read(); msg["type"] != "started" || msg["seq"] != float64(3) {
+		t.Fatalf("Expected started with seq 3, got %v", msg)
+	}
+	msg = c.read()
+	if msg["type"] != "complete" || msg["seq"] != float64(7) || len(c.binary) != 3 {
+		t.Fatalf("Expected complete with seq 7 after 3 audio chunks, got %v (%d chunks)", msg, len(c.binary))
+	}
+
+	// 恢复后会话继续编号
+	c.send(`{"type":"ping"}`)
+	if msg := c.read(); msg["type"] != "pong" || msg["seq"] != float64(8) {
+		t.Fatalf("Expected pong with seq 8, got %v", msg)
+	}
+	c.send(`{"type":"ack","data":{"seq":8}}`)
+	c.send(`{"type":"ack","data":{"seq":100}}`)
+	c.expectError(ErrCodeInvalidRequest)
+
+	// 已确认的消息不能再重发，未知的令牌不能恢复
+	c2 := dialResume(t, url, "tts.v1.json", fmt.Sprintf("?resume_token=%s&last_seq=2", token))
+	c2.expectError(ErrCodeResumeFailed)
+	c2 = dialResume(t, url, "tts.v1.json", "?resume_token=unknown")
+	c2.expectError(ErrCodeResumeFailed)
+}
+
+func TestResume_STTKeepsBufferedAudio(t *testing.T) {
+	cfg := &config.STTConfig{
+		Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 1024},
+		Session:   resumeSessionConfig(),
+		WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+	}
+	asrManager := &mockASRManager{transcribeResult: "测试文本"}
+	handler := NewSTTHandler(session.NewManager(100, 30*time.Second), asrManager, cfg)
+	url := resumeServer(t, handler.HandleConnection, handler.ResumeConnection)
+
+	c := dialResume(t, url, "stt.v1.json", "")
+	token := c.expect("connection")["resume"].(map[string]interface{})["token"].(string)
+
+	// 不足chunk_size的音频在断线期间保留
+	c.conn.WriteMessage(websocket.BinaryMessage, make([]byte, 600))
+	c.send(`{"type":"ping"}`)
+	c.expect("pong")
+	c.drop()
+
+	c = dialResume(t, url, "stt.v1.json", "?resume_token="+token+"&last_seq=2")
+	if data := c.expect("resumed"); data["seq"] != float64(2) {
+		t.Errorf("Expected seq 2, got %v", data)
+	}
+	c.conn.WriteMessage(websocket.BinaryMessage, make([]byte, 600))
+	if msg := c.read(); msg["type"] != "result" || msg["seq"] != float64(3) {
+		t.Fatalf("Expected result with seq 3, got %v", msg)
+	}
+	asrManager.mu.Lock()
+	defer asrManager.mu.Unlock()
+	if len(asrManager.lastAudio) != 1200 {
+		t.Errorf("Expected 1200 bytes of audio across the reconnect, got %d", len(asrManager.lastAudio))
+	}
+}
+
+func TestResume_NotEnabled(t *testing.T) {
+	for _, endpoint := range protocolEndpoints() {
+		t.Run(endpoint.name, func(t *testing.T) {
+			c, data := dialProtocol(t, endpoint, nil)
+			if _, ok := data["resume"]; ok {
+				t.Errorf("Expected no resume token, got %v", data["resume"])
+			}
+			c.send(`{"type":"ack","data":{"seq":1}}`)
+			c.expectError(ErrCodeNotEnabled)
+		})
+	}
+}
+
+func TestParseResumeRequest(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    *ResumeRequest
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"?resume_token=abc", &ResumeRequest{Token: "abc"}, false},
+		{"?resume_token=abc&last_seq=42", &ResumeRequest{Token: "abc", LastSeq: 42}, false},
+		{"?resume_token=abc&last_seq=-1", nil, true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/ws/stt"+tt.query, nil)
+		got, err := ParseResumeRequest(req)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseResumeRequest(%q) error = %v", tt.query, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("ParseResumeRequest(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package ws
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorCodes error消息可能的错误码
+var ErrorCodes = []string{
+	ErrCodeInvalidMessage,
+	ErrCodeUnknownType,
+	ErrCodeInvalidRequest,
+	ErrCodeUnsupportedVersion,
+	ErrCodeUnexpectedBinary,
+	ErrCodeNotFound,
+	ErrCodeQueueFull,
+	ErrCodeNotEnabled,
+	ErrCodeProcessingFailed,
+	ErrCodeResumeFailed,
+}
+
+// ProtocolSchema 由协议的消息定义生成JSON Schema（draft 2020-12），
+// 每种消息是$defs中的一项（名称为"方向.类型"），消息为其中之一
+func ProtocolSchema(title string, specs []MessageSpec) map[string]interface{} {
+	defs := map[string]interface{}{}
+	var oneOf []interface{}
+	for _, spec := range specs {
+		name := spec.Direction + "." + spec.Type
+		defs[name] = messageSchema(spec)
+		oneOf = append(oneOf, map[string]interface{}{"$ref": "#/$defs/" + name})
+	}
+	return map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   title,
+		"$defs":   defs,
+		"oneOf":   oneOf,
+	}
+}
+
+// messageSchema 单种消息的Schema：type固定，data为消息数据，error消息带error和code，
+// 服务端消息在开启会话恢复时带seq
+func messageSchema(spec MessageSpec) map[string]interface{} {
+	properties := map[string]interface{}{
+		"type": map[string]interface{}{"const": spec.Type},
+	}
+	required := []string{"type"}
+	if spec.Direction == "server" {
+		properties["session_id"] = map[string]interface{}{"type": "string"}
+		properties["seq"] = map[string]interface{}{"type": "integer"}
+	}
+	if spec.Data != nil {
+		properties["data"] = typeSchema(reflect.TypeOf(spec.Data))
+		if !spec.Error {
+			required = append(required, "data")
+		}
+	}
+	if spec.Error {
+		properties["error"] = map[string]interface{}{"type": "string"}
+		properties["code"] = map[string]interface{}{"enum": ErrorCodes}
+		required = append(required, "error", "code")
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// typeSchema Go类型对应的Schema，字段名和是否必需取自json标签（omitempty为可选）
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		addStructFields(t, properties, &required)
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// addStructFields 添加结构体字段，未命名的嵌入结构体字段展开到外层
+func addStructFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// ProtocolSchemas 各接口协议的JSON Schema，键为docs/schemas/ws下的文件名
+func ProtocolSchemas() map[string]map[string]interface{} {
+	version := fmt.Sprintf("v%d", ProtocolVersion)
+	return map[string]map[string]interface{}{
+		"stt." + version + ".json": ProtocolSchema("AeroSpeech STT WebSocket protocol "+version, STTProtocol),
+		"tts." + version + ".json": ProtocolSchema("AeroSpeech TTS WebSocket protocol "+version, TTSProtocol),
+	}
+}
@@ -1,7 +1,8 @@
 package ws
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -16,18 +17,103 @@ type STTMessage struct {
 	SessionID string      `json:"session_id,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
 	Error     string      `json:"error,omitempty"`
+	Code      string      `json:"code,omitempty"` // error消息的错误码
+	Seq       uint64      `json:"seq,omitempty"`  // 开启会话恢复时的消息序号
+}
+
+// WithSeq 填入消息序号（实现session.Sequenced）
+func (m STTMessage) WithSeq(seq uint64) interface{} {
+	m.Seq = seq
+	return m
 }
 
 // STTHandler STT WebSocket处理器
 type STTHandler struct {
 	sessionManager *session.Manager
 	asrManager     ASRManager
+	models         func(name string) (ASRManager, error) // 按名称选择模型，为nil时只使用asrManager
+	router         ASRLanguageRouter                     // 未指定模型时按语言选择模型，为nil时使用默认模型
+	hotwords       HotwordResolver                       // 展开会话引用的热词集，为nil时不支持hotword_sets
+	speakers       SpeakerIdentifier                     // 辨认识别片段的说话人，为nil时不支持identify_speaker
+	enhancer       AudioEnhancer                         // 识别前降噪，为nil时不支持enhance
+	enhanceDefault bool                                  // 会话未指定enhance时是否降噪
+	vad            VoiceActivityDetector                 // start消息开启的VAD分句，为nil时不支持vad
+	opus           OpusDecoderFactory                    // Opus解码，为nil时不支持opus和ogg_opus编码
 	config         *config.STTConfig
 }
 
+// sttInterimInterval 发送中间结果的最小语音间隔
+const sttInterimInterval = 500 * time.Millisecond
+
+// STTSessionOptions 识别会话参数
+type STTSessionOptions struct {
+	Model             string // 模型名称，为空时按语言路由
+	config.ASROptions        // 解码参数，Language为空或auto时自动识别
+	IdentifySpeaker   bool   // 识别结果是否标注辨认出的已注册说话人
+	Enhance           *bool  // 识别前是否降噪，为nil时按配置
+}
+
+// ASRRoute 识别使用的模型和语言
+type ASRRoute struct {
+	Model    string
+	Language string
+}
+
+// ASRLanguageRouter 按客户端声明的语言（为空时可能对音频做语种识别）选择模型
+type ASRLanguageRouter func(ctx context.Context, language string, audio []byte) (ASRRoute, error)
+
+// HotwordResolver 将命名热词集展开为热词列表
+type HotwordResolver func(names []string) ([]string, error)
+
+// SpeakerMatch 辨认出的已注册说话人
+type SpeakerMatch struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name,omitempty"`
+	Score float32 `json:"score"`
+}
+
+// SpeakerIdentifier 辨认音频所属的已注册说话人，没有达到阈值的说话人时返回nil
+type SpeakerIdentifier func(ctx context.Context, audio []byte) (*SpeakerMatch, error)
+
+// AudioEnhancer 对PCM16单声道音频降噪，返回相同采样率的PCM16音频
+type AudioEnhancer func(ctx context.Context, audio []byte, sampleRate int) ([]byte, error)
+
+// audioStream 会话的音频输入：按start消息声明的格式转换，开启VAD时按语句识别
+type audioStream struct {
+	converter *audioConverter // 未发送start时为nil，音频为服务端格式
+	vad       VoiceStream     // 未开启VAD时为nil
+	interim   bool            // 是否发送中间结果
+	buffer    []byte          // 未开启VAD时等待识别的音频
+	utterance []byte          // 开启VAD时当前语句已收到的音频（用于中间结果）
+	interimAt int             // 上次发送中间结果时utterance的长度
+	segment   int             // 已结束的语句数
+	received  bool            // start或reset之后是否收到过音频
+}
+
+// close 释放VAD检测流
+func (s *audioStream) close() {
+	if s.vad != nil {
+		s.vad.Close()
+		s.vad = nil
+	}
+}
+
+// sessionModel 会话当前使用的模型和解码参数，按语言路由时在首段音频上确定模型
+type sessionModel struct {
+	manager  ASRManager
+	route    ASRRoute
+	routed   bool              // 是否已确定模型
+	fixed    bool              // 模型由客户端指定，不参与路由
+	options  config.ASROptions // 会话解码参数（可通过config消息修改）
+	identify bool              // 是否辨认说话人（可通过config消息修改）
+	enhance  bool              // 识别前是否降噪（可通过config消息修改）
+}
+
 // ASRManager ASR管理器接口
 type ASRManager interface {
 	Transcribe(ctx interface{}, audio []byte) (string, error)
+	Recognize(ctx interface{}, audio []byte, opts *config.ASROptions) (*config.ASRResult, error)
+	ValidateOptions(opts *config.ASROptions) error
 	GetStats() interface{}
 	GetAvgLatency() interface{}
 	GetPoolUsage() float64
@@ -42,8 +128,130 @@ func NewSTTHandler(sessionManager *session.Manager, asrManager ASRManager, cfg *
 	}
 }
 
-// HandleConnection 处理WebSocket连接
+// SetModelLookup 设置按名称选择模型的查找函数
+func (h *STTHandler) SetModelLookup(lookup func(name string) (ASRManager, error)) {
+	h.models = lookup
+}
+
+// SetLanguageRouter 设置按语言选择模型的路由函数
+func (h *STTHandler) SetLanguageRouter(router ASRLanguageRouter) {
+	h.router = router
+}
+
+// SetHotwordResolver 设置命名热词集的展开函数
+func (h *STTHandler) SetHotwordResolver(resolve HotwordResolver) {
+	h.hotwords = resolve
+}
+
+// SetSpeakerIdentifier 设置说话人辨认函数
+func (h *STTHandler) SetSpeakerIdentifier(identify SpeakerIdentifier) {
+	h.speakers = identify
+}
+
+// SetAudioEnhancer 设置识别前的语音增强，byDefault为会话未指定enhance时是否降噪
+func (h *STTHandler) SetAudioEnhancer(enhance AudioEnhancer, byDefault bool) {
+	h.enhancer = enhance
+	h.enhanceDefault = byDefault
+}
+
+// SetVoiceActivityDetector 设置start消息可开启的VAD分句
+func (h *STTHandler) SetVoiceActivityDetector(vad VoiceActivityDetector) {
+	h.vad = vad
+}
+
+// SetOpusDecoder 设置Opus解码器，设置后start消息可以声明opus和ogg_opus编码
+func (h *STTHandler) SetOpusDecoder(factory OpusDecoderFactory) {
+	h.opus = factory
+}
+
+// encodings start消息可声明的音频编码
+func (h *STTHandler) encodings() []string {
+	encodings := []string{EncodingPCMS16LE, EncodingPCMF32LE, EncodingMulaw}
+	if h.opus != nil {
+		encodings = append(encodings, EncodingOpus, EncodingOggOpus)
+	}
+	return encodings
+}
+
+// vadAvailable 是否可以开启VAD分句（VAD要求模型采样率与VAD一致）
+func (h *STTHandler) vadAvailable() bool {
+	return h.vad != nil && h.vad.SampleRate() == h.config.Audio.SampleRate
+}
+
+// expandHotwordSets 将引用的热词集展开到opts.SetHotwords（热词集的识别器可按需构建）
+func (h *STTHandler) expandHotwordSets(opts *config.ASROptions) error {
+	if len(opts.HotwordSets) == 0 {
+		return nil
+	}
+	if h.hotwords == nil {
+		return newProtocolError(ErrCodeNotEnabled, "hotword sets are not enabled")
+	}
+	words, err := h.hotwords(opts.HotwordSets)
+	if err != nil {
+		return newProtocolError(ErrCodeInvalidRequest, "%v", err)
+	}
+	opts.SetHotwords = words
+	opts.HotwordSets = nil
+	return nil
+}
+
+// HandleConnection 处理WebSocket连接（使用默认模型）
 func (h *STTHandler) HandleConnection(conn *websocket.Conn) {
+	h.HandleConnectionWithModel(conn, "")
+}
+
+// HandleConnectionWithModel 处理WebSocket连接，整个会话使用指定的模型（为空时使用默认模型）
+func (h *STTHandler) HandleConnectionWithModel(conn *websocket.Conn, model string) {
+	h.HandleConnectionWithOptions(conn, STTSessionOptions{Model: model})
+}
+
+// HandleConnectionWithOptions 处理WebSocket连接：指定模型时整个会话使用该模型，
+// 否则按会话语言路由（未声明语言时在首段音频上做语种识别，reset后重新识别）
+func (h *STTHandler) HandleConnectionWithOptions(conn *websocket.Conn, opts STTSessionOptions) {
+	model := &sessionModel{
+		manager:  h.asrManager,
+		route:    ASRRoute{Model: opts.Model, Language: opts.Language},
+		routed:   opts.Model != "" || h.router == nil,
+		fixed:    opts.Model != "",
+		options:  opts.ASROptions,
+		identify: opts.IdentifySpeaker,
+		enhance:  h.enhancer != nil && h.enhanceDefault,
+	}
+	if opts.Enhance != nil {
+		model.enhance = *opts.Enhance
+	}
+	reject := func(code, message string) {
+		conn.WriteJSON(STTMessage{Type: "error", Error: message, Code: code})
+		conn.Close()
+	}
+	if opts.IdentifySpeaker && h.speakers == nil {
+		reject(ErrCodeNotEnabled, "speaker identification is not enabled")
+		return
+	}
+	if model.enhance && h.enhancer == nil {
+		reject(ErrCodeNotEnabled, "speech enhancement is not enabled")
+		return
+	}
+	if err := h.expandHotwordSets(&model.options); err != nil {
+		reject(errorCode(err, ErrCodeInvalidRequest), err.Error())
+		return
+	}
+	if opts.Model != "" {
+		var err error
+		if h.models == nil {
+			err = fmt.Errorf("model not found: %s", opts.Model)
+		} else {
+			model.manager, err = h.models(opts.Model)
+		}
+		if err == nil {
+			err = model.manager.ValidateOptions(&model.options)
+		}
+		if err != nil {
+			reject(ErrCodeInvalidRequest, err.Error())
+			return
+		}
+	}
+
 	// 创建会话
 	sess, err := h.sessionManager.CreateSession(conn, h.config.Session.SendQueueSize)
 	if err != nil {
@@ -51,22 +259,37 @@ func (h *STTHandler) HandleConnection(conn *websocket.Conn) {
 		conn.Close()
 		return
 	}
+	resume := enableResume(sess, resumeScopeSTT, h.config.Session)
 
-	// 发送连接确认消息
+	// 语音增强、路由、识别和说话人辨认使用会话上下文，会话关闭时取消
+	ctx, cancel := sessionContext(sess)
+	defer cancel()
+
+	// 发送连接确认消息，设备信息取自会话实际使用的模型
+	provider := modelProvider(model.manager, h.config.ASR.Provider)
 	configMsg := STTMessage{
 		Type:      "connection",
 		SessionID: sess.ID,
-		Data: map[string]interface{}{
-			"status":      "connected",
-			"session_id": sess.ID,
-			"config": map[string]interface{}{
-				"sample_rate":     h.config.Audio.SampleRate,
-				"chunk_size":      h.config.Audio.ChunkSize,
-				"format":          "pcm_s16le",
-				"provider":        h.config.ASR.Provider.Provider,
-				"gpu_available":   h.config.ASR.Provider.Provider == "cuda",
-				"gpu_device_id":   h.config.ASR.Provider.DeviceID,
+		Data: STTConnectionData{
+			Status:          "connected",
+			SessionID:       sess.ID,
+			ProtocolVersion: ProtocolVersion,
+			Config: STTConnectionConfig{
+				SampleRate:      h.config.Audio.SampleRate,
+				ChunkSize:       h.config.Audio.ChunkSize,
+				Format:          "pcm_s16le",
+				Provider:        provider.Provider,
+				GPUAvailable:    provider.Provider == "cuda",
+				GPUDeviceID:     provider.DeviceID,
+				Model:           opts.Model,
+				Language:        opts.Language,
+				Options:         opts.ASROptions,
+				IdentifySpeaker: opts.IdentifySpeaker,
+				Enhance:         model.enhance,
+				Encodings:       h.encodings(),
+				VADAvailable:    h.vadAvailable(),
 			},
+			Resume: resume,
 		},
 	}
 
@@ -80,7 +303,8 @@ func (h *STTHandler) HandleConnection(conn *websocket.Conn) {
 	SetPongHandler(conn, time.Duration(h.config.WebSocket.ReadTimeout)*time.Second)
 
 	// 处理消息循环
-	audioBuffer := make([]byte, 0, h.config.Audio.ChunkSize*2)
+	stream := &audioStream{buffer: make([]byte, 0, h.config.Audio.ChunkSize*2)}
+	defer stream.close()
 
 	for {
 		messageType, message, err := conn.ReadMessage()
@@ -88,6 +312,10 @@ func (h *STTHandler) HandleConnection(conn *websocket.Conn) {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				logger.Errorf("WebSocket error: %v", err)
 			}
+			// 开启会话恢复时等待客户端重连，会话状态保留
+			if conn = waitResume(sess, conn, err, h.config.WebSocket.ReadTimeout); conn != nil {
+				continue
+			}
 			break
 		}
 
@@ -96,74 +324,383 @@ func (h *STTHandler) HandleConnection(conn *websocket.Conn) {
 
 		switch messageType {
 		case websocket.BinaryMessage:
-			// 音频数据
-			audioBuffer = append(audioBuffer, message...)
-
-			// 当缓冲区达到一定大小时，进行识别
-			if len(audioBuffer) >= h.config.Audio.ChunkSize {
-				h.processAudio(sess, audioBuffer)
-				audioBuffer = audioBuffer[:0] // 清空缓冲区
+			// 音频数据，按声明的格式转换为模型格式
+			stream.received = true
+			audio := message
+			if stream.converter != nil {
+				if audio, err = stream.converter.convert(message); err != nil {
+					h.sendError(sess, ErrCodeInvalidRequest, err.Error())
+					continue
+				}
 			}
+			sess.AddAudioIn(len(message), len(audio))
+			h.acceptAudio(ctx, sess, model, stream, audio)
 
 		case websocket.TextMessage:
 			// 文本消息（控制消息）
-			var msg STTMessage
-			if err := json.Unmarshal(message, &msg); err != nil {
-				logger.Warnf("Failed to parse message: %v", err)
+			msg, err := parseInbound(message)
+			if err != nil {
+				h.sendError(sess, errorCode(err, ErrCodeInvalidMessage), err.Error())
 				continue
 			}
 
 			switch msg.Type {
+			case "hello":
+				// 协商协议版本
+				hello, err := negotiateHello(msg)
+				if err != nil {
+					h.sendError(sess, errorCode(err, ErrCodeInvalidRequest), err.Error())
+					continue
+				}
+				sess.Send(STTMessage{Type: "hello", SessionID: sess.ID, Data: hello})
+
+			case "start":
+				// 声明音频格式和识别方式
+				h.startStream(sess, model, stream, msg)
+
 			case "reset":
-				// 重置识别
-				audioBuffer = audioBuffer[:0]
+				// 重置识别，保留start声明的音频格式
+				stream.buffer, stream.utterance, stream.interimAt, stream.received = stream.buffer[:0], nil, 0, false
+				if stream.converter != nil {
+					stream.converter.reset()
+				}
+				if stream.vad != nil {
+					stream.vad.Flush()
+				}
+				if !model.fixed && h.router != nil {
+					model.routed = false // 下一段音频重新路由
+				}
 				sess.Send(STTMessage{
 					Type:      "reset",
 					SessionID: sess.ID,
-					Data:      map[string]string{"status": "ok"},
+					Data:      StatusData{Status: "ok"},
+				})
+
+			case "config":
+				// 修改会话解码参数
+				h.updateOptions(sess, model, msg)
+
+			case "stats":
+				// 会话的音频流量统计
+				sess.Send(STTMessage{
+					Type:      "stats",
+					SessionID: sess.ID,
+					Data:      sessionStats(sess),
 				})
 
+			case "ack":
+				// 确认收到的消息，不再为恢复保留
+				if err := ackSession(sess, msg); err != nil {
+					h.sendError(sess, errorCode(err, ErrCodeInvalidRequest), err.Error())
+				}
+
 			case "ping":
 				// 心跳响应
 				sess.Send(STTMessage{
 					Type:      "pong",
 					SessionID: sess.ID,
 				})
+
+			default:
+				h.sendError(sess, ErrCodeUnknownType, fmt.Sprintf("unknown message type: %s", msg.Type))
 			}
 		}
 	}
 
 	// 处理剩余的音频数据
-	if len(audioBuffer) > 0 {
-		h.processAudio(sess, audioBuffer)
+	if stream.vad != nil {
+		for _, segment := range stream.vad.Flush() {
+			stream.segment++
+			h.processAudio(ctx, sess, model, segment, true, stream.segment)
+		}
+	} else if len(stream.buffer) > 0 {
+		h.processAudio(ctx, sess, model, stream.buffer, true, 0)
 	}
 
 	// 清理会话
 	h.sessionManager.RemoveSession(sess.ID)
 }
 
-// processAudio 处理音频数据
-func (h *STTHandler) processAudio(sess *session.Session, audio []byte) {
-	// 执行识别
-	result, err := h.asrManager.Transcribe(nil, audio)
+// acceptAudio 处理一段模型格式的音频：未开启VAD时按chunk_size识别，
+// 开启VAD时识别结束的语句，并按间隔对进行中的语句发送中间结果
+func (h *STTHandler) acceptAudio(ctx context.Context, sess *session.Session, model *sessionModel, stream *audioStream, audio []byte) {
+	if stream.vad == nil {
+		stream.buffer = append(stream.buffer, audio...)
+
+		// 当缓冲区达到一定大小时，进行识别
+		if len(stream.buffer) >= h.config.Audio.ChunkSize {
+			h.processAudio(ctx, sess, model, stream.buffer, true, 0)
+			stream.buffer = stream.buffer[:0] // 清空缓冲区
+		}
+		return
+	}
+
+	segments := stream.vad.Accept(audio)
+	if len(segments) == 0 && stream.vad.Speaking() {
+		stream.utterance = append(stream.utterance, audio...)
+	}
+	for _, segment := range segments {
+		stream.segment++
+		h.processAudio(ctx, sess, model, segment, true, stream.segment)
+	}
+	if len(segments) > 0 {
+		// 新语句从下一段音频开始累积
+		stream.utterance, stream.interimAt = nil, 0
+	}
+
+	interimBytes := int(sttInterimInterval.Seconds()*float64(h.config.Audio.SampleRate)) * 2
+	if stream.interim && stream.vad.Speaking() && len(stream.utterance)-stream.interimAt >= interimBytes {
+		stream.interimAt = len(stream.utterance)
+		h.processAudio(ctx, sess, model, stream.utterance, false, stream.segment+1)
+	}
+}
+
+// startStream 处理start消息：设置会话的音频格式、语言和VAD分句，
+// 参数无效或与服务端能力不兼容时返回error消息并保留原设置
+func (h *STTHandler) startStream(sess *session.Session, model *sessionModel, stream *audioStream, msg *inboundMessage) {
+	var req STTStartRequest
+	err := msg.decode(&req)
+	if err == nil && stream.received {
+		err = fmt.Errorf("start must be sent before audio, send reset first")
+	}
+	format := AudioFormat{SampleRate: req.SampleRate, Encoding: req.Encoding, Channels: req.Channels}
+	if format.SampleRate == 0 {
+		format.SampleRate = h.config.Audio.SampleRate
+	}
+	if format.Encoding == "" {
+		format.Encoding = EncodingPCMS16LE
+	}
+	if format.Channels == 0 {
+		format.Channels = 1
+	}
+	if err == nil {
+		err = format.validate(h.opus)
+	}
+	if err == nil && req.Interim && req.VAD == nil {
+		err = fmt.Errorf("interim results require vad")
+	}
+	if err == nil && req.VAD != nil {
+		if !h.vadAvailable() {
+			err = newProtocolError(ErrCodeNotEnabled, "vad is not enabled")
+		} else {
+			err = req.VAD.validate()
+		}
+	}
+
+	var converter *audioConverter
+	if err == nil {
+		converter, err = newAudioConverter(format, h.config.Audio.SampleRate, h.opus)
+	}
+	var vad VoiceStream
+	if err == nil && req.VAD != nil {
+		if vad, err = h.vad.NewStream(req.VAD); err != nil {
+			err = newProtocolError(ErrCodeProcessingFailed, "%v", err)
+		}
+	}
+	if err == nil && req.Language != "" {
+		_, err = h.applyConfig(model, STTConfigUpdate{ASROptions: config.ASROptions{Language: req.Language}})
+	}
+	if err != nil {
+		if vad != nil {
+			vad.Close()
+		}
+		h.sendError(sess, errorCode(err, ErrCodeInvalidRequest), fmt.Sprintf("invalid start: %v", err))
+		return
+	}
+
+	stream.close()
+	*stream = audioStream{converter: converter, vad: vad, interim: req.Interim, buffer: stream.buffer[:0], segment: stream.segment}
+	sess.Send(STTMessage{
+		Type:      "start",
+		SessionID: sess.ID,
+		Data:      STTStartData{AudioFormat: format, Language: model.options.Language, Interim: req.Interim, VAD: req.VAD},
+	})
+}
+
+// processAudio 识别一段音频并发送结果，final为false时为进行中语句的中间结果，
+// segmentID为VAD分句的语句编号（未开启VAD时为0）
+func (h *STTHandler) processAudio(ctx context.Context, sess *session.Session, model *sessionModel, audio []byte, final bool, segmentID int) {
+	var enhanceLatency float64
+	if model.enhance && h.enhancer != nil {
+		start := time.Now()
+		enhanced, err := h.enhancer(ctx, audio, h.config.Audio.SampleRate)
+		if err != nil {
+			logger.Errorf("Speech enhancement failed: %v", err)
+			h.sendError(sess, ErrCodeProcessingFailed, fmt.Sprintf("speech enhancement failed: %v", err))
+			return
+		}
+		audio = enhanced
+		enhanceLatency = float64(time.Since(start).Microseconds()) / 1000
+	}
+
+	if !model.routed {
+		if err := h.routeSession(ctx, model, model.options.Language, audio); err != nil {
+			logger.Errorf("ASR routing failed: %v", err)
+			h.sendError(sess, ErrCodeProcessingFailed, err.Error())
+			return
+		}
+	}
+
+	// 执行识别，语言使用路由结果（声明或识别得到）
+	options := model.options
+	options.Language = model.route.Language
+	result, err := model.manager.Recognize(ctx, audio, &options)
 	if err != nil {
 		logger.Errorf("ASR transcription failed: %v", err)
-		sess.Send(STTMessage{
-			Type:      "error",
-			SessionID: sess.ID,
-			Error:     err.Error(),
-		})
+		h.sendError(sess, ErrCodeProcessingFailed, err.Error())
 		return
 	}
 
-	// 发送识别结果
+	// 发送识别结果，SenseVoice模型附带语言/情感/事件标签
+	data := STTResult{
+		Text:      result.Text,
+		Final:     final,
+		SegmentID: segmentID,
+		Model:     model.route.Model,
+		Language:  model.route.Language,
+		Timestamp: time.Now().Unix(),
+		Lang:      result.Lang,
+		Emotion:   result.Emotion,
+		Event:     result.Event,
+	}
+	if model.enhance && h.enhancer != nil {
+		data.EnhanceLatencyMs = &enhanceLatency
+	}
+	if final && model.identify && h.speakers != nil {
+		// 说话人辨认失败不影响识别结果
+		match, err := h.speakers(ctx, audio)
+		if err != nil {
+			logger.Warnf("Speaker identification failed: %v", err)
+		} else if match != nil {
+			data.Speaker = match
+		}
+	}
 	sess.Send(STTMessage{
 		Type:      "result",
 		SessionID: sess.ID,
-		Data: map[string]interface{}{
-			"text":      result,
-			"timestamp": time.Now().Unix(),
-		},
+		Data:      data,
+	})
+}
+
+// updateOptions 处理config消息，参数无效或当前模型不支持时返回error消息并保留原参数
+func (h *STTHandler) updateOptions(sess *session.Session, model *sessionModel, msg *inboundMessage) {
+	var update STTConfigUpdate
+	err := msg.decode(&update)
+	var applied STTSessionConfig
+	if err == nil {
+		applied, err = h.applyConfig(model, update)
+	}
+	if err != nil {
+		h.sendError(sess, errorCode(err, ErrCodeInvalidRequest), fmt.Sprintf("invalid config: %v", err))
+		return
+	}
+	sess.Send(STTMessage{
+		Type:      "config",
+		SessionID: sess.ID,
+		Data:      applied,
 	})
 }
 
+// applyConfig 合并会话解码参数，参数无效或当前模型不支持时返回错误且不修改会话；
+// 语言变化时下一段音频重新路由
+func (h *STTHandler) applyConfig(model *sessionModel, update STTConfigUpdate) (STTSessionConfig, error) {
+	var err error
+	identify := model.identify
+	if update.IdentifySpeaker != nil {
+		identify = *update.IdentifySpeaker
+		if identify && h.speakers == nil && err == nil {
+			err = newProtocolError(ErrCodeNotEnabled, "speaker identification is not enabled")
+		}
+	}
+	enhance := model.enhance
+	if update.Enhance != nil {
+		enhance = *update.Enhance
+		if enhance && h.enhancer == nil && err == nil {
+			err = newProtocolError(ErrCodeNotEnabled, "speech enhancement is not enabled")
+		}
+	}
+
+	options := model.options
+	if update.Language != "" {
+		options.Language = update.Language
+	}
+	if update.ITN != nil {
+		options.ITN = update.ITN
+	}
+	if update.DecodingMethod != "" {
+		options.DecodingMethod = update.DecodingMethod
+	}
+	if update.MaxActivePaths > 0 {
+		options.MaxActivePaths = update.MaxActivePaths
+	}
+	if update.Punctuate != nil {
+		options.Punctuate = update.Punctuate
+	}
+	if update.KeepTags != nil {
+		options.KeepTags = update.KeepTags
+	}
+	if update.HotwordsScore != 0 {
+		options.HotwordsScore = update.HotwordsScore
+	}
+	if update.Hotwords != nil || update.HotwordSets != nil {
+		// 热词整体替换（空列表清除会话热词）
+		if len(update.Hotwords) > config.MaxHotwordsPerRequest && err == nil {
+			err = fmt.Errorf("too many hotwords: %d, max %d", len(update.Hotwords), config.MaxHotwordsPerRequest)
+		}
+		options.Hotwords, options.HotwordSets, options.SetHotwords = update.Hotwords, update.HotwordSets, nil
+		if err == nil {
+			err = h.expandHotwordSets(&options)
+		}
+	}
+	if err == nil {
+		err = options.Validate()
+	}
+	if err == nil && model.routed {
+		err = model.manager.ValidateOptions(&options)
+	}
+	if err != nil {
+		return STTSessionConfig{}, err
+	}
+
+	if options.Language != model.options.Language {
+		if model.fixed || h.router == nil {
+			model.route.Language = options.Language
+		} else {
+			model.routed = false
+		}
+	}
+	model.options = options
+	model.identify = identify
+	model.enhance = enhance
+	return STTSessionConfig{ASROptions: options, IdentifySpeaker: identify, Enhance: enhance}, nil
+}
+
+// sendError 发送带错误码的错误消息
+func (h *STTHandler) sendError(sess *session.Session, code, message string) {
+	sess.Send(STTMessage{
+		Type:      "error",
+		SessionID: sess.ID,
+		Error:     message,
+		Code:      code,
+	})
+}
+
+// routeSession 按语言为会话选择模型
+func (h *STTHandler) routeSession(ctx context.Context, model *sessionModel, language string, audio []byte) error {
+	route, err := h.router(ctx, language, audio)
+	if err != nil {
+		return err
+	}
+	manager := h.asrManager
+	if route.Model != "" {
+		if h.models == nil {
+			return fmt.Errorf("model not found: %s", route.Model)
+		}
+		if manager, err = h.models(route.Model); err != nil {
+			return err
+		}
+	}
+	model.manager, model.route, model.routed = manager, route, true
+	return nil
+}
+
@@ -1,9 +1,16 @@
 package ws
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,6 +26,12 @@ type mockASRManager struct {
 	stats            interface{}
 	avgLatency       interface{}
 	poolUsage        float64
+	validateError    error
+	mu               sync.Mutex
+	lastOptions      config.ASROptions
+	lastCtx          interface{}
+	lastAudio        []byte
+	tags             config.ASRResult // Recognize返回的语言/情感/事件标签
 }
 
 func (m *mockASRManager) Transcribe(ctx interface{}, audio []byte) (string, error) {
@@ -28,6 +41,34 @@ func (m *mockASRManager) Transcribe(ctx interface{}, audio []byte) (string, erro
 	return m.transcribeResult, nil
 }
 
+func (m *mockASRManager) Recognize(ctx interface{}, audio []byte, opts *config.ASROptions) (*config.ASRResult, error) {
+	m.mu.Lock()
+	m.lastOptions = *opts
+	m.lastAudio = audio
+	m.lastCtx = ctx
+	m.mu.Unlock()
+	text, err := m.Transcribe(ctx, audio)
+	if err != nil {
+		return nil, err
+	}
+	result := m.tags
+	result.Text = text
+	return &result, nil
+}
+
+func (m *mockASRManager) ValidateOptions(opts *config.ASROptions) error {
+	if opts.DecodingMethod == config.DecodingModifiedBeamSearch {
+		return m.validateError
+	}
+	return nil
+}
+
+func (m *mockASRManager) options() config.ASROptions {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastOptions
+}
+
 func (m *mockASRManager) GetStats() interface{} {
 	return m.stats
 }
@@ -40,6 +81,16 @@ func (m *mockASRManager) GetPoolUsage() float64 {
 	return m.poolUsage
 }
 
+// providerASRManager 报告推理设备配置的模拟ASR管理器
+type providerASRManager struct {
+	*mockASRManager
+	provider config.ProviderConfig
+}
+
+func (m *providerASRManager) GetProviderConfig() config.ProviderConfig {
+	return m.provider
+}
+
 func TestSTTHandler_HandleConnection(t *testing.T) {
 	// 创建测试服务器
 	upgrader := websocket.Upgrader{
@@ -400,3 +451,518 @@ func (e *mockError) Error() string {
 	return e.msg
 }
 
+
+func TestSTTHandler_HandleConnectionWithModel(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 4096},
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewSTTHandler(session.NewManager(100, 30*time.Second), &mockASRManager{transcribeResult: "你好"}, cfg)
+		handler.SetModelLookup(func(name string) (ASRManager, error) {
+			if name == "whisper-en" {
+				return &providerASRManager{
+					mockASRManager: &mockASRManager{transcribeResult: "hello"},
+					provider:       config.ProviderConfig{Provider: "cuda", DeviceID: 1},
+				}, nil
+			}
+			return nil, fmt.Errorf("model not found: %s", name)
+		})
+		handler.HandleConnectionWithModel(conn, r.URL.Query().Get("model"))
+	}))
+	defer server.Close()
+
+	url := "ws" + server.URL[4:]
+
+	// 指定模型的会话使用该模型识别
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?model=whisper-en", nil)
+	if err != nil {
+		t.Skipf("Skipping test: cannot connect to test server: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg STTMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v, %v", msg, err)
+	}
+	// 连接确认消息报告所选模型的推理设备
+	data, _ := msg.Data.(map[string]interface{})
+	connConfig, _ := data["config"].(map[string]interface{})
+	if connConfig["provider"] != "cuda" || connConfig["gpu_available"] != true || connConfig["gpu_device_id"] != float64(1) {
+		t.Errorf("Expected provider of whisper-en in connection config, got %+v", connConfig)
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 4096))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to read result: %v", err)
+	}
+	data, _ = msg.Data.(map[string]interface{})
+	if msg.Type != "result" || data["text"] != "hello" {
+		t.Errorf("Expected result from whisper-en, got %+v", msg)
+	}
+
+	// 未知模型返回错误并关闭连接
+	bad, _, err := websocket.DefaultDialer.Dial(url+"?model=missing", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer bad.Close()
+	bad.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := bad.ReadJSON(&msg); err != nil || msg.Type != "error" {
+		t.Errorf("Expected error message for unknown model, got %+v, %v", msg, err)
+	}
+}
+
+func TestSTTHandler_HandleConnectionWithLanguageRouting(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	var routeCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 4096},
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewSTTHandler(session.NewManager(100, 30*time.Second), &mockASRManager{transcribeResult: "你好"}, cfg)
+		handler.SetModelLookup(func(name string) (ASRManager, error) {
+			if name == "whisper-en" {
+				return &mockASRManager{transcribeResult: "hello"}, nil
+			}
+			return nil, fmt.Errorf("model not found: %s", name)
+		})
+		handler.SetLanguageRouter(func(ctx context.Context, language string, audio []byte) (ASRRoute, error) {
+			atomic.AddInt32(&routeCalls, 1)
+			if language == "" {
+				language = "en" // 模拟语种识别
+			}
+			return ASRRoute{Model: "whisper-en", Language: language}, nil
+		})
+		handler.HandleConnectionWithOptions(conn, STTSessionOptions{ASROptions: config.ASROptions{Language: r.URL.Query().Get("language")}})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Skipf("Skipping test: cannot connect to test server: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg STTMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v, %v", msg, err)
+	}
+
+	// 首段音频确定模型，之后的音频沿用
+	for i := 0; i < 2; i++ {
+		conn.WriteMessage(websocket.BinaryMessage, make([]byte, 4096))
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Failed to read result: %v", err)
+		}
+		data, _ := msg.Data.(map[string]interface{})
+		if msg.Type != "result" || data["text"] != "hello" || data["model"] != "whisper-en" || data["language"] != "en" {
+			t.Errorf("Expected routed result, got %+v", msg)
+		}
+	}
+	if calls := atomic.LoadInt32(&routeCalls); calls != 1 {
+		t.Errorf("Expected 1 routing call, got %d", calls)
+	}
+}
+
+func TestSTTHandler_SessionOptions(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	asrManager := &mockASRManager{
+		transcribeResult: "hello",
+		validateError:    fmt.Errorf("unsupported option"),
+		tags:             config.ASRResult{Lang: "en", Emotion: "neutral", Event: "speech"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 4096},
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewSTTHandler(session.NewManager(100, 30*time.Second), asrManager, cfg)
+		handler.SetHotwordResolver(func(names []string) ([]string, error) {
+			if names[0] != "products" {
+				return nil, fmt.Errorf("hotword set not found: %s", names[0])
+			}
+			return []string{"AeroSpeech"}, nil
+		})
+		handler.HandleConnectionWithOptions(conn, STTSessionOptions{ASROptions: config.ASROptions{Language: "zh"}})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Skipf("Skipping test: cannot connect to test server: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg STTMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v, %v", msg, err)
+	}
+
+	// 修改会话参数
+	conn.WriteJSON(STTMessage{Type: "config", Data: map[string]interface{}{"language": "en", "itn": false}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "config" {
+		t.Fatalf("Expected config message, got %+v, %v", msg, err)
+	}
+
+	// 模型不支持的参数被拒绝，保留原参数
+	conn.WriteJSON(STTMessage{Type: "config", Data: map[string]interface{}{"decoding_method": config.DecodingModifiedBeamSearch}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" {
+		t.Fatalf("Expected error message, got %+v, %v", msg, err)
+	}
+
+	// 热词集展开到会话热词，不存在的热词集被拒绝
+	conn.WriteJSON(STTMessage{Type: "config", Data: map[string]interface{}{"hotword_sets": []string{"missing"}}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" {
+		t.Fatalf("Expected error message, got %+v, %v", msg, err)
+	}
+	conn.WriteJSON(STTMessage{Type: "config", Data: map[string]interface{}{"hotwords": []string{"sherpa onnx"}, "hotword_sets": []string{"products"}, "punctuate": false}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "config" {
+		t.Fatalf("Expected config message, got %+v, %v", msg, err)
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 4096))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected result message, got %+v, %v", msg, err)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["lang"] != "en" || data["emotion"] != "neutral" || data["event"] != "speech" {
+		t.Errorf("Expected tags in result message, got %+v", msg.Data)
+	}
+	opts := asrManager.options()
+	if opts.Language != "en" || opts.ITN == nil || *opts.ITN || opts.DecodingMethod != "" {
+		t.Errorf("Unexpected options used for recognition: %+v", opts)
+	}
+	if len(opts.Hotwords) != 1 || len(opts.SetHotwords) != 1 || opts.SetHotwords[0] != "AeroSpeech" || len(opts.HotwordSets) != 0 {
+		t.Errorf("Unexpected hotwords used for recognition: %v / %v / %v", opts.Hotwords, opts.SetHotwords, opts.HotwordSets)
+	}
+	if opts.Punctuate == nil || *opts.Punctuate {
+		t.Errorf("Expected punctuate=false, got %v", opts.Punctuate)
+	}
+}
+
+func TestSTTHandler_IdentifySpeaker(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	asrManager := &mockASRManager{transcribeResult: "你好"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 4096},
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewSTTHandler(session.NewManager(100, 30*time.Second), asrManager, cfg)
+		handler.SetSpeakerIdentifier(func(ctx context.Context, audio []byte) (*SpeakerMatch, error) {
+			return &SpeakerMatch{ID: "alice", Name: "Alice", Score: 0.9}, nil
+		})
+		handler.HandleConnectionWithOptions(conn, STTSessionOptions{IdentifySpeaker: true})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Skipf("Skipping test: cannot connect to test server: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg STTMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v, %v", msg, err)
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 4096))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected result message, got %+v, %v", msg, err)
+	}
+	data, _ := msg.Data.(map[string]interface{})
+	if speaker, _ := data["speaker"].(map[string]interface{}); speaker["id"] != "alice" || speaker["name"] != "Alice" {
+		t.Errorf("Expected speaker in result message, got %+v", msg.Data)
+	}
+
+	// 关闭说话人辨认后结果不再带speaker
+	conn.WriteJSON(STTMessage{Type: "config", Data: map[string]interface{}{"identify_speaker": false}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "config" {
+		t.Fatalf("Expected config message, got %+v, %v", msg, err)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["identify_speaker"] != false {
+		t.Errorf("Expected identify_speaker=false in config message, got %+v", msg.Data)
+	}
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 4096))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected result message, got %+v, %v", msg, err)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["speaker"] != nil {
+		t.Errorf("Expected no speaker in result message, got %+v", msg.Data)
+	}
+}
+
+func TestSTTHandler_Enhance(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	asrManager := &mockASRManager{transcribeResult: "你好"}
+	enhanceCtx := make(chan context.Context, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 4096},
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewSTTHandler(session.NewManager(100, 30*time.Second), asrManager, cfg)
+		handler.SetAudioEnhancer(func(ctx context.Context, audio []byte, sampleRate int) ([]byte, error) {
+			enhanceCtx <- ctx
+			// 模拟降噪：输出全部置为1
+			return bytes.Repeat([]byte{1}, len(audio)), nil
+		}, true)
+		handler.HandleConnectionWithOptions(conn, STTSessionOptions{})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Skipf("Skipping test: cannot connect to test server: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg STTMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v, %v", msg, err)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["config"].(map[string]interface{})["enhance"] != true {
+		t.Errorf("Expected enhance enabled by default, got %+v", msg.Data)
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 4096))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected result message, got %+v, %v", msg, err)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["enhance_latency_ms"] == nil {
+		t.Errorf("Expected enhance_latency_ms in result message, got %+v", msg.Data)
+	}
+	asrManager.mu.Lock()
+	enhanced := asrManager.lastAudio[0] == 1
+	recognizeCtx := asrManager.lastCtx
+	asrManager.mu.Unlock()
+	if !enhanced {
+		t.Error("Expected enhanced audio to be recognized")
+	}
+
+	// 降噪和识别使用同一个会话上下文
+	ctx := <-enhanceCtx
+	if ctx == nil || recognizeCtx != ctx || ctx.Err() != nil {
+		t.Errorf("Expected live session context for enhancement and recognition, got %v / %v", ctx, recognizeCtx)
+	}
+
+	// 关闭降噪后识别原始音频
+	conn.WriteJSON(STTMessage{Type: "config", Data: map[string]interface{}{"enhance": false}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "config" {
+		t.Fatalf("Expected config message, got %+v, %v", msg, err)
+	}
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 4096))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected result message, got %+v, %v", msg, err)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["enhance_latency_ms"] != nil {
+		t.Errorf("Expected no enhance_latency_ms in result message, got %+v", msg.Data)
+	}
+	asrManager.mu.Lock()
+	enhanced = asrManager.lastAudio[0] == 1
+	asrManager.mu.Unlock()
+	if enhanced {
+		t.Error("Expected original audio to be recognized")
+	}
+
+	// 连接断开后会话上下文取消
+	conn.Close()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("Expected session context to be cancelled after disconnect")
+	}
+}
+
+func TestSTTHandler_Start(t *testing.T) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	asrManager := &mockASRManager{transcribeResult: "左侧舱门"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Audio:     config.AudioConfig{SampleRate: 16000, ChunkSize: 3200},
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewSTTHandler(session.NewManager(100, 30*time.Second), asrManager, cfg)
+		handler.SetVoiceActivityDetector(mockVoiceDetector{})
+		handler.HandleConnection(conn)
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg STTMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v, %v", msg, err)
+	}
+	cfg := msg.Data.(map[string]interface{})["config"].(map[string]interface{})
+	if cfg["vad_available"] != true || len(cfg["encodings"].([]interface{})) != 3 {
+		t.Errorf("Unexpected connection config: %v", cfg)
+	}
+
+	// 不兼容的设置被拒绝
+	for _, data := range []map[string]interface{}{
+		{"encoding": "aac"},
+		{"sample_rate": 96000},
+		{"channels": 6},
+		{"interim": true},
+		{"vad": map[string]interface{}{"threshold": 1.5}},
+		{"encoding": "opus", "sample_rate": 48000},
+	} {
+		conn.WriteJSON(STTMessage{Type: "start", Data: data})
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" {
+			t.Fatalf("Expected error for start %v, got %+v, %v", data, msg, err)
+		}
+	}
+
+	// 8kHz μ-law音频转换为16kHz PCM16
+	conn.WriteJSON(STTMessage{Type: "start", Data: map[string]interface{}{"sample_rate": 8000, "encoding": "mulaw", "language": "zh"}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "start" {
+		t.Fatalf("Expected start message, got %+v, %v", msg, err)
+	}
+	if data := msg.Data.(map[string]interface{}); data["sample_rate"] != float64(8000) || data["encoding"] != "mulaw" || data["channels"] != float64(1) || data["language"] != "zh" {
+		t.Errorf("Unexpected start reply: %v", data)
+	}
+	conn.WriteMessage(websocket.BinaryMessage, bytes.Repeat([]byte{0xff}, 1000))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected result message, got %+v, %v", msg, err)
+	}
+	asrManager.mu.Lock()
+	converted := len(asrManager.lastAudio)
+	asrManager.mu.Unlock()
+	if converted < 3900 || converted > 4000 {
+		t.Errorf("Expected about 4000 bytes of 16kHz audio, got %d", converted)
+	}
+	if asrManager.options().Language != "zh" {
+		t.Errorf("Expected language from start, got %q", asrManager.options().Language)
+	}
+
+	// 收到音频后需要先reset
+	conn.WriteJSON(STTMessage{Type: "start", Data: map[string]interface{}{}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" {
+		t.Fatalf("Expected error for start after audio, got %+v, %v", msg, err)
+	}
+	conn.WriteJSON(STTMessage{Type: "reset"})
+	conn.ReadJSON(&msg)
+
+	// 开启VAD和中间结果：32位浮点立体声，语句结束时发送最终结果
+	conn.WriteJSON(STTMessage{Type: "start", Data: map[string]interface{}{
+		"encoding": "pcm_f32le", "channels": 2, "interim": true, "vad": map[string]interface{}{"min_silence_duration": 0.3},
+	}})
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "start" {
+		t.Fatalf("Expected start message, got %+v, %v", msg, err)
+	}
+	speech := make([]byte, 8000*8) // 0.5秒
+	for i := 0; i < len(speech); i += 4 {
+		binary.LittleEndian.PutUint32(speech[i:], math.Float32bits(0.25))
+	}
+	conn.WriteMessage(websocket.BinaryMessage, speech)
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected interim result, got %+v, %v", msg, err)
+	}
+	if data := msg.Data.(map[string]interface{}); data["final"] != false || data["segment_id"] != float64(1) {
+		t.Errorf("Expected interim result for segment 1, got %v", data)
+	}
+	conn.WriteMessage(websocket.BinaryMessage, make([]byte, 800))
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "result" {
+		t.Fatalf("Expected final result, got %+v, %v", msg, err)
+	}
+	if data := msg.Data.(map[string]interface{}); data["final"] != true || data["segment_id"] != float64(1) {
+		t.Errorf("Expected final result for segment 1, got %v", data)
+	}
+	asrManager.mu.Lock()
+	converted = len(asrManager.lastAudio)
+	asrManager.mu.Unlock()
+	if converted != 16000 {
+		t.Errorf("Expected 16000 bytes of mono PCM16 audio, got %d", converted)
+	}
+}
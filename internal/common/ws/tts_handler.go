@@ -2,6 +2,7 @@ package ws
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -16,12 +17,22 @@ type TTSMessage struct {
 	SessionID string      `json:"session_id,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
 	Error     string      `json:"error,omitempty"`
+	Code      string      `json:"code,omitempty"` // error消息的错误码
+	Seq       uint64      `json:"seq,omitempty"`  // 开启会话恢复时的消息序号
+}
+
+// WithSeq 填入消息序号（实现session.Sequenced）
+func (m TTSMessage) WithSeq(seq uint64) interface{} {
+	m.Seq = seq
+	return m
 }
 
 // TTSHandler TTS WebSocket处理器
 type TTSHandler struct {
 	sessionManager *session.Manager
 	ttsManager     TTSManager
+	models         func(name string) (TTSManager, error) // 按名称选择模型，为nil时只使用ttsManager
+	opus           OpusEncoderFactory                    // Opus编码，为nil时只支持pcm_s16le输出
 	config         *config.TTSConfig
 }
 
@@ -31,6 +42,7 @@ type TTSManager interface {
 	GetStats() interface{}
 	GetAvgLatency() interface{}
 	GetPoolUsage() float64
+	GetSampleRate() int
 }
 
 // NewTTSHandler 创建TTS处理器
@@ -42,8 +54,42 @@ func NewTTSHandler(sessionManager *session.Manager, ttsManager TTSManager, cfg *
 	}
 }
 
+// SetModelLookup 设置按名称选择模型的查找函数
+func (h *TTSHandler) SetModelLookup(lookup func(name string) (TTSManager, error)) {
+	h.models = lookup
+}
+
+// SetOpusEncoder 设置Opus编码器，设置后start消息可以选择opus和ogg_opus输出
+func (h *TTSHandler) SetOpusEncoder(factory OpusEncoderFactory) {
+	h.opus = factory
+}
+
+// selectManager 按名称选择模型，名称为空时使用默认模型
+func (h *TTSHandler) selectManager(name string) (TTSManager, error) {
+	if name == "" {
+		return h.ttsManager, nil
+	}
+	if h.models == nil {
+		return nil, fmt.Errorf("model not found: %s", name)
+	}
+	return h.models(name)
+}
+
 // HandleConnection 处理WebSocket连接
 func (h *TTSHandler) HandleConnection(conn *websocket.Conn) {
+	h.HandleConnectionWithModel(conn, "")
+}
+
+// HandleConnectionWithModel 处理WebSocket连接，model为会话的默认模型（为空时使用默认模型），
+// 单个合成请求可以通过model字段覆盖
+func (h *TTSHandler) HandleConnectionWithModel(conn *websocket.Conn, model string) {
+	manager, err := h.selectManager(model)
+	if err != nil {
+		conn.WriteJSON(TTSMessage{Type: "error", Error: err.Error(), Code: ErrCodeInvalidRequest})
+		conn.Close()
+		return
+	}
+
 	// 创建会话
 	sess, err := h.sessionManager.CreateSession(conn, h.config.Session.SendQueueSize)
 	if err != nil {
@@ -51,21 +97,27 @@ func (h *TTSHandler) HandleConnection(conn *websocket.Conn) {
 		conn.Close()
 		return
 	}
+	resume := enableResume(sess, resumeScopeTTS, h.config.Session)
 
-	// 发送连接确认消息
+	// 发送连接确认消息，设备信息取自会话默认模型
+	provider := modelProvider(manager, h.config.TTS.Provider)
 	configMsg := TTSMessage{
 		Type:      "connection",
 		SessionID: sess.ID,
-		Data: map[string]interface{}{
-			"status":      "connected",
-			"session_id": sess.ID,
-			"config": map[string]interface{}{
-				"sample_rate":     h.config.Audio.SampleRate,
-				"format":          "pcm_s16le",
-				"provider":        h.config.TTS.Provider.Provider,
-				"gpu_available":   h.config.TTS.Provider.Provider == "cuda",
-				"gpu_device_id":   h.config.TTS.Provider.DeviceID,
+		Data: TTSConnectionData{
+			Status:          "connected",
+			SessionID:       sess.ID,
+			ProtocolVersion: ProtocolVersion,
+			Config: TTSConnectionConfig{
+				SampleRate:   h.config.Audio.SampleRate,
+				Format:       "pcm_s16le",
+				Provider:     provider.Provider,
+				GPUAvailable: provider.Provider == "cuda",
+				GPUDeviceID:  provider.DeviceID,
+				Model:        model,
+				Encodings:    h.encodings(),
 			},
+			Resume: resume,
 		},
 	}
 
@@ -78,111 +130,218 @@ func (h *TTSHandler) HandleConnection(conn *websocket.Conn) {
 	// 设置Pong处理器
 	SetPongHandler(conn, time.Duration(h.config.WebSocket.ReadTimeout)*time.Second)
 
-	// 处理消息循环
+	// 合成请求在worker中逐条处理，读取循环只负责入队和取消
+	queue := newTTSQueue(sess)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for req := queue.next(); req != nil; req = queue.next() {
+			h.synthesize(queue, req)
+		}
+	}()
+
+	// 处理消息循环，output为start消息选择的输出编码
+	output := TTSStartData{Encoding: EncodingPCMS16LE}
+	requested := false
 	for {
 		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				logger.Errorf("WebSocket error: %v", err)
 			}
+			// 开启会话恢复时等待客户端重连，会话状态保留
+			if conn = waitResume(sess, conn, err, h.config.WebSocket.ReadTimeout); conn != nil {
+				continue
+			}
 			break
 		}
 
 		// 更新会话活动时间
 		h.sessionManager.UpdateActivity(sess.ID)
 
-		if messageType == websocket.TextMessage {
-			// 文本消息（合成请求）
-			var msg TTSMessage
-			if err := json.Unmarshal(message, &msg); err != nil {
-				logger.Warnf("Failed to parse message: %v", err)
+		if messageType != websocket.TextMessage {
+			// 音频只由服务端发送
+			queue.sendError(ErrCodeUnexpectedBinary, "binary messages are not accepted", nil)
+			continue
+		}
+
+		// 文本消息（合成请求和控制消息）
+		msg, err := parseInbound(message)
+		if err != nil {
+			queue.sendError(errorCode(err, ErrCodeInvalidMessage), err.Error(), nil)
+			continue
+		}
+
+		switch msg.Type {
+		case "hello":
+			// 协商协议版本
+			hello, err := negotiateHello(msg)
+			if err != nil {
+				queue.sendError(errorCode(err, ErrCodeInvalidRequest), err.Error(), nil)
+				continue
+			}
+			queue.send(TTSMessage{Type: "hello", Data: hello})
+
+		case "start":
+			// 选择输出音频编码
+			start, err := h.parseStart(msg)
+			if err == nil && requested {
+				err = fmt.Errorf("start must be sent before the first synthesize request")
+			}
+			if err != nil {
+				queue.sendError(errorCode(err, ErrCodeInvalidRequest), fmt.Sprintf("invalid start: %v", err), nil)
+				continue
+			}
+			output = start
+			queue.send(TTSMessage{Type: "start", Data: output})
+
+		case "synthesize":
+			req, err := h.parseSynthesize(msg, model)
+			if err == nil {
+				req.output = output
+				err = queue.enqueue(req)
+			}
+			if err != nil {
+				queue.sendError(errorCode(err, ErrCodeInvalidRequest), err.Error(), requestIDData(msg))
 				continue
 			}
+			requested = true
+
+		case "cancel":
+			// 按请求ID取消排队或正在合成的请求
+			var ref TTSRequestRef
+			if err := msg.decode(&ref); err != nil {
+				queue.sendError(errorCode(err, ErrCodeInvalidRequest), err.Error(), nil)
+			} else if ref.RequestID == "" {
+				queue.sendError(ErrCodeInvalidRequest, "request_id is required", nil)
+			} else if !queue.cancel(ref.RequestID) {
+				queue.sendError(ErrCodeNotFound, fmt.Sprintf("request not found: %s", ref.RequestID), &ErrorData{RequestID: ref.RequestID})
+			}
 
-			switch msg.Type {
-			case "synthesize":
-				h.processSynthesize(sess, conn, msg)
+		case "clear":
+			// 取消全部请求
+			queue.send(TTSMessage{Type: "clear", Data: TTSClearResult{Cancelled: queue.clear()}})
 
-			case "ping":
-				// 心跳响应
-				sess.Send(TTSMessage{
-					Type:      "pong",
-					SessionID: sess.ID,
-				})
+		case "stats":
+			// 会话的音频流量统计
+			queue.send(TTSMessage{Type: "stats", Data: sessionStats(sess)})
+
+		case "ack":
+			// 确认收到的消息，不再为恢复保留
+			if err := ackSession(sess, msg); err != nil {
+				queue.sendError(errorCode(err, ErrCodeInvalidRequest), err.Error(), nil)
 			}
+
+		case "ping":
+			// 心跳响应
+			queue.send(TTSMessage{Type: "pong"})
+
+		default:
+			queue.sendError(ErrCodeUnknownType, fmt.Sprintf("unknown message type: %s", msg.Type), nil)
 		}
 	}
 
+	// 取消未完成的请求并等待worker退出
+	queue.close()
+	<-done
+
 	// 清理会话
 	h.sessionManager.RemoveSession(sess.ID)
 }
 
-// processSynthesize 处理合成请求
-func (h *TTSHandler) processSynthesize(sess *session.Session, conn *websocket.Conn, msg TTSMessage) {
-	// 解析请求数据
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		sess.Send(TTSMessage{
-			Type:      "error",
-			SessionID: sess.ID,
-			Error:     "invalid request data",
-		})
-		return
+// requestIDData 取出合成请求中的request_id，用于在错误消息中回传
+func requestIDData(msg *inboundMessage) *ErrorData {
+	var fields struct {
+		RequestID interface{} `json:"request_id"`
+	}
+	json.Unmarshal(msg.Data, &fields)
+	if id, ok := fields.RequestID.(string); ok && id != "" {
+		return &ErrorData{RequestID: id}
+	}
+	return nil
+}
+
+// parseSynthesize 解析合成请求，model为会话的默认模型
+func (h *TTSHandler) parseSynthesize(msg *inboundMessage, model string) (*ttsRequest, error) {
+	var data SynthesizeRequest
+	if err := msg.decode(&data); err != nil {
+		return nil, err
+	}
+	if data.Text == "" {
+		return nil, fmt.Errorf("text is required")
 	}
 
-	text, _ := data["text"].(string)
-	speakerID := 0
-	if sid, ok := data["speaker_id"].(float64); ok {
-		speakerID = int(sid)
+	req := &ttsRequest{id: data.RequestID, text: data.Text, speakerID: data.SpeakerID, speed: 1.0, model: model}
+	if data.Speed != nil {
+		req.speed = *data.Speed
+	}
+	if data.Model != "" {
+		req.model = data.Model
 	}
-	speed := float32(1.0)
-	if s, ok := data["speed"].(float64); ok {
-		speed = float32(s)
+	if _, err := h.selectManager(req.model); err != nil {
+		return nil, err
 	}
+	return req, nil
+}
 
-	if text == "" {
-		sess.Send(TTSMessage{
-			Type:      "error",
-			SessionID: sess.ID,
-			Error:     "text is required",
+// synthesize 合成一条请求并分块发送音频，请求被取消时停止发送
+func (h *TTSHandler) synthesize(queue *ttsQueue, req *ttsRequest) {
+	failed := func(err error) {
+		queue.finish(req, TTSMessage{
+			Type:  "error",
+			Data:  &ErrorData{RequestID: req.id},
+			Error: err.Error(),
+			Code:  ErrCodeProcessingFailed,
 		})
+	}
+	if !queue.sendFor(req, TTSMessage{Type: "started", Data: TTSRequestRef{RequestID: req.id}}) {
+		return
+	}
+
+	ttsManager, err := h.selectManager(req.model)
+	if err != nil {
+		failed(err)
 		return
 	}
 
 	// 执行合成
-	audio, err := h.ttsManager.Synthesize(nil, text, speakerID, speed)
+	audio, err := ttsManager.Synthesize(req.ctx, req.text, req.speakerID, req.speed)
+	if req.ctx.Err() != nil {
+		// 已取消，cancelled事件已发送
+		return
+	}
 	if err != nil {
 		logger.Errorf("TTS synthesis failed: %v", err)
-		sess.Send(TTSMessage{
-			Type:      "error",
-			SessionID: sess.ID,
-			Error:     err.Error(),
-		})
+		failed(err)
 		return
 	}
 
-	// 分块发送音频数据（直接使用WebSocket连接发送二进制数据）
-	chunkSize := 4096
-	for i := 0; i < len(audio); i += chunkSize {
-		end := i + chunkSize
-		if end > len(audio) {
-			end = len(audio)
-		}
-
-		// 直接使用conn发送二进制音频数据
-		if err := conn.WriteMessage(websocket.BinaryMessage, audio[i:end]); err != nil {
-			logger.Errorf("Failed to send audio chunk: %v", err)
+	// 按输出编码分块发送音频数据，与事件共用发送队列以保证顺序
+	frames, err := h.encodeAudio(req.output, audio, ttsManager.GetSampleRate())
+	if err != nil {
+		logger.Errorf("TTS audio encoding failed: %v", err)
+		failed(err)
+		return
+	}
+	sent := 0
+	for _, frame := range frames {
+		if !queue.sendAudio(req, frame.data) {
+			failed(fmt.Errorf("failed to send audio"))
 			return
 		}
+		queue.sess.AddAudioOut(len(frame.data), frame.pcmBytes)
+		sent += len(frame.data)
 	}
 
 	// 发送完成消息
-	sess.Send(TTSMessage{
-		Type:      "complete",
-		SessionID: sess.ID,
-		Data: map[string]interface{}{
-			"timestamp": time.Now().Unix(),
+	queue.finish(req, TTSMessage{
+		Type: "complete",
+		Data: TTSComplete{
+			RequestID: req.id,
+			Bytes:     sent,
+			PCMBytes:  len(audio),
+			Chunks:    len(frames),
+			Timestamp: time.Now().Unix(),
 		},
 	})
 }
-
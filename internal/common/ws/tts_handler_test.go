@@ -1,9 +1,13 @@
 package ws
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -40,6 +44,10 @@ func (m *mockTTSManager) GetPoolUsage() float64 {
 	return m.poolUsage
 }
 
+func (m *mockTTSManager) GetSampleRate() int {
+	return 16000
+}
+
 func TestTTSHandler_HandleConnection(t *testing.T) {
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
@@ -458,3 +466,279 @@ func TestTTSHandler_HandleEmptyText(t *testing.T) {
 	conn.Close()
 }
 
+
+// blockingTTSManager 合成一直阻塞到请求被取消
+type blockingTTSManager struct {
+	mockTTSManager
+}
+
+func (m *blockingTTSManager) Synthesize(ctx interface{}, text string, speakerID int, speed float32) ([]byte, error) {
+	<-ctx.(context.Context).Done()
+	return nil, ctx.(context.Context).Err()
+}
+
+// startTTSServer 启动TTS测试服务并建立连接，跳过连接确认消息，setup用于设置处理器的可选功能
+func startTTSServer(t *testing.T, ttsManager TTSManager, setup ...func(h *TTSHandler)) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		cfg := &config.TTSConfig{
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		handler := NewTTSHandler(session.NewManager(100, 30*time.Second), ttsManager, cfg)
+		for _, f := range setup {
+			f(handler)
+		}
+		handler.HandleConnection(conn)
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	readTTSEvent(t, conn, nil) // connection
+	return conn
+}
+
+// readTTSEvent 读取下一条JSON消息，之前的音频字节数累加到audio
+func readTTSEvent(t *testing.T, conn *websocket.Conn, audio *int) (string, map[string]interface{}) {
+	t.Helper()
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		if messageType == websocket.BinaryMessage {
+			if audio != nil {
+				*audio += len(data)
+			}
+			continue
+		}
+		var msg TTSMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("Failed to parse message: %v", err)
+		}
+		fields, _ := msg.Data.(map[string]interface{})
+		if fields == nil {
+			fields = map[string]interface{}{}
+		}
+		if msg.Error != "" {
+			fields["error"] = msg.Error
+		}
+		return msg.Type, fields
+	}
+}
+
+// sendTTS 发送控制消息
+func sendTTS(conn *websocket.Conn, msgType string, data map[string]interface{}) {
+	payload, _ := json.Marshal(TTSMessage{Type: msgType, Data: data})
+	conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+func TestTTSHandler_Queue(t *testing.T) {
+	conn := startTTSServer(t, &mockTTSManager{synthesizeResult: make([]byte, 5000)})
+
+	sendTTS(conn, "synthesize", map[string]interface{}{"request_id": "a", "text": "第一句"})
+	sendTTS(conn, "synthesize", map[string]interface{}{"text": "第二句"})
+
+	// 请求按顺序处理，每条请求的音频位于started和complete之间；
+	// queued事件由读取循环发送，与前一条请求的事件先后不定
+	perRequest := map[string][]string{}
+	audio := map[string]int{}
+	var order []string
+	for len(order) < 6 {
+		var n int
+		msgType, data := readTTSEvent(t, conn, &n)
+		id, _ := data["request_id"].(string)
+		if n > 0 {
+			audio[id] += n
+		}
+		perRequest[id] = append(perRequest[id], msgType)
+		order = append(order, msgType+":"+id)
+	}
+	for _, id := range []string{"a", "req-1"} {
+		if want := []string{"queued", "started", "complete"}; !reflect.DeepEqual(perRequest[id], want) {
+			t.Errorf("Events for %s = %v, want %v", id, perRequest[id], want)
+		}
+		if audio[id] != 5000 {
+			t.Errorf("Expected 5000 bytes of audio before complete:%s, got %d", id, audio[id])
+		}
+	}
+	if got := strings.Join(order, ","); !strings.Contains(got, "complete:a,started:req-1") {
+		t.Errorf("Expected req-1 to start after a completes: %s", got)
+	}
+
+	// 重复的请求ID在完成后可以复用
+	sendTTS(conn, "synthesize", map[string]interface{}{"request_id": "a", "text": "再来一句"})
+	if msgType, data := readTTSEvent(t, conn, nil); msgType != "queued" || data["request_id"] != "a" || data["position"] != float64(0) {
+		t.Errorf("Expected queued a at position 0, got %s %v", msgType, data)
+	}
+}
+
+func TestTTSHandler_Cancel(t *testing.T) {
+	conn := startTTSServer(t, &blockingTTSManager{})
+
+	for _, id := range []string{"a", "b", "c"} {
+		sendTTS(conn, "synthesize", map[string]interface{}{"request_id": id, "text": "测试"})
+	}
+	expect := func(wantType, wantID string) map[string]interface{} {
+		t.Helper()
+		msgType, data := readTTSEvent(t, conn, nil)
+		if msgType != wantType || (wantID != "" && data["request_id"] != wantID) {
+			t.Fatalf("Expected %s %s, got %s %v", wantType, wantID, msgType, data)
+		}
+		return data
+	}
+	// a开始合成，b、c排队（started与queued的先后取决于调度）
+	seen := map[string]bool{}
+	for len(seen) < 4 {
+		msgType, data := readTTSEvent(t, conn, nil)
+		seen[msgType+":"+data["request_id"].(string)] = true
+	}
+	if !seen["started:a"] || !seen["queued:b"] || !seen["queued:c"] {
+		t.Fatalf("Unexpected events: %v", seen)
+	}
+
+	// 重复ID
+	sendTTS(conn, "synthesize", map[string]interface{}{"request_id": "b", "text": "测试"})
+	if data := expect("error", "b"); !strings.Contains(data["error"].(string), "duplicate") {
+		t.Errorf("Expected duplicate request_id error, got %v", data)
+	}
+
+	// 取消排队中的请求
+	sendTTS(conn, "cancel", map[string]interface{}{"request_id": "b"})
+	expect("cancelled", "b")
+
+	// 取消正在合成的请求后继续处理下一条
+	sendTTS(conn, "cancel", map[string]interface{}{"request_id": "a"})
+	expect("cancelled", "a")
+	expect("started", "c")
+
+	// 不存在的请求
+	sendTTS(conn, "cancel", map[string]interface{}{"request_id": "b"})
+	expect("error", "b")
+
+	// clear取消全部请求
+	sendTTS(conn, "synthesize", map[string]interface{}{"request_id": "d", "text": "测试"})
+	expect("queued", "d")
+	sendTTS(conn, "clear", nil)
+	expect("cancelled", "c")
+	expect("cancelled", "d")
+	if data := expect("clear", ""); data["cancelled"] != float64(2) {
+		t.Errorf("Expected 2 cancelled requests, got %v", data)
+	}
+}
+
+// fakeOpusEncoder 每帧输出PCM长度1/20的包
+type fakeOpusEncoder struct{}
+
+func (fakeOpusEncoder) Encode(pcm []byte) ([]byte, error) { return make([]byte, len(pcm)/20), nil }
+
+func TestTTSHandler_OpusOutput(t *testing.T) {
+	withOpus := func(h *TTSHandler) {
+		h.SetOpusEncoder(func(sampleRate, channels, bitrate int) (OpusEncoder, error) {
+			if sampleRate != 16000 || channels != 1 || bitrate != 32000 {
+				t.Errorf("Unexpected encoder parameters: %d %d %d", sampleRate, channels, bitrate)
+			}
+			return fakeOpusEncoder{}, nil
+		})
+	}
+	// 0.5秒16kHz音频，25个20ms帧
+	ttsManager := &mockTTSManager{synthesizeResult: make([]byte, 16000)}
+
+	// synthesize之间的二进制消息
+	synthesize := func(conn *websocket.Conn, id string) ([][]byte, map[string]interface{}) {
+		t.Helper()
+		sendTTS(conn, "synthesize", map[string]interface{}{"request_id": id, "text": "测试"})
+		var frames [][]byte
+		for {
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("Failed to read message: %v", err)
+			}
+			if messageType == websocket.BinaryMessage {
+				frames = append(frames, data)
+				continue
+			}
+			var msg TTSMessage
+			json.Unmarshal(data, &msg)
+			if msg.Type == "complete" {
+				return frames, msg.Data.(map[string]interface{})
+			}
+			if msg.Type != "queued" && msg.Type != "started" {
+				t.Fatalf("Unexpected message: %s", data)
+			}
+		}
+	}
+
+	conn := startTTSServer(t, ttsManager, withOpus)
+	sendTTS(conn, "start", map[string]interface{}{"encoding": "opus", "bitrate": 32000})
+	if msgType, data := readTTSEvent(t, conn, nil); msgType != "start" || data["encoding"] != "opus" || data["frame_duration_ms"] != float64(20) {
+		t.Fatalf("Unexpected start reply: %s %v", msgType, data)
+	}
+	frames, complete := synthesize(conn, "a")
+	if len(frames) != 25 || len(frames[0]) != 32 {
+		t.Errorf("Expected 25 packets of 32 bytes, got %d", len(frames))
+	}
+	if complete["bytes"] != float64(800) || complete["pcm_bytes"] != float64(16000) || complete["chunks"] != float64(25) {
+		t.Errorf("Unexpected complete event: %v", complete)
+	}
+	sendTTS(conn, "stats", nil)
+	if msgType, data := readTTSEvent(t, conn, nil); msgType != "stats" {
+		t.Errorf("Expected stats, got %s", msgType)
+	} else if audio := data["audio"].(map[string]interface{}); audio["bytes_out"] != float64(800) || audio["saving_out"] != 0.95 {
+		t.Errorf("Unexpected stats: %v", data)
+	}
+
+	// 合成请求之后不能再修改编码
+	sendTTS(conn, "start", map[string]interface{}{"encoding": "pcm_s16le"})
+	if msgType, data := readTTSEvent(t, conn, nil); msgType != "error" || !strings.Contains(data["error"].(string), "before the first synthesize") {
+		t.Errorf("Expected start error, got %s %v", msgType, data)
+	}
+
+	// ogg_opus：每条请求是一个完整的Ogg Opus流
+	conn = startTTSServer(t, ttsManager, withOpus)
+	sendTTS(conn, "start", map[string]interface{}{"encoding": "ogg_opus", "bitrate": 32000})
+	readTTSEvent(t, conn, nil)
+	frames, complete = synthesize(conn, "b")
+	if len(frames) != 2 || complete["chunks"] != float64(2) {
+		t.Fatalf("Expected header and one audio page, got %d messages", len(frames))
+	}
+	reader := &oggOpusReader{}
+	var packets [][]byte
+	for _, frame := range frames {
+		out, err := reader.feed(frame)
+		if err != nil {
+			t.Fatalf("Invalid ogg output: %v", err)
+		}
+		packets = append(packets, out...)
+	}
+	if len(packets) != 25 {
+		t.Errorf("Expected 25 packets in ogg stream, got %d", len(packets))
+	}
+	last := frames[len(frames)-1]
+	if last[5]&oggFlagEOS == 0 || binary.LittleEndian.Uint64(last[6:]) != opusPreSkip+24000 {
+		t.Errorf("Expected final page with granule %d, got flags %x granule %d", opusPreSkip+24000, last[5], binary.LittleEndian.Uint64(last[6:]))
+	}
+
+	// 未设置编码器时不支持opus
+	conn = startTTSServer(t, ttsManager)
+	sendTTS(conn, "start", map[string]interface{}{"encoding": "opus"})
+	if msgType, data := readTTSEvent(t, conn, nil); msgType != "error" || !strings.Contains(data["error"].(string), "not enabled") {
+		t.Errorf("Expected not enabled error, got %s %v", msgType, data)
+	}
+}
@@ -0,0 +1,144 @@
+package ws
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// TTS输出的Opus码率（bps）
+const (
+	defaultOpusBitrate = 24000
+	minOpusBitrate     = 6000
+	maxOpusBitrate     = 128000
+)
+
+// ttsChunkSize pcm_s16le输出时每个二进制消息的字节数
+const ttsChunkSize = 4096
+
+// audioFrame 发送给客户端的一个二进制消息，pcmBytes为它包含的音频编码前的PCM字节数
+type audioFrame struct {
+	data     []byte
+	pcmBytes int
+}
+
+// encodings start消息可选择的输出编码
+func (h *TTSHandler) encodings() []string {
+	encodings := []string{EncodingPCMS16LE}
+	if h.opus != nil {
+		encodings = append(encodings, EncodingOpus, EncodingOggOpus)
+	}
+	return encodings
+}
+
+// parseStart 解析start消息，返回会话的输出音频编码
+func (h *TTSHandler) parseStart(msg *inboundMessage) (TTSStartData, error) {
+	var req TTSStartRequest
+	if err := msg.decode(&req); err != nil {
+		return TTSStartData{}, err
+	}
+	switch req.Encoding {
+	case "", EncodingPCMS16LE:
+		if req.Bitrate != 0 {
+			return TTSStartData{}, fmt.Errorf("bitrate is only supported for opus encodings")
+		}
+		return TTSStartData{Encoding: EncodingPCMS16LE}, nil
+	case EncodingOpus, EncodingOggOpus:
+		if h.opus == nil {
+			return TTSStartData{}, newProtocolError(ErrCodeNotEnabled, "opus encoding is not enabled")
+		}
+	default:
+		return TTSStartData{}, fmt.Errorf("unsupported encoding: %s", req.Encoding)
+	}
+
+	output := TTSStartData{Encoding: req.Encoding, Bitrate: req.Bitrate, FrameDurationMs: int(opusFrameDuration.Milliseconds())}
+	if output.Bitrate == 0 {
+		output.Bitrate = defaultOpusBitrate
+	}
+	if output.Bitrate < minOpusBitrate || output.Bitrate > maxOpusBitrate {
+		return TTSStartData{}, fmt.Errorf("bitrate must be between %d and %d", minOpusBitrate, maxOpusBitrate)
+	}
+	return output, nil
+}
+
+// encodeAudio 将合成的PCM16单声道音频按输出编码分为二进制消息
+func (h *TTSHandler) encodeAudio(output TTSStartData, audio []byte, sampleRate int) ([]audioFrame, error) {
+	if output.Encoding != EncodingOpus && output.Encoding != EncodingOggOpus {
+		var frames []audioFrame
+		for i := 0; i < len(audio); i += ttsChunkSize {
+			end := i + ttsChunkSize
+			if end > len(audio) {
+				end = len(audio)
+			}
+			frames = append(frames, audioFrame{data: audio[i:end], pcmBytes: end - i})
+		}
+		return frames, nil
+	}
+
+	// 转换为Opus支持的采样率，按帧编码，末帧补齐静音
+	rate := opusSampleRate(sampleRate)
+	pcm := audio
+	if rate != sampleRate {
+		samples := make([]float32, len(audio)/2)
+		for i := range samples {
+			samples[i] = decodeSample(EncodingPCMS16LE, audio[i*2:])
+		}
+		pcm = encodePCM16(newResampler(sampleRate, rate).process(samples))
+	}
+	encoder, err := h.opus(rate, 1, output.Bitrate)
+	if err != nil {
+		return nil, err
+	}
+	frameSize := rate * int(opusFrameDuration.Milliseconds()) / 1000 * 2
+	// offset 编码音频中的位置对应的原始PCM字节偏移（按样本对齐）
+	offset := func(n int) int { return len(audio) * n / len(pcm) &^ 1 }
+	var packets [][]byte
+	var pcmBytes []int
+	for i := 0; i < len(pcm); i += frameSize {
+		frame := make([]byte, frameSize)
+		copy(frame, pcm[i:])
+		packet, err := encoder.Encode(frame)
+		if err != nil {
+			return nil, fmt.Errorf("opus encoding failed: %v", err)
+		}
+		end := i + frameSize
+		if end > len(pcm) {
+			end = len(pcm)
+		}
+		packets = append(packets, packet)
+		pcmBytes = append(pcmBytes, offset(end)-offset(i))
+	}
+
+	if output.Encoding == EncodingOpus {
+		frames := make([]audioFrame, len(packets))
+		for i, packet := range packets {
+			frames[i] = audioFrame{data: packet, pcmBytes: pcmBytes[i]}
+		}
+		return frames, nil
+	}
+
+	// 每条请求为一个Ogg Opus流：第一个消息为头页，之后每个消息为一页音频
+	writer := newOggOpusWriter(rand.Uint32())
+	frames := []audioFrame{{data: writer.headers(sampleRate, 1)}}
+	samples := len(pcm) / 2 * opusGranuleRate / rate
+	frameSamples := opusGranuleRate * int(opusFrameDuration.Milliseconds()) / 1000
+	for i := 0; i < len(packets); i += oggPacketsPerPage {
+		end := i + oggPacketsPerPage
+		if end > len(packets) {
+			end = len(packets)
+		}
+		frame := audioFrame{}
+		for _, n := range pcmBytes[i:end] {
+			frame.pcmBytes += n
+		}
+		pageSamples := (end - i) * frameSamples
+		if end == len(packets) {
+			pageSamples = samples - i*frameSamples
+		}
+		frame.data = writer.audio(packets[i:end], pageSamples, end == len(packets))
+		frames = append(frames, frame)
+	}
+	if len(packets) == 0 {
+		frames = append(frames, audioFrame{data: writer.audio(nil, 0, true)})
+	}
+	return frames, nil
+}
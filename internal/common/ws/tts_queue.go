@@ -0,0 +1,227 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+const (
+	maxTTSQueueSize    = 32 // 每个会话等待合成的最大请求数
+	maxTTSRequestIDLen = 64 // 客户端请求ID的最大长度
+)
+
+// ttsRequest 会话中的一条合成请求
+type ttsRequest struct {
+	id        string
+	text      string
+	speakerID int
+	speed     float32
+	model     string
+	output    TTSStartData // 会话的输出音频编码
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// ttsQueue 会话的合成队列：读取循环入队和取消，worker按顺序逐条合成。
+// 请求被取消后不再发送它的任何消息，cancelled事件之后不会再收到该请求的音频
+type ttsQueue struct {
+	sess    *session.Session
+	mu      sync.Mutex
+	pending []*ttsRequest
+	current *ttsRequest // 正在合成的请求
+	nextID  int
+	wake    chan struct{}
+	closed  bool
+}
+
+// newTTSQueue 创建合成队列
+func newTTSQueue(sess *session.Session) *ttsQueue {
+	return &ttsQueue{sess: sess, wake: make(chan struct{}, 1)}
+}
+
+// active 请求ID是否在排队或合成中，调用方持有锁
+func (q *ttsQueue) active(id string) bool {
+	if q.current != nil && q.current.id == id {
+		return true
+	}
+	for _, req := range q.pending {
+		if req.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue 请求入队并发送queued事件（position为排在它前面的请求数），未指定ID时分配会话内唯一的ID
+func (q *ttsQueue) enqueue(req *ttsRequest) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) >= maxTTSQueueSize {
+		return newProtocolError(ErrCodeQueueFull, "too many queued requests, max %d", maxTTSQueueSize)
+	}
+	if req.id == "" {
+		for req.id == "" || q.active(req.id) {
+			q.nextID++
+			req.id = fmt.Sprintf("req-%d", q.nextID)
+		}
+	} else if len(req.id) > maxTTSRequestIDLen {
+		return fmt.Errorf("request_id too long, max %d characters", maxTTSRequestIDLen)
+	} else if q.active(req.id) {
+		return fmt.Errorf("duplicate request_id: %s", req.id)
+	}
+	req.ctx, req.cancel = context.WithCancel(context.Background())
+
+	position := len(q.pending)
+	if q.current != nil {
+		position++
+	}
+	q.pending = append(q.pending, req)
+	q.send(TTSMessage{Type: "queued", Data: TTSQueued{RequestID: req.id, Position: position}})
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next 等待下一条请求并标记为正在合成，队列关闭时返回nil
+func (q *ttsQueue) next() *ttsRequest {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return nil
+		}
+		if len(q.pending) > 0 {
+			req := q.pending[0]
+			q.pending = q.pending[1:]
+			q.current = req
+			q.mu.Unlock()
+			return req
+		}
+		q.mu.Unlock()
+		<-q.wake
+	}
+}
+
+// cancel 取消指定请求，请求不存在（或已完成）时返回false
+func (q *ttsQueue) cancel(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.current != nil && q.current.id == id {
+		q.cancelLocked(q.current)
+		q.current = nil
+		return true
+	}
+	for i, req := range q.pending {
+		if req.id == id {
+			q.pending = append(q.pending[:i:i], q.pending[i+1:]...)
+			q.cancelLocked(req)
+			return true
+		}
+	}
+	return false
+}
+
+// clear 取消正在合成和排队的全部请求，返回取消的请求数
+func (q *ttsQueue) clear() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.pending)
+	if q.current != nil {
+		q.cancelLocked(q.current)
+		q.current = nil
+		n++
+	}
+	for _, req := range q.pending {
+		q.cancelLocked(req)
+	}
+	q.pending = nil
+	return n
+}
+
+// cancelLocked 取消请求并发送cancelled事件，调用方持有锁
+func (q *ttsQueue) cancelLocked(req *ttsRequest) {
+	req.cancel()
+	q.send(TTSMessage{Type: "cancelled", Data: TTSRequestRef{RequestID: req.id}})
+}
+
+// close 关闭队列并取消全部请求（连接已断开，不再发送事件）
+func (q *ttsQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	if q.current != nil {
+		q.current.cancel()
+	}
+	for _, req := range q.pending {
+		req.cancel()
+	}
+	q.pending = nil
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// sendFor 在req仍是正在合成的请求时发送消息
+func (q *ttsQueue) sendFor(req *ttsRequest, msg TTSMessage) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.current != req {
+		return false
+	}
+	q.send(msg)
+	return true
+}
+
+// sendAudio 发送req的一段音频，发送队列满时等待，请求被取消时返回false
+func (q *ttsQueue) sendAudio(req *ttsRequest, audio []byte) bool {
+	for {
+		q.mu.Lock()
+		if q.current != req {
+			q.mu.Unlock()
+			return false
+		}
+		err := q.sess.SendBinary(audio)
+		q.mu.Unlock()
+		if err != session.ErrSendQueueFull {
+			return err == nil
+		}
+		select {
+		case <-req.ctx.Done():
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// finish 结束正在合成的请求，仍未被取消时发送msg
+func (q *ttsQueue) finish(req *ttsRequest, msg TTSMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.current == req {
+		q.current = nil
+		q.send(msg)
+	}
+	req.cancel()
+}
+
+// send 发送消息
+func (q *ttsQueue) send(msg TTSMessage) {
+	msg.SessionID = q.sess.ID
+	q.sess.Send(msg)
+}
+
+// sendError 发送带错误码的错误消息，data为nil时不带数据
+func (q *ttsQueue) sendError(code, message string, data *ErrorData) {
+	msg := TTSMessage{Type: "error", Error: message, Code: code}
+	if data != nil {
+		msg.Data = data
+	}
+	q.send(msg)
+}
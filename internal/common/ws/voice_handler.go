@@ -0,0 +1,403 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+const (
+	voiceFrameDuration = 100 * time.Millisecond // 回复音频每帧时长
+	voicePlaybackLead  = 300 * time.Millisecond // 回复音频比实时播放提前发送的时长
+	voiceTurnQueueSize = 4                      // 等待处理的语句数
+)
+
+// VoiceMessage 语音对话消息结构
+type VoiceMessage struct {
+	Type      string      `json:"type"`
+	SessionID string      `json:"session_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Code      string      `json:"code,omitempty"` // 错误码（见protocol.go）
+}
+
+// VoiceActivityDetector 语音活动检测器接口
+type VoiceActivityDetector interface {
+	NewStream(opts *VADOptions) (VoiceStream, error) // opts为nil时使用服务端配置
+	SampleRate() int
+}
+
+// VoiceStream 单个会话的语音活动检测流
+type VoiceStream interface {
+	Accept(audio []byte) [][]byte // 输入音频，返回已结束的语句
+	Speaking() bool               // 当前是否处于语音中
+	Flush() [][]byte              // 结束当前语句
+	Close()
+}
+
+// SpeechSynthesizer 语音对话的合成接口，输出PCM16单声道音频
+type SpeechSynthesizer interface {
+	Synthesize(ctx interface{}, text string, speakerID int, speed float32) ([]byte, error)
+	GetSampleRate() int
+}
+
+// VoiceSessionOptions 语音对话会话参数
+type VoiceSessionOptions struct {
+	Language  string  // 识别语言，为空时由模型自动识别
+	SpeakerID int     // 回复的说话人
+	Speed     float32 // 回复的语速
+}
+
+// VoiceHandler 全双工语音对话WebSocket处理器：VAD切分用户语音并逐句识别，
+// 识别结果交给对话处理器，回复经TTS合成后在同一连接上按实时速度推送
+type VoiceHandler struct {
+	sessionManager *session.Manager
+	vad            VoiceActivityDetector
+	asrManager     ASRManager
+	tts            SpeechSynthesizer
+	dialog         DialogHandler
+	config         *config.STTConfig
+}
+
+// NewVoiceHandler 创建语音对话处理器
+func NewVoiceHandler(sessionManager *session.Manager, vad VoiceActivityDetector, asrManager ASRManager, tts SpeechSynthesizer, dialog DialogHandler, cfg *config.STTConfig) *VoiceHandler {
+	return &VoiceHandler{
+		sessionManager: sessionManager,
+		vad:            vad,
+		asrManager:     asrManager,
+		tts:            tts,
+		dialog:         dialog,
+		config:         cfg,
+	}
+}
+
+// voiceTurn 一轮对话，从识别出语句开始到回复播放结束
+type voiceTurn struct {
+	id       int
+	cancel   context.CancelFunc
+	started  time.Time     // 开始发送回复音频的时间，未开始时为零值
+	duration time.Duration // 回复音频时长
+}
+
+// voiceConversation 单个连接的对话状态
+type voiceConversation struct {
+	h      *VoiceHandler
+	sess   *session.Session
+	opts   VoiceSessionOptions
+	mu     sync.Mutex
+	turn   *voiceTurn // 进行中的一轮，空闲时为nil
+	nextID int
+}
+
+// HandleConnection 处理WebSocket连接
+func (h *VoiceHandler) HandleConnection(conn *websocket.Conn, opts VoiceSessionOptions) {
+	stream, err := h.vad.NewStream(nil)
+	if err != nil {
+		conn.WriteJSON(VoiceMessage{Type: "error", Error: err.Error()})
+		conn.Close()
+		return
+	}
+	defer stream.Close()
+
+	// 创建会话
+	sess, err := h.sessionManager.CreateSession(conn, h.config.Session.SendQueueSize)
+	if err != nil {
+		logger.Errorf("Failed to create session: %v", err)
+		conn.Close()
+		return
+	}
+	defer h.sessionManager.RemoveSession(sess.ID)
+
+	// 发送连接确认消息
+	if err := sess.Send(VoiceMessage{
+		Type:      "connection",
+		SessionID: sess.ID,
+		Data: map[string]interface{}{
+			"status":     "connected",
+			"session_id": sess.ID,
+			"config": map[string]interface{}{
+				"input_sample_rate":  h.vad.SampleRate(),
+				"output_sample_rate": h.tts.GetSampleRate(),
+				"format":             "pcm_s16le",
+				"language":           opts.Language,
+				"speaker_id":         opts.SpeakerID,
+				"speed":              opts.Speed,
+			},
+		},
+	}); err != nil {
+		logger.Errorf("Failed to send connection message: %v", err)
+		sess.Close()
+		return
+	}
+
+	// 设置Pong处理器
+	SetPongHandler(conn, time.Duration(h.config.WebSocket.ReadTimeout)*time.Second)
+
+	// 语句按顺序逐句处理，读取循环只做VAD以便及时检测打断
+	c := &voiceConversation{h: h, sess: sess, opts: opts}
+	ctx, cancel := context.WithCancel(context.Background())
+	turns := make(chan []byte, voiceTurnQueueSize)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for audio := range turns {
+			c.respond(ctx, audio)
+		}
+	}()
+	defer func() {
+		cancel()
+		close(turns)
+		wg.Wait()
+	}()
+
+	enqueue := func(segments [][]byte) {
+		for _, segment := range segments {
+			select {
+			case turns <- segment:
+			default:
+				c.sendError(ErrCodeQueueFull, "too many pending utterances, utterance dropped")
+			}
+		}
+	}
+
+	speaking := false
+	for {
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logger.Errorf("WebSocket error: %v", err)
+			}
+			break
+		}
+
+		// 更新会话活动时间
+		h.sessionManager.UpdateActivity(sess.ID)
+
+		switch messageType {
+		case websocket.BinaryMessage:
+			// 用户音频
+			segments := stream.Accept(message)
+			if stream.Speaking() && !speaking {
+				// 用户开始说话，打断正在播放的回复
+				c.send("speech_start", nil, "")
+				c.interrupt("barge_in", true)
+			}
+			speaking = stream.Speaking()
+			enqueue(segments)
+
+		case websocket.TextMessage:
+			// 文本消息（控制消息）
+			msg, err := parseInbound(message)
+			if err != nil {
+				c.sendError(errorCode(err, ErrCodeInvalidMessage), err.Error())
+				continue
+			}
+
+			switch msg.Type {
+			case "flush":
+				// 立即结束当前语句（如按键说话松开按键）
+				speaking = false
+				enqueue(stream.Flush())
+
+			case "interrupt":
+				// 客户端主动打断当前一轮
+				c.interrupt("client", false)
+
+			case "ping":
+				// 心跳响应
+				c.send("pong", nil, "")
+
+			default:
+				c.sendError(ErrCodeUnknownType, fmt.Sprintf("unknown message type: %s", msg.Type))
+			}
+		}
+	}
+}
+
+// send 发送消息
+func (c *voiceConversation) send(msgType string, data interface{}, errMsg string) {
+	c.sess.Send(VoiceMessage{Type: msgType, SessionID: c.sess.ID, Data: data, Error: errMsg})
+}
+
+// sendError 发送error消息
+func (c *voiceConversation) sendError(code, message string) {
+	c.sess.Send(VoiceMessage{Type: "error", SessionID: c.sess.ID, Error: message, Code: code})
+}
+
+// sendTurn 在turn仍是进行中的一轮时发送消息，被打断后不再发送
+func (c *voiceConversation) sendTurn(turn *voiceTurn, msgType string, data map[string]interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.turn != turn {
+		return false
+	}
+	data["turn_id"] = turn.id
+	c.send(msgType, data, "")
+	return true
+}
+
+// interrupt 打断进行中的一轮并发送interrupted事件，playingOnly为true时只打断正在播放的回复
+func (c *voiceConversation) interrupt(reason string, playingOnly bool) {
+	c.mu.Lock()
+	turn := c.turn
+	if turn == nil || (playingOnly && turn.started.IsZero()) {
+		c.mu.Unlock()
+		return
+	}
+	c.turn = nil
+	played := time.Duration(0)
+	if !turn.started.IsZero() {
+		played = time.Since(turn.started)
+		if played > turn.duration {
+			played = turn.duration
+		}
+	}
+	c.send("interrupted", map[string]interface{}{
+		"turn_id":   turn.id,
+		"reason":    reason,
+		"played_ms": played.Milliseconds(),
+	}, "")
+	c.mu.Unlock()
+	turn.cancel()
+}
+
+// respond 识别一句用户话语，获取回复并合成播放
+func (c *voiceConversation) respond(ctx context.Context, audio []byte) {
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.nextID++
+	turn := &voiceTurn{id: c.nextID, cancel: cancel}
+	c.turn = turn
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		if c.turn == turn {
+			c.turn = nil
+		}
+		c.mu.Unlock()
+		cancel()
+	}()
+
+	opts := config.ASROptions{Language: c.opts.Language}
+	result, err := c.h.asrManager.Recognize(ctx, audio, &opts)
+	if err != nil {
+		logger.Errorf("ASR transcription failed: %v", err)
+		c.sendError(ErrCodeProcessingFailed, err.Error())
+		return
+	}
+	text := strings.TrimSpace(result.Text)
+	if text == "" {
+		// 噪声或无法识别的语音
+		return
+	}
+	language := c.opts.Language
+	if result.Lang != "" {
+		language = result.Lang
+	}
+	if !c.sendTurn(turn, "transcript", map[string]interface{}{
+		"text":     text,
+		"language": language,
+		"duration": float64(len(audio)/2) / float64(c.h.vad.SampleRate()),
+	}) {
+		return
+	}
+
+	reply, err := c.h.dialog.Reply(ctx, DialogTurn{SessionID: c.sess.ID, TurnID: turn.id, Text: text, Language: language})
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Errorf("Dialog handler failed: %v", err)
+			c.sendError(ErrCodeProcessingFailed, fmt.Sprintf("dialog failed: %v", err))
+		}
+		return
+	}
+	if reply == nil || strings.TrimSpace(reply.Text) == "" {
+		c.sendTurn(turn, "reply_end", map[string]interface{}{"duration_ms": 0})
+		return
+	}
+	if err := reply.validate(); err != nil {
+		logger.Warnf("Invalid dialog reply: %v", err)
+		c.sendError(ErrCodeProcessingFailed, fmt.Sprintf("invalid dialog reply: %v", err))
+		return
+	}
+	speakerID, speed := c.opts.SpeakerID, c.opts.Speed
+	if reply.SpeakerID != nil {
+		speakerID = *reply.SpeakerID
+	}
+	if reply.Speed != nil {
+		speed = *reply.Speed
+	}
+	if !c.sendTurn(turn, "reply", map[string]interface{}{"text": reply.Text}) {
+		return
+	}
+
+	pcm, err := c.h.tts.Synthesize(ctx, reply.Text, speakerID, speed)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Errorf("TTS synthesis failed: %v", err)
+			c.sendError(ErrCodeProcessingFailed, err.Error())
+		}
+		return
+	}
+	if c.play(ctx, turn, pcm) {
+		c.sendTurn(turn, "reply_end", map[string]interface{}{"duration_ms": turn.duration.Milliseconds()})
+	}
+}
+
+// play 按实时速度分帧发送回复音频并等待客户端播放完毕，被打断时返回false
+func (c *voiceConversation) play(ctx context.Context, turn *voiceTurn, audio []byte) bool {
+	rate := c.h.tts.GetSampleRate()
+	offsetDuration := func(offset int) time.Duration {
+		return time.Duration(offset/2) * time.Second / time.Duration(rate)
+	}
+	frame := int(voiceFrameDuration.Seconds()*float64(rate)) * 2
+
+	c.mu.Lock()
+	if c.turn != turn {
+		c.mu.Unlock()
+		return false
+	}
+	turn.started, turn.duration = time.Now(), offsetDuration(len(audio))
+	c.mu.Unlock()
+
+	for offset := 0; offset < len(audio); offset += frame {
+		if wait := time.Until(turn.started.Add(offsetDuration(offset) - voicePlaybackLead)); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(wait):
+			}
+		}
+		end := offset + frame
+		if end > len(audio) {
+			end = len(audio)
+		}
+		// 持锁发送，保证打断后不再发出音频
+		c.mu.Lock()
+		if c.turn != turn {
+			c.mu.Unlock()
+			return false
+		}
+		err := c.sess.SendBinary(audio[offset:end])
+		c.mu.Unlock()
+		if err != nil {
+			logger.Warnf("Failed to send reply audio: %v", err)
+			return false
+		}
+	}
+
+	// 客户端播放完毕前仍可被打断
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(time.Until(turn.started.Add(turn.duration))):
+		return true
+	}
+}
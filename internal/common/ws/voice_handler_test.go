@@ -0,0 +1,311 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/session"
+)
+
+// mockVoiceDetector 模拟VAD：含非0字节的音频为语音，语音之后的全0音频结束语句
+type mockVoiceDetector struct{}
+
+func (mockVoiceDetector) NewStream(*VADOptions) (VoiceStream, error) { return &mockVoiceStream{}, nil }
+
+func (mockVoiceDetector) SampleRate() int { return 16000 }
+
+type mockVoiceStream struct {
+	speech []byte
+}
+
+func (s *mockVoiceStream) Accept(audio []byte) [][]byte {
+	for _, b := range audio {
+		if b != 0 {
+			s.speech = append(s.speech, audio...)
+			return nil
+		}
+	}
+	return s.Flush()
+}
+
+func (s *mockVoiceStream) Speaking() bool { return len(s.speech) > 0 }
+
+func (s *mockVoiceStream) Flush() [][]byte {
+	if len(s.speech) == 0 {
+		return nil
+	}
+	segment := s.speech
+	s.speech = nil
+	return [][]byte{segment}
+}
+
+func (s *mockVoiceStream) Close() {}
+
+// mockSynthesizer 合成指定时长的16kHz音频
+type mockSynthesizer struct {
+	duration time.Duration
+}
+
+func (m *mockSynthesizer) Synthesize(ctx interface{}, text string, speakerID int, speed float32) ([]byte, error) {
+	return make([]byte, int(m.duration.Seconds()*16000)*2), nil
+}
+
+func (m *mockSynthesizer) GetSampleRate() int { return 16000 }
+
+// recordingDialog 记录收到的话语并回复固定前缀
+type recordingDialog struct {
+	mu    sync.Mutex
+	turns []DialogTurn
+}
+
+func (d *recordingDialog) Reply(ctx context.Context, turn DialogTurn) (*DialogReply, error) {
+	d.mu.Lock()
+	d.turns = append(d.turns, turn)
+	d.mu.Unlock()
+	return &DialogReply{Text: "收到：" + turn.Text}, nil
+}
+
+// startVoiceServer 启动语音对话测试服务并建立连接，返回连接确认消息
+func startVoiceServer(t *testing.T, tts *mockSynthesizer, dialog DialogHandler) (*websocket.Conn, VoiceMessage) {
+	t.Helper()
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cfg := &config.STTConfig{
+			Session:   config.SessionConfig{SendQueueSize: 100},
+			WebSocket: config.WebSocketConfig{ReadTimeout: 30},
+		}
+		asrManager := &mockASRManager{transcribeResult: "打开左侧舱门"}
+		handler := NewVoiceHandler(session.NewManager(100, 30*time.Second), mockVoiceDetector{}, asrManager, tts, dialog, cfg)
+		handler.HandleConnection(conn, VoiceSessionOptions{Language: "zh", Speed: 1.0})
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+server.URL[4:], nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, readVoiceEvent(t, conn)
+}
+
+// readVoiceEvent 读取下一条JSON消息，跳过音频
+func readVoiceEvent(t *testing.T, conn *websocket.Conn) VoiceMessage {
+	t.Helper()
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		if messageType == websocket.BinaryMessage {
+			continue
+		}
+		var msg VoiceMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("Failed to parse message: %v", err)
+		}
+		return msg
+	}
+}
+
+func TestVoiceHandler_Conversation(t *testing.T) {
+	dialog := &recordingDialog{}
+	conn, msg := startVoiceServer(t, &mockSynthesizer{duration: 250 * time.Millisecond}, dialog)
+	if msg.Type != "connection" {
+		t.Fatalf("Expected connection message, got %+v", msg)
+	}
+	cfg := msg.Data.(map[string]interface{})["config"].(map[string]interface{})
+	if cfg["input_sample_rate"] != float64(16000) || cfg["output_sample_rate"] != float64(16000) {
+		t.Errorf("Unexpected connection config: %v", cfg)
+	}
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2, 3, 4})
+	if msg := readVoiceEvent(t, conn); msg.Type != "speech_start" {
+		t.Fatalf("Expected speech_start, got %+v", msg)
+	}
+	conn.WriteMessage(websocket.BinaryMessage, []byte{0, 0, 0, 0})
+
+	msg = readVoiceEvent(t, conn)
+	data, _ := msg.Data.(map[string]interface{})
+	if msg.Type != "transcript" || data["text"] != "打开左侧舱门" || data["turn_id"] != float64(1) || data["language"] != "zh" {
+		t.Fatalf("Expected transcript, got %+v", msg)
+	}
+	msg = readVoiceEvent(t, conn)
+	if data, _ := msg.Data.(map[string]interface{}); msg.Type != "reply" || data["text"] != "收到：打开左侧舱门" {
+		t.Fatalf("Expected reply, got %+v", msg)
+	}
+
+	// 回复音频按帧发送，之后是reply_end
+	received := 0
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		messageType, payload, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		if messageType == websocket.BinaryMessage {
+			received += len(payload)
+			continue
+		}
+		json.Unmarshal(payload, &msg)
+		break
+	}
+	if want := int(0.25*16000) * 2; received != want {
+		t.Errorf("Expected %d bytes of reply audio, got %d", want, received)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); msg.Type != "reply_end" || data["duration_ms"] != float64(250) {
+		t.Errorf("Expected reply_end, got %+v", msg)
+	}
+
+	dialog.mu.Lock()
+	defer dialog.mu.Unlock()
+	if len(dialog.turns) != 1 || dialog.turns[0].TurnID != 1 || dialog.turns[0].SessionID == "" || dialog.turns[0].Language != "zh" {
+		t.Errorf("Unexpected dialog turns: %+v", dialog.turns)
+	}
+}
+
+func TestVoiceHandler_BargeIn(t *testing.T) {
+	conn, _ := startVoiceServer(t, &mockSynthesizer{duration: 5 * time.Second}, &recordingDialog{})
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2})
+	readVoiceEvent(t, conn) // speech_start
+	conn.WriteMessage(websocket.BinaryMessage, []byte{0, 0})
+	readVoiceEvent(t, conn) // transcript
+	if msg := readVoiceEvent(t, conn); msg.Type != "reply" {
+		t.Fatalf("Expected reply, got %+v", msg)
+	}
+
+	// 收到回复音频后用户开始说话
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if messageType, _, err := conn.ReadMessage(); err != nil || messageType != websocket.BinaryMessage {
+		t.Fatalf("Expected reply audio, got %d, %v", messageType, err)
+	}
+	conn.WriteMessage(websocket.BinaryMessage, []byte{5, 6})
+
+	var interrupted VoiceMessage
+	for interrupted.Type == "" {
+		msg := readVoiceEvent(t, conn)
+		switch msg.Type {
+		case "speech_start":
+		case "interrupted":
+			interrupted = msg
+		default:
+			t.Fatalf("Unexpected message before interrupted: %+v", msg)
+		}
+	}
+	data := interrupted.Data.(map[string]interface{})
+	if data["turn_id"] != float64(1) || data["reason"] != "barge_in" || data["played_ms"].(float64) >= 5000 {
+		t.Errorf("Unexpected interrupted event: %+v", interrupted)
+	}
+
+	// 打断后不再发送该轮的音频
+	conn.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	if messageType, payload, err := conn.ReadMessage(); err == nil {
+		t.Errorf("Expected no more messages after interruption, got %d: %q", messageType, payload)
+	}
+}
+
+func TestVoiceHandler_ClientInterrupt(t *testing.T) {
+	dialog := &blockingDialog{release: make(chan struct{})}
+	defer close(dialog.release)
+	conn, _ := startVoiceServer(t, &mockSynthesizer{duration: time.Second}, dialog)
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2})
+	readVoiceEvent(t, conn) // speech_start
+	conn.WriteMessage(websocket.BinaryMessage, []byte{0, 0})
+	readVoiceEvent(t, conn) // transcript
+
+	// 等待回复期间客户端主动打断
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"interrupt"}`))
+	msg := readVoiceEvent(t, conn)
+	data, _ := msg.Data.(map[string]interface{})
+	if msg.Type != "interrupted" || data["reason"] != "client" || data["played_ms"] != float64(0) {
+		t.Errorf("Expected client interruption, got %+v", msg)
+	}
+
+	// flush立即结束当前语句
+	conn.WriteMessage(websocket.BinaryMessage, []byte{3, 4})
+	readVoiceEvent(t, conn) // speech_start
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"flush"}`))
+	msg = readVoiceEvent(t, conn)
+	if data, _ := msg.Data.(map[string]interface{}); msg.Type != "transcript" || data["turn_id"] != float64(2) {
+		t.Errorf("Expected transcript for flushed utterance, got %+v", msg)
+	}
+}
+
+// blockingDialog 等待请求取消或release关闭后才返回
+type blockingDialog struct {
+	release chan struct{}
+}
+
+func (d *blockingDialog) Reply(ctx context.Context, turn DialogTurn) (*DialogReply, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-d.release:
+		return &DialogReply{}, nil
+	}
+}
+
+// invalidReplyDialog 回复超出范围的语速
+type invalidReplyDialog struct{}
+
+func (invalidReplyDialog) Reply(ctx context.Context, turn DialogTurn) (*DialogReply, error) {
+	speed := float32(50)
+	return &DialogReply{Text: "好的", Speed: &speed}, nil
+}
+
+func TestVoiceHandler_InvalidDialogReply(t *testing.T) {
+	conn, _ := startVoiceServer(t, &mockSynthesizer{duration: time.Second}, invalidReplyDialog{})
+
+	conn.WriteMessage(websocket.BinaryMessage, []byte{1, 2})
+	readVoiceEvent(t, conn) // speech_start
+	conn.WriteMessage(websocket.BinaryMessage, []byte{0, 0})
+	readVoiceEvent(t, conn) // transcript
+
+	// 不合成超出范围的回复，返回error消息
+	if msg := readVoiceEvent(t, conn); msg.Type != "error" || msg.Code != ErrCodeProcessingFailed {
+		t.Errorf("Expected error for invalid dialog reply, got %+v", msg)
+	}
+}
+
+func TestVoiceHandler_InvalidMessage(t *testing.T) {
+	conn, _ := startVoiceServer(t, &mockSynthesizer{duration: time.Second}, &recordingDialog{})
+
+	for _, tc := range []struct {
+		message string
+		code    string
+	}{
+		{"not json", ErrCodeInvalidMessage},
+		{`{"data": {}}`, ErrCodeInvalidMessage},
+		{`{"type": "dance"}`, ErrCodeUnknownType},
+	} {
+		conn.WriteMessage(websocket.TextMessage, []byte(tc.message))
+		if msg := readVoiceEvent(t, conn); msg.Type != "error" || msg.Code != tc.code {
+			t.Errorf("Message %s: expected %s error, got %+v", tc.message, tc.code, msg)
+		}
+	}
+
+	// 连接保持可用
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type": "ping"}`))
+	if msg := readVoiceEvent(t, conn); msg.Type != "pong" {
+		t.Errorf("Expected pong, got %+v", msg)
+	}
+}
@@ -0,0 +1,89 @@
+package enhance
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Denoiser 基于sherpa-onnx GTCRN模型的语音降噪
+type Denoiser struct {
+	slots      chan *sherpa.OfflineSpeechDenoiser
+	sampleRate int // 模型输入采样率
+}
+
+// NewDenoiser 创建语音降噪器，按pool_size创建多个实例以支持并发
+func NewDenoiser(cfg *config.EnhancementConfig) (*Denoiser, error) {
+	if _, err := os.Stat(cfg.ModelPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("enhancement model file not found: %s", cfg.ModelPath)
+	}
+
+	size := cfg.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	denoiser := &Denoiser{slots: make(chan *sherpa.OfflineSpeechDenoiser, size)}
+	for i := 0; i < size; i++ {
+		sd := sherpa.NewOfflineSpeechDenoiser(&sherpa.OfflineSpeechDenoiserConfig{
+			Model: sherpa.OfflineSpeechDenoiserModelConfig{
+				Gtcrn:      sherpa.OfflineSpeechDenoiserGtcrnModelConfig{Model: cfg.ModelPath},
+				NumThreads: int32(cfg.Provider.NumThreads),
+				Provider:   config.GetProvider(&cfg.Provider),
+			},
+		})
+		if sd == nil {
+			denoiser.Close()
+			return nil, fmt.Errorf("failed to create speech denoiser")
+		}
+		denoiser.sampleRate = sd.SampleRate()
+		denoiser.slots <- sd
+	}
+	return denoiser, nil
+}
+
+// Enhance 对PCM16单声道音频降噪，返回与输入采样率相同的PCM16音频
+func (d *Denoiser) Enhance(ctx context.Context, audio []byte, sampleRate int) ([]byte, error) {
+	samples := utils.SamplesInt16ToFloat(audio)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("invalid or empty audio data")
+	}
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+	samples = utils.ResampleAudio(samples, sampleRate, d.sampleRate)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("audio is too short")
+	}
+
+	var sd *sherpa.OfflineSpeechDenoiser
+	select {
+	case sd = <-d.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	denoised := sd.Run(samples, d.sampleRate)
+	d.slots <- sd
+
+	if denoised == nil || len(denoised.Samples) == 0 {
+		return nil, fmt.Errorf("speech denoiser returned no audio")
+	}
+	return utils.SamplesFloatToInt16(utils.ResampleAudio(denoised.Samples, denoised.SampleRate, sampleRate)), nil
+}
+
+// Close 释放全部实例
+func (d *Denoiser) Close() error {
+	for {
+		select {
+		case sd := <-d.slots:
+			sherpa.DeleteOfflineSpeechDenoiser(sd)
+		default:
+			return nil
+		}
+	}
+}
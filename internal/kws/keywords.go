@@ -0,0 +1,129 @@
+package kws
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// keywordInfo 关键词生效的加分和触发阈值
+type keywordInfo struct {
+	score     float32
+	threshold float32
+}
+
+// keywordTable 按检测结果中的关键词文本查找加分和阈值
+type keywordTable map[string]keywordInfo
+
+// parseKeyword 解析sherpa-onnx格式的关键词，如 "x iǎo ài :2.0 #0.6 @小爱同学"：
+// 返回检测结果中的关键词文本（@后的显示文本，没有时为分词序列）及其加分和阈值（未指定时使用默认值）
+func parseKeyword(spec string, score, threshold float32) (string, keywordInfo) {
+	info := keywordInfo{score: score, threshold: threshold}
+	display := ""
+	if at := strings.Index(spec, "@"); at >= 0 {
+		// @之后到行尾为显示文本
+		spec, display = spec[:at], strings.TrimSpace(spec[at+1:])
+	}
+
+	var tokens []string
+	for _, field := range strings.Fields(spec) {
+		switch field[0] {
+		case ':':
+			if v, err := strconv.ParseFloat(field[1:], 32); err == nil {
+				info.score = float32(v)
+			}
+		case '#':
+			if v, err := strconv.ParseFloat(field[1:], 32); err == nil {
+				info.threshold = float32(v)
+			}
+		default:
+			tokens = append(tokens, field)
+		}
+	}
+	if display == "" {
+		display = strings.Join(tokens, " ")
+	}
+	return display, info
+}
+
+// keywordTokens 关键词的分词序列（去除:加分、#阈值和@显示文本）
+func keywordTokens(spec string) []string {
+	if at := strings.Index(spec, "@"); at >= 0 {
+		spec = spec[:at]
+	}
+	var tokens []string
+	for _, field := range strings.Fields(spec) {
+		if field[0] != ':' && field[0] != '#' {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// tokenSet 模型词表（tokens.txt）中的分词
+type tokenSet map[string]bool
+
+// readTokens 读取模型词表，每行为 "分词 编号"
+func readTokens(path string) (tokenSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tokens := tokenSet{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			tokens[fields[0]] = true
+		}
+	}
+	return tokens, nil
+}
+
+// validate 检查关键词的分词都在模型词表中（sherpa-onnx遇到词表外的分词时无法创建检测流）
+func (t tokenSet) validate(keywords []string) error {
+	for _, spec := range keywords {
+		tokens := keywordTokens(spec)
+		if len(tokens) == 0 {
+			return fmt.Errorf("invalid keyword %q: no tokens", spec)
+		}
+		for _, token := range tokens {
+			if !t[token] {
+				return fmt.Errorf("invalid keyword %q: token %q is not in the model's tokens.txt", spec, token)
+			}
+		}
+	}
+	return nil
+}
+
+// add 登记关键词
+func (t keywordTable) add(keywords []string, score, threshold float32) {
+	for _, spec := range keywords {
+		if key, info := parseKeyword(spec, score, threshold); key != "" {
+			t[key] = info
+		}
+	}
+}
+
+// clone 复制关键词表
+func (t keywordTable) clone() keywordTable {
+	out := make(keywordTable, len(t))
+	for k, v := range t {
+		out[k] = v
+	}
+	return out
+}
+
+// readKeywordsFile 读取关键词文件（每行一个关键词，忽略空行）
+func readKeywordsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keywords []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			keywords = append(keywords, line)
+		}
+	}
+	return keywords, nil
+}
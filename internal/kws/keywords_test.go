@@ -0,0 +1,72 @@
+package kws
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyword(t *testing.T) {
+	tests := []struct {
+		spec string
+		key  string
+		info keywordInfo
+	}{
+		{"n ǐ h ǎo @你好", "你好", keywordInfo{1.0, 0.25}},
+		{"x iǎo ài :2.0 #0.6 @小爱同学", "小爱同学", keywordInfo{2.0, 0.6}},
+		{"▁HE LL O ▁WORLD #0.3", "▁HE LL O ▁WORLD", keywordInfo{1.0, 0.3}},
+		{"▁HI @hi there", "hi there", keywordInfo{1.0, 0.25}},
+	}
+	for _, tt := range tests {
+		key, info := parseKeyword(tt.spec, 1.0, 0.25)
+		if key != tt.key || info != tt.info {
+			t.Errorf("parseKeyword(%q) = %q, %+v, want %q, %+v", tt.spec, key, info, tt.key, tt.info)
+		}
+	}
+}
+
+func TestKeywordTable(t *testing.T) {
+	global := keywordTable{}
+	global.add([]string{"n ǐ h ǎo @你好"}, 1.0, 0.25)
+
+	session := global.clone()
+	session.add([]string{"k āi d ēng :1.5 @开灯"}, 1.0, 0.25)
+	if _, ok := global["开灯"]; ok {
+		t.Error("Session keywords should not leak into global table")
+	}
+	if session["开灯"].score != 1.5 || session["你好"].threshold != 0.25 {
+		t.Errorf("Unexpected session table: %+v", session)
+	}
+}
+
+func TestReadKeywordsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keywords.txt")
+	os.WriteFile(path, []byte("n ǐ h ǎo @你好\n\n  k āi d ēng @开灯  \n"), 0644)
+
+	keywords, err := readKeywordsFile(path)
+	if err != nil {
+		t.Fatalf("readKeywordsFile() error = %v", err)
+	}
+	if want := []string{"n ǐ h ǎo @你好", "k āi d ēng @开灯"}; !reflect.DeepEqual(keywords, want) {
+		t.Errorf("readKeywordsFile() = %v, want %v", keywords, want)
+	}
+}
+
+func TestTokenSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.txt")
+	os.WriteFile(path, []byte("<blk> 0\nn 1\nǐ 2\nh 3\nǎo 4\n"), 0644)
+
+	tokens, err := readTokens(path)
+	if err != nil {
+		t.Fatalf("readTokens() error = %v", err)
+	}
+	if err := tokens.validate([]string{"n ǐ h ǎo :2.0 #0.6 @你好"}); err != nil {
+		t.Errorf("validate() error = %v", err)
+	}
+	for _, spec := range []string{"k āi d ēng @开灯", ":2.0 @空"} {
+		if err := tokens.validate([]string{spec}); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package kws
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// modelSampleRate 关键词检测模型的输入采样率
+const modelSampleRate = 16000
+
+// decodeStep 逐段解码的样本数（0.1秒）。sherpa-onnx-go的检测结果不含时间戳，
+// 按该粒度输入音频并解码，触发位置精确到0.1秒
+const decodeStep = modelSampleRate / 10
+
+// Detection 一次关键词触发
+type Detection struct {
+	Keyword   string  // 关键词（显示文本）
+	Offset    float64 // 触发位置：检测到关键词时已解码音频的时长（秒，精度0.1秒）
+	Boost     float32 // 关键词生效的加分（配置值，sherpa-onnx不提供检测置信度）
+	Threshold float32 // 关键词生效的触发阈值
+}
+
+// instance 单个关键词检测器，同一时间只允许一个会话解码
+type instance struct {
+	mu      sync.Mutex
+	spotter *sherpa.KeywordSpotter
+}
+
+// Spotter 基于sherpa-onnx的流式关键词检测，按pool_size创建多个检测器，会话按轮转分配
+type Spotter struct {
+	instances []*instance
+	next      uint32
+	keywords  keywordTable // 全局关键词
+	global    []string
+	tokens    tokenSet // 模型词表，用于校验会话关键词
+	score     float32  // 默认关键词加分
+	threshold float32  // 默认触发阈值
+}
+
+// NewSpotter 创建关键词检测器
+func NewSpotter(cfg *config.KWSConfig) (*Spotter, error) {
+	keywords := append([]string(nil), cfg.Keywords...)
+	if cfg.KeywordsFile != "" {
+		fromFile, err := readKeywordsFile(cfg.KeywordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keywords file: %w", err)
+		}
+		keywords = append(fromFile, keywords...)
+	}
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("at least one keyword is required")
+	}
+	if err := config.ValidateKeywords(keywords); err != nil {
+		return nil, err
+	}
+	tokens, err := readTokens(cfg.TokensPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+	if err := tokens.validate(keywords); err != nil {
+		return nil, err
+	}
+
+	size := cfg.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	buf := strings.Join(keywords, "\n")
+	spotterConfig := sherpa.KeywordSpotterConfig{
+		FeatConfig: sherpa.FeatureConfig{SampleRate: modelSampleRate, FeatureDim: 80},
+		ModelConfig: sherpa.OnlineModelConfig{
+			Transducer: sherpa.OnlineTransducerModelConfig{
+				Encoder: cfg.EncoderPath,
+				Decoder: cfg.DecoderPath,
+				Joiner:  cfg.JoinerPath,
+			},
+			Tokens:     cfg.TokensPath,
+			NumThreads: cfg.Provider.NumThreads,
+			Provider:   config.GetProvider(&cfg.Provider),
+		},
+		MaxActivePaths:    cfg.MaxActivePaths,
+		KeywordsScore:     cfg.KeywordsScore,
+		KeywordsThreshold: cfg.KeywordsThreshold,
+		KeywordsBuf:       buf,
+		KeywordsBufSize:   len(buf),
+	}
+
+	s := &Spotter{keywords: keywordTable{}, global: keywords, tokens: tokens}
+	s.keywords.add(keywords, cfg.KeywordsScore, cfg.KeywordsThreshold)
+	for i := 0; i < size; i++ {
+		spotter := sherpa.NewKeywordSpotter(&spotterConfig)
+		if spotter == nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to create keyword spotter")
+		}
+		s.instances = append(s.instances, &instance{spotter: spotter})
+	}
+	s.score, s.threshold = cfg.KeywordsScore, cfg.KeywordsThreshold
+	return s, nil
+}
+
+// Keywords 全局关键词
+func (s *Spotter) Keywords() []string {
+	return s.global
+}
+
+// SampleRate 输入音频采样率
+func (s *Spotter) SampleRate() int {
+	return modelSampleRate
+}
+
+// NewStream 创建检测流，keywords为在全局关键词之外追加的关键词
+func (s *Spotter) NewStream(keywords []string) (*Stream, error) {
+	if err := config.ValidateKeywords(keywords); err != nil {
+		return nil, err
+	}
+	if err := s.tokens.validate(keywords); err != nil {
+		return nil, err
+	}
+	inst := s.instances[int(atomic.AddUint32(&s.next, 1)-1)%len(s.instances)]
+
+	inst.mu.Lock()
+	var stream *sherpa.OnlineStream
+	if len(keywords) > 0 {
+		stream = sherpa.NewKeywordStreamWithKeywords(inst.spotter, strings.Join(keywords, "/"))
+	} else {
+		stream = sherpa.NewKeywordStream(inst.spotter)
+	}
+	inst.mu.Unlock()
+
+	table := s.keywords
+	if len(keywords) > 0 {
+		table = s.keywords.clone()
+		table.add(keywords, s.score, s.threshold)
+	}
+	return &Stream{
+		inst:     inst,
+		stream:   stream,
+		keywords: table,
+		defaults: keywordInfo{score: s.score, threshold: s.threshold},
+	}, nil
+}
+
+// Close 释放全部检测器
+func (s *Spotter) Close() error {
+	for _, inst := range s.instances {
+		sherpa.DeleteKeywordSpotter(inst.spotter)
+	}
+	s.instances = nil
+	return nil
+}
+
+// Stream 单个会话的关键词检测流
+type Stream struct {
+	inst     *instance
+	stream   *sherpa.OnlineStream
+	keywords keywordTable
+	defaults keywordInfo // 关键词表中找不到时使用的默认加分和阈值
+	samples  int64       // 已输入的样本数
+}
+
+// Accept 输入PCM16单声道16kHz音频，返回本段音频中触发的关键词
+func (st *Stream) Accept(audio []byte) []Detection {
+	samples := utils.SamplesInt16ToFloat(audio)
+	if len(samples) == 0 {
+		return nil
+	}
+
+	st.inst.mu.Lock()
+	defer st.inst.mu.Unlock()
+
+	var detections []Detection
+	for len(samples) > 0 {
+		n := decodeStep
+		if n > len(samples) {
+			n = len(samples)
+		}
+		st.stream.AcceptWaveform(modelSampleRate, samples[:n])
+		st.samples += int64(n)
+		samples = samples[n:]
+		detections = st.decode(detections)
+	}
+	return detections
+}
+
+// decode 解码已输入的音频，将触发的关键词追加到detections，调用方持有检测器锁
+func (st *Stream) decode(detections []Detection) []Detection {
+	for st.inst.spotter.IsReady(st.stream) {
+		st.inst.spotter.Decode(st.stream)
+		result := st.inst.spotter.GetResult(st.stream)
+		if result.Keyword == "" {
+			continue
+		}
+		// 检测到关键词后必须立即重置
+		st.inst.spotter.Reset(st.stream)
+		info, ok := st.keywords[result.Keyword]
+		if !ok {
+			info = st.defaults
+		}
+		detections = append(detections, Detection{
+			Keyword:   result.Keyword,
+			Offset:    float64(st.samples) / modelSampleRate,
+			Boost:     info.score,
+			Threshold: info.threshold,
+		})
+	}
+	return detections
+}
+
+// Close 释放检测流
+func (st *Stream) Close() {
+	if st.stream != nil {
+		sherpa.DeleteOnlineStream(st.stream)
+		st.stream = nil
+	}
+}
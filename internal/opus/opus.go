@@ -0,0 +1,72 @@
+package opus
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"layeh.com/gopus"
+)
+
+// maxPacketDuration Opus包的最大时长（毫秒），决定解码缓冲区大小
+const maxPacketDuration = 120
+
+// maxPacketSize 单个Opus包的最大字节数
+const maxPacketSize = 4000
+
+// Decoder 单个音频流的Opus解码器（基于随包编译的libopus，无需系统库）
+type Decoder struct {
+	dec        *gopus.Decoder
+	sampleRate int
+	channels   int
+}
+
+// NewDecoder 创建解码器，输出指定采样率和声道数的音频，
+// 声道数与码流不同时由解码器上混或下混
+func NewDecoder(sampleRate, channels int) (*Decoder, error) {
+	dec, err := gopus.NewDecoder(sampleRate, channels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create opus decoder: %v", err)
+	}
+	return &Decoder{dec: dec, sampleRate: sampleRate, channels: channels}, nil
+}
+
+// Decode 解码一个Opus包，返回PCM16交错音频
+func (d *Decoder) Decode(packet []byte) ([]byte, error) {
+	if len(packet) == 0 {
+		return nil, fmt.Errorf("empty packet")
+	}
+	samples, err := d.dec.Decode(packet, d.sampleRate*maxPacketDuration/1000, false)
+	if err != nil {
+		return nil, err
+	}
+	pcm := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(s))
+	}
+	return pcm, nil
+}
+
+// Encoder 单个音频流的Opus编码器，使用语音（VoIP）模式
+type Encoder struct {
+	enc      *gopus.Encoder
+	channels int
+}
+
+// NewEncoder 创建编码器，bitrate为目标码率（bps）
+func NewEncoder(sampleRate, channels, bitrate int) (*Encoder, error) {
+	enc, err := gopus.NewEncoder(sampleRate, channels, gopus.Voip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create opus encoder: %v", err)
+	}
+	enc.SetBitrate(bitrate)
+	return &Encoder{enc: enc, channels: channels}, nil
+}
+
+// Encode 将一帧PCM16交错音频编码为一个Opus包，帧长须为2.5、5、10、20、40或60毫秒
+func (e *Encoder) Encode(pcm []byte) ([]byte, error) {
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[i*2:]))
+	}
+	return e.enc.Encode(samples, len(samples)/e.channels, maxPacketSize)
+}
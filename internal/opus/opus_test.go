@@ -0,0 +1,59 @@
+package opus
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	encoder, err := NewEncoder(16000, 1, 24000)
+	if err != nil {
+		t.Fatalf("Failed to create encoder: %v", err)
+	}
+	decoder, err := NewDecoder(16000, 1)
+	if err != nil {
+		t.Fatalf("Failed to create decoder: %v", err)
+	}
+
+	// 1秒440Hz正弦波，按20ms分帧编码
+	frame := make([]byte, 320*2)
+	var encoded, decoded int
+	var energy float64
+	for n := 0; n < 50; n++ {
+		for i := 0; i < 320; i++ {
+			s := 0.5 * math.Sin(2*math.Pi*440*float64(n*320+i)/16000)
+			binary.LittleEndian.PutUint16(frame[i*2:], uint16(int16(s*32767)))
+		}
+		packet, err := encoder.Encode(frame)
+		if err != nil {
+			t.Fatalf("Failed to encode frame %d: %v", n, err)
+		}
+		encoded += len(packet)
+
+		pcm, err := decoder.Decode(packet)
+		if err != nil {
+			t.Fatalf("Failed to decode frame %d: %v", n, err)
+		}
+		if len(pcm) != len(frame) {
+			t.Fatalf("Expected %d bytes per frame, got %d", len(frame), len(pcm))
+		}
+		decoded += len(pcm)
+		for i := 0; i < len(pcm); i += 2 {
+			s := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) / 32768
+			energy += s * s
+		}
+	}
+
+	// 24kbps约为16kHz PCM16（256kbps）的十分之一
+	if encoded*8 > decoded {
+		t.Errorf("Expected encoded size under 1/8 of PCM, got %d of %d bytes", encoded, decoded)
+	}
+	if rms := math.Sqrt(energy / float64(decoded/2)); rms < 0.2 {
+		t.Errorf("Expected decoded signal near the input level, got rms %.3f", rms)
+	}
+
+	if _, err := decoder.Decode(nil); err == nil {
+		t.Error("Expected error for empty packet")
+	}
+}
@@ -0,0 +1,133 @@
+package speaker
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// modelSampleRate 说话人向量模型的输入采样率
+const modelSampleRate = 16000
+
+// Segment 说话人分离结果中的一段，时间单位为秒
+type Segment struct {
+	Start   float64
+	End     float64
+	Speaker int
+}
+
+// Diarizer 基于sherpa-onnx的离线说话人分离（pyannote分割 + 说话人向量聚类）
+type Diarizer struct {
+	slots chan *sherpa.OfflineSpeakerDiarization
+	cfg   sherpa.OfflineSpeakerDiarizationConfig
+}
+
+// NewDiarizer 创建说话人分离器，按pool_size创建多个实例以支持并发
+func NewDiarizer(cfg *config.SpeakerConfig) (*Diarizer, error) {
+	d := &cfg.Diarization
+	for _, path := range []string{d.SegmentationModel, cfg.EmbeddingModel} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("speaker model file not found: %s", path)
+		}
+	}
+
+	size := d.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	provider := config.GetProvider(&cfg.Provider)
+	diarizer := &Diarizer{
+		slots: make(chan *sherpa.OfflineSpeakerDiarization, size),
+		cfg: sherpa.OfflineSpeakerDiarizationConfig{
+			Segmentation: sherpa.OfflineSpeakerSegmentationModelConfig{
+				Pyannote:   sherpa.OfflineSpeakerSegmentationPyannoteModelConfig{Model: d.SegmentationModel},
+				NumThreads: cfg.Provider.NumThreads,
+				Provider:   provider,
+			},
+			Embedding: sherpa.SpeakerEmbeddingExtractorConfig{
+				Model:      cfg.EmbeddingModel,
+				NumThreads: cfg.Provider.NumThreads,
+				Provider:   provider,
+			},
+			Clustering: sherpa.FastClusteringConfig{
+				NumClusters: d.NumSpeakers,
+				Threshold:   d.Threshold,
+			},
+			MinDurationOn:  d.MinDurationOn,
+			MinDurationOff: d.MinDurationOff,
+		},
+	}
+	if diarizer.cfg.Clustering.NumClusters <= 0 {
+		diarizer.cfg.Clustering.NumClusters = -1
+	}
+
+	for i := 0; i < size; i++ {
+		sd := sherpa.NewOfflineSpeakerDiarization(&diarizer.cfg)
+		if sd == nil {
+			diarizer.Close()
+			return nil, fmt.Errorf("failed to create offline speaker diarization")
+		}
+		diarizer.slots <- sd
+	}
+	return diarizer, nil
+}
+
+// Diarize 对PCM16单声道音频做说话人分离，返回按开始时间排序的语音段
+// numSpeakers<=0时按threshold聚类（threshold<=0使用配置值）
+func (d *Diarizer) Diarize(ctx context.Context, audio []byte, sampleRate, numSpeakers int, threshold float32) ([]Segment, error) {
+	samples := utils.SamplesInt16ToFloat(audio)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("invalid or empty audio data")
+	}
+
+	var sd *sherpa.OfflineSpeakerDiarization
+	select {
+	case sd = <-d.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { d.slots <- sd }()
+
+	if rate := sd.SampleRate(); sampleRate > 0 && sampleRate != rate {
+		samples = utils.ResampleAudio(samples, sampleRate, rate)
+	}
+
+	clustering := d.cfg.Clustering
+	if numSpeakers > 0 {
+		clustering.NumClusters = numSpeakers
+	} else if threshold > 0 {
+		clustering.NumClusters = -1
+		clustering.Threshold = threshold
+	}
+	// SetConfig仅更新聚类参数，每次调用前重置，避免上一个请求的参数残留
+	sd.SetConfig(&sherpa.OfflineSpeakerDiarizationConfig{Clustering: clustering})
+
+	result := sd.Process(samples)
+	segments := make([]Segment, 0, len(result))
+	for _, r := range result {
+		segments = append(segments, Segment{
+			Start:   float64(r.Start),
+			End:     float64(r.End),
+			Speaker: r.Speaker,
+		})
+	}
+	return segments, nil
+}
+
+// Close 释放全部实例
+func (d *Diarizer) Close() error {
+	for {
+		select {
+		case sd := <-d.slots:
+			sherpa.DeleteOfflineSpeakerDiarization(sd)
+		default:
+			return nil
+		}
+	}
+}
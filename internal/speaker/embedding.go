@@ -0,0 +1,88 @@
+package speaker
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Extractor 基于sherpa-onnx的说话人向量提取
+type Extractor struct {
+	slots chan *sherpa.SpeakerEmbeddingExtractor
+	dim   int
+}
+
+// NewExtractor 创建说话人向量提取器，按verification.pool_size创建多个实例以支持并发
+func NewExtractor(cfg *config.SpeakerConfig) (*Extractor, error) {
+	if _, err := os.Stat(cfg.EmbeddingModel); os.IsNotExist(err) {
+		return nil, fmt.Errorf("speaker model file not found: %s", cfg.EmbeddingModel)
+	}
+
+	size := cfg.Verification.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	extractor := &Extractor{slots: make(chan *sherpa.SpeakerEmbeddingExtractor, size)}
+	for i := 0; i < size; i++ {
+		ex := sherpa.NewSpeakerEmbeddingExtractor(&sherpa.SpeakerEmbeddingExtractorConfig{
+			Model:      cfg.EmbeddingModel,
+			NumThreads: cfg.Provider.NumThreads,
+			Provider:   config.GetProvider(&cfg.Provider),
+		})
+		if ex == nil {
+			extractor.Close()
+			return nil, fmt.Errorf("failed to create speaker embedding extractor")
+		}
+		extractor.dim = ex.Dim()
+		extractor.slots <- ex
+	}
+	return extractor, nil
+}
+
+// Dim 说话人向量维度
+func (e *Extractor) Dim() int {
+	return e.dim
+}
+
+// Embed 提取PCM16单声道音频（16kHz）的说话人向量
+func (e *Extractor) Embed(ctx context.Context, audio []byte) ([]float32, error) {
+	samples := utils.SamplesInt16ToFloat(audio)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("invalid or empty audio data")
+	}
+
+	var ex *sherpa.SpeakerEmbeddingExtractor
+	select {
+	case ex = <-e.slots:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { e.slots <- ex }()
+
+	stream := ex.CreateStream()
+	defer sherpa.DeleteOnlineStream(stream)
+	stream.AcceptWaveform(modelSampleRate, samples)
+	stream.InputFinished()
+	if !ex.IsReady(stream) {
+		return nil, fmt.Errorf("audio is too short to extract speaker embedding")
+	}
+	return ex.Compute(stream), nil
+}
+
+// Close 释放全部实例
+func (e *Extractor) Close() error {
+	for {
+		select {
+		case ex := <-e.slots:
+			sherpa.DeleteSpeakerEmbeddingExtractor(ex)
+		default:
+			return nil
+		}
+	}
+}
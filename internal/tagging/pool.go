@@ -0,0 +1,241 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/logger"
+)
+
+// Pool 音频标注资源池
+type Pool struct {
+	providers chan Provider
+	config    *config.AudioTaggingConfig
+	size      int
+	mu        sync.RWMutex
+	stats     *PoolStats
+	ctx       context.Context
+	cancel    context.CancelFunc
+	factory   providerFactory
+}
+
+// providerFactory 创建Provider的函数（测试时可替换）
+type providerFactory func(cfg *config.AudioTaggingConfig) (Provider, error)
+
+// defaultProviderFactory 创建sherpa-onnx Provider
+func defaultProviderFactory(cfg *config.AudioTaggingConfig) (Provider, error) {
+	provider, err := NewTaggingProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// PoolStats 资源池统计信息
+type PoolStats struct {
+	TotalCreated   int
+	TotalDestroyed int
+	CurrentActive  int
+	MaxWaitTime    time.Duration
+	TotalWaits     int64
+	mu             sync.RWMutex
+}
+
+// NewPool 创建音频标注资源池
+func NewPool(cfg *config.AudioTaggingConfig, size int) (*Pool, error) {
+	return newPool(cfg, size, defaultProviderFactory)
+}
+
+// newPool 使用指定的Provider工厂创建资源池
+func newPool(cfg *config.AudioTaggingConfig, size int, factory providerFactory) (*Pool, error) {
+	if size <= 0 {
+		size = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pool := &Pool{
+		providers: make(chan Provider, size),
+		config:    cfg,
+		size:      size,
+		stats:     &PoolStats{},
+		ctx:       ctx,
+		cancel:    cancel,
+		factory:   factory,
+	}
+
+	// 并行初始化Provider
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successCount := 0
+
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			provider, err := factory(cfg)
+			if err != nil {
+				logger.Warnf("Failed to create audio tagging provider %d: %v", index, err)
+				return
+			}
+
+			// 预热Provider
+			if err := provider.Warmup(); err != nil {
+				logger.Warnf("Failed to warmup audio tagging provider %d: %v", index, err)
+				provider.Release()
+				return
+			}
+
+			pool.providers <- provider
+			pool.stats.mu.Lock()
+			pool.stats.TotalCreated++
+			pool.stats.CurrentActive++
+			pool.stats.mu.Unlock()
+
+			mu.Lock()
+			successCount++
+			mu.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+
+	if successCount == 0 {
+		cancel()
+		return nil, fmt.Errorf("failed to create any audio tagging provider")
+	}
+
+	logger.Infof("Audio tagging pool initialized with %d/%d providers", successCount, size)
+
+	return pool, nil
+}
+
+// Get 从资源池获取Provider
+func (p *Pool) Get(ctx context.Context) (Provider, error) {
+	startTime := time.Now()
+
+	select {
+	case provider, ok := <-p.providers:
+		if !ok {
+			return nil, fmt.Errorf("pool is closed")
+		}
+		waitTime := time.Since(startTime)
+		p.stats.mu.Lock()
+		if waitTime > p.stats.MaxWaitTime {
+			p.stats.MaxWaitTime = waitTime
+		}
+		p.stats.TotalWaits++
+		p.stats.CurrentActive--
+		p.stats.mu.Unlock()
+		return provider, nil
+
+	case <-time.After(100 * time.Millisecond):
+		// 超时，尝试创建临时Provider
+		logger.Warn("Audio tagging pool timeout, creating temporary provider")
+		provider, err := p.createTemporaryProvider()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary provider: %w", err)
+		}
+		return provider, nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case <-p.ctx.Done():
+		return nil, fmt.Errorf("pool is closed")
+	}
+}
+
+// createTemporaryProvider 创建临时Provider
+func (p *Pool) createTemporaryProvider() (Provider, error) {
+	provider, err := p.factory(p.config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := provider.Warmup(); err != nil {
+		provider.Release()
+		return nil, err
+	}
+
+	p.stats.mu.Lock()
+	p.stats.TotalCreated++
+	p.stats.mu.Unlock()
+
+	logger.Info("Created temporary audio tagging provider")
+	return provider, nil
+}
+
+// Put 归还Provider到资源池
+func (p *Pool) Put(provider Provider) {
+	if provider == nil {
+		return
+	}
+
+	select {
+	case p.providers <- provider:
+		p.stats.mu.Lock()
+		p.stats.CurrentActive++
+		p.stats.mu.Unlock()
+	default:
+		// 池已满，释放Provider
+		provider.Release()
+		p.stats.mu.Lock()
+		p.stats.TotalDestroyed++
+		p.stats.mu.Unlock()
+	}
+}
+
+// Available 获取池中空闲的Provider数量
+func (p *Pool) Available() int {
+	return len(p.providers)
+}
+
+// GetUsage 获取资源池使用率
+func (p *Pool) GetUsage() float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.size == 0 {
+		return 0
+	}
+	return float64(len(p.providers)) / float64(p.size)
+}
+
+// GetStats 获取资源池统计信息
+func (p *Pool) GetStats() map[string]interface{} {
+	p.stats.mu.RLock()
+	defer p.stats.mu.RUnlock()
+
+	return map[string]interface{}{
+		"size":            p.size,
+		"available":       len(p.providers),
+		"total_created":   p.stats.TotalCreated,
+		"total_destroyed": p.stats.TotalDestroyed,
+		"current_active":  p.stats.CurrentActive,
+		"max_wait_time":   p.stats.MaxWaitTime.String(),
+		"total_waits":     p.stats.TotalWaits,
+		"usage":           p.GetUsage(),
+	}
+}
+
+// Close 关闭资源池
+func (p *Pool) Close() error {
+	p.cancel()
+
+	close(p.providers)
+
+	for provider := range p.providers {
+		provider.Release()
+		p.stats.mu.Lock()
+		p.stats.TotalDestroyed++
+		p.stats.mu.Unlock()
+	}
+
+	logger.Info("Audio tagging pool closed")
+	return nil
+}
@@ -0,0 +1,90 @@
+package tagging
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// Provider 音频标注Provider接口
+type Provider interface {
+	Tag(samples []float32, topK int) ([]config.AudioLabel, error)
+	Warmup() error
+	Release() error
+}
+
+// modelSampleRate 音频标注模型的输入采样率
+const modelSampleRate = 16000
+
+// TaggingProvider sherpa-onnx音频标注Provider实现
+type TaggingProvider struct {
+	tagging *sherpa.AudioTagging
+}
+
+// NewTaggingProvider 创建音频标注Provider
+func NewTaggingProvider(cfg *config.AudioTaggingConfig) (*TaggingProvider, error) {
+	for _, path := range []string{cfg.ModelPath, cfg.LabelsPath} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("audio tagging model file not found: %s", path)
+		}
+	}
+
+	// sherpa_onnx包未导出模型配置的类型别名，逐字段赋值
+	var taggingConfig sherpa.AudioTaggingConfig
+	taggingConfig.Model.NumThreads = int32(cfg.Provider.NumThreads)
+	taggingConfig.Model.Provider = config.GetProvider(&cfg.Provider)
+	taggingConfig.Labels = cfg.LabelsPath
+	taggingConfig.TopK = int32(cfg.TopK)
+	switch cfg.ModelType {
+	case "zipformer":
+		taggingConfig.Model.Zipformer.Model = cfg.ModelPath
+	default:
+		taggingConfig.Model.Ced = cfg.ModelPath
+	}
+
+	tagging := sherpa.NewAudioTagging(&taggingConfig)
+	if tagging == nil {
+		return nil, fmt.Errorf("failed to create audio tagging (model_type: %s)", cfg.ModelType)
+	}
+	return &TaggingProvider{tagging: tagging}, nil
+}
+
+// Tag 标注16kHz单声道音频，返回得分最高的topK个标签
+func (p *TaggingProvider) Tag(samples []float32, topK int) ([]config.AudioLabel, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("invalid or empty audio data")
+	}
+
+	stream := sherpa.NewAudioTaggingStream(p.tagging)
+	if stream == nil {
+		return nil, fmt.Errorf("failed to create audio tagging stream")
+	}
+	defer sherpa.DeleteOfflineStream(stream)
+	stream.AcceptWaveform(modelSampleRate, samples)
+
+	events := p.tagging.Compute(stream, int32(topK))
+	labels := make([]config.AudioLabel, 0, len(events))
+	for _, e := range events {
+		labels = append(labels, config.AudioLabel{Name: e.Name, Index: e.Index, Score: e.Prob})
+	}
+	return labels, nil
+}
+
+// Warmup 预热Provider
+func (p *TaggingProvider) Warmup() error {
+	// 0.1秒静音
+	_, err := p.Tag(make([]float32, modelSampleRate/10), 1)
+	return err
+}
+
+// Release 释放资源
+func (p *TaggingProvider) Release() error {
+	if p.tagging != nil {
+		sherpa.DeleteAudioTagging(p.tagging)
+		p.tagging = nil
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+)
+
+// minTailSamples 末尾不足该长度（0.1秒）的残余音频不单独作为窗口
+const minTailSamples = modelSampleRate / 10
+
+// Tagger 音频事件标注（如等待音乐、振铃、静音）
+type Tagger struct {
+	pool   *Pool
+	config *config.AudioTaggingConfig
+}
+
+// NewTagger 创建音频标注器
+func NewTagger(cfg *config.AudioTaggingConfig) (*Tagger, error) {
+	return newTagger(cfg, defaultProviderFactory)
+}
+
+// newTagger 使用指定的Provider工厂创建标注器
+func newTagger(cfg *config.AudioTaggingConfig, factory providerFactory) (*Tagger, error) {
+	pool, err := newPool(cfg, cfg.PoolSize, factory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audio tagging pool: %w", err)
+	}
+	return &Tagger{pool: pool, config: cfg}, nil
+}
+
+// Tag 标注PCM16单声道音频（16kHz）。总是返回整段音频的标签，
+// opts.Window>0 时另外按不重叠的时间窗口分别标注
+func (t *Tagger) Tag(ctx context.Context, audio []byte, opts *config.AudioTagOptions) (*config.AudioTagResult, error) {
+	samples := utils.SamplesInt16ToFloat(audio)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("invalid or empty audio data")
+	}
+
+	topK := t.config.TopK
+	var window float64
+	if opts != nil {
+		if opts.TopK > 0 {
+			topK = opts.TopK
+		}
+		window = opts.Window
+	}
+	if topK > config.MaxAudioTagTopK {
+		return nil, fmt.Errorf("top_k must be in [1, %d]", config.MaxAudioTagTopK)
+	}
+	if window != 0 && window < config.MinAudioTagWindow {
+		return nil, fmt.Errorf("window must be at least %gs", config.MinAudioTagWindow)
+	}
+
+	var bounds [][2]int
+	if window > 0 {
+		bounds = windowBounds(len(samples), int(window*modelSampleRate))
+		if len(bounds) > config.MaxAudioTagWindows {
+			return nil, fmt.Errorf("too many windows: %d, max %d", len(bounds), config.MaxAudioTagWindows)
+		}
+	}
+
+	provider, err := t.pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.pool.Put(provider)
+
+	labels, err := provider.Tag(samples, topK)
+	if err != nil {
+		return nil, err
+	}
+	result := &config.AudioTagResult{
+		Duration: seconds(len(samples)),
+		Labels:   labels,
+	}
+
+	for _, b := range bounds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		labels, err := provider.Tag(samples[b[0]:b[1]], topK)
+		if err != nil {
+			return nil, fmt.Errorf("failed to tag window %.2f-%.2fs: %w", seconds(b[0]), seconds(b[1]), err)
+		}
+		result.Windows = append(result.Windows, config.AudioTagWindow{
+			Start:  seconds(b[0]),
+			End:    seconds(b[1]),
+			Labels: labels,
+		})
+	}
+	return result, nil
+}
+
+// windowBounds 把n个采样点按size切分为不重叠的窗口，过短的末尾并入前一个窗口
+func windowBounds(n, size int) [][2]int {
+	if size <= 0 {
+		return nil
+	}
+	var bounds [][2]int
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		if end-start < minTailSamples && len(bounds) > 0 {
+			bounds[len(bounds)-1][1] = end
+			break
+		}
+		bounds = append(bounds, [2]int{start, end})
+	}
+	return bounds
+}
+
+// seconds 采样点数转换为秒（保留毫秒）
+func seconds(n int) float64 {
+	return math.Round(float64(n)/modelSampleRate*1000) / 1000
+}
+
+// GetPoolStats 获取资源池统计信息
+func (t *Tagger) GetPoolStats() map[string]interface{} {
+	return t.pool.GetStats()
+}
+
+// Close 关闭标注器
+func (t *Tagger) Close() error {
+	return t.pool.Close()
+}
@@ -0,0 +1,152 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// mockProvider 按输入长度返回标签的Provider
+type mockProvider struct {
+	released *int32
+}
+
+func (m *mockProvider) Tag(samples []float32, topK int) ([]config.AudioLabel, error) {
+	labels := []config.AudioLabel{
+		{Name: "Music", Index: 137, Score: 0.9},
+		{Name: "Silence", Index: 500, Score: float32(len(samples)) / modelSampleRate},
+	}
+	if topK < len(labels) {
+		labels = labels[:topK]
+	}
+	return labels, nil
+}
+
+func (m *mockProvider) Warmup() error { return nil }
+
+func (m *mockProvider) Release() error {
+	atomic.AddInt32(m.released, 1)
+	return nil
+}
+
+func newMockTagger(t *testing.T, size int) (*Tagger, *int32) {
+	t.Helper()
+	var released int32
+	cfg := &config.AudioTaggingConfig{TopK: 2, PoolSize: size}
+	tagger, err := newTagger(cfg, func(*config.AudioTaggingConfig) (Provider, error) {
+		return &mockProvider{released: &released}, nil
+	})
+	if err != nil {
+		t.Fatalf("newTagger() error = %v", err)
+	}
+	return tagger, &released
+}
+
+func TestWindowBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		size int
+		want [][2]int
+	}{
+		{"exact", 32000, 16000, [][2]int{{0, 16000}, {16000, 32000}}},
+		{"partial tail", 40000, 16000, [][2]int{{0, 16000}, {16000, 32000}, {32000, 40000}}},
+		{"short tail merged", 33000, 16000, [][2]int{{0, 16000}, {16000, 33000}}},
+		{"shorter than window", 8000, 16000, [][2]int{{0, 8000}}},
+		{"no window", 8000, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := windowBounds(tt.n, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("windowBounds(%d, %d) = %v, want %v", tt.n, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagger_Tag(t *testing.T) {
+	tagger, _ := newMockTagger(t, 1)
+	defer tagger.Close()
+
+	audio := make([]byte, 2*modelSampleRate*5/2) // 2.5秒
+	result, err := tagger.Tag(context.Background(), audio, nil)
+	if err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	if result.Duration != 2.5 || len(result.Labels) != 2 || result.Windows != nil {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	result, err = tagger.Tag(context.Background(), audio, &config.AudioTagOptions{TopK: 1, Window: 1})
+	if err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+	if len(result.Labels) != 1 || len(result.Windows) != 3 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	last := result.Windows[2]
+	if last.Start != 2 || last.End != 2.5 || len(last.Labels) != 1 {
+		t.Errorf("Unexpected last window: %+v", last)
+	}
+	if tagger.pool.Available() != 1 {
+		t.Errorf("Expected provider to be returned to pool, available = %d", tagger.pool.Available())
+	}
+}
+
+func TestTagger_TagErrors(t *testing.T) {
+	tagger, _ := newMockTagger(t, 1)
+	defer tagger.Close()
+
+	audio := make([]byte, 2*modelSampleRate)
+	tests := []struct {
+		name  string
+		audio []byte
+		opts  *config.AudioTagOptions
+	}{
+		{"empty audio", nil, nil},
+		{"window too short", audio, &config.AudioTagOptions{Window: 0.1}},
+		{"top_k too large", audio, &config.AudioTagOptions{TopK: config.MaxAudioTagTopK + 1}},
+		{"too many windows", make([]byte, 2*modelSampleRate*(config.MaxAudioTagWindows+1)), &config.AudioTagOptions{Window: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tagger.Tag(context.Background(), tt.audio, tt.opts); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	provider, _ := tagger.pool.Get(context.Background())
+	defer tagger.pool.Put(provider)
+	if _, err := tagger.Tag(ctx, audio, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPool_PutReleasesWhenFull(t *testing.T) {
+	tagger, released := newMockTagger(t, 1)
+
+	var extra int32
+	tagger.pool.Put(&mockProvider{released: &extra})
+	if extra != 1 {
+		t.Errorf("Expected extra provider to be released, got %d", extra)
+	}
+	stats := tagger.GetPoolStats()
+	if stats["total_destroyed"] != 1 || stats["size"] != 1 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+
+	tagger.Close()
+	if *released != 1 {
+		t.Errorf("Expected pooled provider to be released on close, got %d", *released)
+	}
+	if _, err := tagger.pool.Get(context.Background()); err == nil {
+		t.Error("Expected error from closed pool")
+	}
+}
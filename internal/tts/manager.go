@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
@@ -14,12 +15,29 @@ import (
 type Manager struct {
 	pool      *Pool
 	config    *config.TTSModelConfig
+	poolSize  int
+	factory   providerFactory
+	poolMu    sync.RWMutex // 保护pool和config，热切换时替换
+	reloadMu  sync.Mutex   // 串行执行模型热切换
+	model     ModelStatus
+	draining  int32 // 原子操作：等待释放的旧资源池数量
 	stats     *Stats
 	statsMu   sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
 }
 
+// ModelStatus 当前模型状态
+type ModelStatus struct {
+	ModelPath       string     `json:"model_path"`
+	Generation      int        `json:"generation"` // 每次热切换成功后加1
+	LoadedAt        time.Time  `json:"loaded_at"`
+	PoolSize        int        `json:"pool_size"`
+	DrainingPools   int        `json:"draining_pools"` // 等待在途请求结束的旧资源池数量
+	LastReloadAt    *time.Time `json:"last_reload_at,omitempty"`
+	LastReloadError string     `json:"last_reload_error,omitempty"`
+}
+
 // Stats 统计信息
 type Stats struct {
 	TotalRequests      int64
@@ -33,7 +51,12 @@ type Stats struct {
 
 // NewManager 创建TTS管理器
 func NewManager(cfg *config.TTSModelConfig, poolSize int) (*Manager, error) {
-	pool, err := NewPool(cfg, poolSize)
+	return newManager(cfg, poolSize, defaultProviderFactory)
+}
+
+// newManager 使用指定的Provider工厂创建管理器
+func newManager(cfg *config.TTSModelConfig, poolSize int, factory providerFactory) (*Manager, error) {
+	pool, err := newPool(cfg, poolSize, factory)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TTS pool: %w", err)
 	}
@@ -41,8 +64,15 @@ func NewManager(cfg *config.TTSModelConfig, poolSize int) (*Manager, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	manager := &Manager{
-		pool:   pool,
-		config: cfg,
+		pool:     pool,
+		config:   cfg,
+		poolSize: poolSize,
+		factory:  factory,
+		model: ModelStatus{
+			ModelPath: cfg.ModelPath,
+			LoadedAt:  time.Now(),
+			PoolSize:  pool.Available(),
+		},
 		stats: &Stats{
 			LatencyHistory: make([]time.Duration, 0, 1000),
 		},
@@ -67,12 +97,15 @@ func (m *Manager) Synthesize(ctx interface{}, text string, speakerID int, speed
 	} else {
 		poolCtx = context.Background()
 	}
-	provider, err := m.pool.Get(poolCtx)
+	pool := m.acquirePool()
+	defer pool.inflight.Done()
+
+	provider, err := pool.Get(poolCtx)
 	if err != nil {
 		m.recordFailure()
 		return nil, fmt.Errorf("failed to get provider from pool: %w", err)
 	}
-	defer m.pool.Put(provider)
+	defer pool.Put(provider)
 
 	// 执行合成
 	result, err := provider.Synthesize(text, speakerID, speed)
@@ -88,6 +121,102 @@ func (m *Manager) Synthesize(ctx interface{}, text string, speakerID int, speed
 	return result, nil
 }
 
+// acquirePool 获取当前资源池并登记在途请求，调用方结束后需调用pool.inflight.Done()
+func (m *Manager) acquirePool() *Pool {
+	m.poolMu.RLock()
+	defer m.poolMu.RUnlock()
+
+	m.pool.inflight.Add(1)
+	return m.pool
+}
+
+// Reload 热切换模型：在后台加载新模型到新资源池并预热，全部成功后原子切换，
+// 旧资源池在在途请求结束后释放；加载或预热失败时保留当前模型
+func (m *Manager) Reload(cfg *config.TTSModelConfig) error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	logger.Infof("Loading TTS model for hot swap: %s", cfg.ModelPath)
+	pool, err := newPool(cfg, m.poolSize, m.factory)
+	if err == nil && pool.Available() < m.poolSize {
+		// 部分Provider预热失败，视为新模型不可用
+		err = fmt.Errorf("only %d/%d providers warmed up", pool.Available(), m.poolSize)
+		pool.Close()
+	}
+	if err != nil {
+		m.recordReload(err)
+		logger.Errorf("TTS model hot swap failed, keeping current model: %v", err)
+		return fmt.Errorf("failed to load TTS model: %w", err)
+	}
+
+	m.poolMu.Lock()
+	old := m.pool
+	m.pool = pool
+	m.config = cfg
+	m.poolMu.Unlock()
+
+	m.recordReload(nil)
+	logger.Infof("TTS model switched to %s, draining previous pool", cfg.ModelPath)
+
+	// 等待旧资源池的在途请求结束后释放
+	atomic.AddInt32(&m.draining, 1)
+	go func() {
+		defer atomic.AddInt32(&m.draining, -1)
+		if err := old.Drain(); err != nil {
+			logger.Errorf("Failed to release previous TTS pool: %v", err)
+			return
+		}
+		logger.Info("Previous TTS pool released")
+	}()
+
+	return nil
+}
+
+// recordReload 记录热切换结果
+func (m *Manager) recordReload(err error) {
+	m.poolMu.Lock()
+	defer m.poolMu.Unlock()
+
+	now := time.Now()
+	m.model.LastReloadAt = &now
+	if err != nil {
+		m.model.LastReloadError = err.Error()
+		return
+	}
+	m.model.LastReloadError = ""
+	m.model.ModelPath = m.config.ModelPath
+	m.model.Generation++
+	m.model.LoadedAt = now
+	m.model.PoolSize = m.pool.Available()
+}
+
+// GetModelStatus 获取当前模型状态
+func (m *Manager) GetModelStatus() ModelStatus {
+	m.poolMu.RLock()
+	status := m.model
+	m.poolMu.RUnlock()
+
+	status.DrainingPools = int(atomic.LoadInt32(&m.draining))
+	return status
+}
+
+// GetModelConfig 获取当前模型配置的副本
+func (m *Manager) GetModelConfig() *config.TTSModelConfig {
+	m.poolMu.RLock()
+	defer m.poolMu.RUnlock()
+
+	cfg := *m.config
+	return &cfg
+}
+
+// GetProviderConfig 获取当前模型的推理设备配置
+func (m *Manager) GetProviderConfig() config.ProviderConfig {
+	m.poolMu.RLock()
+	defer m.poolMu.RUnlock()
+
+	return m.config.Provider
+}
+
 // recordSuccess 记录成功请求
 func (m *Manager) recordSuccess(latency time.Duration) {
 	m.statsMu.Lock()
@@ -148,14 +277,28 @@ func (m *Manager) GetAvgLatency() interface{} {
 	return m.stats.TotalLatency / time.Duration(m.stats.SuccessfulRequests)
 }
 
+// GetSampleRate 获取合成音频的采样率
+func (m *Manager) GetSampleRate() int {
+	m.poolMu.RLock()
+	defer m.poolMu.RUnlock()
+	return modelSampleRate(m.config.ModelPath)
+}
+
 // GetPoolUsage 获取资源池使用率
 func (m *Manager) GetPoolUsage() float64 {
+	m.poolMu.RLock()
+	defer m.poolMu.RUnlock()
 	return m.pool.GetUsage()
 }
 
 // GetPoolStats 获取资源池统计信息
 func (m *Manager) GetPoolStats() map[string]interface{} {
-	return m.pool.GetStats()
+	m.poolMu.RLock()
+	stats := m.pool.GetStats()
+	m.poolMu.RUnlock()
+
+	stats["model"] = m.GetModelStatus()
+	return stats
 }
 
 // cleanupStats 定期清理统计信息
@@ -185,6 +328,10 @@ func (m *Manager) cleanupStats() {
 // Close 关闭管理器
 func (m *Manager) Close() error {
 	m.cancel()
-	return m.pool.Close()
+
+	m.poolMu.RLock()
+	pool := m.pool
+	m.poolMu.RUnlock()
+	return pool.Close()
 }
 
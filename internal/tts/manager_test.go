@@ -2,9 +2,11 @@ package tts
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
 )
@@ -479,3 +481,120 @@ func TestManager_GetPoolStats(t *testing.T) {
 	}
 }
 
+// swapProvider 用于热切换测试的Provider，可阻塞合成并记录释放
+type swapProvider struct {
+	audio    []byte
+	block    chan struct{}
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (p *swapProvider) Synthesize(text string, speakerID int, speed float32) ([]byte, error) {
+	if p.block != nil {
+		p.started <- struct{}{}
+		<-p.block
+	}
+	return p.audio, nil
+}
+
+func (p *swapProvider) Warmup() error      { return nil }
+func (p *swapProvider) Reset() error       { return nil }
+func (p *swapProvider) GetSampleRate() int { return 24000 }
+
+func (p *swapProvider) Release() error {
+	close(p.released)
+	return nil
+}
+
+func TestManager_ReloadWhileInFlight(t *testing.T) {
+	oldProvider := &swapProvider{
+		audio:    []byte{1},
+		block:    make(chan struct{}),
+		started:  make(chan struct{}, 1),
+		released: make(chan struct{}),
+	}
+	newProvider := &swapProvider{audio: []byte{2}, released: make(chan struct{})}
+
+	factory := func(cfg *config.TTSModelConfig) (Provider, error) {
+		if cfg.ModelPath == "old.onnx" {
+			return oldProvider, nil
+		}
+		return newProvider, nil
+	}
+
+	manager, err := newManager(&config.TTSModelConfig{ModelPath: "old.onnx"}, 1, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	defer manager.Close()
+
+	// 发起一个阻塞在旧模型上的请求
+	done := make(chan []byte, 1)
+	go func() {
+		audio, _ := manager.Synthesize(context.Background(), "你好", 0, 1.0)
+		done <- audio
+	}()
+	<-oldProvider.started
+
+	if err := manager.Reload(&config.TTSModelConfig{ModelPath: "new.onnx"}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	// 新请求立即使用新模型
+	audio, err := manager.Synthesize(context.Background(), "你好", 0, 1.0)
+	if err != nil || len(audio) != 1 || audio[0] != 2 {
+		t.Errorf("Expected new model after reload, got %v, %v", audio, err)
+	}
+
+	// 在途请求结束前旧模型不得释放
+	select {
+	case <-oldProvider.released:
+		t.Fatal("Old provider released while a request was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(oldProvider.block)
+	if audio := <-done; len(audio) != 1 || audio[0] != 1 {
+		t.Errorf("Expected in-flight request to finish on old model, got %v", audio)
+	}
+
+	select {
+	case <-oldProvider.released:
+	case <-time.After(time.Second):
+		t.Fatal("Old provider was not released after in-flight request finished")
+	}
+
+	status := manager.GetModelStatus()
+	if status.ModelPath != "new.onnx" || status.Generation != 1 || status.LastReloadError != "" {
+		t.Errorf("Unexpected model status: %+v", status)
+	}
+}
+
+func TestManager_ReloadFailureKeepsModel(t *testing.T) {
+	factory := func(cfg *config.TTSModelConfig) (Provider, error) {
+		if cfg.ModelPath == "broken.onnx" {
+			return nil, errors.New("model file not found")
+		}
+		return &swapProvider{audio: []byte{1}, released: make(chan struct{})}, nil
+	}
+
+	manager, err := newManager(&config.TTSModelConfig{ModelPath: "old.onnx"}, 2, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	defer manager.Close()
+
+	if err := manager.Reload(&config.TTSModelConfig{ModelPath: "broken.onnx"}); err == nil {
+		t.Fatal("Expected reload of broken model to fail")
+	}
+
+	audio, err := manager.Synthesize(context.Background(), "你好", 0, 1.0)
+	if err != nil || len(audio) != 1 {
+		t.Errorf("Expected old model to keep serving, got %v, %v", audio, err)
+	}
+
+	status := manager.GetModelStatus()
+	if status.ModelPath != "old.onnx" || status.Generation != 0 || status.LastReloadError == "" {
+		t.Errorf("Unexpected model status: %+v", status)
+	}
+}
@@ -19,6 +19,20 @@ type Pool struct {
 	stats       *PoolStats
 	ctx         context.Context
 	cancel      context.CancelFunc
+	factory     providerFactory
+	inflight    sync.WaitGroup // 在途请求，热切换后等待其结束再关闭
+}
+
+// providerFactory 创建Provider的函数（测试时可替换）
+type providerFactory func(cfg *config.TTSModelConfig) (Provider, error)
+
+// defaultProviderFactory 创建sherpa-onnx Provider
+func defaultProviderFactory(cfg *config.TTSModelConfig) (Provider, error) {
+	provider, err := NewTTSProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
 }
 
 // PoolStats 资源池统计信息
@@ -33,6 +47,11 @@ type PoolStats struct {
 
 // NewPool 创建TTS资源池
 func NewPool(cfg *config.TTSModelConfig, size int) (*Pool, error) {
+	return newPool(cfg, size, defaultProviderFactory)
+}
+
+// newPool 使用指定的Provider工厂创建资源池
+func newPool(cfg *config.TTSModelConfig, size int, factory providerFactory) (*Pool, error) {
 	if size <= 0 {
 		size = 5 // TTS默认池大小较小
 	}
@@ -46,8 +65,9 @@ func NewPool(cfg *config.TTSModelConfig, size int) (*Pool, error) {
 		stats: &PoolStats{
 			CurrentActive: 0,
 		},
-		ctx:    ctx,
-		cancel: cancel,
+		ctx:     ctx,
+		cancel:  cancel,
+		factory: factory,
 	}
 
 	// 并行初始化Provider
@@ -60,7 +80,7 @@ func NewPool(cfg *config.TTSModelConfig, size int) (*Pool, error) {
 		go func(index int) {
 			defer wg.Done()
 
-			provider, err := NewTTSProvider(cfg)
+			provider, err := factory(cfg)
 			if err != nil {
 				logger.Warnf("Failed to create TTS provider %d: %v", index, err)
 				return
@@ -135,7 +155,7 @@ func (p *Pool) Get(ctx context.Context) (Provider, error) {
 
 // createTemporaryProvider 创建临时Provider
 func (p *Pool) createTemporaryProvider() (Provider, error) {
-	provider, err := NewTTSProvider(p.config)
+	provider, err := p.factory(p.config)
 	if err != nil {
 		return nil, err
 	}
@@ -174,6 +194,11 @@ func (p *Pool) Put(provider Provider) {
 	}
 }
 
+// Available 获取池中空闲的Provider数量
+func (p *Pool) Available() int {
+	return len(p.providers)
+}
+
 // GetUsage 获取资源池使用率
 func (p *Pool) GetUsage() float64 {
 	p.mu.RLock()
@@ -204,6 +229,12 @@ func (p *Pool) GetStats() map[string]interface{} {
 	}
 }
 
+// Drain 等待在途请求结束后关闭资源池（用于模型热切换后释放旧模型）
+func (p *Pool) Drain() error {
+	p.inflight.Wait()
+	return p.Close()
+}
+
 // Close 关闭资源池
 func (p *Pool) Close() error {
 	p.cancel()
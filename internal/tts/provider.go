@@ -37,6 +37,22 @@ func isVitsModel(modelPath string) bool {
 	       strings.Contains(modelName, "huayan")
 }
 
+// modelType 获取模型类型（"vits" 或 "kokoro"）
+func modelType(modelPath string) string {
+	if isVitsModel(modelPath) {
+		return "vits"
+	}
+	return "kokoro"
+}
+
+// modelSampleRate 根据模型类型获取输出采样率
+func modelSampleRate(modelPath string) int {
+	if isVitsModel(modelPath) {
+		return 22050 // Piper 模型默认采样率
+	}
+	return 24000 // 默认采样率（Kokoro）
+}
+
 // NewTTSProvider 创建TTS Provider
 func NewTTSProvider(cfg *config.TTSModelConfig) (*TTSProvider, error) {
 	// 检查模型文件是否存在
@@ -61,7 +77,7 @@ func NewTTSProvider(cfg *config.TTSModelConfig) (*TTSProvider, error) {
 	}
 	
 	// 构建sherpa-onnx配置
-	sampleRate := 24000 // 默认采样率（Kokoro）
+	sampleRate := modelSampleRate(cfg.ModelPath)
 	
 	// 判断模型类型
 	useVits := isVitsModel(cfg.ModelPath)
@@ -71,8 +87,6 @@ func NewTTSProvider(cfg *config.TTSModelConfig) (*TTSProvider, error) {
 	
 	if useVits {
 		// VITS/Piper 模型配置
-		sampleRate = 22050 // Piper 模型默认采样率
-		
 		vitsConfig = sherpa.OfflineTtsVitsModelConfig{
 			Model:   cfg.ModelPath,
 			Tokens:  cfg.TokensPath,
@@ -0,0 +1,176 @@
+package tts
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+// ErrModelNotFound 请求的模型不存在
+var ErrModelNotFound = errors.New("model not found")
+
+// ModelInfo 已加载模型的信息
+type ModelInfo struct {
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Languages  []string               `json:"languages"`
+	SampleRate int                    `json:"sample_rate"`
+	Provider   string                 `json:"provider"`
+	Default    bool                   `json:"default"`
+	PoolUsage  float64                `json:"pool_usage"`
+	Pool       map[string]interface{} `json:"pool"`
+}
+
+// Registry 按名称管理多个合成模型，每个模型有独立的资源池
+type Registry struct {
+	mu          sync.RWMutex
+	managers    map[string]*Manager
+	names       []string // 注册顺序
+	defaultName string
+}
+
+// NewRegistry 创建模型注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		managers: make(map[string]*Manager),
+	}
+}
+
+// NewRegistryFromConfig 按配置创建全部合成模型，任一模型加载失败时释放已创建的模型
+func NewRegistryFromConfig(models []*config.TTSModelConfig, defaultName string) (*Registry, error) {
+	r := NewRegistry()
+	for _, cfg := range models {
+		manager, err := NewManager(cfg, cfg.PoolSize)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("failed to load TTS model %s: %w", cfg.Name, err)
+		}
+		if err := r.Register(cfg.Name, manager); err != nil {
+			manager.Close()
+			r.Close()
+			return nil, err
+		}
+	}
+	if defaultName != "" {
+		if err := r.SetDefault(defaultName); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register 注册模型，第一个注册的模型为默认模型
+func (r *Registry) Register(name string, manager *Manager) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.managers[name]; exists {
+		return fmt.Errorf("TTS model %s already registered", name)
+	}
+	r.managers[name] = manager
+	r.names = append(r.names, name)
+	if r.defaultName == "" {
+		r.defaultName = name
+	}
+	return nil
+}
+
+// SetDefault 设置默认模型
+func (r *Registry) SetDefault(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.managers[name]; !exists {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, name)
+	}
+	r.defaultName = name
+	return nil
+}
+
+// Get 按名称获取模型，名称为空时返回默认模型
+func (r *Registry) Get(name string) (*Manager, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if name == "" {
+		name = r.defaultName
+	}
+	manager, exists := r.managers[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, name)
+	}
+	return manager, nil
+}
+
+// Default 获取默认模型，未注册任何模型时返回nil
+func (r *Registry) Default() *Manager {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.managers[r.defaultName]
+}
+
+// DefaultName 获取默认模型名称
+func (r *Registry) DefaultName() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
+
+// Names 按注册顺序返回模型名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.names))
+	copy(names, r.names)
+	return names
+}
+
+// List 按注册顺序返回全部模型的信息
+func (r *Registry) List() []ModelInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]ModelInfo, 0, len(r.names))
+	for _, name := range r.names {
+		manager := r.managers[name]
+		cfg := manager.GetModelConfig()
+
+		languages := cfg.Languages
+		if languages == nil {
+			languages = []string{}
+		}
+
+		infos = append(infos, ModelInfo{
+			Name:       name,
+			Type:       modelType(cfg.ModelPath),
+			Languages:  languages,
+			SampleRate: manager.GetSampleRate(),
+			Provider:   cfg.Provider.Provider,
+			Default:    name == r.defaultName,
+			PoolUsage:  manager.GetPoolUsage(),
+			Pool:       manager.GetPoolStats(),
+		})
+	}
+	return infos
+}
+
+// Close 释放全部模型
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for _, name := range r.names {
+		if err := r.managers[name].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	r.managers = make(map[string]*Manager)
+	r.names = nil
+	r.defaultName = ""
+	return firstErr
+}
@@ -0,0 +1,46 @@
+package tts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+)
+
+func TestRegistry(t *testing.T) {
+	factory := func(cfg *config.TTSModelConfig) (Provider, error) {
+		return &swapProvider{audio: []byte{1}, released: make(chan struct{})}, nil
+	}
+
+	r := NewRegistry()
+	defer r.Close()
+
+	kokoro, err := newManager(&config.TTSModelConfig{ModelPath: "kokoro-v1.onnx"}, 1, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	vits, err := newManager(&config.TTSModelConfig{ModelPath: "vits-zh-aishell3.onnx", Languages: []string{"zh"}}, 1, factory)
+	if err != nil {
+		t.Fatalf("newManager() error = %v", err)
+	}
+	r.Register("kokoro", kokoro)
+	r.Register("aishell3", vits)
+
+	if m, err := r.Get(""); err != nil || m != kokoro {
+		t.Errorf("Expected default model kokoro, got %v, %v", m, err)
+	}
+	if _, err := r.Get("missing"); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+
+	infos := r.List()
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 models, got %d", len(infos))
+	}
+	if infos[0].Type != "kokoro" || infos[0].SampleRate != 24000 || !infos[0].Default {
+		t.Errorf("Unexpected info for kokoro: %+v", infos[0])
+	}
+	if infos[1].Type != "vits" || infos[1].Languages[0] != "zh" || infos[1].Default {
+		t.Errorf("Unexpected info for aishell3: %+v", infos[1])
+	}
+}
@@ -0,0 +1,148 @@
+package vad
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zhangjun/AeroSpeech-ONNX/internal/common/config"
+	"github.com/zhangjun/AeroSpeech-ONNX/pkg/utils"
+
+	sherpa "github.com/k2-fsa/sherpa-onnx-go/sherpa_onnx"
+)
+
+// modelSampleRate VAD模型的输入采样率
+const modelSampleRate = 16000
+
+// bufferSeconds 单个检测流缓存的最长音频（秒），需要大于max_speech_duration
+const bufferSeconds = 60
+
+// Detector 基于sherpa-onnx的语音活动检测（silero或ten-vad），每个会话创建独立的检测流
+type Detector struct {
+	config sherpa.VadModelConfig
+}
+
+// NewDetector 创建语音活动检测器，创建一个检测流验证模型可用
+func NewDetector(cfg *config.VADConfig) (*Detector, error) {
+	if _, err := os.Stat(cfg.ModelPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("VAD model file not found: %s", cfg.ModelPath)
+	}
+
+	model := sherpa.SileroVadModelConfig{
+		Model:              cfg.ModelPath,
+		Threshold:          cfg.Threshold,
+		MinSilenceDuration: cfg.MinSilenceDuration,
+		MinSpeechDuration:  cfg.MinSpeechDuration,
+		WindowSize:         512,
+		MaxSpeechDuration:  cfg.MaxSpeechDuration,
+	}
+	d := &Detector{config: sherpa.VadModelConfig{SampleRate: modelSampleRate, NumThreads: 1, Provider: "cpu"}}
+	switch cfg.Provider {
+	case "silero":
+		d.config.SileroVad = model
+	case "ten":
+		model.WindowSize = 256
+		d.config.TenVad = sherpa.TenVadModelConfig(model)
+	default:
+		return nil, fmt.Errorf("unsupported VAD type: %s", cfg.Provider)
+	}
+
+	stream, err := d.NewStream(nil)
+	if err != nil {
+		return nil, err
+	}
+	stream.Close()
+	return d, nil
+}
+
+// StreamOptions 单个检测流的参数，为0的字段使用检测器的配置
+type StreamOptions struct {
+	Threshold          float32
+	MinSilenceDuration float32
+	MinSpeechDuration  float32
+	MaxSpeechDuration  float32
+}
+
+// SampleRate 输入音频采样率
+func (d *Detector) SampleRate() int {
+	return modelSampleRate
+}
+
+// NewStream 创建检测流，opts为nil时使用检测器的配置
+func (d *Detector) NewStream(opts *StreamOptions) (*Stream, error) {
+	cfg := d.config
+	if opts != nil {
+		if cfg.SileroVad.Model != "" {
+			cfg.SileroVad = applyStreamOptions(cfg.SileroVad, opts)
+		} else {
+			cfg.TenVad = sherpa.TenVadModelConfig(applyStreamOptions(sherpa.SileroVadModelConfig(cfg.TenVad), opts))
+		}
+	}
+	vad := sherpa.NewVoiceActivityDetector(&cfg, bufferSeconds)
+	if vad == nil {
+		return nil, fmt.Errorf("failed to create voice activity detector")
+	}
+	return &Stream{vad: vad}, nil
+}
+
+// applyStreamOptions 用检测流参数覆盖模型配置
+func applyStreamOptions(m sherpa.SileroVadModelConfig, opts *StreamOptions) sherpa.SileroVadModelConfig {
+	if opts.Threshold > 0 {
+		m.Threshold = opts.Threshold
+	}
+	if opts.MinSilenceDuration > 0 {
+		m.MinSilenceDuration = opts.MinSilenceDuration
+	}
+	if opts.MinSpeechDuration > 0 {
+		m.MinSpeechDuration = opts.MinSpeechDuration
+	}
+	if opts.MaxSpeechDuration > 0 {
+		m.MaxSpeechDuration = opts.MaxSpeechDuration
+	}
+	return m
+}
+
+// Stream 单个会话的语音活动检测流，不支持并发调用
+type Stream struct {
+	vad *sherpa.VoiceActivityDetector
+}
+
+// Accept 输入PCM16单声道16kHz音频，返回本段音频中结束的语句（PCM16）
+func (s *Stream) Accept(audio []byte) [][]byte {
+	samples := utils.SamplesInt16ToFloat(audio)
+	if len(samples) == 0 {
+		return nil
+	}
+	s.vad.AcceptWaveform(samples)
+	return s.drain()
+}
+
+// Speaking 当前是否处于语音中
+func (s *Stream) Speaking() bool {
+	return s.vad.IsSpeech()
+}
+
+// Flush 结束当前语句并返回尚未取出的语句，之后重新开始检测
+func (s *Stream) Flush() [][]byte {
+	s.vad.Flush()
+	segments := s.drain()
+	s.vad.Reset()
+	return segments
+}
+
+// drain 取出已结束的语句
+func (s *Stream) drain() [][]byte {
+	var segments [][]byte
+	for !s.vad.IsEmpty() {
+		segments = append(segments, utils.SamplesFloatToInt16(s.vad.Front().Samples))
+		s.vad.Pop()
+	}
+	return segments
+}
+
+// Close 释放检测流
+func (s *Stream) Close() {
+	if s.vad != nil {
+		sherpa.DeleteVoiceActivityDetector(s.vad)
+		s.vad = nil
+	}
+}
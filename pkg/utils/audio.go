@@ -2,6 +2,8 @@ package utils
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math"
 )
 
@@ -73,3 +75,149 @@ func ResampleAudio(samples []float32, fromRate, toRate int) []float32 {
 	return resampled
 }
 
+
+// StripWAVHeader 去除WAV文件头，返回PCM数据部分；非WAV数据原样返回
+func StripWAVHeader(data []byte) []byte {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return data
+	}
+
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkID := string(data[offset : offset+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		offset += 8
+		if chunkID == "data" {
+			end := offset + chunkSize
+			if chunkSize < 0 || end > len(data) {
+				end = len(data)
+			}
+			return data[offset:end]
+		}
+		// chunk按偶数字节对齐
+		offset += chunkSize + chunkSize%2
+	}
+
+	return data
+}
+
+// EncodeWAV 为16位PCM数据添加WAV文件头
+func EncodeWAV(pcm []byte, sampleRate, channels int) []byte {
+	data := make([]byte, 0, 44+len(pcm))
+	data = append(data, WAVHeader(len(pcm), sampleRate, channels)...)
+	return append(data, pcm...)
+}
+
+// WAVHeader 生成16位PCM的44字节WAV文件头，dataSize为PCM数据字节数
+func WAVHeader(dataSize, sampleRate, channels int) []byte {
+	if channels <= 0 {
+		channels = 1
+	}
+	const bitsPerSample = 16
+	blockAlign := channels * bitsPerSample / 8
+	byteRate := sampleRate * blockAlign
+
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(36+dataSize))
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16) // fmt块大小
+	binary.LittleEndian.PutUint16(header[20:22], 1)  // PCM格式
+	binary.LittleEndian.PutUint16(header[22:24], uint16(channels))
+	binary.LittleEndian.PutUint32(header[24:28], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:32], uint32(byteRate))
+	binary.LittleEndian.PutUint16(header[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(header[34:36], bitsPerSample)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], uint32(dataSize))
+
+	return header
+}
+
+// ErrUnsupportedWAV WAV文件的编码不受支持（只支持16位PCM）
+var ErrUnsupportedWAV = errors.New("unsupported wav format")
+
+// WAV编码标识
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+)
+
+// WAVToPCM16 将上传的音频转换为sampleRate采样率的单声道16位PCM。
+// 非WAV数据视为已是该格式原样返回；16位PCM的WAV按需混合为单声道并重采样，
+// 其他编码（8/24/32位、浮点、压缩格式）或缺少fmt/data块时返回ErrUnsupportedWAV
+func WAVToPCM16(data []byte, sampleRate int) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return data, nil
+	}
+
+	var format, channels, bits int
+	var rate int
+	var pcm []byte
+	hasFormat, hasData := false, false
+	offset := 12
+	for offset+8 <= len(data) && !hasData {
+		chunkID := string(data[offset : offset+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		offset += 8
+		end := offset + chunkSize
+		if chunkSize < 0 || end > len(data) {
+			end = len(data)
+		}
+		switch chunkID {
+		case "fmt ":
+			if end-offset < 16 {
+				return nil, fmt.Errorf("%w: invalid fmt chunk", ErrUnsupportedWAV)
+			}
+			chunk := data[offset:end]
+			format = int(binary.LittleEndian.Uint16(chunk[0:2]))
+			channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+			rate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			bits = int(binary.LittleEndian.Uint16(chunk[14:16]))
+			if format == wavFormatExtensible && len(chunk) >= 26 {
+				// WAVE_FORMAT_EXTENSIBLE的子格式GUID前两个字节为实际编码
+				format = int(binary.LittleEndian.Uint16(chunk[24:26]))
+			}
+			hasFormat = true
+		case "data":
+			pcm = data[offset:end]
+			hasData = true
+		}
+		// chunk按偶数字节对齐
+		offset += chunkSize + chunkSize%2
+	}
+	if !hasFormat || !hasData {
+		return nil, fmt.Errorf("%w: missing fmt or data chunk", ErrUnsupportedWAV)
+	}
+	if format != wavFormatPCM || bits != 16 {
+		return nil, fmt.Errorf("%w: only 16-bit PCM is supported, got format %d with %d bits", ErrUnsupportedWAV, format, bits)
+	}
+	if channels < 1 || rate <= 0 {
+		return nil, fmt.Errorf("%w: invalid channels %d or sample rate %d", ErrUnsupportedWAV, channels, rate)
+	}
+
+	if channels > 1 {
+		pcm = downmixPCM16(pcm, channels)
+	}
+	if rate != sampleRate {
+		pcm = SamplesFloatToInt16(ResampleAudio(SamplesInt16ToFloat(pcm[:len(pcm)/2*2]), rate, sampleRate))
+	}
+	return pcm, nil
+}
+
+// downmixPCM16 将多声道16位PCM混合为单声道（各声道取平均）
+func downmixPCM16(pcm []byte, channels int) []byte {
+	frameSize := channels * 2
+	frames := len(pcm) / frameSize
+	mono := make([]byte, frames*2)
+	for i := 0; i < frames; i++ {
+		sum := 0
+		for ch := 0; ch < channels; ch++ {
+			offset := i*frameSize + ch*2
+			sum += int(int16(binary.LittleEndian.Uint16(pcm[offset : offset+2])))
+		}
+		binary.LittleEndian.PutUint16(mono[i*2:i*2+2], uint16(int16(sum/channels)))
+	}
+	return mono
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/binary"
+	"errors"
 	"math"
 	"testing"
 )
@@ -227,3 +228,78 @@ func TestSamplesFloatToInt16Clamping(t *testing.T) {
 	}
 }
 
+
+func TestStripWAVHeader(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4}
+
+	// 构造带LIST块的WAV文件
+	wav := []byte("RIFF\x00\x00\x00\x00WAVE")
+	wav = append(wav, []byte("LIST\x03\x00\x00\x00abc\x00")...)
+	wav = append(wav, []byte("data\x04\x00\x00\x00")...)
+	wav = append(wav, pcm...)
+
+	result := StripWAVHeader(wav)
+	if string(result) != string(pcm) {
+		t.Errorf("Expected PCM payload %v, got %v", pcm, result)
+	}
+
+	// 非WAV数据原样返回
+	if got := StripWAVHeader(pcm); string(got) != string(pcm) {
+		t.Errorf("Expected raw data to be returned unchanged, got %v", got)
+	}
+}
+
+func TestEncodeWAV(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4, 5, 6}
+	wav := EncodeWAV(pcm, 24000, 1)
+
+	if len(wav) != 44+len(pcm) {
+		t.Fatalf("Expected %d bytes, got %d", 44+len(pcm), len(wav))
+	}
+	if string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		t.Error("Expected RIFF/WAVE header")
+	}
+	if rate := binary.LittleEndian.Uint32(wav[24:28]); rate != 24000 {
+		t.Errorf("Expected sample rate 24000, got %d", rate)
+	}
+	if got := StripWAVHeader(wav); string(got) != string(pcm) {
+		t.Errorf("Expected round trip to return PCM, got %v", got)
+	}
+}
+
+func TestWAVToPCM16(t *testing.T) {
+	pcm := make([]byte, 3200) // 16kHz下0.1秒
+
+	// 16kHz单声道WAV去除文件头
+	got, err := WAVToPCM16(EncodeWAV(pcm, 16000, 1), 16000)
+	if err != nil || len(got) != len(pcm) {
+		t.Errorf("Expected %d bytes of PCM, got %d (err %v)", len(pcm), len(got), err)
+	}
+
+	// 8kHz重采样到16kHz
+	got, err = WAVToPCM16(EncodeWAV(pcm, 8000, 1), 16000)
+	if err != nil || len(got) != 2*len(pcm) {
+		t.Errorf("Expected %d bytes after resampling, got %d (err %v)", 2*len(pcm), len(got), err)
+	}
+
+	// 立体声混合为单声道
+	stereo := []byte{0x10, 0x00, 0x30, 0x00, 0xf0, 0xff, 0xf0, 0xff}
+	got, err = WAVToPCM16(EncodeWAV(stereo, 16000, 2), 16000)
+	want := []byte{0x20, 0x00, 0xf0, 0xff}
+	if err != nil || string(got) != string(want) {
+		t.Errorf("Expected downmixed PCM %v, got %v (err %v)", want, got, err)
+	}
+
+	// 非WAV数据原样返回
+	if got, err := WAVToPCM16(pcm, 16000); err != nil || len(got) != len(pcm) {
+		t.Errorf("Expected raw PCM to be returned unchanged, got %d bytes (err %v)", len(got), err)
+	}
+
+	// 32位浮点WAV不受支持
+	float := EncodeWAV(pcm, 16000, 1)
+	binary.LittleEndian.PutUint16(float[20:22], 3)
+	binary.LittleEndian.PutUint16(float[34:36], 32)
+	if _, err := WAVToPCM16(float, 16000); !errors.Is(err, ErrUnsupportedWAV) {
+		t.Errorf("Expected ErrUnsupportedWAV for float WAV, got %v", err)
+	}
+}
@@ -2,7 +2,6 @@ package utils
 
 import (
 	"fmt"
-	"os"
 )
 
 // ErrorCode 错误码
@@ -64,8 +63,3 @@ func WrapError(code ErrorCode, message string, err error) *AppError {
 	}
 }
 
-// ReadFile 读取文件（工具函数）
-func ReadFile(filePath string) ([]byte, error) {
-	return os.ReadFile(filePath)
-}
-
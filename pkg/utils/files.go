@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathOutsideDir 路径超出允许的目录
+var ErrPathOutsideDir = fmt.Errorf("path is outside the allowed directory")
+
+// ErrFileTooLarge 文件超过大小限制
+var ErrFileTooLarge = fmt.Errorf("file exceeds the size limit")
+
+// ResolveInDir 将相对路径解析为root目录内的绝对路径
+// 拒绝绝对路径、".."越界以及指向目录外的符号链接
+func ResolveInDir(root, name string) (string, error) {
+	if root == "" {
+		return "", fmt.Errorf("root directory is not configured")
+	}
+	if name == "" || filepath.IsAbs(name) {
+		return "", ErrPathOutsideDir
+	}
+
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve root directory: %w", err)
+	}
+	rootReal, err := filepath.EvalSymlinks(rootAbs)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve root directory: %w", err)
+	}
+
+	joined := filepath.Join(rootReal, name)
+	if !isWithinDir(rootReal, joined) {
+		return "", ErrPathOutsideDir
+	}
+
+	// 解析符号链接后再次检查
+	real, err := filepath.EvalSymlinks(joined)
+	if err != nil {
+		return "", err
+	}
+	if !isWithinDir(rootReal, real) {
+		return "", ErrPathOutsideDir
+	}
+
+	return real, nil
+}
+
+// isWithinDir 检查path是否位于dir内
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && rel != "."
+}
+
+// ReadFileInDir 读取root目录内的文件，maxSize大于0时限制文件大小
+func ReadFileInDir(root, name string, maxSize int64) ([]byte, error) {
+	path, err := ResolveInDir(root, name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("not a regular file")
+	}
+	if maxSize > 0 && info.Size() > maxSize {
+		return nil, fmt.Errorf("%w: %d > %d bytes", ErrFileTooLarge, info.Size(), maxSize)
+	}
+
+	return io.ReadAll(file)
+}
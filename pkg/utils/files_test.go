@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveInDir(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "a.wav"), []byte("audio"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.wav")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"relative file", "sub/a.wav", false},
+		{"cleaned relative file", "sub/../sub/a.wav", false},
+		{"absolute path", filepath.Join(root, "sub", "a.wav"), true},
+		{"parent escape", "../" + filepath.Base(outside) + "/secret.txt", true},
+		{"symlink escape", "link.wav", true},
+		{"root itself", ".", true},
+		{"empty", "", true},
+		{"missing file", "sub/missing.wav", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveInDir(root, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveInDir(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadFileInDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.wav"), []byte("audio data"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	data, err := ReadFileInDir(root, "a.wav", 0)
+	if err != nil {
+		t.Fatalf("ReadFileInDir() error = %v", err)
+	}
+	if string(data) != "audio data" {
+		t.Errorf("Unexpected content: %q", data)
+	}
+
+	if _, err := ReadFileInDir(root, "a.wav", 4); err == nil {
+		t.Error("Expected error for file exceeding size limit")
+	}
+
+	if _, err := ReadFileInDir("", "a.wav", 0); err == nil {
+		t.Error("Expected error when root is not configured")
+	}
+}